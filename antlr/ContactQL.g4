@@ -11,6 +11,9 @@ LPAREN: '(';
 RPAREN: ')';
 AND: [Aa][Nn][Dd];
 OR: [Oo][Rr];
+NOT: [Nn][Oo][Tt];
+IN: [Ii][Nn];
+COMMA: ',';
 COMPARATOR: (
 		'='
 		| '!='
@@ -43,11 +46,14 @@ ERROR: .;
 parse: expression EOF;
 
 expression:
-	expression AND expression	# combinationAnd
-	| expression expression		# combinationImpicitAnd
-	| expression OR expression	# combinationOr
-	| LPAREN expression RPAREN	# expressionGrouping
-	| TEXT COMPARATOR literal	# condition
-	| literal					# implicitCondition;
-
-literal: TEXT # textLiteral | STRING # stringLiteral;
\ No newline at end of file
+	NOT expression									# negation
+	| expression AND expression						# combinationAnd
+	| expression expression							# combinationImpicitAnd
+	| expression OR expression						# combinationOr
+	| LPAREN expression RPAREN						# expressionGrouping
+	| TEXT IN LPAREN literal (COMMA literal)* RPAREN	# inCondition
+	| TEXT COMPARATOR literal						# condition
+	| literal										# implicitCondition;
+
+// keywords are only reserved where they're expected so they can still be used as values
+literal: (TEXT | NOT | IN) # textLiteral | STRING # stringLiteral;
\ No newline at end of file
@@ -0,0 +1,298 @@
+// Package directory reads and writes assets as a directory tree, with a file per asset type and a file per flow, which
+// is easier to review and merge than a single assets file.
+//
+//   channels.json           -> a JSON array of channels
+//   fields.json             -> a JSON array of fields
+//   ...
+//   flows/<uuid>.json       -> a flow definition
+//   locations/<name>.json   -> a location hierarchy
+//
+// Files for asset types which aren't used can be omitted.
+package directory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	flowsDir     = "flows"
+	locationsDir = "locations"
+)
+
+// the asset types which are stored as a single file, with their keys in the static assets format
+var listFiles = []struct {
+	key     string
+	file    string
+	newItem func() interface{}
+}{
+	{"channels", "channels.json", func() interface{} { return &static.Channel{} }},
+	{"classifiers", "classifiers.json", func() interface{} { return &static.Classifier{} }},
+	{"externalServices", "external_services.json", func() interface{} { return &static.ExternalService{} }},
+	{"fields", "fields.json", func() interface{} { return &static.Field{} }},
+	{"globals", "globals.json", func() interface{} { return &static.Global{} }},
+	{"groups", "groups.json", func() interface{} { return &static.Group{} }},
+	{"labels", "labels.json", func() interface{} { return &static.Label{} }},
+	{"msgCatalogs", "msg_catalogs.json", func() interface{} { return &static.MsgCatalog{} }},
+	{"resthooks", "resthooks.json", func() interface{} { return &static.Resthook{} }},
+	{"schedules", "schedules.json", func() interface{} { return &static.Schedule{} }},
+	{"templates", "templates.json", func() interface{} { return &static.Template{} }},
+	{"ticketers", "ticketers.json", func() interface{} { return &static.Ticketer{} }},
+	{"topics", "topics.json", func() interface{} { return &static.Topic{} }},
+	{"users", "users.json", func() interface{} { return &static.User{} }},
+}
+
+// LoadSource loads a static source from the given assets directory, migrating flows to the latest spec version
+func LoadSource(path string, config *migrations.Config) (*static.StaticSource, error) {
+	data, err := ReadAssets(path, config)
+	if err != nil {
+		return nil, err
+	}
+	return static.NewSource(data)
+}
+
+// ReadAssets reads the given assets directory into a single assets file, migrating flows to the latest spec version.
+// Errors include the path of the file, relative to the directory, which caused them.
+func ReadAssets(path string, config *migrations.Config) (json.RawMessage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading assets directory '%s'", path)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("'%s' is not a directory", path)
+	}
+
+	if err := checkForUnknownFiles(path); err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]interface{})
+
+	for _, lf := range listFiles {
+		data, err := os.ReadFile(filepath.Join(path, lf.file))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "error reading '%s'", lf.file)
+		}
+
+		items := make([]json.RawMessage, 0)
+		if err := jsonx.Unmarshal(data, &items); err != nil {
+			return nil, errors.Wrapf(err, "error reading '%s'", lf.file)
+		}
+
+		for i, item := range items {
+			if err := utils.UnmarshalAndValidate(item, lf.newItem()); err != nil {
+				return nil, errors.Wrapf(err, "error reading '%s[%d]'", lf.file, i)
+			}
+		}
+
+		all[lf.key] = items
+	}
+
+	flows, err := readDir(path, flowsDir, func(data []byte) (json.RawMessage, error) {
+		migrated, err := migrations.MigrateToLatest(data, config)
+		if err != nil {
+			return nil, err
+		}
+		return migrated, utils.UnmarshalAndValidate(migrated, &static.Flow{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(flows) > 0 {
+		all["flows"] = flows
+	}
+
+	locations, err := readDir(path, locationsDir, func(data []byte) (json.RawMessage, error) {
+		return data, jsonx.Unmarshal(data, &envs.LocationHierarchy{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) > 0 {
+		all["locations"] = locations
+	}
+
+	return jsonx.Marshal(all)
+}
+
+// reads all the JSON files in the given subdirectory, ordered by name
+func readDir(base, dir string, read func([]byte) (json.RawMessage, error)) ([]json.RawMessage, error) {
+	files, err := filepath.Glob(filepath.Join(base, dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	items := make([]json.RawMessage, 0, len(files))
+	for _, file := range files {
+		rel := filepath.Join(dir, filepath.Base(file))
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading '%s'", rel)
+		}
+
+		item, err := read(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading '%s'", rel)
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// checks for JSON files which aren't part of the layout, which are probably mistakes like misspelled names
+func checkForUnknownFiles(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return errors.Wrapf(err, "error reading assets directory '%s'", path)
+	}
+
+	known := make(map[string]bool, len(listFiles))
+	for _, lf := range listFiles {
+		known[lf.file] = true
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") && !known[e.Name()] {
+			return errors.Errorf("unrecognized assets file '%s'", e.Name())
+		}
+	}
+	return nil
+}
+
+// WriteAssets writes the given single file assets as an assets directory at the given path, which is created if it
+// doesn't exist. Any asset files already in the directory are replaced, so that assets which have been removed don't
+// come back when the directory is read again, but other files are left alone. Flows are written as is, i.e. they
+// aren't migrated.
+func WriteAssets(data json.RawMessage, path string) error {
+	all := make(map[string][]json.RawMessage)
+	if err := jsonx.Unmarshal(data, &all); err != nil {
+		return errors.Wrap(err, "unable to read assets")
+	}
+
+	// check that we know where to put everything so nothing is lost
+	known := map[string]bool{"flows": true, "locations": true}
+	for _, lf := range listFiles {
+		known[lf.key] = true
+	}
+	for key := range all {
+		if !known[key] {
+			return errors.Errorf("unrecognized asset type '%s'", key)
+		}
+	}
+
+	// work out all the files to write before touching the directory, so that nothing is removed if we can't write them
+	files := make(map[string]json.RawMessage)
+	addFile := func(rel string, item json.RawMessage) error {
+		if _, exists := files[rel]; exists {
+			return errors.Errorf("multiple assets would be written to '%s'", rel)
+		}
+		files[rel] = item
+		return nil
+	}
+
+	for _, lf := range listFiles {
+		if items := all[lf.key]; len(items) > 0 {
+			data, err := jsonx.Marshal(items)
+			if err != nil {
+				return err
+			}
+			files[lf.file] = data
+		}
+	}
+
+	for _, item := range all["flows"] {
+		flow := &static.Flow{}
+		if err := jsonx.Unmarshal(item, flow); err != nil {
+			return errors.Wrap(err, "unable to read flow")
+		}
+
+		if err := addFile(filepath.Join(flowsDir, string(flow.UUID())+".json"), item); err != nil {
+			return err
+		}
+	}
+
+	for i, item := range all["locations"] {
+		header := &struct {
+			Name string `json:"name"`
+		}{}
+		if err := jsonx.Unmarshal(item, header); err != nil {
+			return errors.Wrap(err, "unable to read location hierarchy")
+		}
+
+		name := utils.Snakify(header.Name)
+		if name == "" {
+			name = fmt.Sprintf("hierarchy_%d", i+1)
+		}
+
+		if err := addFile(filepath.Join(locationsDir, name+".json"), item); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	if err := removeAssetFiles(path); err != nil {
+		return err
+	}
+
+	for rel, item := range files {
+		if err := writeJSON(filepath.Join(path, rel), item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removes any files in the given assets directory which are part of the layout
+func removeAssetFiles(path string) error {
+	existing := make([]string, 0, len(listFiles))
+	for _, lf := range listFiles {
+		existing = append(existing, filepath.Join(path, lf.file))
+	}
+	for _, dir := range []string{flowsDir, locationsDir} {
+		inDir, err := filepath.Glob(filepath.Join(path, dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		existing = append(existing, inDir...)
+	}
+
+	for _, file := range existing {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "error removing '%s'", file)
+		}
+	}
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := jsonx.MarshalPretty(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,166 @@
+package directory_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets/directory"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSource(t *testing.T) {
+	source, err := directory.LoadSource("testdata/org", nil)
+	require.NoError(t, err)
+
+	fields, _ := source.Fields()
+	assert.Equal(t, 1, len(fields))
+	groups, _ := source.Groups()
+	assert.Equal(t, 2, len(groups))
+	locations, _ := source.Locations()
+	assert.Equal(t, 1, len(locations))
+
+	// flows are migrated to the latest spec version
+	flow, err := source.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+	assert.Equal(t, "Two Questions", flow.Name())
+
+	specVersion, _ := jsonparser.GetString(flow.Definition(), "spec_version")
+	assert.Equal(t, definition.CurrentSpecVersion.String(), specVersion)
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Males", sa.Groups().Get("4f1f98fc-27a7-4a69-bbdb-24744ba739a9").Name())
+
+	_, err = directory.LoadSource("testdata/xxx", nil)
+	assert.EqualError(t, err, "error reading assets directory 'testdata/xxx': stat testdata/xxx: no such file or directory")
+
+	_, err = directory.LoadSource("testdata/org/groups.json", nil)
+	assert.EqualError(t, err, "'testdata/org/groups.json' is not a directory")
+}
+
+func TestLoadSourceErrors(t *testing.T) {
+	tcs := []struct {
+		files map[string]string
+		err   string
+	}{
+		{
+			files: map[string]string{"fields.json": `{"key": "age"}`},
+			err:   "error reading 'fields.json': json: cannot unmarshal object",
+		},
+		{
+			files: map[string]string{"fields.json": `[{"uuid": "f1b5aea6-6586-41c7-9020-1a6326cc6565", "key": "age", "name": "Age", "type": "number"}, {"uuid": "d66a7823-eada-40e5-9a3a-57239d4690bf", "name": "Gender"}]`},
+			err:   "error reading 'fields.json[1]': field 'key' is required, field 'type' is required",
+		},
+		{
+			files: map[string]string{"fileds.json": `[]`},
+			err:   "unrecognized assets file 'fileds.json'",
+		},
+		{
+			files: map[string]string{"flows/registration.json": `{"name": "Registration", "spec_version": "13.1.0"}`},
+			err:   "error reading 'flows/registration.json': unable to read flow header: field 'uuid' is required",
+		},
+		{
+			files: map[string]string{"flows/registration.json": `{"uuid": "xyz", "name": "Registration", "spec_version": "13.0"}`},
+			err:   "error reading 'flows/registration.json': unable to read flow header: field 'uuid' must be a valid UUID4",
+		},
+		{
+			files: map[string]string{"locations/rwanda.json": `[]`},
+			err:   "error reading 'locations/rwanda.json': json: cannot unmarshal array",
+		},
+	}
+
+	for _, tc := range tcs {
+		dir := t.TempDir()
+
+		for name, content := range tc.files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+
+		// some errors come from the JSON decoder so we only check their start
+		_, err := directory.LoadSource(dir, &migrations.Config{})
+		if assert.Error(t, err) {
+			assert.True(t, strings.HasPrefix(err.Error(), tc.err), "error mismatch for %v, got: %s", tc.files, err)
+		}
+	}
+}
+
+func TestWriteAssets(t *testing.T) {
+	data, err := directory.ReadAssets("testdata/org", nil)
+	require.NoError(t, err)
+
+	// write back out to a new directory and read again
+	dir := t.TempDir()
+	require.NoError(t, directory.WriteAssets(data, dir))
+
+	for _, path := range []string{"channels.json", "fields.json", "groups.json", "flows/615b8a0f-588c-4d20-a05f-363b0b4ce6f4.json", "locations/rwanda.json"} {
+		assert.FileExists(t, filepath.Join(dir, path))
+	}
+
+	data2, err := directory.ReadAssets(dir, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(data2))
+
+	// and check it's readable as a single assets file
+	_, err = static.NewSource(data2)
+	assert.NoError(t, err)
+
+	err = directory.WriteAssets([]byte(`{"flows": [], "widgets": []}`), t.TempDir())
+	assert.EqualError(t, err, "unrecognized asset type 'widgets'")
+
+	err = directory.WriteAssets([]byte(`[]`), t.TempDir())
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "unable to read assets: json: cannot unmarshal array"))
+	}
+
+	err = directory.WriteAssets([]byte(`{"locations": [{"name": "Rwanda"}, {"name": "rwanda"}]}`), t.TempDir())
+	assert.EqualError(t, err, "multiple assets would be written to 'locations/rwanda.json'")
+
+	err = directory.WriteAssets([]byte(`{"locations": [{"name": 123}]}`), t.TempDir())
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "unable to read location hierarchy: json: cannot unmarshal number"))
+	}
+}
+
+func TestWriteAssetsToExistingDirectory(t *testing.T) {
+	data, err := directory.ReadAssets("testdata/org", nil)
+	require.NoError(t, err)
+
+	// create a directory with assets which are no longer in the assets file, and a file which isn't an asset
+	dir := t.TempDir()
+	stale := []string{"labels.json", "flows/2a6a7e5b-7d9c-4c5e-8bb1-6e5f0b6c0c4d.json", "locations/kenya.json"}
+	for _, path := range append(stale, "README.md") {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(`[]`), 0644))
+	}
+
+	// a failed write leaves the directory as it was
+	err = directory.WriteAssets([]byte(`{"locations": [{"name": "Kenya"}, {"name": "kenya"}]}`), dir)
+	assert.EqualError(t, err, "multiple assets would be written to 'locations/kenya.json'")
+	for _, path := range stale {
+		assert.FileExists(t, filepath.Join(dir, path))
+	}
+
+	require.NoError(t, directory.WriteAssets(data, dir))
+
+	for _, path := range stale {
+		assert.NoFileExists(t, filepath.Join(dir, path))
+	}
+	assert.FileExists(t, filepath.Join(dir, "README.md"))
+
+	// so reading the directory gives us back exactly what we wrote
+	data2, err := directory.ReadAssets(dir, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(data2))
+}
@@ -63,3 +63,17 @@ func (r *FlowReference) String() string {
 }
 
 var _ UUIDReference = (*FlowReference)(nil)
+
+// FlowNotFoundError is the error returned by a source which doesn't have the requested flow
+type FlowNotFoundError struct {
+	UUID FlowUUID
+}
+
+// NewFlowNotFoundError creates a new flow not found error
+func NewFlowNotFoundError(uuid FlowUUID) *FlowNotFoundError {
+	return &FlowNotFoundError{UUID: uuid}
+}
+
+func (e *FlowNotFoundError) Error() string {
+	return fmt.Sprintf("no such flow with UUID '%s'", e.UUID)
+}
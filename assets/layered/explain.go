@@ -0,0 +1,171 @@
+package layered
+
+import (
+	"github.com/nyaruka/goflow/assets"
+
+	"github.com/pkg/errors"
+)
+
+// Explain returns the name of the layer which provides the asset of the given type (e.g. "field") with the given
+// UUID or key, or an empty string if no layer provides it. Asset types are named as in asset references, and for
+// locations the key is ignored and the layer which provides all location hierarchies is returned.
+func (s *Source) Explain(assetType, key string) (string, error) {
+	has, exists := layerHas[assetType]
+	if !exists {
+		return "", errors.Errorf("unknown asset type '%s'", assetType)
+	}
+
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		found, err := has(s.layers[i].source, key)
+		if err != nil {
+			return "", errors.Wrapf(err, "error reading assets from layer '%s'", s.layers[i].name)
+		}
+		if found {
+			return s.layers[i].name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// functions to check whether a single source has the asset of each type with the given key
+var layerHas = map[string]func(assets.Source, string) (bool, error){
+	"channel": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Channels()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"classifier": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Classifiers()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"external_service": func(s assets.Source, key string) (bool, error) {
+		items, err := s.ExternalServices()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"field": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Fields()
+		for _, item := range items {
+			if item.Key() == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"flow": func(s assets.Source, key string) (bool, error) {
+		_, err := s.Flow(assets.FlowUUID(key))
+		var notFound *assets.FlowNotFoundError
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return err == nil, err
+	},
+	"global": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Globals()
+		for _, item := range items {
+			if item.Key() == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"group": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Groups()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"label": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Labels()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"location": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Locations()
+		return len(items) > 0, err
+	},
+	"msg_catalog": func(s assets.Source, key string) (bool, error) {
+		items, err := s.MsgCatalogs()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"resthook": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Resthooks()
+		for _, item := range items {
+			if item.Slug() == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"schedule": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Schedules()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"template": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Templates()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"ticketer": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Ticketers()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"topic": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Topics()
+		for _, item := range items {
+			if string(item.UUID()) == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+	"user": func(s assets.Source, key string) (bool, error) {
+		items, err := s.Users()
+		for _, item := range items {
+			if item.Email() == key {
+				return true, nil
+			}
+		}
+		return false, err
+	},
+}
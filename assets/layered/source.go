@@ -0,0 +1,376 @@
+// Package layered is an implementation of Source which stacks other sources so that later layers can override the
+// assets of earlier layers.
+package layered
+
+import (
+	"github.com/nyaruka/goflow/assets"
+
+	"github.com/pkg/errors"
+)
+
+// Layer is a named source in a layered source
+type Layer struct {
+	name   string
+	source assets.Source
+}
+
+// NewLayer creates a new layer with the given name, e.g. the path of the file it was loaded from
+func NewLayer(name string, source assets.Source) *Layer {
+	return &Layer{name: name, source: source}
+}
+
+// Name returns the name of this layer
+func (l *Layer) Name() string { return l.name }
+
+// Source returns the source of this layer
+func (l *Layer) Source() assets.Source { return l.source }
+
+// Source is an asset source which stacks other sources, where assets in later layers override assets in earlier layers
+// with the same UUID, or the same key for fields and globals, slug for resthooks and email for users. Location
+// hierarchies can't be matched so a layer with any location hierarchies replaces those of earlier layers.
+type Source struct {
+	layers []*Layer
+}
+
+// NewSource creates a new layered source from the given layers, which are ordered from bottom to top
+func NewSource(layers ...*Layer) *Source {
+	return &Source{layers: layers}
+}
+
+var _ assets.Source = (*Source)(nil)
+var _ assets.FlowLister = (*Source)(nil)
+
+// Layers returns the layers of this source
+func (s *Source) Layers() []*Layer { return s.layers }
+
+// Channels returns all channel assets
+func (s *Source) Channels() ([]assets.Channel, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Channels()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Channel, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Channel)) })
+	return set, nil
+}
+
+// Classifiers returns all classifier assets
+func (s *Source) Classifiers() ([]assets.Classifier, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Classifiers()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Classifier, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Classifier)) })
+	return set, nil
+}
+
+// ExternalServices returns all external service assets
+func (s *Source) ExternalServices() ([]assets.ExternalService, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.ExternalServices()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.ExternalService, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.ExternalService)) })
+	return set, nil
+}
+
+// Fields returns all field assets
+func (s *Source) Fields() ([]assets.Field, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Fields()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(item.Key(), item)
+		}
+	}
+
+	set := make([]assets.Field, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Field)) })
+	return set, nil
+}
+
+// Flow returns the flow asset with the given UUID from the topmost layer which has it. Errors other than the flow not
+// being found in a layer are returned rather than falling through to lower layers.
+func (s *Source) Flow(uuid assets.FlowUUID) (assets.Flow, error) {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		flow, err := s.layers[i].source.Flow(uuid)
+		if err == nil {
+			return flow, nil
+		}
+
+		var notFound *assets.FlowNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, errors.Wrapf(err, "error reading assets from layer '%s'", s.layers[i].name)
+		}
+	}
+
+	return nil, assets.NewFlowNotFoundError(uuid)
+}
+
+// Flows returns all flow assets from layers which can list their flows
+func (s *Source) Flows() ([]assets.Flow, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		lister, isLister := l.source.(assets.FlowLister)
+		if !isLister {
+			continue
+		}
+		items, err := lister.Flows()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Flow, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Flow)) })
+	return set, nil
+}
+
+// Globals returns all global assets
+func (s *Source) Globals() ([]assets.Global, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Globals()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(item.Key(), item)
+		}
+	}
+
+	set := make([]assets.Global, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Global)) })
+	return set, nil
+}
+
+// Groups returns all group assets
+func (s *Source) Groups() ([]assets.Group, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Groups()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Group, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Group)) })
+	return set, nil
+}
+
+// Labels returns all label assets
+func (s *Source) Labels() ([]assets.Label, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Labels()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Label, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Label)) })
+	return set, nil
+}
+
+// Locations returns the location hierarchies of the topmost layer which has any
+func (s *Source) Locations() ([]assets.LocationHierarchy, error) {
+	set := make([]assets.LocationHierarchy, 0)
+	for _, l := range s.layers {
+		items, err := l.source.Locations()
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 0 {
+			set = items
+		}
+	}
+	return set, nil
+}
+
+// MsgCatalogs returns all message catalog assets
+func (s *Source) MsgCatalogs() ([]assets.MsgCatalog, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.MsgCatalogs()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.MsgCatalog, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.MsgCatalog)) })
+	return set, nil
+}
+
+// Resthooks returns all resthook assets
+func (s *Source) Resthooks() ([]assets.Resthook, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Resthooks()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(item.Slug(), item)
+		}
+	}
+
+	set := make([]assets.Resthook, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Resthook)) })
+	return set, nil
+}
+
+// Schedules returns all schedule assets
+func (s *Source) Schedules() ([]assets.Schedule, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Schedules()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Schedule, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Schedule)) })
+	return set, nil
+}
+
+// Templates returns all template assets
+func (s *Source) Templates() ([]assets.Template, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Templates()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Template, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Template)) })
+	return set, nil
+}
+
+// Ticketers returns all ticketer assets
+func (s *Source) Ticketers() ([]assets.Ticketer, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Ticketers()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Ticketer, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Ticketer)) })
+	return set, nil
+}
+
+// Topics returns all topic assets
+func (s *Source) Topics() ([]assets.Topic, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Topics()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(string(item.UUID()), item)
+		}
+	}
+
+	set := make([]assets.Topic, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.Topic)) })
+	return set, nil
+}
+
+// Users returns all user assets
+func (s *Source) Users() ([]assets.User, error) {
+	m := newMerger()
+	for _, l := range s.layers {
+		items, err := l.source.Users()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			m.add(item.Email(), item)
+		}
+	}
+
+	set := make([]assets.User, 0, m.len())
+	m.each(func(item interface{}) { set = append(set, item.(assets.User)) })
+	return set, nil
+}
+
+// merges items by key, where later items replace earlier items with the same key but keep their position
+type merger struct {
+	keys  []string
+	items map[string]interface{}
+}
+
+func newMerger() *merger {
+	return &merger{items: make(map[string]interface{})}
+}
+
+func (m *merger) add(key string, item interface{}) {
+	if _, exists := m.items[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.items[key] = item
+}
+
+func (m *merger) len() int { return len(m.keys) }
+
+func (m *merger) each(fn func(interface{})) {
+	for _, k := range m.keys {
+		fn(m.items[k])
+	}
+}
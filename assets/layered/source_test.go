@@ -0,0 +1,199 @@
+package layered_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/layered"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/engine"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseJSON = `{
+	"channels": [
+		{"uuid": "58e9b092-fe42-4173-876c-ff45a14a24fe", "name": "Android", "address": "+12345671111", "schemes": ["tel"], "roles": ["send", "receive"]}
+	],
+	"classifiers": [
+		{"uuid": "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000", "name": "Booking", "type": "wit", "intents": ["book_flight"]}
+	],
+	"fields": [
+		{"uuid": "d66a7823-eada-40e5-9a3a-57239d4690bf", "key": "gender", "name": "Gender", "type": "text"},
+		{"uuid": "f1b5aea6-6586-41c7-9020-1a6326cc6565", "key": "age", "name": "Age", "type": "number"}
+	],
+	"flows": [
+		{"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Registration", "spec_version": "13.1.0", "language": "eng", "type": "messaging", "nodes": []},
+		{"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "name": "Survey", "spec_version": "13.1.0", "language": "eng", "type": "messaging", "nodes": []}
+	],
+	"globals": [
+		{"key": "org_name", "name": "Org Name", "value": "Nyaruka"},
+		{"key": "access_token", "name": "Access Token", "value": "A213CD78"}
+	],
+	"locations": [
+		{"name": "Rwanda", "children": [{"name": "Kigali City"}]}
+	],
+	"users": [
+		{"email": "bob@nyaruka.com", "name": "Bob"}
+	]
+}`
+
+const overlayJSON = `{
+	"classifiers": [
+		{"uuid": "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000", "name": "Booking (Draft)", "type": "wit", "intents": ["book_flight", "book_hotel"]}
+	],
+	"fields": [
+		{"uuid": "a8d3a5e2-5d2e-4c8a-9f3a-0e2a2c2a3b41", "key": "district", "name": "District", "type": "district"}
+	],
+	"flows": [
+		{"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Registration (Draft)", "spec_version": "13.1.0", "language": "eng", "type": "messaging", "nodes": []}
+	],
+	"globals": [
+		{"key": "access_token", "name": "Access Token", "value": "TESTTOKEN"}
+	]
+}`
+
+func TestLayeredSource(t *testing.T) {
+	base, err := static.NewSource([]byte(baseJSON))
+	require.NoError(t, err)
+	overlay, err := static.NewSource([]byte(overlayJSON))
+	require.NoError(t, err)
+
+	source := layered.NewSource(layered.NewLayer("base.json", base), layered.NewLayer("draft.json", overlay))
+	assert.Equal(t, 2, len(source.Layers()))
+	assert.Equal(t, "base.json", source.Layers()[0].Name())
+	assert.Equal(t, base, source.Layers()[0].Source())
+
+	// assets which only exist in one layer are included, and those in both come from the top layer
+	channels, err := source.Channels()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(channels))
+	assert.Equal(t, "Android", channels[0].Name())
+
+	classifiers, err := source.Classifiers()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(classifiers))
+	assert.Equal(t, "Booking (Draft)", classifiers[0].Name())
+	assert.Equal(t, []string{"book_flight", "book_hotel"}, classifiers[0].Intents())
+
+	fields, err := source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(fields))
+	assert.Equal(t, "gender", fields[0].Key())
+	assert.Equal(t, "age", fields[1].Key())
+	assert.Equal(t, "district", fields[2].Key())
+
+	// overridden assets keep their original position
+	globals, err := source.Globals()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(globals))
+	assert.Equal(t, "org_name", globals[0].Key())
+	assert.Equal(t, "Nyaruka", globals[0].Value())
+	assert.Equal(t, "access_token", globals[1].Key())
+	assert.Equal(t, "TESTTOKEN", globals[1].Value())
+
+	flow, err := source.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+	assert.Equal(t, "Registration (Draft)", flow.Name())
+
+	flow, err = source.Flow("76f0a02f-3b75-4b86-9064-e9195e1b3a02")
+	require.NoError(t, err)
+	assert.Equal(t, "Survey", flow.Name())
+
+	_, err = source.Flow("d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c")
+	assert.EqualError(t, err, "no such flow with UUID 'd9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c'")
+
+	allFlows, err := source.Flows()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(allFlows))
+	assert.Equal(t, "Registration (Draft)", allFlows[0].Name())
+	assert.Equal(t, "Survey", allFlows[1].Name())
+
+	// locations come from the top layer which has any
+	locations, err := source.Locations()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(locations))
+
+	users, err := source.Users()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+
+	// and the merged assets can be used to create session assets
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "TESTTOKEN", sa.Globals().Get("access_token").Value())
+	assert.NotNil(t, sa.Fields().Get("district"))
+
+	// an empty layered source has no assets
+	empty := layered.NewSource()
+	fields, err = empty.Fields()
+	assert.NoError(t, err)
+	assert.Equal(t, []assets.Field{}, fields)
+
+	_, err = empty.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	assert.EqualError(t, err, "no such flow with UUID '615b8a0f-588c-4d20-a05f-363b0b4ce6f4'")
+}
+
+// a source which fails to load flows, e.g. because a remote endpoint is down
+type brokenFlowsSource struct {
+	assets.Source
+}
+
+func (s *brokenFlowsSource) Flow(assets.FlowUUID) (assets.Flow, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestLayeredSourceWithErrors(t *testing.T) {
+	base, err := static.NewSource([]byte(baseJSON))
+	require.NoError(t, err)
+
+	// errors other than a flow not being found aren't hidden by falling through to lower layers
+	source := layered.NewSource(layered.NewLayer("base.json", base), layered.NewLayer("remote", &brokenFlowsSource{base}))
+
+	_, err = source.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	assert.EqualError(t, err, "error reading assets from layer 'remote': connection refused")
+
+	_, err = source.Explain("flow", "615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	assert.EqualError(t, err, "error reading assets from layer 'remote': connection refused")
+}
+
+func TestExplain(t *testing.T) {
+	base, err := static.NewSource([]byte(baseJSON))
+	require.NoError(t, err)
+	overlay, err := static.NewSource([]byte(overlayJSON))
+	require.NoError(t, err)
+
+	source := layered.NewSource(layered.NewLayer("base.json", base), layered.NewLayer("draft.json", overlay))
+
+	tcs := []struct {
+		assetType string
+		key       string
+		layer     string
+	}{
+		{"channel", "58e9b092-fe42-4173-876c-ff45a14a24fe", "base.json"},
+		{"classifier", "1c06c884-39dd-4ce4-ad9f-9a01cbe6c000", "draft.json"},
+		{"field", "gender", "base.json"},
+		{"field", "district", "draft.json"},
+		{"field", "xxx", ""},
+		{"flow", "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "draft.json"},
+		{"flow", "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "base.json"},
+		{"flow", "d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c", ""},
+		{"global", "org_name", "base.json"},
+		{"global", "access_token", "draft.json"},
+		{"group", "d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c", ""},
+		{"location", "", "base.json"},
+		{"user", "bob@nyaruka.com", "base.json"},
+	}
+
+	for _, tc := range tcs {
+		layer, err := source.Explain(tc.assetType, tc.key)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.layer, layer, "layer mismatch for %s %s", tc.assetType, tc.key)
+	}
+
+	_, err = source.Explain("xxx", "foo")
+	assert.EqualError(t, err, "unknown asset type 'xxx'")
+}
@@ -0,0 +1,353 @@
+// Package remote is an implementation of Source which fetches assets from HTTP endpoints.
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Source is an asset source which fetches each type of asset from its own HTTP endpoint, e.g. channels from
+// <base URL>/channels, and each flow from <base URL>/flows/<uuid>. Endpoints are named after the keys used in the
+// static assets JSON format and return JSON arrays of assets in that format, except flow endpoints which return a
+// single flow definition.
+//
+// Fetched assets are cached for the configured TTL, after which they are revalidated using the ETag returned by the
+// endpoint. If fetching fails and there is a previously fetched copy, that copy is used instead. Because each flow
+// has its own endpoint, the number of cached responses is limited and the least recently used are evicted first.
+type Source struct {
+	client     *http.Client
+	baseURL    string
+	headers    map[string]string
+	ttl        time.Duration
+	maxEntries int
+
+	mutex   sync.Mutex
+	entries map[string]*entry
+	uses    int
+}
+
+// a cached response from an endpoint
+type entry struct {
+	mutex     sync.Mutex
+	value     interface{}
+	etag      string
+	fetchedOn time.Time
+	lastUse   int // guarded by the source's mutex
+}
+
+// NewSource creates a new HTTP source with the given base URL, which sends the given headers (e.g. Authorization)
+// with each request, and caches up to the given number of responses for the given TTL
+func NewSource(client *http.Client, baseURL string, headers map[string]string, ttl time.Duration, maxEntries int) *Source {
+	return &Source{
+		client:     client,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		headers:    headers,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*entry),
+	}
+}
+
+var _ assets.Source = (*Source)(nil)
+
+// errNotFound is returned by endpoints which return a 404
+var errNotFound = errors.New("not found")
+
+// Channels returns all channel assets
+func (s *Source) Channels() ([]assets.Channel, error) {
+	set, err := s.getSet("channels")
+	if err != nil {
+		return nil, err
+	}
+	return set.Channels()
+}
+
+// Classifiers returns all classifier assets
+func (s *Source) Classifiers() ([]assets.Classifier, error) {
+	set, err := s.getSet("classifiers")
+	if err != nil {
+		return nil, err
+	}
+	return set.Classifiers()
+}
+
+// ExternalServices returns all external service assets
+func (s *Source) ExternalServices() ([]assets.ExternalService, error) {
+	set, err := s.getSet("externalServices")
+	if err != nil {
+		return nil, err
+	}
+	return set.ExternalServices()
+}
+
+// Fields returns all field assets
+func (s *Source) Fields() ([]assets.Field, error) {
+	set, err := s.getSet("fields")
+	if err != nil {
+		return nil, err
+	}
+	return set.Fields()
+}
+
+// Flow returns the flow asset with the given UUID
+func (s *Source) Flow(uuid assets.FlowUUID) (assets.Flow, error) {
+	v, err := s.get("flows/"+string(uuid), func(data []byte) (interface{}, error) {
+		flow := &static.Flow{}
+		if err := utils.UnmarshalAndValidate(data, flow); err != nil {
+			return nil, err
+		}
+		if flow.UUID() != uuid {
+			return nil, errors.Errorf("endpoint returned flow with UUID '%s'", flow.UUID())
+		}
+		return flow, nil
+	})
+	if err == errNotFound {
+		return nil, assets.NewFlowNotFoundError(uuid)
+	} else if err != nil {
+		return nil, err
+	}
+	return v.(assets.Flow), nil
+}
+
+// Globals returns all global assets
+func (s *Source) Globals() ([]assets.Global, error) {
+	set, err := s.getSet("globals")
+	if err != nil {
+		return nil, err
+	}
+	return set.Globals()
+}
+
+// Groups returns all group assets
+func (s *Source) Groups() ([]assets.Group, error) {
+	set, err := s.getSet("groups")
+	if err != nil {
+		return nil, err
+	}
+	return set.Groups()
+}
+
+// Labels returns all label assets
+func (s *Source) Labels() ([]assets.Label, error) {
+	set, err := s.getSet("labels")
+	if err != nil {
+		return nil, err
+	}
+	return set.Labels()
+}
+
+// Locations returns all location assets
+func (s *Source) Locations() ([]assets.LocationHierarchy, error) {
+	set, err := s.getSet("locations")
+	if err != nil {
+		return nil, err
+	}
+	return set.Locations()
+}
+
+// MsgCatalogs returns all message catalog assets
+func (s *Source) MsgCatalogs() ([]assets.MsgCatalog, error) {
+	set, err := s.getSet("msgCatalogs")
+	if err != nil {
+		return nil, err
+	}
+	return set.MsgCatalogs()
+}
+
+// Resthooks returns all resthook assets
+func (s *Source) Resthooks() ([]assets.Resthook, error) {
+	set, err := s.getSet("resthooks")
+	if err != nil {
+		return nil, err
+	}
+	return set.Resthooks()
+}
+
+// Schedules returns all schedule assets
+func (s *Source) Schedules() ([]assets.Schedule, error) {
+	set, err := s.getSet("schedules")
+	if err != nil {
+		return nil, err
+	}
+	return set.Schedules()
+}
+
+// Templates returns all template assets
+func (s *Source) Templates() ([]assets.Template, error) {
+	set, err := s.getSet("templates")
+	if err != nil {
+		return nil, err
+	}
+	return set.Templates()
+}
+
+// Ticketers returns all ticketer assets
+func (s *Source) Ticketers() ([]assets.Ticketer, error) {
+	set, err := s.getSet("ticketers")
+	if err != nil {
+		return nil, err
+	}
+	return set.Ticketers()
+}
+
+// Topics returns all topic assets
+func (s *Source) Topics() ([]assets.Topic, error) {
+	set, err := s.getSet("topics")
+	if err != nil {
+		return nil, err
+	}
+	return set.Topics()
+}
+
+// Users returns all user assets
+func (s *Source) Users() ([]assets.User, error) {
+	set, err := s.getSet("users")
+	if err != nil {
+		return nil, err
+	}
+	return set.Users()
+}
+
+// gets the assets returned by an endpoint which returns a JSON array of assets in the static assets JSON format
+func (s *Source) getSet(endpoint string) (*static.StaticSource, error) {
+	v, err := s.get(endpoint, func(data []byte) (interface{}, error) {
+		return static.NewSource([]byte(fmt.Sprintf(`{"%s": %s}`, endpoint, data)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*static.StaticSource), nil
+}
+
+// gets the decoded response of the given endpoint, from the cache if it's still fresh, and otherwise by fetching it
+func (s *Source) get(endpoint string, decode func([]byte) (interface{}, error)) (interface{}, error) {
+	e := s.entry(endpoint)
+
+	// only one request to an endpoint at a time so concurrent sessions don't all fetch the same thing
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := dates.Now()
+	if e.value != nil && now.Sub(e.fetchedOn) < s.ttl {
+		return e.value, nil
+	}
+
+	value, etag, err := s.fetch(endpoint, e.etag, decode)
+	if err == nil {
+		if value != nil {
+			e.value, e.etag = value, etag
+		}
+		e.fetchedOn = now
+		return e.value, nil
+	}
+
+	// if asset no longer exists, forget about it
+	if err == errNotFound {
+		e.value, e.etag = nil, ""
+		s.forget(endpoint, e)
+		return nil, err
+	}
+
+	// otherwise use a stale copy if we have one
+	if e.value != nil {
+		return e.value, nil
+	}
+
+	return nil, errors.Wrapf(err, "error fetching assets from %s", s.url(endpoint))
+}
+
+// gets the cache entry for the given endpoint, creating it if necessary, in which case the least recently used entry
+// is evicted if the cache is full
+func (s *Source) entry(endpoint string) *entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e := s.entries[endpoint]
+	if e == nil {
+		if len(s.entries) >= s.maxEntries {
+			s.evictLeastRecentlyUsed()
+		}
+
+		e = &entry{}
+		s.entries[endpoint] = e
+	}
+
+	s.uses++
+	e.lastUse = s.uses
+	return e
+}
+
+// removes the cache entry for the given endpoint if it hasn't already been replaced
+func (s *Source) forget(endpoint string, e *entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.entries[endpoint] == e {
+		delete(s.entries, endpoint)
+	}
+}
+
+func (s *Source) evictLeastRecentlyUsed() {
+	var oldestEndpoint string
+	var oldest *entry
+	for endpoint, e := range s.entries {
+		if oldest == nil || e.lastUse < oldest.lastUse {
+			oldestEndpoint, oldest = endpoint, e
+		}
+	}
+	delete(s.entries, oldestEndpoint)
+}
+
+// fetches the given endpoint, returning nil if the given etag is still valid
+func (s *Source) fetch(endpoint, etag string, decode func([]byte) (interface{}, error)) (interface{}, string, error) {
+	request, err := httpx.NewRequest("GET", s.url(endpoint), nil, s.headers)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := httpx.Do(s.client, request, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && etag != "":
+		return nil, etag, nil
+	case response.StatusCode == http.StatusNotFound:
+		return nil, "", errNotFound
+	case response.StatusCode != http.StatusOK:
+		return nil, "", errors.Errorf("endpoint returned status %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	value, err := decode(body)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "unable to read response")
+	}
+
+	return value, response.Header.Get("ETag"), nil
+}
+
+func (s *Source) url(endpoint string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, endpoint)
+}
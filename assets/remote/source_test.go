@@ -0,0 +1,243 @@
+package remote_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/remote"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/engine"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a fake org API which serves assets from a map of paths to JSON bodies, using the body length and version as ETags
+type assetServer struct {
+	mutex    sync.Mutex
+	bodies   map[string]string
+	versions map[string]int
+	requests map[string]int
+	failing  bool
+}
+
+func newAssetServer() *assetServer {
+	return &assetServer{bodies: make(map[string]string), versions: make(map[string]int), requests: make(map[string]int)}
+}
+
+func (s *assetServer) set(path, body string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.bodies[path] = body
+	s.versions[path]++
+}
+
+func (s *assetServer) setFailing(failing bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failing = failing
+}
+
+func (s *assetServer) requestCount(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests[r.URL.Path]++
+
+	if r.Header.Get("Authorization") != "Token 123456" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, exists := s.bodies[r.URL.Path]
+	if !exists {
+		// asset types we don't have anything for are empty lists, but flows must exist
+		if strings.HasPrefix(r.URL.Path, "/api/flows/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body = `[]`
+	}
+
+	etag := fmt.Sprintf(`"%d-%d"`, len(body), s.versions[r.URL.Path])
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(body))
+}
+
+func TestSource(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	assetsJSON, err := os.ReadFile("../../cmd/flowrunner/testdata/two_questions.json")
+	require.NoError(t, err)
+
+	flowJSON, _, _, err := jsonparser.Get(assetsJSON, "flows", "[0]")
+	require.NoError(t, err)
+	fieldsJSON, _, _, err := jsonparser.Get(assetsJSON, "fields")
+	require.NoError(t, err)
+
+	api := newAssetServer()
+	api.set("/api/flows/615b8a0f-588c-4d20-a05f-363b0b4ce6f4", string(flowJSON))
+	api.set("/api/fields", string(fieldsJSON))
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	t0 := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	dates.SetNowSource(dates.NewFixedNowSource(t0))
+
+	source := remote.NewSource(http.DefaultClient, server.URL+"/api/", map[string]string{"Authorization": "Token 123456"}, time.Minute, 100)
+
+	// session assets can be created from the source, with flows loaded lazily
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.requestCount("/api/fields"))
+	assert.Equal(t, 1, api.requestCount("/api/channels"))
+	assert.Equal(t, 0, api.requestCount("/api/flows/615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+
+	flow, err := sa.Flows().Get("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+	assert.Equal(t, "Two Questions", flow.Name())
+	assert.Equal(t, 1, api.requestCount("/api/flows/615b8a0f-588c-4d20-a05f-363b0b4ce6f4"))
+
+	_, err = source.Flow("d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c")
+	assert.EqualError(t, err, "no such flow with UUID 'd9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c'")
+
+	// within the TTL, assets come from the cache
+	fields, err := source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(fields))
+	assert.Equal(t, 1, api.requestCount("/api/fields"))
+
+	// after the TTL, they're revalidated and are still the same so aren't fetched again
+	dates.SetNowSource(dates.NewFixedNowSource(t0.Add(2 * time.Minute)))
+
+	fields2, err := source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, fields, fields2)
+	assert.Equal(t, 2, api.requestCount("/api/fields"))
+
+	// and then each asset type is refreshed independently when it changes on the server
+	api.set("/api/fields", `[{"uuid": "f1b5aea6-6586-41c7-9020-1a6326cc6565", "key": "age", "name": "Age", "type": "number"}]`)
+	dates.SetNowSource(dates.NewFixedNowSource(t0.Add(4 * time.Minute)))
+
+	fields, err = source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, "age", fields[0].Key())
+	assert.Equal(t, 3, api.requestCount("/api/fields"))
+
+	// if the server is failing, we use stale copies
+	api.setFailing(true)
+	dates.SetNowSource(dates.NewFixedNowSource(t0.Add(6 * time.Minute)))
+
+	fields, err = source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, "age", fields[0].Key())
+
+	flow2, err := source.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+	assert.Equal(t, "Two Questions", flow2.Name())
+
+	// but that's an error if we don't have a stale copy
+	source = remote.NewSource(http.DefaultClient, server.URL+"/api/", map[string]string{"Authorization": "Token 123456"}, time.Minute, 100)
+
+	_, err = source.Topics()
+	assert.EqualError(t, err, fmt.Sprintf("error fetching assets from %s/api/topics: endpoint returned status 503", server.URL))
+
+	// as are invalid responses
+	api.setFailing(false)
+	api.set("/api/groups", `[{"uuid": "xyz"}]`)
+
+	_, err = source.Groups()
+	assert.EqualError(t, err, fmt.Sprintf("error fetching assets from %s/api/groups: unable to read response: unable to read assets: field 'uuid' must be a valid UUID4", server.URL))
+}
+
+func TestSourceConcurrency(t *testing.T) {
+	api := newAssetServer()
+	api.set("/api/labels", `[{"uuid": "3f65d88a-95dc-4140-9451-943e94e06fea", "name": "Spam"}]`)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	source := remote.NewSource(http.DefaultClient, server.URL+"/api", map[string]string{"Authorization": "Token 123456"}, time.Hour, 100)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			labels, err := source.Labels()
+			assert.NoError(t, err)
+			assert.Equal(t, []assets.Label{labels[0]}, labels)
+			assert.Equal(t, "Spam", labels[0].Name())
+		}()
+	}
+
+	wg.Wait()
+
+	// concurrent requests for the same asset type only result in a single fetch
+	assert.Equal(t, 1, api.requestCount("/api/labels"))
+}
+
+func TestSourceCacheLimit(t *testing.T) {
+	api := newAssetServer()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	source := remote.NewSource(http.DefaultClient, server.URL+"/api", map[string]string{"Authorization": "Token 123456"}, time.Hour, 2)
+
+	_, err := source.Fields()
+	require.NoError(t, err)
+	_, err = source.Labels()
+	require.NoError(t, err)
+
+	// flows which don't exist aren't kept in the cache...
+	_, err = source.Flow("d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c")
+	assert.EqualError(t, err, "no such flow with UUID 'd9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c'")
+
+	// so fetching topics doesn't evict labels
+	_, err = source.Topics()
+	require.NoError(t, err)
+	_, err = source.Labels()
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.requestCount("/api/labels"))
+
+	// but fields, as the least recently used, were evicted to make room for the flow
+	_, err = source.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.requestCount("/api/fields"))
+
+	// and re-fetching them evicted topics
+	_, err = source.Topics()
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.requestCount("/api/labels"))
+	assert.Equal(t, 2, api.requestCount("/api/topics"))
+}
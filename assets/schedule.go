@@ -0,0 +1,96 @@
+package assets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/uuids"
+)
+
+// ScheduleUUID is the UUID of a schedule
+type ScheduleUUID uuids.UUID
+
+// Schedule is a set of weekly opening hours in a timezone, with dated exceptions such as holidays. If an exception
+// has no hours then the schedule is closed for that entire date.
+//
+//   {
+//     "uuid": "4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6",
+//     "name": "Office Hours",
+//     "timezone": "Africa/Kigali",
+//     "intervals": [
+//       {"day": "mon", "start": "09:00", "end": "17:00"},
+//       {"day": "tue", "start": "09:00", "end": "17:00"}
+//     ],
+//     "exceptions": [
+//       {"date": "2021-12-25", "name": "Christmas Day"},
+//       {"date": "2021-12-24", "name": "Christmas Eve", "hours": [{"start": "09:00", "end": "12:00"}]}
+//     ]
+//   }
+//
+// @asset schedule
+type Schedule interface {
+	UUID() ScheduleUUID
+	Name() string
+	Timezone() string
+	Intervals() []ScheduleInterval
+	Exceptions() []ScheduleException
+}
+
+// ScheduleHours is a range of time in a day when a schedule is open. An end of midnight means the end of the day, and
+// hours can't span midnight so overnight opening is split across consecutive days.
+type ScheduleHours interface {
+	Start() dates.TimeOfDay
+	End() dates.TimeOfDay
+}
+
+// ScheduleInterval is a range of time on a day of the week when a schedule is open
+type ScheduleInterval interface {
+	ScheduleHours
+
+	Day() time.Weekday
+}
+
+// ScheduleException replaces the weekly intervals of a schedule on a specific date
+type ScheduleException interface {
+	Date() dates.Date
+	Name() string
+	Hours() []ScheduleHours
+}
+
+// ScheduleReference is used to reference a schedule
+type ScheduleReference struct {
+	UUID ScheduleUUID `json:"uuid" validate:"required,uuid"`
+	Name string       `json:"name"`
+}
+
+// NewScheduleReference creates a new schedule reference with the given UUID and name
+func NewScheduleReference(uuid ScheduleUUID, name string) *ScheduleReference {
+	return &ScheduleReference{UUID: uuid, Name: name}
+}
+
+// Type returns the name of the asset type
+func (r *ScheduleReference) Type() string {
+	return "schedule"
+}
+
+// GenericUUID returns the untyped UUID
+func (r *ScheduleReference) GenericUUID() uuids.UUID {
+	return uuids.UUID(r.UUID)
+}
+
+// Identity returns the unique identity of the asset
+func (r *ScheduleReference) Identity() string {
+	return string(r.UUID)
+}
+
+// Variable returns whether this a variable (vs concrete) reference
+func (r *ScheduleReference) Variable() bool {
+	return false
+}
+
+func (r *ScheduleReference) String() string {
+	return fmt.Sprintf("%s[uuid=%s,name=%s]", r.Type(), r.Identity(), r.Name)
+}
+
+var _ UUIDReference = (*ScheduleReference)(nil)
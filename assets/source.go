@@ -12,9 +12,15 @@ type Source interface {
 	Labels() ([]Label, error)
 	Locations() ([]LocationHierarchy, error)
 	Resthooks() ([]Resthook, error)
+	Schedules() ([]Schedule, error)
 	Templates() ([]Template, error)
 	Ticketers() ([]Ticketer, error)
 	Topics() ([]Topic, error)
 	Users() ([]User, error)
 	MsgCatalogs() ([]MsgCatalog, error)
 }
+
+// FlowLister is implemented by sources which can list all of their flows
+type FlowLister interface {
+	Flows() ([]Flow, error)
+}
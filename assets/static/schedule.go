@@ -0,0 +1,185 @@
+package static
+
+import (
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/utils"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+func init() {
+	utils.RegisterValidatorTag("time_of_day", func(fl validator.FieldLevel) bool {
+		_, err := dates.ParseTimeOfDay("tt:mm", fl.Field().String())
+		return err == nil
+	}, func(validator.FieldError) string {
+		return "is not a valid time of day"
+	})
+	utils.RegisterValidatorTag("hours_end", func(fl validator.FieldLevel) bool {
+		hours, isHours := fl.Parent().Interface().(ScheduleHours)
+		if !isHours {
+			return false
+		}
+		start, err1 := dates.ParseTimeOfDay("tt:mm", hours.Start_)
+		end, err2 := dates.ParseTimeOfDay("tt:mm", hours.End_)
+		if err1 != nil || err2 != nil {
+			return true // reported by time_of_day
+		}
+		return end.Equal(dates.ZeroTimeOfDay) || end.Compare(start) > 0
+	}, func(validator.FieldError) string {
+		return "must be after start or 00:00 for the end of the day"
+	})
+	utils.RegisterValidatorTag("iso_date", func(fl validator.FieldLevel) bool {
+		_, err := dates.ParseDate(dates.ISO8601Date, fl.Field().String())
+		return err == nil
+	}, func(validator.FieldError) string {
+		return "is not a valid ISO8601 date"
+	})
+	utils.RegisterValidatorTag("timezone", func(fl validator.FieldLevel) bool {
+		_, err := time.LoadLocation(fl.Field().String())
+		return err == nil
+	}, func(validator.FieldError) string {
+		return "is not a valid timezone"
+	})
+}
+
+var weekdaysByCode = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var weekdayCodes = map[time.Weekday]string{
+	time.Sunday:    "sun",
+	time.Monday:    "mon",
+	time.Tuesday:   "tue",
+	time.Wednesday: "wed",
+	time.Thursday:  "thu",
+	time.Friday:    "fri",
+	time.Saturday:  "sat",
+}
+
+// ScheduleHours is a JSON serializable implementation of a range of opening hours. Hours can't span midnight so
+// overnight opening should be split into hours on consecutive days.
+type ScheduleHours struct {
+	Start_ string `json:"start" validate:"required,time_of_day"`
+	End_   string `json:"end"   validate:"required,time_of_day,hours_end"`
+}
+
+// NewScheduleHours creates a new range of opening hours
+func NewScheduleHours(start, end dates.TimeOfDay) *ScheduleHours {
+	return &ScheduleHours{Start_: formatTimeOfDay(start), End_: formatTimeOfDay(end)}
+}
+
+// Start returns the time of day these hours start
+func (h *ScheduleHours) Start() dates.TimeOfDay { return parseTimeOfDay(h.Start_) }
+
+// End returns the time of day these hours end
+func (h *ScheduleHours) End() dates.TimeOfDay { return parseTimeOfDay(h.End_) }
+
+// ScheduleInterval is a JSON serializable implementation of a weekly opening interval
+type ScheduleInterval struct {
+	Day_ string `json:"day" validate:"required,oneof=sun mon tue wed thu fri sat"`
+	ScheduleHours
+}
+
+// NewScheduleInterval creates a new weekly opening interval
+func NewScheduleInterval(day time.Weekday, start, end dates.TimeOfDay) *ScheduleInterval {
+	return &ScheduleInterval{Day_: weekdayCodes[day], ScheduleHours: *NewScheduleHours(start, end)}
+}
+
+// Day returns the day of the week of this interval
+func (i *ScheduleInterval) Day() time.Weekday { return weekdaysByCode[i.Day_] }
+
+// ScheduleException is a JSON serializable implementation of a schedule exception
+type ScheduleException struct {
+	Date_  string           `json:"date"            validate:"required,iso_date"`
+	Name_  string           `json:"name"`
+	Hours_ []*ScheduleHours `json:"hours,omitempty" validate:"omitempty,dive"`
+}
+
+// NewScheduleException creates a new schedule exception
+func NewScheduleException(date dates.Date, name string, hours []*ScheduleHours) *ScheduleException {
+	return &ScheduleException{Date_: date.String(), Name_: name, Hours_: hours}
+}
+
+// Date returns the date of this exception
+func (e *ScheduleException) Date() dates.Date {
+	d, _ := dates.ParseDate(dates.ISO8601Date, e.Date_)
+	return d
+}
+
+// Name returns the name of this exception, e.g. the holiday
+func (e *ScheduleException) Name() string { return e.Name_ }
+
+// Hours returns the opening hours on the date of this exception, which will be empty if closed all day
+func (e *ScheduleException) Hours() []assets.ScheduleHours {
+	hours := make([]assets.ScheduleHours, len(e.Hours_))
+	for i := range e.Hours_ {
+		hours[i] = e.Hours_[i]
+	}
+	return hours
+}
+
+// Schedule is a JSON serializable implementation of a schedule asset
+type Schedule struct {
+	UUID_       assets.ScheduleUUID  `json:"uuid"                 validate:"required,uuid"`
+	Name_       string               `json:"name"`
+	Timezone_   string               `json:"timezone,omitempty"   validate:"omitempty,timezone"`
+	Intervals_  []*ScheduleInterval  `json:"intervals"            validate:"omitempty,dive"`
+	Exceptions_ []*ScheduleException `json:"exceptions,omitempty" validate:"omitempty,dive"`
+}
+
+// NewSchedule creates a new schedule
+func NewSchedule(uuid assets.ScheduleUUID, name, timezone string, intervals []*ScheduleInterval, exceptions []*ScheduleException) assets.Schedule {
+	return &Schedule{
+		UUID_:       uuid,
+		Name_:       name,
+		Timezone_:   timezone,
+		Intervals_:  intervals,
+		Exceptions_: exceptions,
+	}
+}
+
+// UUID returns the UUID of this schedule
+func (s *Schedule) UUID() assets.ScheduleUUID { return s.UUID_ }
+
+// Name returns the name of this schedule
+func (s *Schedule) Name() string { return s.Name_ }
+
+// Timezone returns the IANA timezone name of this schedule, which may be empty
+func (s *Schedule) Timezone() string { return s.Timezone_ }
+
+// Intervals returns the weekly opening intervals of this schedule
+func (s *Schedule) Intervals() []assets.ScheduleInterval {
+	intervals := make([]assets.ScheduleInterval, len(s.Intervals_))
+	for i := range s.Intervals_ {
+		intervals[i] = s.Intervals_[i]
+	}
+	return intervals
+}
+
+// Exceptions returns the dated exceptions of this schedule
+func (s *Schedule) Exceptions() []assets.ScheduleException {
+	exceptions := make([]assets.ScheduleException, len(s.Exceptions_))
+	for i := range s.Exceptions_ {
+		exceptions[i] = s.Exceptions_[i]
+	}
+	return exceptions
+}
+
+func parseTimeOfDay(s string) dates.TimeOfDay {
+	t, _ := dates.ParseTimeOfDay("tt:mm", s)
+	return t
+}
+
+func formatTimeOfDay(t dates.TimeOfDay) string {
+	s, _ := t.Format("tt:mm", "")
+	return s
+}
@@ -0,0 +1,74 @@
+package static_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	schedule := static.NewSchedule(
+		assets.ScheduleUUID("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6"),
+		"Office Hours",
+		"Africa/Kigali",
+		[]*static.ScheduleInterval{
+			static.NewScheduleInterval(time.Monday, dates.NewTimeOfDay(9, 0, 0, 0), dates.NewTimeOfDay(17, 0, 0, 0)),
+		},
+		[]*static.ScheduleException{
+			static.NewScheduleException(dates.NewDate(2021, 12, 24), "Christmas Eve", []*static.ScheduleHours{
+				static.NewScheduleHours(dates.NewTimeOfDay(9, 0, 0, 0), dates.NewTimeOfDay(12, 0, 0, 0)),
+			}),
+		},
+	)
+	assert.Equal(t, assets.ScheduleUUID("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6"), schedule.UUID())
+	assert.Equal(t, "Office Hours", schedule.Name())
+	assert.Equal(t, "Africa/Kigali", schedule.Timezone())
+	assert.Equal(t, 1, len(schedule.Intervals()))
+	assert.Equal(t, time.Monday, schedule.Intervals()[0].Day())
+	assert.Equal(t, dates.NewTimeOfDay(9, 0, 0, 0), schedule.Intervals()[0].Start())
+	assert.Equal(t, dates.NewTimeOfDay(17, 0, 0, 0), schedule.Intervals()[0].End())
+	assert.Equal(t, 1, len(schedule.Exceptions()))
+	assert.Equal(t, dates.NewDate(2021, 12, 24), schedule.Exceptions()[0].Date())
+	assert.Equal(t, "Christmas Eve", schedule.Exceptions()[0].Name())
+	assert.Equal(t, dates.NewTimeOfDay(12, 0, 0, 0), schedule.Exceptions()[0].Hours()[0].End())
+
+	marshaled, err := jsonx.Marshal(schedule)
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, []byte(`{
+		"uuid": "4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6",
+		"name": "Office Hours",
+		"timezone": "Africa/Kigali",
+		"intervals": [{"day": "mon", "start": "09:00", "end": "17:00"}],
+		"exceptions": [{"date": "2021-12-24", "name": "Christmas Eve", "hours": [{"start": "09:00", "end": "12:00"}]}]
+	}`), marshaled, "schedule JSON mismatch")
+
+	// check validation of invalid schedules
+	invalid := &static.Schedule{}
+	err = utils.UnmarshalAndValidate([]byte(`{
+		"uuid": "4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6",
+		"name": "Office Hours",
+		"timezone": "Mars/Olympus",
+		"intervals": [{"day": "xxx", "start": "25:00", "end": "17:00"}],
+		"exceptions": [{"date": "2021-13-45", "name": "Bad Day"}]
+	}`), invalid)
+	assert.EqualError(t, err, "field 'timezone' is not a valid timezone, field 'intervals[0].day' failed tag 'oneof', field 'intervals[0].start' is not a valid time of day, field 'exceptions[0].date' is not a valid ISO8601 date")
+
+	// hours can't end before they start, i.e. span midnight, but can end at midnight
+	invalid = &static.Schedule{}
+	err = utils.UnmarshalAndValidate([]byte(`{
+		"uuid": "4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6",
+		"name": "Night Shift",
+		"intervals": [{"day": "mon", "start": "22:00", "end": "02:00"}, {"day": "tue", "start": "22:00", "end": "00:00"}],
+		"exceptions": [{"date": "2021-12-24", "name": "Christmas Eve", "hours": [{"start": "12:00", "end": "12:00"}]}]
+	}`), invalid)
+	assert.EqualError(t, err, "field 'intervals[0].end' must be after start or 00:00 for the end of the day, field 'exceptions[0].hours[0].end' must be after start or 00:00 for the end of the day")
+}
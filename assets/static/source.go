@@ -26,6 +26,7 @@ type StaticSource struct {
 		Locations        []*envs.LocationHierarchy `json:"locations"`
 		MsgCatalogs      []*MsgCatalog             `json:"msgCatalogs" validate:"omitempty"`
 		Resthooks        []*Resthook               `json:"resthooks" validate:"omitempty,dive"`
+		Schedules        []*Schedule               `json:"schedules" validate:"omitempty,dive"`
 		Templates        []*Template               `json:"templates" validate:"omitempty,dive"`
 		Ticketers        []*Ticketer               `json:"ticketers" validate:"omitempty,dive"`
 		Topics           []*Topic                  `json:"topics" validate:"omitempty,dive"`
@@ -57,6 +58,7 @@ func LoadSource(path string) (*StaticSource, error) {
 }
 
 var _ assets.Source = (*StaticSource)(nil)
+var _ assets.FlowLister = (*StaticSource)(nil)
 
 // Channels returns all channel assets
 func (s *StaticSource) Channels() ([]assets.Channel, error) {
@@ -100,7 +102,16 @@ func (s *StaticSource) Flow(uuid assets.FlowUUID) (assets.Flow, error) {
 			return flow, nil
 		}
 	}
-	return nil, errors.Errorf("no such flow with UUID '%s'", uuid)
+	return nil, assets.NewFlowNotFoundError(uuid)
+}
+
+// Flows returns all flow assets
+func (s *StaticSource) Flows() ([]assets.Flow, error) {
+	set := make([]assets.Flow, len(s.s.Flows))
+	for i := range s.s.Flows {
+		set[i] = s.s.Flows[i]
+	}
+	return set, nil
 }
 
 // Globals returns all global assets
@@ -156,6 +167,15 @@ func (s *StaticSource) Resthooks() ([]assets.Resthook, error) {
 	return set, nil
 }
 
+// Schedules returns all schedule assets
+func (s *StaticSource) Schedules() ([]assets.Schedule, error) {
+	set := make([]assets.Schedule, len(s.s.Schedules))
+	for i := range s.s.Schedules {
+		set[i] = s.s.Schedules[i]
+	}
+	return set, nil
+}
+
 // Templates returns all template assets
 func (s *StaticSource) Templates() ([]assets.Template, error) {
 	set := make([]assets.Template, len(s.s.Templates))
@@ -11,7 +11,7 @@ type Template struct {
 	t struct {
 		UUID         assets.TemplateUUID    `json:"uuid"         validate:"required,uuid"`
 		Name         string                 `json:"name"`
-		Translations []*TemplateTranslation `json:"translations"`
+		Translations []*TemplateTranslation `json:"translations" jsonschema:"template_translation"`
 	}
 }
 
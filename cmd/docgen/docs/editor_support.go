@@ -76,6 +76,8 @@ func (g *editorSupportGenerator) buildContextCompletion(items map[string][]*Tagg
 		completion.NewDynamicType("fields", "fields", completion.NewProperty("{key}", gettext("{key} for the contact"), "any")),
 		completion.NewDynamicType("results", "results", completion.NewProperty("{key}", gettext("the result for {key}"), "result")),
 		completion.NewDynamicType("globals", "globals", completion.NewProperty("{key}", gettext("the global value {key}"), "text")),
+		completion.NewDynamicType("schedules", "schedules", completion.NewProperty("{key}", gettext("the schedule {key}"), "schedule")),
+		completion.NewDynamicType("params", "params", completion.NewProperty("{key}", gettext("the parameter {key}"), "any")),
 
 		// the urns type also added here as it's "dynamic" in sense that keys are known at build time
 		createURNsType(gettext),
@@ -140,9 +142,11 @@ func (g *editorSupportGenerator) buildFunctionListing(items map[string][]*Tagged
 // creates a text file which lists all the context paths using example fields
 func createContextPathListFile(outputDir string, c *completion.Completion) error {
 	context := completion.NewContext(map[string][]string{
-		"fields":  {"age", "gender"},
-		"globals": {"org_name"},
-		"results": {"response_1"},
+		"fields":    {"age", "gender"},
+		"globals":   {"org_name"},
+		"params":    {"age"},
+		"schedules": {"office_hours"},
+		"results":   {"response_1"},
 	})
 	nodes := c.EnumerateNodes(context)
 
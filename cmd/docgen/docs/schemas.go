@@ -0,0 +1,351 @@
+package docs
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/cmd/docgen/jsonschema"
+	"github.com/nyaruka/goflow/flows/actions"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/modifiers"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers"
+	"github.com/nyaruka/goflow/flows/routers/waits"
+	"github.com/nyaruka/goflow/flows/routers/waits/hints"
+	"github.com/nyaruka/goflow/flows/triggers"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterGenerator(&schemasGenerator{})
+}
+
+const goflowPkg = "github.com/nyaruka/goflow/"
+
+// a Go type which JSON is read into, which can be a field of a named type, e.g. where the type has its own unmarshaling
+// which reads JSON into an unexported struct
+type goType struct {
+	pkg   string
+	name  string
+	field string
+}
+
+// the Go types which JSON of kinds which aren't typed is read into
+var singleKinds = map[string]goType{
+	"category":             {"flows/routers", "categoryEnvelope", ""},
+	"exit":                 {"flows/definition", "exitEnvelope", ""},
+	"flow":                 {"flows/definition", "flowEnvelope", ""},
+	"location":             {"envs", "locationEnvelope", ""},
+	"node":                 {"flows/definition", "nodeEnvelope", ""},
+	"template":             {"assets/static", "Template", "t"},
+	"template_translation": {"assets/static", "TemplateTranslation", "t"},
+}
+
+// the Go types which JSON of each type of typed kinds is read into, other than actions, events and hints which are read
+// into the registered types themselves
+var typedKinds = map[string]map[string]goType{
+	"router": {
+		routers.TypeRandom:   {"flows/routers", "baseRouterEnvelope", ""},
+		routers.TypeSchedule: {"flows/routers", "scheduleRouterEnvelope", ""},
+		routers.TypeSmart:    {"flows/routers", "smartRouterEnvelope", ""},
+		routers.TypeSwitch:   {"flows/routers", "switchRouterEnvelope", ""},
+	},
+	"wait": {
+		waits.TypeCallback: {"flows/routers/waits", "baseWaitEnvelope", ""},
+		waits.TypeDial:     {"flows/routers/waits", "dialWaitEnvelope", ""},
+		waits.TypeMsg:      {"flows/routers/waits", "msgWaitEnvelope", ""},
+		waits.TypeUntil:    {"flows/routers/waits", "untilWaitEnvelope", ""},
+	},
+	"resume": {
+		resumes.TypeCallback:      {"flows/resumes", "callbackResumeEnvelope", ""},
+		resumes.TypeDial:          {"flows/resumes", "dialResumeEnvelope", ""},
+		resumes.TypeMsg:           {"flows/resumes", "msgResumeEnvelope", ""},
+		resumes.TypeRunExpiration: {"flows/resumes", "baseResumeEnvelope", ""},
+		resumes.TypeWaitTimeout:   {"flows/resumes", "baseResumeEnvelope", ""},
+		resumes.TypeWaitUntil:     {"flows/resumes", "baseResumeEnvelope", ""},
+	},
+	"modifier": {
+		modifiers.TypeChannel:  {"flows/modifiers", "channelModifierEnvelope", ""},
+		modifiers.TypeField:    {"flows/modifiers", "fieldModifierEnvelope", ""},
+		modifiers.TypeGroups:   {"flows/modifiers", "groupsModifierEnvelope", ""},
+		modifiers.TypeLanguage: {"flows/modifiers", "LanguageModifier", ""},
+		modifiers.TypeName:     {"flows/modifiers", "NameModifier", ""},
+		modifiers.TypeStatus:   {"flows/modifiers", "StatusModifier", ""},
+		modifiers.TypeTimezone: {"flows/modifiers", "timezoneModifierEnvelope", ""},
+		modifiers.TypeURN:      {"flows/modifiers", "URNModifier", ""},
+		modifiers.TypeURNs:     {"flows/modifiers", "URNsModifier", ""},
+	},
+	"trigger": {
+		triggers.TypeCampaign:   {"flows/triggers", "campaignTriggerEnvelope", ""},
+		triggers.TypeChannel:    {"flows/triggers", "channelTriggerEnvelope", ""},
+		triggers.TypeFlowAction: {"flows/triggers", "flowActionTriggerEnvelope", ""},
+		triggers.TypeManual:     {"flows/triggers", "manualTriggerEnvelope", ""},
+		triggers.TypeMsg:        {"flows/triggers", "msgTriggerEnvelope", ""},
+		triggers.TypeTicket:     {"flows/triggers", "ticketTriggerEnvelope", ""},
+	},
+}
+
+// the typed kinds which get a schema per type, and the directories those schemas are written to
+var schemaKinds = []struct {
+	kind string
+	dir  string
+}{
+	{"action", "actions"},
+	{"router", "routers"},
+	{"wait", "waits"},
+	{"event", "events"},
+	{"trigger", "triggers"},
+	{"resume", "resumes"},
+	{"modifier", "modifiers"},
+}
+
+// the Go types or kinds which asset JSON is read into, keyed by their asset tag
+var schemaAssets = map[string]interface{}{
+	"channel":          goType{"assets/static", "Channel", ""},
+	"classifier":       goType{"assets/static", "Classifier", ""},
+	"external_service": goType{"assets/static", "ExternalService", ""},
+	"field":            goType{"assets/static", "Field", ""},
+	"flow":             "flow",
+	"global":           goType{"assets/static", "Global", ""},
+	"group":            goType{"assets/static", "Group", ""},
+	"label":            goType{"assets/static", "Label", ""},
+	"location":         "location",
+	"resthook":         goType{"assets/static", "Resthook", ""},
+	"schedule":         goType{"assets/static", "Schedule", ""},
+	"template":         "template",
+	"ticketer":         goType{"assets/static", "Ticketer", ""},
+	"topic":            goType{"assets/static", "Topic", ""},
+	"user":             goType{"assets/static", "User", ""},
+}
+
+// NewSchemaReflector creates a JSON schema reflector with all the kinds of JSON read by the engine registered, by
+// loading the packages which read them from the source in the given base directory
+func NewSchemaReflector(baseDir string) (*jsonschema.Reflector, error) {
+	// the types of actions, events and hints are the registered types themselves
+	registered := map[string]map[string]interface{}{"action": {}, "event": {}, "hint": {}}
+	for typeName, f := range actions.RegisteredTypes() {
+		registered["action"][typeName] = f()
+	}
+	for typeName, f := range events.RegisteredTypes() {
+		registered["event"][typeName] = f()
+	}
+	for typeName, f := range hints.RegisteredTypes() {
+		registered["hint"][typeName] = f()
+	}
+
+	typed := make(map[string]map[string]goType, len(typedKinds)+len(registered))
+	for kind, kindTypes := range typedKinds {
+		typed[kind] = kindTypes
+	}
+	for kind, values := range registered {
+		typed[kind] = make(map[string]goType, len(values))
+		for typeName, v := range values {
+			t := reflect.TypeOf(v).Elem()
+			typed[kind][typeName] = goType{strings.TrimPrefix(t.PkgPath(), goflowPkg), t.Name(), ""}
+		}
+	}
+
+	// load all the packages which have the types we need
+	pkgPaths := make(map[string]bool)
+	addPkg := func(t goType) { pkgPaths[goflowPkg+t.pkg] = true }
+	for _, t := range singleKinds {
+		addPkg(t)
+	}
+	for _, kindTypes := range typed {
+		for _, t := range kindTypes {
+			addPkg(t)
+		}
+	}
+	for _, v := range schemaAssets {
+		if t, isType := v.(goType); isType {
+			addPkg(t)
+		}
+	}
+
+	pkgs, err := jsonschema.LoadPackages(baseDir, sortedKeys(pkgPaths)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading packages")
+	}
+
+	r := jsonschema.NewReflector()
+
+	for kind, t := range singleKinds {
+		typ, err := lookupType(pkgs, t)
+		if err != nil {
+			return nil, err
+		}
+		r.Register(kind, typ)
+	}
+	for kind, kindTypes := range typed {
+		for typeName, t := range kindTypes {
+			typ, err := lookupType(pkgs, t)
+			if err != nil {
+				return nil, err
+			}
+			r.RegisterTyped(kind, typeName, typ)
+		}
+	}
+	for assetType, v := range schemaAssets {
+		if t, isType := v.(goType); isType {
+			typ, err := lookupType(pkgs, t)
+			if err != nil {
+				return nil, err
+			}
+			r.Register("asset."+assetType, typ)
+		}
+	}
+
+	return r, nil
+}
+
+// looks up the given Go type in the given loaded packages
+func lookupType(pkgs *jsonschema.Packages, t goType) (types.Type, error) {
+	typ, err := pkgs.Lookup(goflowPkg+t.pkg, t.name)
+	if err != nil || t.field == "" {
+		return typ, err
+	}
+
+	if st, isStruct := typ.Underlying().(*types.Struct); isStruct {
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == t.field {
+				return st.Field(i).Type(), nil
+			}
+		}
+	}
+	return nil, errors.Errorf("no such field %s of type %s.%s", t.field, t.pkg, t.name)
+}
+
+type schemasGenerator struct{}
+
+func (g *schemasGenerator) Name() string {
+	return "JSON schemas"
+}
+
+func (g *schemasGenerator) Generate(baseDir, outputDir string, items map[string][]*TaggedItem, gettext func(string) string) error {
+	schemas, err := BuildSchemas(baseDir, items, gettext)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedSchemaNames(schemas) {
+		outputPath := path.Join(outputDir, "schemas", name)
+		if err := os.MkdirAll(path.Dir(outputPath), 0777); err != nil {
+			return err
+		}
+
+		marshaled, err := jsonx.MarshalPretty(schemas[name])
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(outputPath, marshaled, 0755); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(" > %d JSON schemas written to %s\n", len(schemas), path.Join(outputDir, "schemas"))
+	return nil
+}
+
+// BuildSchemas builds JSON schema documents for flow definitions of the current spec version, each type of action,
+// router, wait, event, trigger, resume and modifier, and each type of asset, keyed by the relative paths they are
+// written to, e.g. "actions/send_msg.json"
+func BuildSchemas(baseDir string, items map[string][]*TaggedItem, gettext func(string) string) (map[string]*jsonschema.Schema, error) {
+	r, err := NewSchemaReflector(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]*jsonschema.Schema)
+
+	flow, err := r.Kind("flow")
+	if err != nil {
+		return nil, err
+	}
+
+	flowDoc := r.Document(flow, fmt.Sprintf("Flow definition (spec version %s)", definition.CurrentSpecVersion), gettext("A flow definition in the current spec version."))
+	flowDoc.Properties = copyProperties(flowDoc.Properties)
+
+	// flows are only migrated if they're older than the current version, so any version with the same major version is read as is
+	flowDoc.Properties["spec_version"] = &jsonschema.Schema{Type: jsonschema.Types{"string"}, Pattern: fmt.Sprintf(`^%d\.\d+(\.\d+)?$`, definition.CurrentSpecVersion.Major())}
+	schemas["flow.json"] = flowDoc
+
+	for _, k := range schemaKinds {
+		for _, typeName := range r.TypesOf(k.kind) {
+			s, err := r.Typed(k.kind, typeName)
+			if err != nil {
+				return nil, err
+			}
+
+			title := fmt.Sprintf("%s %s", typeName, k.kind)
+			schemas[path.Join(k.dir, typeName+".json")] = r.Document(s, title, itemSummary(items, k.kind, typeName, gettext))
+		}
+	}
+
+	for assetType, v := range schemaAssets {
+		kind, isKind := v.(string)
+		if !isKind {
+			kind = "asset." + assetType
+		}
+
+		s, err := r.Kind(kind)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error building schema for asset type %s", assetType)
+		}
+
+		title := fmt.Sprintf("%s asset", assetType)
+		schemas[path.Join("assets", assetType+".json")] = r.Document(s, title, itemSummary(items, "asset", assetType, gettext))
+	}
+
+	return schemas, nil
+}
+
+// gets the first paragraph of the description of the given tagged item, if there is one
+func itemSummary(items map[string][]*TaggedItem, tag, value string, gettext func(string) string) string {
+	for _, item := range items[tag] {
+		if item.tagValue == value {
+			lines := make([]string, 0, len(item.description))
+			for _, line := range item.description {
+				if strings.TrimSpace(line) == "" {
+					break
+				}
+				lines = append(lines, strings.TrimSpace(line))
+			}
+			return gettext(strings.Join(lines, " "))
+		}
+	}
+	return ""
+}
+
+func copyProperties(props map[string]*jsonschema.Schema) map[string]*jsonschema.Schema {
+	c := make(map[string]*jsonschema.Schema, len(props))
+	for k, v := range props {
+		c[k] = v
+	}
+	return c
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSchemaNames(schemas map[string]*jsonschema.Schema) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
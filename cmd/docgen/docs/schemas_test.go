@@ -0,0 +1,259 @@
+package docs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/goflow/cmd/docgen/docs"
+	"github.com/nyaruka/goflow/cmd/docgen/jsonschema"
+	"github.com/nyaruka/goflow/flows/actions"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers"
+	"github.com/nyaruka/goflow/flows/triggers"
+
+	jsonschema5 "github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the keys in assets files of the asset types other than flows, which are validated after migration
+var assetKeys = map[string]string{
+	"channels":         "channel",
+	"classifiers":      "classifier",
+	"externalServices": "external_service",
+	"fields":           "field",
+	"globals":          "global",
+	"groups":           "group",
+	"labels":           "label",
+	"locations":        "location",
+	"resthooks":        "resthook",
+	"schedules":        "schedule",
+	"templates":        "template",
+	"ticketers":        "ticketer",
+	"topics":           "topic",
+	"users":            "user",
+}
+
+func TestBuildSchemas(t *testing.T) {
+	items, err := docs.FindAllTaggedItems("../../../")
+	require.NoError(t, err)
+
+	schemas, err := docs.BuildSchemas("../../../", items, func(s string) string { return s })
+	require.NoError(t, err)
+
+	assert.Contains(t, schemas, "flow.json")
+	assert.Contains(t, schemas, "actions/send_msg.json")
+	assert.Contains(t, schemas, "routers/switch.json")
+	assert.Contains(t, schemas, "waits/msg.json")
+	assert.Contains(t, schemas, "events/msg_created.json")
+	assert.Contains(t, schemas, "triggers/manual.json")
+	assert.Contains(t, schemas, "resumes/msg.json")
+	assert.Contains(t, schemas, "modifiers/field.json")
+	assert.Contains(t, schemas, "assets/location.json")
+
+	// every registered type of the engine should have a schema
+	for typeName := range actions.RegisteredTypes() {
+		assert.NotNil(t, schemas["actions/"+typeName+".json"], "no schema for action type %s", typeName)
+	}
+	for typeName := range events.RegisteredTypes() {
+		assert.NotNil(t, schemas["events/"+typeName+".json"], "no schema for event type %s", typeName)
+	}
+	for _, typeName := range routers.RegisteredTypes() {
+		assert.NotNil(t, schemas["routers/"+typeName+".json"], "no schema for router type %s", typeName)
+	}
+	for typeName := range resumes.RegisteredTypes() {
+		assert.NotNil(t, schemas["resumes/"+typeName+".json"], "no schema for resume type %s", typeName)
+	}
+	for typeName := range triggers.RegisteredTypes() {
+		assert.NotNil(t, schemas["triggers/"+typeName+".json"], "no schema for trigger type %s", typeName)
+	}
+
+	assert.Equal(t, "Flow definition (spec version 13.2.0)", schemas["flow.json"].Title)
+	assert.Equal(t, `^13\.\d+(\.\d+)?$`, schemas["flow.json"].Properties["spec_version"].Pattern)
+	assert.Equal(t, "send_msg action", schemas["actions/send_msg.json"].Title)
+	assert.Equal(t, "Can be used to reply to the current contact in a flow. The text field may contain templates. The action will attempt to find pairs of URNs and channels which can be used for sending. If it can't find such a pair, it will create a message without a channel or URN.", schemas["actions/send_msg.json"].Description)
+
+	// every schema should be a valid schema document which only references its own definitions
+	compiled := make(map[string]*jsonschema5.Schema, len(schemas))
+	for name, s := range schemas {
+		assert.Equal(t, jsonschema.Draft, s.Schema)
+		compiled[name] = compileSchema(t, name, s)
+	}
+
+	sendMsg := compiled["actions/send_msg.json"]
+	assert.NoError(t, validate(sendMsg, []byte(`{"type": "send_msg", "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9", "text": "Hi"}`)))
+	assert.Error(t, validate(sendMsg, []byte(`{"type": "send_msg", "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9"}`)))
+	assert.Error(t, validate(sendMsg, []byte(`{"type": "send_msg", "uuid": "xyz", "text": "Hi"}`)))
+	assert.Error(t, validate(sendMsg, []byte(`{"type": "send_msg", "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9", "text": "Hi", "quick_replies": [1]}`)))
+	assert.Error(t, validate(sendMsg, []byte(`{"type": "add_input_labels", "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9", "text": "Hi"}`)))
+
+	flow := compiled["flow.json"]
+	assert.NoError(t, validate(flow, []byte(`{"uuid": "8ca44c09-791d-453a-9799-a70dd3303306", "name": "Test", "spec_version": "13.1.0", "language": "eng", "type": "messaging", "nodes": [{"uuid": "a58be63b-907d-4a1a-856b-0bb5579d7507", "actions": [{"type": "add_input_labels", "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9", "labels": []}], "exits": [{"uuid": "d4ba5fa8-8c82-4a5d-a77a-cdbbbd9d8bbd"}]}]}`)))
+	assert.Error(t, validate(flow, []byte(`{"uuid": "8ca44c09-791d-453a-9799-a70dd3303306", "name": "Test", "spec_version": "13.1.0", "language": "eng", "type": "messaging", "nodes": [{"uuid": "a58be63b-907d-4a1a-856b-0bb5579d7507", "actions": [{"type": "foo"}], "exits": [{"uuid": "d4ba5fa8-8c82-4a5d-a77a-cdbbbd9d8bbd"}]}]}`)))
+	assert.Error(t, validate(flow, []byte(`{"uuid": "8ca44c09-791d-453a-9799-a70dd3303306", "name": "Test", "spec_version": "12.0.0", "language": "eng", "type": "messaging", "nodes": []}`)))
+}
+
+func TestSchemasAgainstTestdata(t *testing.T) {
+	built, err := docs.BuildSchemas("../../../", nil, func(s string) string { return s })
+	require.NoError(t, err)
+
+	schemas := make(map[string]*jsonschema5.Schema, len(built))
+	for name, s := range built {
+		schemas[name] = compileSchema(t, name, s)
+	}
+
+	// the schema for any event is the union of the schemas of every type
+	r, err := docs.NewSchemaReflector("../../../")
+	require.NoError(t, err)
+	event, err := r.Kind("event")
+	require.NoError(t, err)
+	eventSchema := compileSchema(t, "event", r.Document(event, "event", ""))
+
+	validateEvents := func(raw json.RawMessage, source string) {
+		var events []json.RawMessage
+		require.NoError(t, json.Unmarshal(raw, &events), "error unmarshaling events in %s", source)
+
+		for i, e := range events {
+			assert.NoError(t, validate(eventSchema, e), "event %d in %s is invalid", i, source)
+		}
+	}
+
+	// testdata of the flows packages is lists of test cases, and those without read errors should be valid
+	for _, dir := range []string{"actions", "modifiers", "resumes", "routers"} {
+		files, err := filepath.Glob(path.Join("../../../flows", dir, "testdata", "*.json"))
+		require.NoError(t, err)
+
+		for _, file := range files {
+			if strings.HasPrefix(path.Base(file), "_") {
+				continue // assets
+			}
+
+			typeName := strings.TrimSuffix(path.Base(file), ".json")
+			schema := schemas[path.Join(dir, typeName+".json")]
+			require.NotNil(t, schema, "no schema for %s", file)
+
+			var cases []map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(mustReadFile(t, file), &cases))
+
+			for i, tc := range cases {
+				if tc["read_error"] != nil {
+					continue
+				}
+
+				key := strings.TrimSuffix(dir, "s")
+				assert.NoError(t, validate(schema, tc[key]), "%s in test case %d of %s is invalid", key, i, file)
+
+				if tc["events"] != nil {
+					validateEvents(tc["events"], file)
+				}
+			}
+		}
+	}
+
+	// runner testdata has assets with flows of various spec versions, and the triggers, resumes and events of sessions
+	files, err := filepath.Glob("../../../test/testdata/runner/*.json")
+	require.NoError(t, err)
+
+	assetFiles, err := filepath.Glob("../../../flows/*/testdata/_assets.json")
+	require.NoError(t, err)
+	files = append(files, assetFiles...)
+
+	for _, file := range files {
+		var parsed map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(mustReadFile(t, file), &parsed))
+
+		if parsed["flows"] != nil {
+			var flows []json.RawMessage
+			require.NoError(t, json.Unmarshal(parsed["flows"], &flows))
+
+			for i, f := range flows {
+				migrated, err := migrations.MigrateToLatest(f, &migrations.Config{BaseMediaURL: "https://temba.io/"})
+				if err != nil {
+					continue // some flows are invalid on purpose
+				}
+				assert.NoError(t, validate(schemas["flow.json"], migrated), "flow %d in %s is invalid", i, file)
+			}
+		}
+
+		for key, assetType := range assetKeys {
+			if parsed[key] != nil {
+				var assets []json.RawMessage
+				require.NoError(t, json.Unmarshal(parsed[key], &assets))
+
+				for i, a := range assets {
+					assert.NoError(t, validate(schemas["assets/"+assetType+".json"], a), "%s %d in %s is invalid", assetType, i, file)
+				}
+			}
+		}
+
+		if parsed["trigger"] != nil {
+			triggerType := typeOf(t, parsed["trigger"])
+			assert.NoError(t, validate(schemas["triggers/"+triggerType+".json"], parsed["trigger"]), "trigger in %s is invalid", file)
+		}
+
+		if parsed["resumes"] != nil {
+			var resumes []json.RawMessage
+			require.NoError(t, json.Unmarshal(parsed["resumes"], &resumes))
+
+			for i, r := range resumes {
+				resumeType := typeOf(t, r)
+				assert.NoError(t, validate(schemas["resumes/"+resumeType+".json"], r), "resume %d in %s is invalid", i, file)
+			}
+		}
+
+		if parsed["outputs"] != nil {
+			var outputs []map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(parsed["outputs"], &outputs))
+
+			for _, o := range outputs {
+				validateEvents(o["events"], file)
+			}
+		}
+	}
+}
+
+func mustReadFile(t *testing.T, file string) []byte {
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	return data
+}
+
+func typeOf(t *testing.T, data json.RawMessage) string {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	require.NoError(t, json.Unmarshal(data, &typed))
+	return typed.Type
+}
+
+// compiles the given schema document with an independent JSON Schema implementation, which also checks that it's valid
+func compileSchema(t *testing.T, name string, s *jsonschema.Schema) *jsonschema5.Schema {
+	marshaled, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	compiler := jsonschema5.NewCompiler()
+	compiler.AssertFormat = true
+	require.NoError(t, compiler.AddResource(name, bytes.NewReader(marshaled)))
+
+	compiled, err := compiler.Compile(name)
+	require.NoError(t, err, "error compiling schema %s", name)
+	return compiled
+}
+
+func validate(s *jsonschema5.Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
@@ -0,0 +1,132 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Packages are Go packages loaded so that the types which JSON is read into can be found, even when they're unexported.
+// Packages in the main module are type checked from source, ignoring function bodies, and other packages are imported
+// from the export data built by the go command.
+type Packages struct {
+	fset    *token.FileSet
+	listed  map[string]*listedPackage
+	checked map[string]*types.Package
+	exports types.Importer
+}
+
+// the fields we need of the package descriptions output by go list
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	Module     *struct{ Main bool }
+}
+
+// LoadPackages loads the given packages and their dependencies, running the go command in the given directory
+func LoadPackages(dir string, paths ...string) (*Packages, error) {
+	cmd := exec.Command("go", append([]string{"list", "-export", "-deps", "-json"}, paths...)...)
+	cmd.Dir = dir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listing packages: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	p := &Packages{
+		fset:    token.NewFileSet(),
+		listed:  make(map[string]*listedPackage),
+		checked: make(map[string]*types.Package),
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		lp := &listedPackage{}
+		if err := decoder.Decode(lp); err != nil {
+			return nil, errors.Wrap(err, "error reading package list")
+		}
+		p.listed[lp.ImportPath] = lp
+	}
+
+	p.exports = importer.ForCompiler(p.fset, "gc", func(path string) (io.ReadCloser, error) {
+		lp := p.listed[path]
+		if lp == nil || lp.Export == "" {
+			return nil, errors.Errorf("no export data for package %s", path)
+		}
+		return os.Open(lp.Export)
+	})
+
+	for _, path := range paths {
+		if _, err := p.Import(path); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Lookup looks up the named type in the given loaded package
+func (p *Packages) Lookup(pkgPath, name string) (types.Type, error) {
+	pkg, err := p.Import(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, isType := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !isType {
+		return nil, errors.Errorf("no such type %s.%s", pkgPath, name)
+	}
+	return obj.Type(), nil
+}
+
+// Import imports the given loaded package
+func (p *Packages) Import(path string) (*types.Package, error) {
+	return p.ImportFrom(path, "", 0)
+}
+
+// ImportFrom imports the given loaded package, and is what makes this a types.ImporterFrom
+func (p *Packages) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg := p.checked[path]; pkg != nil {
+		return pkg, nil
+	}
+
+	lp := p.listed[path]
+	if lp == nil {
+		return nil, errors.Errorf("package %s hasn't been loaded", path)
+	}
+	if lp.Module == nil || !lp.Module.Main {
+		return p.exports.Import(path)
+	}
+
+	files := make([]*ast.File, len(lp.GoFiles))
+	for i, name := range lp.GoFiles {
+		f, err := parser.ParseFile(p.fset, filepath.Join(lp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = f
+	}
+
+	config := &types.Config{Importer: p, IgnoreFuncBodies: true}
+	pkg, err := config.Check(path, p.fset, files, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error checking package %s", path)
+	}
+
+	p.checked[path] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,429 @@
+package jsonschema
+
+import (
+	"go/types"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Reflector builds schemas from registered kinds and Go types, collecting the definitions which they reference
+type Reflector struct {
+	kinds     map[string]*kind
+	defs      map[string]*Schema
+	overrides map[string]*Schema
+	err       error
+}
+
+// NewReflector creates a new reflector
+func NewReflector() *Reflector {
+	r := &Reflector{
+		kinds:     make(map[string]*kind),
+		defs:      make(map[string]*Schema),
+		overrides: make(map[string]*Schema),
+	}
+
+	// types which have their own JSON marshaling
+	r.Override("time.Time", &Schema{Type: Types{"string"}, Format: "date-time"})
+	r.Override("github.com/shopspring/decimal.Decimal", &Schema{Type: Types{"number", "string"}})
+	r.Override("github.com/Masterminds/semver.Version", &Schema{Type: Types{"string"}})
+	return r
+}
+
+// Override sets the schema used for the named type with the given package qualified name, e.g. "time.Time"
+func (r *Reflector) Override(name string, s *Schema) {
+	r.overrides[name] = s
+}
+
+// Kind gets a schema which references the given registered kind
+func (r *Reflector) Kind(name string) (*Schema, error) {
+	s := r.kindRef(name)
+	return s, r.err
+}
+
+// Typed gets a schema which references the given type of the given registered kind
+func (r *Reflector) Typed(kind, typeName string) (*Schema, error) {
+	k := r.kinds[kind]
+	if k == nil || k.typed[typeName] == nil {
+		return nil, errors.Errorf("no such type '%s' of kind '%s'", typeName, kind)
+	}
+	s := r.typedRef(kind, typeName)
+	return s, r.err
+}
+
+// Type gets a schema for the given type
+func (r *Reflector) Type(t types.Type) (*Schema, error) {
+	s := r.inline(t)
+	return s, r.err
+}
+
+// Document creates a standalone schema document with the given schema as its root, which includes the definitions
+// that it references
+func (r *Reflector) Document(root *Schema, title, description string) *Schema {
+	var doc *Schema
+	if name := root.refName(); name != "" {
+		doc = r.defs[name].copy()
+	} else {
+		doc = root.copy()
+	}
+
+	doc.Schema = Draft
+	doc.Title = title
+	doc.Description = description
+	doc.Defs = r.referenced(doc)
+	return doc
+}
+
+// gets all the definitions referenced directly or indirectly by the given schema
+func (r *Reflector) referenced(root *Schema) map[string]*Schema {
+	defs := make(map[string]*Schema)
+	pending := []*Schema{root}
+
+	for len(pending) > 0 {
+		s := pending[0]
+		pending = pending[1:]
+
+		s.walk(func(sub *Schema) {
+			if name := sub.refName(); name != "" && defs[name] == nil {
+				defs[name] = r.defs[name]
+				pending = append(pending, defs[name])
+			}
+		})
+	}
+
+	if len(defs) == 0 {
+		return nil
+	}
+	return defs
+}
+
+func (r *Reflector) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// gets a reference to the given kind, defining it if necessary
+func (r *Reflector) kindRef(name string) *Schema {
+	k := r.kinds[name]
+	if k == nil {
+		r.fail(errors.Errorf("no such kind '%s'", name))
+		return &Schema{}
+	}
+
+	if r.defs[name] == nil {
+		def := &Schema{}
+		r.defs[name] = def // defined before reflecting so that recursive references terminate
+
+		if k.single != nil {
+			*def = *r.inline(k.single)
+		} else {
+			for _, typeName := range r.TypesOf(name) {
+				def.OneOf = append(def.OneOf, r.typedRef(name, typeName))
+			}
+		}
+	}
+	return refTo(name)
+}
+
+// gets a reference to the given type of the given kind, defining it if necessary
+func (r *Reflector) typedRef(kind, typeName string) *Schema {
+	name := kind + "." + typeName
+
+	if r.defs[name] == nil {
+		def := &Schema{}
+		r.defs[name] = def
+
+		*def = *r.inline(r.kinds[kind].typed[typeName])
+		if def.Properties == nil {
+			def.Properties = make(map[string]*Schema)
+		}
+		def.Properties["type"] = &Schema{Type: Types{"string"}, Const: typeName}
+		def.require("type")
+	}
+	return refTo(name)
+}
+
+// gets a reference to the given named struct type, defining it if necessary
+func (r *Reflector) structRef(t *types.Named, st *types.Struct) *Schema {
+	name := path.Base(t.Obj().Pkg().Path()) + "." + t.Obj().Name()
+
+	if r.defs[name] == nil {
+		def := &Schema{}
+		r.defs[name] = def
+
+		*def = *r.structSchema(st)
+	}
+	return refTo(name)
+}
+
+// gets the schema for the given type, describing structs inline rather than by reference
+func (r *Reflector) inline(t types.Type) *Schema {
+	t = derefType(t)
+	if st, isStruct := t.Underlying().(*types.Struct); isStruct && r.overrides[qualifiedName(t)] == nil && !hasOwnUnmarshaling(t) {
+		return r.structSchema(st)
+	}
+	return r.schemaFor(t)
+}
+
+// gets the schema for the given type, describing named structs by reference
+func (r *Reflector) schemaFor(t types.Type) *Schema {
+	t = derefType(t)
+
+	if s := r.overrides[qualifiedName(t)]; s != nil {
+		return s.copy()
+	}
+	if isRawMessage(t) {
+		return &Schema{} // could be anything
+	}
+	if hasOwnUnmarshaling(t) {
+		if b, isBasic := t.Underlying().(*types.Basic); isBasic && b.Info()&types.IsString != 0 {
+			return &Schema{Type: Types{"string"}}
+		}
+		return &Schema{} // could be anything
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch info := u.Info(); {
+		case info&types.IsString != 0:
+			return &Schema{Type: Types{"string"}}
+		case info&types.IsBoolean != 0:
+			return &Schema{Type: Types{"boolean"}}
+		case info&types.IsInteger != 0:
+			return &Schema{Type: Types{"integer"}}
+		case info&types.IsFloat != 0:
+			return &Schema{Type: Types{"number"}}
+		}
+	case *types.Slice:
+		if b, isBasic := u.Elem().Underlying().(*types.Basic); isBasic && b.Kind() == types.Byte {
+			return &Schema{Type: Types{"string"}} // byte slices are base64 encoded
+		}
+		return &Schema{Type: Types{"array"}, Items: r.schemaFor(u.Elem())}
+	case *types.Array:
+		return &Schema{Type: Types{"array"}, Items: r.schemaFor(u.Elem())}
+	case *types.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: r.schemaFor(u.Elem())}
+	case *types.Struct:
+		if named, isNamed := t.(*types.Named); isNamed {
+			return r.structRef(named, u)
+		}
+		return r.structSchema(u) // anonymous structs can't be recursive
+	}
+
+	return &Schema{} // e.g. interfaces
+}
+
+// gets the schema for the given struct type from its fields
+func (r *Reflector) structSchema(st *types.Struct) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	r.addFields(s, st)
+	return s
+}
+
+func (r *Reflector) addFields(s *Schema, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		f, tag := st.Field(i), reflect.StructTag(st.Tag(i))
+		name := strings.Split(tag.Get("json"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		// fields of embedded structs are promoted to this struct
+		if f.Embedded() && name == "" {
+			if est, isStruct := derefType(f.Type()).Underlying().(*types.Struct); isStruct {
+				r.addFields(s, est)
+				continue
+			}
+		}
+
+		if !f.Exported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name()
+		}
+
+		fs, required := r.fieldSchema(f.Type(), tag)
+		if required {
+			s.require(name)
+		} else {
+			fs = fs.nullable() // Go will accept null for optional fields
+		}
+
+		s.Properties[name] = fs
+	}
+}
+
+// gets the schema for a struct field with the given type and tag, and whether it's required
+func (r *Reflector) fieldSchema(t types.Type, tag reflect.StructTag) (*Schema, bool) {
+	var s *Schema
+
+	if kind := tag.Get("jsonschema"); kind != "" {
+		s = r.kindRef(kind)
+
+		if _, isSlice := t.Underlying().(*types.Slice); isSlice && !isRawMessage(t) {
+			s = &Schema{Type: Types{"array"}, Items: s}
+		}
+	} else {
+		s = r.schemaFor(t)
+	}
+
+	return s, applyValidation(s, tag.Get("validate"))
+}
+
+// applies the given validate tag to the given schema, returning whether the field is required
+func applyValidation(s *Schema, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	tags := strings.Split(tag, ",")
+	var elemTags []string
+
+	// tags after dive apply to the items of a slice or the values of a map
+	for i, t := range tags {
+		if t == "dive" {
+			tags, elemTags = tags[:i], tags[i+1:]
+			break
+		}
+	}
+
+	omitEmpty, required := false, false
+
+	for _, t := range tags {
+		switch t {
+		case "omitempty":
+			omitEmpty = true
+		case "required":
+			required = true
+		default:
+			// validations of zero values fail unless the field is omitempty, so they make a field required
+			if applyConstraint(s, t) && !omitEmpty {
+				required = true
+			}
+		}
+	}
+
+	if len(elemTags) > 0 {
+		if s.Items != nil {
+			applyValidation(s.Items, strings.Join(elemTags, ","))
+		} else if s.AdditionalProperties != nil {
+			applyValidation(s.AdditionalProperties, strings.Join(elemTags, ","))
+		}
+	}
+
+	// validations are skipped for empty values of omitempty fields, so empty strings are also allowed
+	if omitEmpty && s.Type.has("string") && (s.Format != "" || s.Pattern != "" || len(s.Enum) > 0 || s.MinLength != nil) {
+		*s = Schema{OneOf: []*Schema{s.copy(), {Type: Types{"string"}, Enum: []string{""}}}}
+	}
+
+	return required
+}
+
+// applies a single validation to the given schema, returning whether it fails for zero values
+func applyConstraint(s *Schema, tag string) bool {
+	// expand aliases like http_method
+	if alias := utils.ValidatorAlias(tag); alias != "" {
+		failsForZero := false
+		for _, t := range strings.Split(alias, ",") {
+			if applyConstraint(s, t) {
+				failsForZero = true
+			}
+		}
+		return failsForZero
+	}
+
+	// alternatives which are all eq=... are an enum
+	if strings.HasPrefix(tag, "eq=") {
+		values := make([]string, 0)
+		for _, alt := range strings.Split(tag, "|") {
+			if !strings.HasPrefix(alt, "eq=") {
+				return false
+			}
+			values = append(values, alt[3:])
+		}
+		if s.Type.has("string") {
+			s.Enum = values
+		}
+		return true
+	}
+
+	name, param := tag, ""
+	if i := strings.Index(tag, "="); i >= 0 {
+		name, param = tag[:i], tag[i+1:]
+	}
+	isString, isArray := s.Type.has("string"), s.Type.has("array")
+
+	switch name {
+	case "min", "max", "len":
+		n, err := strconv.Atoi(param)
+		if err != nil || !(isString || isArray) {
+			return false
+		}
+		if name != "max" {
+			setLimit(s, isString, true, n)
+		}
+		if name != "min" {
+			setLimit(s, isString, false, n)
+		}
+		return name != "max" && n > 0
+	case "uuid", "uuid4":
+		s.Format = "uuid"
+		return true
+	case "email":
+		s.Format = "email"
+		return true
+	case "url":
+		s.Format = "uri"
+		return true
+	case "startswith":
+		s.Pattern = "^" + regexp.QuoteMeta(param)
+		return param != ""
+	case "oneof":
+		if isString {
+			s.Enum = strings.Fields(param)
+		}
+		return true
+	}
+	return false
+}
+
+func setLimit(s *Schema, isString, min bool, n int) {
+	switch {
+	case isString && min:
+		s.MinLength = &n
+	case isString:
+		s.MaxLength = &n
+	case min:
+		s.MinItems = &n
+	default:
+		s.MaxItems = &n
+	}
+}
+
+// gets the package qualified name of the given type, e.g. "time.Time", which is the name of the alias for aliased types
+func qualifiedName(t types.Type) string {
+	return types.TypeString(t, nil)
+}
+
+func isRawMessage(t types.Type) bool {
+	return qualifiedName(t) == "encoding/json.RawMessage"
+}
+
+// whether values of the given type are unmarshaled by their own code rather than from their fields
+func hasOwnUnmarshaling(t types.Type) bool {
+	if isRawMessage(t) {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, "UnmarshalJSON")
+	_, isMethod := obj.(*types.Func)
+	return isMethod
+}
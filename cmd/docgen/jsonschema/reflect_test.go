@@ -0,0 +1,127 @@
+package jsonschema_test
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/cmd/docgen/jsonschema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shapesPkg = "github.com/nyaruka/goflow/cmd/docgen/jsonschema/testdata/shapes"
+
+func TestPackages(t *testing.T) {
+	pkgs, err := jsonschema.LoadPackages(".", shapesPkg)
+	require.NoError(t, err)
+
+	// unexported types can be looked up
+	circle, err := pkgs.Lookup(shapesPkg, "circle")
+	require.NoError(t, err)
+	assert.Equal(t, shapesPkg+".circle", circle.String())
+
+	// as can types of packages outside of this module
+	timeType, err := pkgs.Lookup("time", "Time")
+	require.NoError(t, err)
+	assert.Equal(t, "time.Time", timeType.String())
+
+	_, err = pkgs.Lookup(shapesPkg, "square")
+	assert.EqualError(t, err, "no such type "+shapesPkg+".square")
+
+	_, err = pkgs.Lookup("net/http", "Client")
+	assert.EqualError(t, err, "package net/http hasn't been loaded")
+
+	_, err = jsonschema.LoadPackages(".", "github.com/nyaruka/goflow/xxx")
+	assert.Error(t, err)
+}
+
+func TestReflector(t *testing.T) {
+	pkgs, err := jsonschema.LoadPackages(".", shapesPkg)
+	require.NoError(t, err)
+
+	lookup := func(name string) types.Type {
+		typ, err := pkgs.Lookup(shapesPkg, name)
+		require.NoError(t, err)
+		return typ
+	}
+
+	r := jsonschema.NewReflector()
+	r.RegisterTyped("shape", "circle", lookup("circle"))
+	r.RegisterTyped("shape", "polygon", types.NewPointer(lookup("polygon")))
+	r.Register("drawing", lookup("drawing"))
+
+	assert.Equal(t, []string{"drawing", "shape"}, r.Kinds())
+	assert.Equal(t, []string{"circle", "polygon"}, r.TypesOf("shape"))
+	assert.Equal(t, []string{}, r.TypesOf("drawing"))
+	assert.Equal(t, []string{}, r.TypesOf("xxx"))
+
+	drawing, err := r.Kind("drawing")
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/drawing", drawing.Ref)
+
+	doc := r.Document(drawing, "Drawing", "A drawing of shapes.")
+	marshaled, err := jsonx.Marshal(doc)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Drawing",
+		"description": "A drawing of shapes.",
+		"type": "object",
+		"properties": {
+			"created_on": {"type": ["string", "null"], "format": "date-time"},
+			"extra": {},
+			"labels": {"type": ["array", "null"], "items": {"type": "string", "minLength": 1}},
+			"name": {"type": "string", "maxLength": 64},
+			"shapes": {"type": ["array", "null"], "items": {"$ref": "#/$defs/shape"}}
+		},
+		"required": ["name"],
+		"$defs": {
+			"shape": {"oneOf": [{"$ref": "#/$defs/shape.circle"}, {"$ref": "#/$defs/shape.polygon"}]},
+			"shape.circle": {
+				"type": "object",
+				"properties": {
+					"center": {"oneOf": [{"$ref": "#/$defs/shapes.point"}, {"type": "null"}]},
+					"radius": {"type": "integer"},
+					"type": {"type": "string", "const": "circle"},
+					"uuid": {"type": "string", "format": "uuid"}
+				},
+				"required": ["radius", "type", "uuid"]
+			},
+			"shape.polygon": {
+				"type": "object",
+				"properties": {
+					"color": {"oneOf": [{"type": "string", "enum": ["red", "green", "blue"]}, {"type": "string", "enum": [""]}, {"type": "null"}]},
+					"points": {"type": "array", "items": {"$ref": "#/$defs/shapes.point"}, "minItems": 3},
+					"type": {"type": "string", "const": "polygon"},
+					"uuid": {"type": "string", "format": "uuid"}
+				},
+				"required": ["points", "type", "uuid"]
+			},
+			"shapes.point": {
+				"type": "object",
+				"properties": {
+					"x": {"type": ["integer", "null"]},
+					"y": {"type": ["integer", "null"]}
+				}
+			}
+		}
+	}`, string(marshaled))
+
+	// recursive types are described by reference
+	tree, err := r.Type(lookup("tree"))
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/shapes.tree", tree.Properties["children"].Items.Ref)
+
+	polygon, err := r.Typed("shape", "polygon")
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/shape.polygon", polygon.Ref)
+
+	_, err = r.Typed("shape", "square")
+	assert.EqualError(t, err, "no such type 'square' of kind 'shape'")
+
+	_, err = jsonschema.NewReflector().Kind("xxx")
+	assert.EqualError(t, err, "no such kind 'xxx'")
+}
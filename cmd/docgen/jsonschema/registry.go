@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"go/types"
+	"sort"
+)
+
+// a named kind of JSON object, which is either described by a single Go type, or is one of several types of object
+// described by different Go types and distinguished by their type property
+type kind struct {
+	single types.Type
+	typed  map[string]types.Type
+}
+
+func (r *Reflector) getKind(name string) *kind {
+	k := r.kinds[name]
+	if k == nil {
+		k = &kind{typed: make(map[string]types.Type)}
+		r.kinds[name] = k
+	}
+	return k
+}
+
+// Register registers the Go type which JSON of the given kind is read into, e.g. "exit"
+func (r *Reflector) Register(kind string, t types.Type) {
+	r.getKind(kind).single = derefType(t)
+}
+
+// RegisterTyped registers the Go type which JSON of the given kind and type is read into, e.g. kind "router" and type
+// "switch", where the type is given by the type property of the JSON object
+func (r *Reflector) RegisterTyped(kind, typeName string, t types.Type) {
+	r.getKind(kind).typed[typeName] = derefType(t)
+}
+
+// Kinds gets the names of all registered kinds
+func (r *Reflector) Kinds() []string {
+	names := make([]string, 0, len(r.kinds))
+	for name := range r.kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TypesOf gets the names of the registered types of the given kind, which is empty if it's not a typed kind
+func (r *Reflector) TypesOf(kind string) []string {
+	k := r.kinds[kind]
+	if k == nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(k.typed))
+	for name := range k.typed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func derefType(t types.Type) types.Type {
+	for {
+		p, isPointer := t.(*types.Pointer)
+		if !isPointer {
+			return t
+		}
+		t = p.Elem()
+	}
+}
@@ -0,0 +1,134 @@
+// Package jsonschema generates JSON Schema documents from the Go types which JSON is read into, using their json and
+// validate struct tags. Types are found by loading their packages from source so they can be unexported, e.g. the
+// envelope types that the engine reads JSON into. Only the subset of JSON Schema needed to describe these types is
+// generated.
+//
+// Fields which hold raw JSON that is read separately, e.g. the actions of a node, can be described by registering the
+// Go type that the JSON is read into as a named kind, and tagging the field with that kind:
+//
+//	Actions []json.RawMessage `json:"actions,omitempty" jsonschema:"action"`
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Draft is the version of JSON Schema used by generated documents
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Types is one or more JSON types, which marshals as a single string when there is only one type
+type Types []string
+
+// MarshalJSON marshals these types into JSON
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// has returns whether these types include the given type
+func (t Types) has(typeName string) bool {
+	for _, n := range t {
+		if n == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type   Types    `json:"type,omitempty"`
+	Const  string   `json:"const,omitempty"`
+	Enum   []string `json:"enum,omitempty"`
+	Format string   `json:"format,omitempty"`
+
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	OneOf []*Schema `json:"oneOf,omitempty"`
+
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// creates a new schema which references the definition with the given name
+func refTo(name string) *Schema {
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// gets the name of the definition referenced by this schema, if it's a reference
+func (s *Schema) refName() string {
+	if len(s.Ref) > len("#/$defs/") {
+		return s.Ref[len("#/$defs/"):]
+	}
+	return ""
+}
+
+// makes this schema also accept null, which Go accepts for any type
+func (s *Schema) nullable() *Schema {
+	if s.Ref == "" && len(s.Type) == 0 && len(s.OneOf) == 0 {
+		return s // already accepts anything
+	}
+	if len(s.Type) > 0 {
+		if !s.Type.has("null") {
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	}
+	if s.Ref == "" {
+		s.OneOf = append(s.OneOf, &Schema{Type: Types{"null"}})
+		return s
+	}
+	return &Schema{OneOf: []*Schema{s, {Type: Types{"null"}}}}
+}
+
+// calls the given function for this schema and all its subschemas
+func (s *Schema) walk(fn func(*Schema)) {
+	fn(s)
+
+	if s.Items != nil {
+		s.Items.walk(fn)
+	}
+	if s.AdditionalProperties != nil {
+		s.AdditionalProperties.walk(fn)
+	}
+	for _, p := range s.Properties {
+		p.walk(fn)
+	}
+	for _, o := range s.OneOf {
+		o.walk(fn)
+	}
+}
+
+// adds the given required property, keeping required properties sorted
+func (s *Schema) require(name string) {
+	for _, r := range s.Required {
+		if r == name {
+			return
+		}
+	}
+	s.Required = append(s.Required, name)
+	sort.Strings(s.Required)
+}
+
+// copies this schema, shallowly
+func (s *Schema) copy() *Schema {
+	c := *s
+	return &c
+}
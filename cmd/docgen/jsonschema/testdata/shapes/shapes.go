@@ -0,0 +1,41 @@
+package shapes
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type baseShape struct {
+	Type string `json:"type" validate:"required"`
+	UUID string `json:"uuid" validate:"required,uuid4"`
+}
+
+type circle struct {
+	baseShape
+	Center point `json:"center"`
+	Radius int   `json:"radius" validate:"required"`
+}
+
+type polygon struct {
+	baseShape
+	Points []point `json:"points" validate:"min=3"`
+	Color  string  `json:"color,omitempty" validate:"omitempty,oneof=red green blue"`
+}
+
+type drawing struct {
+	Name      string            `json:"name" validate:"required,max=64"`
+	Shapes    []json.RawMessage `json:"shapes" jsonschema:"shape"`
+	Labels    []string          `json:"labels,omitempty" validate:"omitempty,dive,min=1"`
+	CreatedOn time.Time         `json:"created_on"`
+	Extra     json.RawMessage   `json:"extra,omitempty"`
+}
+
+type tree struct {
+	Name     string  `json:"name" validate:"required"`
+	Children []*tree `json:"children,omitempty"`
+}
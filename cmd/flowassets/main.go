@@ -0,0 +1,71 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowassets
+// flowassets split assets.json assets/
+// flowassets join assets/ > assets.json
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets/directory"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+)
+
+const usage = `usage: flowassets split <assets.json> <dir>
+       flowassets join <dir>`
+
+func main() {
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	var err error
+
+	if len(args) == 3 && args[0] == "split" {
+		err = Split(args[1], args[2])
+	} else if len(args) == 2 && args[0] == "join" {
+		err = Join(args[1], os.Stdout)
+	} else {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// Split writes the given single assets file as an assets directory
+func Split(assetsPath, dirPath string) error {
+	data, err := os.ReadFile(assetsPath)
+	if err != nil {
+		return err
+	}
+
+	return directory.WriteAssets(data, dirPath)
+}
+
+// Join reads the given assets directory and writes it as a single assets file, migrating flows to the latest spec version
+func Join(dirPath string, out io.Writer) error {
+	data, err := directory.ReadAssets(dirPath, &migrations.Config{})
+	if err != nil {
+		return err
+	}
+
+	pretty, err := jsonx.MarshalPretty(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(pretty))
+	return err
+}
@@ -0,0 +1,52 @@
+package main_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets/static"
+	main "github.com/nyaruka/goflow/cmd/flowassets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAndJoin(t *testing.T) {
+	dir := t.TempDir()
+
+	err := main.Split("../flowrunner/testdata/two_questions.json", dir)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(dir, "flows", "615b8a0f-588c-4d20-a05f-363b0b4ce6f4.json"))
+	assert.FileExists(t, filepath.Join(dir, "channels.json"))
+
+	out := &bytes.Buffer{}
+	err = main.Join(dir, out)
+	require.NoError(t, err)
+
+	// joined assets can be loaded as a single assets file
+	source, err := static.NewSource(out.Bytes())
+	require.NoError(t, err)
+
+	flow, err := source.Flow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4")
+	require.NoError(t, err)
+	assert.Equal(t, "Two Questions", flow.Name())
+
+	// and split again to the same files
+	dir2 := t.TempDir()
+	joined := filepath.Join(t.TempDir(), "assets.json")
+	require.NoError(t, os.WriteFile(joined, out.Bytes(), 0644))
+	require.NoError(t, main.Split(joined, dir2))
+
+	channels1, _ := os.ReadFile(filepath.Join(dir, "channels.json"))
+	channels2, _ := os.ReadFile(filepath.Join(dir2, "channels.json"))
+	assert.Equal(t, string(channels1), string(channels2))
+
+	err = main.Split("../flowrunner/testdata/xxx.json", t.TempDir())
+	assert.EqualError(t, err, "open ../flowrunner/testdata/xxx.json: no such file or directory")
+
+	err = main.Join("../flowrunner/testdata/xxx", out)
+	assert.EqualError(t, err, "error reading assets directory '../flowrunner/testdata/xxx': stat ../flowrunner/testdata/xxx: no such file or directory")
+}
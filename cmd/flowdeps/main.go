@@ -0,0 +1,94 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowdeps
+// flowdeps assets.json
+// flowdeps -format dot assets.json | dot -Tsvg > deps.svg
+// flowdeps -impact field:gender assets.json
+// flowdeps -writers favorite_color assets.json
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/inspect/graph"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowdeps [flags] <assets.json>`
+
+func main() {
+	var format, impact, writers string
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.StringVar(&format, "format", "json", "output format of graph: json or dot")
+	flags.StringVar(&impact, "impact", "", "show flows affected by removing an asset, e.g. field:gender or flow:<uuid>")
+	flags.StringVar(&writers, "writers", "", "show flows which write the result with this key")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	if len(args) != 1 {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if err := FlowDeps(args[0], format, impact, writers, os.Stdout); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+// FlowDeps builds the dependency graph for the flows in the given assets file and writes the requested output
+func FlowDeps(assetsPath, format, impact, writers string, out io.Writer) error {
+	source, err := static.LoadSource(assetsPath)
+	if err != nil {
+		return err
+	}
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, &migrations.Config{BaseMediaURL: "http://temba.io"})
+	if err != nil {
+		return errors.Wrap(err, "error reading assets")
+	}
+
+	g, err := graph.Build(sa)
+	if err != nil {
+		return err
+	}
+
+	if impact != "" {
+		if !strings.Contains(impact, ":") {
+			return errors.Errorf("asset must be specified as type:identity, e.g. field:gender")
+		}
+		return writeJSON(out, g.Impact(graph.NodeID(impact)))
+	}
+	if writers != "" {
+		return writeJSON(out, g.ResultWriters(writers))
+	}
+
+	switch format {
+	case "json":
+		return writeJSON(out, g)
+	case "dot":
+		_, err := io.WriteString(out, g.DOT())
+		return err
+	}
+	return errors.Errorf("unknown output format '%s'", format)
+}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	output, err := jsonx.MarshalPretty(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(output))
+	return err
+}
@@ -0,0 +1,41 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	main "github.com/nyaruka/goflow/cmd/flowdeps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowDeps(t *testing.T) {
+	assetsPath := "../../flows/inspect/graph/testdata/assets.json"
+
+	out := &strings.Builder{}
+	err := main.FlowDeps(assetsPath, "json", "", "", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `"id": "field:gender"`)
+
+	out = &strings.Builder{}
+	err = main.FlowDeps(assetsPath, "dot", "", "", out)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "digraph dependencies {"))
+
+	out = &strings.Builder{}
+	err = main.FlowDeps(assetsPath, "json", "field:gender", "", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `"name": "Registration"`)
+	assert.Contains(t, out.String(), `"name": "Campaign"`)
+
+	out = &strings.Builder{}
+	err = main.FlowDeps(assetsPath, "json", "", "name", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `"name": "Profile"`)
+
+	err = main.FlowDeps(assetsPath, "json", "gender", "", out)
+	assert.EqualError(t, err, "asset must be specified as type:identity, e.g. field:gender")
+
+	err = main.FlowDeps(assetsPath, "xml", "", "", out)
+	assert.EqualError(t, err, "unknown output format 'xml'")
+}
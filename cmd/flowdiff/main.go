@@ -0,0 +1,139 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowdiff
+// flowdiff old_flow.json new_flow.json
+// flowdiff -merge base_flow.json our_flow.json their_flow.json
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/definition/diff"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowdiff [flags] <old.json> <new.json>
+       flowdiff -merge [flags] <base.json> <ours.json> <theirs.json>`
+
+func main() {
+	var merge, asJSON bool
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.BoolVar(&merge, "merge", false, "three-way merge instead of diff")
+	flags.BoolVar(&asJSON, "json", false, "output JSON instead of text")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	if (!merge && len(args) != 2) || (merge && len(args) != 3) {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	var output []byte
+	var clean bool
+	var err error
+
+	if merge {
+		output, clean, err = Merge(args[0], args[1], args[2], asJSON)
+	} else {
+		output, clean, err = Diff(args[0], args[1], asJSON)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+
+	fmt.Println(string(output))
+
+	if !clean {
+		os.Exit(1)
+	}
+}
+
+// Diff compares the flows in the two given files, returning the output and whether the flows are the same
+func Diff(oldPath, newPath string, asJSON bool) ([]byte, bool, error) {
+	old, err := readFlow(oldPath)
+	if err != nil {
+		return nil, false, err
+	}
+	new, err := readFlow(newPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d, err := diff.Compare(old, new)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if asJSON {
+		output, err := jsonx.MarshalPretty(d)
+		return output, d.IsEmpty(), err
+	}
+
+	return []byte(d.Format()), d.IsEmpty(), nil
+}
+
+// Merge does a three-way merge of the flows in the given files, returning the output and whether the merge was
+// without conflicts
+func Merge(basePath, oursPath, theirsPath string, asJSON bool) ([]byte, bool, error) {
+	paths := []string{basePath, oursPath, theirsPath}
+	fs := make([]flows.Flow, len(paths))
+	for i, path := range paths {
+		var err error
+		if fs[i], err = readFlow(path); err != nil {
+			return nil, false, err
+		}
+	}
+
+	merged, conflicts, err := diff.Merge(fs[0], fs[1], fs[2])
+	if err != nil {
+		return nil, false, err
+	}
+
+	if asJSON {
+		output, err := jsonx.MarshalPretty(struct {
+			Flow      flows.Flow       `json:"flow"`
+			Conflicts []*diff.Conflict `json:"conflicts"`
+		}{merged, conflicts})
+		return output, len(conflicts) == 0, err
+	}
+
+	flowJSON, err := jsonx.MarshalPretty(merged)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(conflicts) == 0 {
+		return flowJSON, true, nil
+	}
+
+	lines := make([]string, len(conflicts))
+	for i := range conflicts {
+		lines[i] = conflicts[i].String()
+	}
+	return []byte(strings.Join(lines, "\n") + "\n\n" + string(flowJSON)), false, nil
+}
+
+// reads a single flow definition from the given file
+func readFlow(path string) (flows.Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading flow file '%s'", path)
+	}
+
+	flow, err := definition.ReadFlow(json.RawMessage(data), &migrations.Config{BaseMediaURL: "http://temba.io"})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading flow '%s'", path)
+	}
+	return flow, nil
+}
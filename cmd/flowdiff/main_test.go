@@ -0,0 +1,40 @@
+package main_test
+
+import (
+	"testing"
+
+	main "github.com/nyaruka/goflow/cmd/flowdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	output, clean, err := main.Diff("../../flows/definition/diff/testdata/base.json", "../../flows/definition/diff/testdata/base.json", false)
+	require.NoError(t, err)
+	assert.True(t, clean)
+	assert.Equal(t, "", string(output))
+
+	output, clean, err = main.Diff("../../flows/definition/diff/testdata/base.json", "../../flows/definition/diff/testdata/theirs.json", false)
+	require.NoError(t, err)
+	assert.False(t, clean)
+	assert.Contains(t, string(output), `~ flow name: "Favorites" → "Favourites"`)
+
+	output, _, err = main.Diff("../../flows/definition/diff/testdata/base.json", "../../flows/definition/diff/testdata/theirs.json", true)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `"type": "property_changed"`)
+
+	_, _, err = main.Diff("../../flows/definition/diff/testdata/base.json", "missing.json", false)
+	assert.EqualError(t, err, "error reading flow file 'missing.json': open missing.json: no such file or directory")
+}
+
+func TestMerge(t *testing.T) {
+	output, clean, err := main.Merge("../../flows/definition/diff/testdata/base.json", "../../flows/definition/diff/testdata/ours.json", "../../flows/definition/diff/testdata/theirs.json", false)
+	require.NoError(t, err)
+	assert.True(t, clean)
+	assert.Contains(t, string(output), `"name": "Favourites"`)
+
+	output, clean, err = main.Merge("../../flows/definition/diff/testdata/base.json", "../../flows/definition/diff/testdata/ours.json", "../../flows/definition/diff/testdata/conflicting.json", true)
+	require.NoError(t, err)
+	assert.False(t, clean)
+	assert.Contains(t, string(output), `"path": "nodes[1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01].actions[a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21].text"`)
+}
@@ -11,11 +11,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/buger/jsonparser"
 	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/layered"
 	"github.com/nyaruka/goflow/assets/static"
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
@@ -44,34 +44,62 @@ const contactJSON = `{
 }
 `
 
-const usage = `usage: flowrunner [flags] <assets.json> [flow_uuid]`
+const usage = `usage: flowrunner [flags] <assets.json>... [flow_uuid]`
 
 func main() {
-	var initialMsg, contactLang, witToken string
+	var initialMsg, contactLang, witToken, replayPath string
 	var printRepro bool
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 	flags.StringVar(&initialMsg, "msg", "", "initial message to trigger session with")
 	flags.StringVar(&contactLang, "lang", "eng", "initial language of the contact")
 	flags.StringVar(&witToken, "wit.token", "", "access token for wit.ai")
 	flags.BoolVar(&printRepro, "repro", false, "print repro afterwards")
+	flags.StringVar(&replayPath, "replay", "", "replay the repro in the given file instead of running interactively")
 	flags.Parse(os.Args[1:])
 	args := flags.Args()
 
-	if !(len(args) == 1 || len(args) == 2) {
+	if len(args) == 0 {
 		fmt.Println(usage)
 		flags.PrintDefaults()
 		os.Exit(1)
 	}
 
-	assetsPath := args[0]
+	// assets files are layered so that later files override assets in earlier files, and can be followed by a flow UUID
+	assetsPaths := args
 	var flowUUID assets.FlowUUID
-	if len(args) == 2 {
-		flowUUID = assets.FlowUUID(args[1])
+	if len(args) > 1 && uuids.IsV4(args[len(args)-1]) {
+		assetsPaths = args[:len(args)-1]
+		flowUUID = assets.FlowUUID(args[len(args)-1])
 	}
 
-	engine := createEngine(witToken)
+	if replayPath != "" {
+		reproJSON, err := os.ReadFile(replayPath)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 
-	repro, err := RunFlow(engine, assetsPath, flowUUID, initialMsg, envs.Language(contactLang), os.Stdin, os.Stdout)
+		warnings, err := ReplayFlow(assetsPaths, reproJSON, os.Stdout)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		for _, w := range warnings {
+			fmt.Printf("⚠️ diverged: %s\n", w)
+		}
+		return
+	}
+
+	// if we're printing a repro, record all service calls so they can be replayed
+	var recorder *engine.ServiceRecorder
+	if printRepro {
+		recorder = engine.NewServiceRecorder()
+	}
+
+	eng := createEngine(witToken, recorder)
+
+	repro, err := RunFlow(eng, assetsPaths, flowUUID, initialMsg, envs.Language(contactLang), os.Stdin, os.Stdout)
 
 	if err != nil {
 		fmt.Println(err.Error())
@@ -79,15 +107,31 @@ func main() {
 	}
 
 	if printRepro {
+		repro.Services = recorder.Bundle()
+
 		fmt.Println("---------------------------------------")
 		marshaledRepro, _ := jsonx.MarshalPretty(repro)
 		fmt.Println(string(marshaledRepro))
 	}
 }
 
-func createEngine(witToken string) flows.Engine {
+// loads the given assets files as layers, so that assets in later files override those in earlier files
+func loadAssets(paths []string) (*layered.Source, error) {
+	layers := make([]*layered.Layer, len(paths))
+	for i, path := range paths {
+		source, err := static.LoadSource(path)
+		if err != nil {
+			return nil, err
+		}
+		layers[i] = layered.NewLayer(path, source)
+	}
+	return layered.NewSource(layers...), nil
+}
+
+func createEngine(witToken string, recorder *engine.ServiceRecorder) flows.Engine {
 	builder := engine.NewBuilder().
-		WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, map[string]string{"User-Agent": "goflow-runner"}, 10000))
+		WithWebhookServiceFactory(webhooks.NewServiceFactory(http.DefaultClient, nil, nil, map[string]string{"User-Agent": "goflow-runner"}, 10000)).
+		WithServiceRecorder(recorder)
 
 	if witToken != "" {
 		builder.WithClassificationServiceFactory(func(session flows.Session, classifier *flows.Classifier) (flows.ClassificationService, error) {
@@ -102,24 +146,23 @@ func createEngine(witToken string) flows.Engine {
 }
 
 // RunFlow steps through a flow
-func RunFlow(eng flows.Engine, assetsPath string, flowUUID assets.FlowUUID, initialMsg string, contactLang envs.Language, in io.Reader, out io.Writer) (*Repro, error) {
-	assetsJSON, err := os.ReadFile(assetsPath)
+func RunFlow(eng flows.Engine, assetsPaths []string, flowUUID assets.FlowUUID, initialMsg string, contactLang envs.Language, in io.Reader, out io.Writer) (*Repro, error) {
+	source, err := loadAssets(assetsPaths)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error reading assets file '%s'", assetsPath)
+		return nil, err
 	}
 
-	// if user didn't provide a flow UUID, look for the UUID of the first flow
+	// if user didn't provide a flow UUID, use the first flow of the last assets file with flows
 	if flowUUID == "" {
-		uuidBytes, _, _, err := jsonparser.Get(assetsJSON, "flows", "[0]", "uuid")
-		if err != nil {
-			return nil, errors.New("no flows found in assets file")
+		for i := len(source.Layers()) - 1; i >= 0 && flowUUID == ""; i-- {
+			layerFlows, _ := source.Layers()[i].Source().(assets.FlowLister).Flows()
+			if len(layerFlows) > 0 {
+				flowUUID = layerFlows[0].UUID()
+			}
+		}
+		if flowUUID == "" {
+			return nil, errors.New("no flows found in assets files")
 		}
-		flowUUID = assets.FlowUUID(uuidBytes)
-	}
-
-	source, err := static.NewSource(assetsJSON)
-	if err != nil {
-		return nil, err
 	}
 
 	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
@@ -159,7 +202,12 @@ func RunFlow(eng flows.Engine, assetsPath string, flowUUID assets.FlowUUID, init
 
 		repro.Trigger = tb.Build()
 	}
-	fmt.Fprintf(out, "Starting flow '%s'....\n---------------------------------------\n", flow.Name())
+	if len(assetsPaths) > 1 {
+		layer, _ := source.Explain("flow", string(flow.UUID()))
+		fmt.Fprintf(out, "Starting flow '%s' from '%s'....\n---------------------------------------\n", flow.Name(), layer)
+	} else {
+		fmt.Fprintf(out, "Starting flow '%s'....\n---------------------------------------\n", flow.Name())
+	}
 
 	// start our session
 	session, sprint, err := eng.NewSession(sa, repro.Trigger)
@@ -203,6 +251,62 @@ func RunFlow(eng flows.Engine, assetsPath string, flowUUID assets.FlowUUID, init
 	return repro, nil
 }
 
+// ReplayFlow replays a repro using its recorded service calls and returns any warnings about divergence from the
+// recorded session
+func ReplayFlow(assetsPaths []string, reproJSON []byte, out io.Writer) ([]string, error) {
+	source, err := loadAssets(assetsPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing assets")
+	}
+
+	envelope := &reproEnvelope{}
+	if err := jsonx.Unmarshal(reproJSON, envelope); err != nil {
+		return nil, errors.Wrap(err, "error reading repro")
+	}
+
+	trigger, err := triggers.ReadTrigger(sa, envelope.Trigger, assets.PanicOnMissing)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading repro trigger")
+	}
+
+	bundle := envelope.Services
+	if bundle == nil {
+		bundle = &engine.ServiceBundle{}
+	}
+	replayer := engine.NewServiceReplayer(bundle)
+	eng := engine.NewBuilder().WithServiceReplayer(replayer).Build()
+
+	fmt.Fprintf(out, "Replaying flow '%s'....\n---------------------------------------\n", trigger.Flow().Name)
+
+	session, sprint, err := eng.NewSession(sa, trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	printEvents(sprint.Events(), out)
+
+	for i, resumeJSON := range envelope.Resumes {
+		resume, err := resumes.ReadResume(sa, resumeJSON, assets.PanicOnMissing)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading repro resume #%d", i+1)
+		}
+
+		sprint, err := session.Resume(resume)
+		if err != nil {
+			return nil, err
+		}
+
+		printEvents(sprint.Events(), out)
+	}
+
+	return replayer.Warnings(), nil
+}
+
 func createMessage(contact *flows.Contact, text string) *flows.MsgIn {
 	return flows.NewMsgIn(flows.MsgUUID(uuids.New()), contact.URNs()[0].URN(), nil, text, []utils.Attachment{})
 }
@@ -313,8 +417,15 @@ func PrintEvent(event flows.Event, out io.Writer) {
 	fmt.Fprint(out, msg)
 }
 
-// Repro describes the trigger and resumes needed to reproduce this session
+// Repro describes the trigger, resumes and service calls needed to reproduce this session
 type Repro struct {
-	Trigger flows.Trigger  `json:"trigger"`
-	Resumes []flows.Resume `json:"resumes,omitempty"`
+	Trigger  flows.Trigger         `json:"trigger"`
+	Resumes  []flows.Resume        `json:"resumes,omitempty"`
+	Services *engine.ServiceBundle `json:"services,omitempty"`
+}
+
+type reproEnvelope struct {
+	Trigger  json.RawMessage       `json:"trigger"`
+	Resumes  []json.RawMessage     `json:"resumes,omitempty"`
+	Services *engine.ServiceBundle `json:"services,omitempty"`
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/goflow/assets"
 	main "github.com/nyaruka/goflow/cmd/flowrunner"
@@ -22,7 +23,7 @@ func TestRunFlow(t *testing.T) {
 	in := strings.NewReader("I like red\npepsi\n")
 	out := &strings.Builder{}
 
-	_, err := main.RunFlow(test.NewEngine(), "testdata/two_questions.json", assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", in, out)
+	_, err := main.RunFlow(test.NewEngine(), []string{"testdata/two_questions.json"}, assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", in, out)
 	require.NoError(t, err)
 
 	// remove input prompts and split output by line to get each event
@@ -47,10 +48,47 @@ func TestRunFlow(t *testing.T) {
 	// run again but don't specify the flow
 	in = strings.NewReader("I like red\npepsi\n")
 	out = &strings.Builder{}
-	_, err = main.RunFlow(test.NewEngine(), "testdata/two_questions.json", "", "", "eng", in, out)
+	_, err = main.RunFlow(test.NewEngine(), []string{"testdata/two_questions.json"}, "", "", "eng", in, out)
 	require.NoError(t, err)
 
 	assert.Contains(t, out.String(), "Starting flow 'Two Questions'")
+
+	// run with a draft version of the flow layered over the original assets
+	in = strings.NewReader("I like red\npepsi\n")
+	out = &strings.Builder{}
+	_, err = main.RunFlow(test.NewEngine(), []string{"testdata/two_questions.json", "testdata/two_questions_draft.json"}, "", "", "eng", in, out)
+	require.NoError(t, err)
+
+	lines = strings.Split(strings.Replace(out.String(), "> ", "", -1), "\n")
+	assert.Equal(t, "Starting flow 'Two Questions (Draft)' from 'testdata/two_questions_draft.json'....", lines[0])
+	assert.Equal(t, "💬 message created \"Hey Ben Haggerty! What is your favorite color? (red/blue)\"", lines[2])
+
+	_, err = main.RunFlow(test.NewEngine(), []string{"testdata/two_questions.json", "testdata/missing.json"}, "", "", "eng", in, out)
+	assert.EqualError(t, err, "error reading file 'testdata/missing.json': open testdata/missing.json: no such file or directory")
+}
+
+func TestReplayFlow(t *testing.T) {
+	in := strings.NewReader("I like red\npepsi\n")
+	out := &strings.Builder{}
+
+	repro, err := main.RunFlow(test.NewEngine(), []string{"testdata/two_questions.json"}, assets.FlowUUID("615b8a0f-588c-4d20-a05f-363b0b4ce6f4"), "", "eng", in, out)
+	require.NoError(t, err)
+
+	runLines := strings.Split(strings.Replace(out.String(), "> ", "", -1), "\n")
+
+	// replay the repro and check we get the same events
+	out = &strings.Builder{}
+	warnings, err := main.ReplayFlow([]string{"testdata/two_questions.json"}, jsonx.MustMarshal(repro), out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, warnings)
+
+	replayLines := strings.Split(out.String(), "\n")
+
+	assert.Equal(t, "Replaying flow 'Two Questions'....", replayLines[0])
+	assert.Equal(t, runLines[1:], replayLines[1:])
+
+	_, err = main.ReplayFlow([]string{"testdata/two_questions.json"}, []byte(`{"trigger": {}}`), out)
+	assert.EqualError(t, err, "error reading repro trigger: field 'type' is required")
 }
 
 func TestPrintEvent(t *testing.T) {
@@ -66,8 +104,8 @@ func TestPrintEvent(t *testing.T) {
 		expected string
 	}{
 		{events.NewBroadcastCreated(map[envs.Language]*events.BroadcastTranslation{"eng": {Text: "hello"}}, "eng", nil, nil, nil), `🔉 broadcasted 'hello' to ...`},
-		{events.NewContactFieldChanged(sa.Fields().Get("gender"), flows.NewValue(types.NewXText("M"), nil, nil, "", "", "")), `✏️ field 'gender' changed to 'M'`},
-		{events.NewContactFieldChanged(sa.Fields().Get("gender"), nil), `✏️ field 'gender' cleared`},
+		{events.NewContactFieldChanged(sa.Fields().Get("gender"), flows.NewValue(types.NewXText("M"), nil, nil, "", "", ""), nil), `✏️ field 'gender' changed to 'M'`},
+		{events.NewContactFieldChanged(sa.Fields().Get("gender"), nil, nil), `✏️ field 'gender' cleared`},
 		{events.NewContactGroupsChanged([]*flows.Group{sa.Groups().Get("b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")}, nil), `👪 added to 'Testers'`},
 		{events.NewContactGroupsChanged(nil, []*flows.Group{sa.Groups().Get("b7cf0d83-f1c9-411c-96fd-c511a4cfa86d")}), `👪 removed from 'Testers'`},
 		{events.NewContactLanguageChanged("eng"), `🌐 language changed to 'eng'`},
@@ -0,0 +1,150 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowserver
+// flowserver -address :8800 -assets org_assets.json -enable-webhooks
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/services/airtime/dtone"
+	"github.com/nyaruka/goflow/services/classification/bothub"
+	"github.com/nyaruka/goflow/services/classification/wit"
+	"github.com/nyaruka/goflow/services/webhooks"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowserver [flags]`
+
+func main() {
+	config := NewDefaultConfig()
+
+	var assetsPath, userAgent, witToken, bothubToken, dtoneKey, dtoneSecret string
+	var webhookMaxBodyBytes int
+	var enableWebhooks bool
+	var httpTimeout time.Duration
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.StringVar(&config.Address, "address", config.Address, "address to listen on")
+	flags.Int64Var(&config.MaxBodyBytes, "max-body-bytes", config.MaxBodyBytes, "maximum size of request bodies")
+	flags.DurationVar(&config.ReadTimeout, "read-timeout", config.ReadTimeout, "maximum duration for reading requests")
+	flags.DurationVar(&config.WriteTimeout, "write-timeout", config.WriteTimeout, "maximum duration for writing responses")
+	flags.StringVar(&assetsPath, "assets", "", "assets file to use for requests which don't include assets")
+	flags.BoolVar(&enableWebhooks, "enable-webhooks", false, "enable webhook calls to public addresses")
+	flags.StringVar(&userAgent, "webhook-user-agent", "goflow-server", "user agent for webhook calls")
+	flags.IntVar(&webhookMaxBodyBytes, "webhook-max-body-bytes", 10000, "maximum size of webhook response bodies")
+	flags.DurationVar(&httpTimeout, "http-timeout", 15*time.Second, "timeout for webhook and service calls")
+	flags.StringVar(&witToken, "wit-token", "", "access token for classifiers of type wit")
+	flags.StringVar(&bothubToken, "bothub-token", "", "access token for classifiers of type bothub")
+	flags.StringVar(&dtoneKey, "dtone-key", "", "API key for airtime transfers with DT One")
+	flags.StringVar(&dtoneSecret, "dtone-secret", "", "API secret for airtime transfers with DT One")
+	flags.Usage = func() {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	var source assets.Source
+	if assetsPath != "" {
+		var err error
+		if source, err = static.LoadSource(assetsPath); err != nil {
+			log.Fatalf("error loading assets: %s", err)
+		}
+	}
+
+	// flows and assets come from request bodies so services can only access public addresses
+	httpClient := &http.Client{Timeout: httpTimeout}
+	httpAccess := newHTTPAccess()
+
+	// there are no ticketing service implementations in this repo, so flows which open tickets will fail
+	builder := engine.NewBuilder()
+	if enableWebhooks {
+		builder.WithWebhookServiceFactory(newWebhookServiceFactory(httpClient, httpAccess, userAgent, webhookMaxBodyBytes))
+	}
+	if witToken != "" || bothubToken != "" {
+		builder.WithClassificationServiceFactory(newClassificationServiceFactory(httpClient, witToken, bothubToken))
+	}
+	if dtoneKey != "" && dtoneSecret != "" {
+		builder.WithAirtimeServiceFactory(newAirtimeServiceFactory(httpClient, dtoneKey, dtoneSecret))
+	}
+
+	server := NewServer(config, builder.Build(), source)
+
+	// stop gracefully when we're signalled to stop
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		<-stop
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	log.Printf("flowserver listening on %s", config.Address)
+
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("error starting server: %s", err)
+	}
+
+	log.Print("flowserver stopped")
+}
+
+// private, loopback and link-local networks which webhooks aren't allowed to access
+var disallowedNets = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"0.0.0.0/8",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+func newHTTPAccess() *httpx.AccessConfig {
+	nets := make([]*net.IPNet, len(disallowedNets))
+	for i, cidr := range disallowedNets {
+		_, nets[i], _ = net.ParseCIDR(cidr)
+	}
+	return httpx.NewAccessConfig(10*time.Second, nil, nets)
+}
+
+func newWebhookServiceFactory(httpClient *http.Client, httpAccess *httpx.AccessConfig, userAgent string, maxBodyBytes int) engine.WebhookServiceFactory {
+	retries := httpx.NewFixedRetries(3*time.Second, 6*time.Second)
+	return webhooks.NewServiceFactory(httpClient, retries, httpAccess, map[string]string{"User-Agent": userAgent}, maxBodyBytes)
+}
+
+// LUIS classifiers need an endpoint, app and key per classifier which assets don't include, so aren't supported
+func newClassificationServiceFactory(httpClient *http.Client, witToken, bothubToken string) engine.ClassificationServiceFactory {
+	return func(session flows.Session, classifier *flows.Classifier) (flows.ClassificationService, error) {
+		switch {
+		case classifier.Type() == "wit" && witToken != "":
+			return wit.NewService(httpClient, nil, classifier, witToken), nil
+		case classifier.Type() == "bothub" && bothubToken != "":
+			return bothub.NewService(httpClient, nil, classifier, bothubToken), nil
+		}
+		return nil, errors.Errorf("no service configured for classifiers of type %s", classifier.Type())
+	}
+}
+
+func newAirtimeServiceFactory(httpClient *http.Client, key, secret string) engine.AirtimeServiceFactory {
+	return func(flows.Session) (flows.AirtimeService, error) {
+		return dtone.NewService(httpClient, nil, key, secret), nil
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPAccess(t *testing.T) {
+	access := newHTTPAccess()
+
+	tcs := []struct {
+		url     string
+		allowed bool
+	}{
+		{"http://127.0.0.1/", false},
+		{"http://10.1.2.3/", false},
+		{"http://192.168.1.1:8080/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[::1]/", false},
+		{"http://[fe80::1]/", false},
+		{"http://93.184.216.34/", true},
+	}
+
+	for _, tc := range tcs {
+		request, _ := http.NewRequest("GET", tc.url, nil)
+		allowed, err := access.Allow(request)
+		require.NoError(t, err)
+		assert.Equal(t, tc.allowed, allowed, "allowed mismatch for %s", tc.url)
+	}
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration of a flow server
+type Config struct {
+	Address      string
+	MaxBodyBytes int64
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// NewDefaultConfig returns the default configuration of a flow server
+func NewDefaultConfig() *Config {
+	return &Config{
+		Address:      ":8800",
+		MaxBodyBytes: 1024 * 1024,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+}
+
+// Server is an HTTP server which exposes the engine as a JSON API
+type Server struct {
+	config     *Config
+	engine     flows.Engine
+	assets     assets.Source
+	httpServer *http.Server
+}
+
+// NewServer creates a new flow server which runs sessions on the given engine. If a source of assets is provided, it
+// is used for requests which don't include their own assets.
+func NewServer(config *Config, eng flows.Engine, source assets.Source) *Server {
+	s := &Server{config: config, engine: eng, assets: source}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handle(http.MethodGet, s.health))
+	mux.HandleFunc("/flow/start", s.handle(http.MethodPost, s.startSession))
+	mux.HandleFunc("/flow/resume", s.handle(http.MethodPost, s.resumeSession))
+	mux.HandleFunc("/flow/inspect", s.handle(http.MethodPost, s.inspectFlow))
+	mux.HandleFunc("/flow/migrate", s.handle(http.MethodPost, s.migrateFlow))
+	mux.HandleFunc("/expression/evaluate", s.handle(http.MethodPost, s.evaluateExpression))
+	mux.HandleFunc("/contactql/parse", s.handle(http.MethodPost, s.parseQuery))
+
+	s.httpServer = &http.Server{
+		Addr:         config.Address,
+		Handler:      mux,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+	return s
+}
+
+// Handler returns the HTTP handler of this server
+func (s *Server) Handler() http.Handler { return s.httpServer.Handler }
+
+// ListenAndServe starts listening for requests, and blocks until the server is stopped
+func (s *Server) ListenAndServe() error {
+	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Stop gracefully stops this server, waiting for active requests to complete
+func (s *Server) Stop(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+//------------------------------------------------------------------------------------------
+// Request handling
+//------------------------------------------------------------------------------------------
+
+// a handler returns a response to be marshaled as JSON, or an error
+type jsonHandler func(*http.Request, []byte) (interface{}, error)
+
+// an error which should be returned to the client with a specific status code, and optionally a specific response
+type httpError struct {
+	status   int
+	err      error
+	response interface{}
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(err error) error { return &httpError{status: http.StatusBadRequest, err: err} }
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// wraps a JSON handler so that it only accepts the given method, and reads the body of the request with a limit
+func (s *Server) handle(method string, handler jsonHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, &errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, &errorResponse{Error: "request body exceeds limit"})
+			return
+		}
+
+		response, err := handler(r, body)
+		if err != nil {
+			status := http.StatusInternalServerError
+			var errResponse interface{} = &errorResponse{Error: err.Error()}
+
+			if httpErr, isHTTPErr := err.(*httpError); isHTTPErr {
+				status = httpErr.status
+				if httpErr.response != nil {
+					errResponse = httpErr.response
+				}
+			}
+			writeJSON(w, status, errResponse)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, response interface{}) {
+	body, err := jsonx.Marshal(response)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = jsonx.MustMarshal(&errorResponse{Error: "unable to marshal response"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// reads and validates a request body
+func readRequest(body []byte, request interface{}) error {
+	if err := utils.UnmarshalAndValidate(body, request); err != nil {
+		return badRequest(errors.Wrap(err, "invalid request"))
+	}
+	return nil
+}
+
+// reads session assets from the given inline assets, or uses the assets of the server if there aren't any
+func (s *Server) readAssets(data json.RawMessage, required bool) (flows.SessionAssets, error) {
+	var source assets.Source
+	var err error
+
+	if len(data) > 0 {
+		if source, err = static.NewSource(data); err != nil {
+			return nil, badRequest(errors.Wrap(err, "unable to read assets"))
+		}
+	} else if s.assets != nil {
+		source = s.assets
+	} else if required {
+		return nil, badRequest(errors.New("request must include assets"))
+	} else {
+		return nil, nil
+	}
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to load assets"))
+	}
+	return sa, nil
+}
+
+// reads an environment, or returns the default environment if none is provided
+func readEnvironment(data json.RawMessage) (envs.Environment, error) {
+	if len(data) == 0 {
+		return envs.NewBuilder().Build(), nil
+	}
+
+	env, err := envs.ReadEnvironment(data)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to read environment"))
+	}
+	return env, nil
+}
+
+//------------------------------------------------------------------------------------------
+// Endpoints
+//------------------------------------------------------------------------------------------
+
+type healthResponse struct {
+	Status      string `json:"status"`
+	SpecVersion string `json:"spec_version"`
+}
+
+// GET /health
+func (s *Server) health(r *http.Request, body []byte) (interface{}, error) {
+	return &healthResponse{Status: "ok", SpecVersion: definition.CurrentSpecVersion.String()}, nil
+}
+
+type sessionResponse struct {
+	Session   flows.Session    `json:"session"`
+	Events    []flows.Event    `json:"events"`
+	Segments  []flows.Segment  `json:"segments"`
+	Modifiers []flows.Modifier `json:"modifiers"`
+}
+
+func newSessionResponse(session flows.Session, sprint flows.Sprint) *sessionResponse {
+	return &sessionResponse{Session: session, Events: sprint.Events(), Segments: sprint.Segments(), Modifiers: sprint.Modifiers()}
+}
+
+type startRequest struct {
+	Assets  json.RawMessage `json:"assets"`
+	Trigger json.RawMessage `json:"trigger" validate:"required"`
+}
+
+// POST /flow/start
+//
+//   {
+//     "assets": {"flows": [...]},
+//     "trigger": {"type": "manual", "flow": {...}, "contact": {...}, ...}
+//   }
+func (s *Server) startSession(r *http.Request, body []byte) (interface{}, error) {
+	request := &startRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	sa, err := s.readAssets(request.Assets, true)
+	if err != nil {
+		return nil, err
+	}
+
+	trigger, err := triggers.ReadTrigger(sa, request.Trigger, assets.IgnoreMissing)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to read trigger"))
+	}
+
+	session, sprint, err := s.engine.NewSession(sa, trigger)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to start session"))
+	}
+
+	return newSessionResponse(session, sprint), nil
+}
+
+type resumeRequest struct {
+	Assets  json.RawMessage `json:"assets"`
+	Session json.RawMessage `json:"session" validate:"required"`
+	Resume  json.RawMessage `json:"resume" validate:"required"`
+}
+
+// POST /flow/resume
+//
+//   {
+//     "assets": {"flows": [...]},
+//     "session": {"uuid": "...", ...},
+//     "resume": {"type": "msg", "msg": {...}, ...}
+//   }
+func (s *Server) resumeSession(r *http.Request, body []byte) (interface{}, error) {
+	request := &resumeRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	sa, err := s.readAssets(request.Assets, true)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.engine.ReadSession(sa, request.Session, assets.IgnoreMissing)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to read session"))
+	}
+
+	resume, err := resumes.ReadResume(sa, request.Resume, assets.IgnoreMissing)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to read resume"))
+	}
+
+	sprint, err := session.Resume(resume)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to resume session"))
+	}
+
+	return newSessionResponse(session, sprint), nil
+}
+
+type inspectRequest struct {
+	Flow   json.RawMessage `json:"flow" validate:"required"`
+	Assets json.RawMessage `json:"assets"`
+}
+
+// POST /flow/inspect
+//
+//   {
+//     "flow": {"uuid": "...", "nodes": [...], ...},
+//     "assets": {"fields": [...]}
+//   }
+func (s *Server) inspectFlow(r *http.Request, body []byte) (interface{}, error) {
+	request := &inspectRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	flow, err := definition.ReadFlow(request.Flow, nil)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to read flow"))
+	}
+
+	// inspection without assets skips checking of dependencies
+	sa, err := s.readAssets(request.Assets, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.Inspect(sa), nil
+}
+
+type migrateRequest struct {
+	Flow      json.RawMessage `json:"flow" validate:"required"`
+	ToVersion string          `json:"to_version"`
+}
+
+// POST /flow/migrate
+//
+//   {
+//     "flow": {"uuid": "...", "spec_version": "13.0.0", ...},
+//     "to_version": "13.1.0"
+//   }
+func (s *Server) migrateFlow(r *http.Request, body []byte) (interface{}, error) {
+	request := &migrateRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	toVersion := definition.CurrentSpecVersion
+	if request.ToVersion != "" {
+		var err error
+		if toVersion, err = semver.NewVersion(request.ToVersion); err != nil {
+			return nil, badRequest(errors.Wrapf(err, "invalid version '%s'", request.ToVersion))
+		}
+	}
+
+	migrated, err := migrations.MigrateToVersion(request.Flow, toVersion, nil)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "unable to migrate flow"))
+	}
+
+	return json.RawMessage(migrated), nil
+}
+
+type evaluateRequest struct {
+	Template    string          `json:"template" validate:"required"`
+	Context     json.RawMessage `json:"context"`
+	Environment json.RawMessage `json:"environment"`
+}
+
+type evaluateResponse struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// POST /expression/evaluate
+//
+//   {
+//     "template": "Hi @contact.name",
+//     "context": {"contact": {"name": "Bob"}},
+//     "environment": {"timezone": "Africa/Kigali", ...}
+//   }
+func (s *Server) evaluateExpression(r *http.Request, body []byte) (interface{}, error) {
+	request := &evaluateRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	env, err := readEnvironment(request.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := types.XObjectEmpty
+	if len(request.Context) > 0 {
+		asObject, isObject := types.JSONToXValue(request.Context).(*types.XObject)
+		if !isObject {
+			return nil, badRequest(errors.New("context must be a JSON object"))
+		}
+		ctx = asObject
+	}
+
+	response := &evaluateResponse{}
+	response.Output, err = excellent.EvaluateTemplate(env, ctx, request.Template, nil)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	return response, nil
+}
+
+type parseRequest struct {
+	Query       string          `json:"query" validate:"required"`
+	Environment json.RawMessage `json:"environment"`
+	Assets      json.RawMessage `json:"assets"`
+}
+
+type parseResponse struct {
+	Query      string                `json:"query"`
+	Inspection *contactql.Inspection `json:"inspection"`
+}
+
+type parseErrorResponse struct {
+	Error *contactql.QueryError `json:"error"`
+}
+
+// POST /contactql/parse
+//
+//   {
+//     "query": "age > 10 AND name ~ bob",
+//     "environment": {"redaction_policy": "none", ...},
+//     "assets": {"fields": [...], "groups": [...]}
+//   }
+func (s *Server) parseQuery(r *http.Request, body []byte) (interface{}, error) {
+	request := &parseRequest{}
+	if err := readRequest(body, request); err != nil {
+		return nil, err
+	}
+
+	env, err := readEnvironment(request.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// without assets, fields and groups aren't checked
+	sa, err := s.readAssets(request.Assets, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolver contactql.Resolver
+	if sa != nil {
+		resolver = sa
+	}
+
+	query, err := contactql.ParseQuery(env, request.Query, resolver)
+	if err != nil {
+		if isQueryErr, qerr := contactql.IsQueryError(err); isQueryErr {
+			return nil, &httpError{status: http.StatusBadRequest, err: err, response: &parseErrorResponse{Error: qerr.(*contactql.QueryError)}}
+		}
+		return nil, badRequest(err)
+	}
+
+	return &parseResponse{Query: query.String(), Inspection: contactql.Inspect(query)}, nil
+}
@@ -0,0 +1,255 @@
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets/static"
+	main "github.com/nyaruka/goflow/cmd/flowserver"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const triggerJSON = `{
+	"type": "manual",
+	"flow": {"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Two Questions"},
+	"contact": {"uuid": "ba96bf7f-bc2a-4873-a7c7-254d1927c4e3", "name": "Ben Haggerty", "status": "active", "language": "eng", "created_on": "2018-01-01T12:00:00Z", "urns": ["tel:+12065551212"]},
+	"triggered_on": "2018-10-18T14:20:30.000000Z"
+}`
+
+const resumeJSON = `{
+	"type": "msg",
+	"msg": {"uuid": "9bf91c2b-ce58-4cef-aacc-281e03f69ab5", "urn": "tel:+12065551212", "text": "I like red"},
+	"resumed_on": "2018-10-18T14:21:30.000000Z"
+}`
+
+func newTestServer(t *testing.T, config *main.Config, withAssets bool) *httptest.Server {
+	if config == nil {
+		config = main.NewDefaultConfig()
+	}
+
+	var server *main.Server
+	if withAssets {
+		source, err := static.LoadSource("../flowrunner/testdata/two_questions.json")
+		require.NoError(t, err)
+
+		server = main.NewServer(config, test.NewEngine(), source)
+	} else {
+		server = main.NewServer(config, test.NewEngine(), nil)
+	}
+
+	return httptest.NewServer(server.Handler())
+}
+
+func request(t *testing.T, server *httptest.Server, method, path, body string) (int, []byte) {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, respBody
+}
+
+func TestHealth(t *testing.T) {
+	server := newTestServer(t, nil, false)
+	defer server.Close()
+
+	status, body := request(t, server, "GET", "/health", "")
+	assert.Equal(t, 200, status)
+	test.AssertEqualJSON(t, []byte(`{"status": "ok", "spec_version": "13.2.0"}`), body, "health response mismatch")
+
+	status, body = request(t, server, "POST", "/health", "")
+	assert.Equal(t, 405, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "method not allowed"}`), body, "health response mismatch")
+}
+
+func TestStartAndResume(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../flowrunner/testdata/two_questions.json")
+	require.NoError(t, err)
+
+	server := newTestServer(t, nil, false)
+	defer server.Close()
+
+	// start a session with inline assets
+	status, body := request(t, server, "POST", "/flow/start", fmt.Sprintf(`{"assets": %s, "trigger": %s}`, assetsJSON, triggerJSON))
+	require.Equal(t, 200, status, string(body))
+
+	sessionJSON, _, _, err := jsonparser.Get(body, "session")
+	require.NoError(t, err)
+
+	sessionStatus, _ := jsonparser.GetString(sessionJSON, "status")
+	assert.Equal(t, "waiting", sessionStatus)
+	assert.Equal(t, []string{"msg_created", "msg_wait"}, eventTypes(t, body))
+
+	// resume it
+	status, body = request(t, server, "POST", "/flow/resume", fmt.Sprintf(`{"assets": %s, "session": %s, "resume": %s}`, assetsJSON, sessionJSON, resumeJSON))
+	require.Equal(t, 200, status, string(body))
+
+	assert.Equal(t, []string{"msg_received", "run_result_changed", "contact_language_changed", "msg_created", "msg_wait"}, eventTypes(t, body))
+
+	segments, _, _, _ := jsonparser.Get(body, "segments")
+	assert.True(t, len(segments) > 2)
+
+	// trying to start a session without assets is an error if the server doesn't have its own
+	status, body = request(t, server, "POST", "/flow/start", fmt.Sprintf(`{"trigger": %s}`, triggerJSON))
+	assert.Equal(t, 400, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "request must include assets"}`), body, "error response mismatch")
+
+	// as is sending invalid requests
+	status, body = request(t, server, "POST", "/flow/start", `{"assets": {}}`)
+	assert.Equal(t, 400, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "invalid request: field 'trigger' is required"}`), body, "error response mismatch")
+
+	status, body = request(t, server, "POST", "/flow/start", `[]`)
+	assert.Equal(t, 400, status)
+	assert.Contains(t, string(body), "invalid request")
+
+	status, body = request(t, server, "POST", "/flow/resume", fmt.Sprintf(`{"assets": %s, "session": {}, "resume": %s}`, assetsJSON, resumeJSON))
+	assert.Equal(t, 400, status)
+	assert.Contains(t, string(body), "unable to read session")
+
+	// server with its own assets can be used without including assets in requests
+	server = newTestServer(t, nil, true)
+	defer server.Close()
+
+	status, body = request(t, server, "POST", "/flow/start", fmt.Sprintf(`{"trigger": %s}`, triggerJSON))
+	require.Equal(t, 200, status, string(body))
+	assert.Equal(t, []string{"msg_created", "msg_wait"}, eventTypes(t, body))
+}
+
+func TestRequestLimits(t *testing.T) {
+	config := main.NewDefaultConfig()
+	config.MaxBodyBytes = 100
+
+	server := newTestServer(t, config, true)
+	defer server.Close()
+
+	status, body := request(t, server, "POST", "/flow/start", fmt.Sprintf(`{"trigger": %s}`, triggerJSON))
+	assert.Equal(t, 413, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "request body exceeds limit"}`), body, "error response mismatch")
+}
+
+func TestInspectAndMigrate(t *testing.T) {
+	assetsJSON, err := os.ReadFile("../flowrunner/testdata/two_questions.json")
+	require.NoError(t, err)
+
+	flowJSON, _, _, err := jsonparser.Get(assetsJSON, "flows", "[0]")
+	require.NoError(t, err)
+
+	server := newTestServer(t, nil, false)
+	defer server.Close()
+
+	// inspect without assets
+	status, body := request(t, server, "POST", "/flow/inspect", fmt.Sprintf(`{"flow": %s}`, flowJSON))
+	require.Equal(t, 200, status, string(body))
+
+	results, _, _, _ := jsonparser.Get(body, "results")
+	assert.Contains(t, string(results), `"key":"favorite_color"`)
+
+	// and with assets
+	status, body = request(t, server, "POST", "/flow/inspect", fmt.Sprintf(`{"flow": %s, "assets": %s}`, flowJSON, assetsJSON))
+	require.Equal(t, 200, status, string(body))
+
+	deps, _, _, _ := jsonparser.Get(body, "dependencies")
+	assert.NotContains(t, string(deps), `"missing":true`)
+
+	status, body = request(t, server, "POST", "/flow/inspect", `{"flow": {"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4"}}`)
+	assert.Equal(t, 400, status)
+	assert.Contains(t, string(body), "unable to read flow")
+
+	// migrate a flow to a specific version
+	status, body = request(t, server, "POST", "/flow/migrate", `{"flow": {"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "name": "Empty", "spec_version": "13.0.0", "language": "eng", "type": "messaging", "nodes": []}, "to_version": "13.1.0"}`)
+	require.Equal(t, 200, status, string(body))
+
+	specVersion, _ := jsonparser.GetString(body, "spec_version")
+	assert.Equal(t, "13.1.0", specVersion)
+
+	status, body = request(t, server, "POST", "/flow/migrate", `{"flow": {"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02"}, "to_version": "x"}`)
+	assert.Equal(t, 400, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "invalid version 'x': Invalid Semantic Version"}`), body, "error response mismatch")
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	server := newTestServer(t, nil, false)
+	defer server.Close()
+
+	status, body := request(t, server, "POST", "/expression/evaluate", `{"template": "Hi @contact.name, you are @(contact.age + 1)", "context": {"contact": {"name": "Bob", "age": 32}}}`)
+	assert.Equal(t, 200, status)
+	test.AssertEqualJSON(t, []byte(`{"output": "Hi Bob, you are 33"}`), body, "evaluate response mismatch")
+
+	status, body = request(t, server, "POST", "/expression/evaluate", `{"template": "@(format_date(\"2022-03-01\"))", "environment": {"date_format": "DD-MM-YYYY", "time_format": "tt:mm", "timezone": "UTC"}}`)
+	assert.Equal(t, 200, status)
+	test.AssertEqualJSON(t, []byte(`{"output": "01-03-2022"}`), body, "evaluate response mismatch")
+
+	// evaluation errors are returned with the output
+	status, body = request(t, server, "POST", "/expression/evaluate", `{"template": "Hi @(1 / 0)"}`)
+	assert.Equal(t, 200, status)
+	test.AssertEqualJSON(t, []byte(`{"output": "Hi ", "error": "error evaluating @(1 / 0): division by zero"}`), body, "evaluate response mismatch")
+
+	status, body = request(t, server, "POST", "/expression/evaluate", `{"template": "@foo", "context": [1, 2]}`)
+	assert.Equal(t, 400, status)
+	test.AssertEqualJSON(t, []byte(`{"error": "context must be a JSON object"}`), body, "error response mismatch")
+}
+
+func TestParseQuery(t *testing.T) {
+	server := newTestServer(t, nil, true)
+	defer server.Close()
+
+	status, body := request(t, server, "POST", "/contactql/parse", `{"query": "gender = m OR tel:+12065551212"}`)
+	assert.Equal(t, 200, status)
+	test.AssertEqualJSON(t, []byte(`{
+		"query": "gender = \"m\" OR tel = \"+12065551212\"",
+		"inspection": {
+			"attributes": [],
+			"schemes": ["tel"],
+			"fields": [{"key": "gender", "name": "Gender"}],
+			"groups": [],
+			"allow_as_group": true
+		}
+	}`), body, "parse response mismatch")
+
+	status, body = request(t, server, "POST", "/contactql/parse", `{"query": "xyz = 123"}`)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "unknown_property", mustGetString(t, body, "error", "code"))
+
+	status, body = request(t, server, "POST", "/contactql/parse", `{"query": "name = "}`)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "unexpected_token", mustGetString(t, body, "error", "code"))
+}
+
+func eventTypes(t *testing.T, body []byte) []string {
+	var resp struct {
+		Events []struct {
+			Type string `json:"type"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+
+	types := make([]string, len(resp.Events))
+	for i := range resp.Events {
+		types[i] = resp.Events[i].Type
+	}
+	return types
+}
+
+func mustGetString(t *testing.T, body []byte, path ...string) string {
+	v, err := jsonparser.GetString(body, path...)
+	require.NoError(t, err, string(body))
+	return v
+}
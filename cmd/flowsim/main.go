@@ -0,0 +1,95 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowsim
+// flowsim assets.json contacts.json simulation.json
+// flowsim -json assets.json contacts.json simulation.json
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/test/simulation"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowsim [flags] <assets.json> <contacts.json> <simulation.json>`
+
+func main() {
+	var asJSON bool
+	var seed int64
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.BoolVar(&asJSON, "json", false, "output the report as JSON")
+	flags.Int64Var(&seed, "seed", 0, "seed for random routers and sampling, overriding the simulation file")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	if len(args) != 3 {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if err := FlowSim(args[0], args[1], args[2], seed, asJSON, os.Stdout); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+// FlowSim runs the simulation described in the given file for the contacts in the given file, and writes the report
+// to out. If seed is non-zero it overrides the seed in the simulation file.
+func FlowSim(assetsPath, contactsPath, configPath string, seed int64, asJSON bool, out io.Writer) error {
+	env := envs.NewBuilder().Build()
+
+	source, err := static.LoadSource(assetsPath)
+	if err != nil {
+		return err
+	}
+
+	sa, err := engine.NewSessionAssets(env, source, &migrations.Config{BaseMediaURL: "http://temba.io"})
+	if err != nil {
+		return errors.Wrap(err, "error reading assets")
+	}
+
+	contactsJSON, err := os.ReadFile(contactsPath)
+	if err != nil {
+		return errors.Wrapf(err, "error reading contacts file '%s'", contactsPath)
+	}
+
+	contacts, err := simulation.ReadContacts(sa, contactsJSON)
+	if err != nil {
+		return err
+	}
+
+	config, err := simulation.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if seed != 0 {
+		config.Seed = seed
+	}
+
+	report, err := simulation.Simulate(env, sa, contacts, config)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		reportJSON, err := jsonx.MarshalPretty(report)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(reportJSON))
+	} else {
+		report.Write(out)
+	}
+	return nil
+}
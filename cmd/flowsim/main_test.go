@@ -0,0 +1,39 @@
+package main_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	main "github.com/nyaruka/goflow/cmd/flowsim"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowSim(t *testing.T) {
+	assetsPath := "../../test/simulation/testdata/assets.json"
+	contactsPath := "../../test/simulation/testdata/contacts.json"
+	configPath := "../../test/simulation/testdata/simulation.json"
+
+	out := &bytes.Buffer{}
+	err := main.FlowSim(assetsPath, contactsPath, configPath, 0, false, out)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "Simulated 9 of 12 contacts")
+	assert.Contains(t, out.String(), "Gender (Favorite Colors) 9 (100.0%)")
+
+	out.Reset()
+	err = main.FlowSim(assetsPath, contactsPath, configPath, 456, true, out)
+	require.NoError(t, err)
+
+	simulated, err := jsonparser.GetInt(out.Bytes(), "simulated")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), simulated)
+
+	err = main.FlowSim(assetsPath, "testdata/xxx.json", configPath, 0, false, out)
+	assert.EqualError(t, err, "error reading contacts file 'testdata/xxx.json': open testdata/xxx.json: no such file or directory")
+
+	err = main.FlowSim(assetsPath, contactsPath, "testdata/xxx.json", 0, false, out)
+	assert.EqualError(t, err, "error reading simulation file 'testdata/xxx.json': open testdata/xxx.json: no such file or directory")
+}
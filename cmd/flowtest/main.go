@@ -0,0 +1,146 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowtest
+// flowtest assets.json registration.json survey.json
+// flowtest -update assets.json registration.json
+// flowtest -coverage coverage.json -coverage-html coverage.html assets.json registration.json
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/test/coverage"
+	"github.com/nyaruka/goflow/test/scenario"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowtest [flags] <assets.json> <scenarios.json>...`
+
+func main() {
+	var update bool
+	var coverageJSON, coverageHTML string
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.BoolVar(&update, "update", false, "rewrite expected messages and expectations from actual output")
+	flags.StringVar(&coverageJSON, "coverage", "", "write a JSON coverage report to this file")
+	flags.StringVar(&coverageHTML, "coverage-html", "", "write an HTML coverage report to this file")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	if len(args) < 2 {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	var collector *coverage.Collector
+	if coverageJSON != "" || coverageHTML != "" {
+		collector = coverage.NewCollector()
+	}
+
+	passed, err := FlowTest(args[0], args[1:], update, collector, os.Stdout)
+	if err == nil && collector != nil {
+		err = WriteCoverage(collector.Report(), coverageJSON, coverageHTML)
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(2)
+	}
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+// FlowTest runs the scenarios in the given files against the flows in the given assets file, writing the outcome of
+// each scenario to out. If update is set, the scenario files are rewritten with the actual messages and state. If a
+// coverage collector is provided, the sessions of scenarios are added to it.
+func FlowTest(assetsPath string, suitePaths []string, update bool, collector *coverage.Collector, out io.Writer) (bool, error) {
+	source, err := static.LoadSource(assetsPath)
+	if err != nil {
+		return false, err
+	}
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, &migrations.Config{BaseMediaURL: "http://temba.io"})
+	if err != nil {
+		return false, errors.Wrap(err, "error reading assets")
+	}
+
+	allPassed := true
+
+	for _, path := range suitePaths {
+		suite, err := scenario.LoadSuite(path)
+		if err != nil {
+			return false, err
+		}
+
+		var results []*scenario.Result
+		if update {
+			results, err = scenario.Update(sa, suite)
+		} else {
+			results, err = scenario.RunWithCoverage(sa, suite, collector)
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "error running scenarios in '%s'", path)
+		}
+
+		fmt.Fprintf(out, "%s (%s)\n", path, suite.Flow.Name)
+
+		for _, r := range results {
+			if r.Passed() {
+				fmt.Fprintf(out, "  ✓ %s\n", r.Description)
+			} else {
+				allPassed = false
+				fmt.Fprintf(out, "  ✗ %s\n", r.Description)
+				for _, f := range r.Failures {
+					fmt.Fprintf(out, "      %s\n", f)
+				}
+			}
+		}
+
+		if update {
+			updated, err := jsonx.MarshalPretty(suite)
+			if err != nil {
+				return false, err
+			}
+			if err := os.WriteFile(path, append(updated, '\n'), 0666); err != nil {
+				return false, errors.Wrapf(err, "error writing scenarios file '%s'", path)
+			}
+		}
+	}
+
+	return allPassed, nil
+}
+
+// WriteCoverage writes the given coverage report as JSON and/or HTML to the given paths
+func WriteCoverage(report *coverage.Report, jsonPath, htmlPath string) error {
+	if jsonPath != "" {
+		reportJSON, err := jsonx.MarshalPretty(report)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(jsonPath, reportJSON, 0666); err != nil {
+			return errors.Wrapf(err, "error writing coverage file '%s'", jsonPath)
+		}
+	}
+
+	if htmlPath != "" {
+		f, err := os.Create(htmlPath)
+		if err != nil {
+			return errors.Wrapf(err, "error writing coverage file '%s'", htmlPath)
+		}
+		defer f.Close()
+
+		if err := report.WriteHTML(f); err != nil {
+			return errors.Wrapf(err, "error writing coverage file '%s'", htmlPath)
+		}
+	}
+	return nil
+}
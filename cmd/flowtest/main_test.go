@@ -0,0 +1,79 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	main "github.com/nyaruka/goflow/cmd/flowtest"
+	"github.com/nyaruka/goflow/test/coverage"
+	"github.com/nyaruka/goflow/test/scenario"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowTest(t *testing.T) {
+	assetsPath := "../../test/scenario/testdata/assets.json"
+	suitePath := "../../test/scenario/testdata/support.json"
+
+	out := &strings.Builder{}
+	passed, err := main.FlowTest(assetsPath, []string{suitePath}, false, nil, out)
+	require.NoError(t, err)
+	assert.True(t, passed)
+	assert.Equal(t, "../../test/scenario/testdata/support.json (Support)\n  ✓ billing question\n  ✓ other question opens ticket\n", out.String())
+
+	// copy the scenarios and break one
+	original, err := os.ReadFile(suitePath)
+	require.NoError(t, err)
+
+	brokenPath := filepath.Join(t.TempDir(), "support.json")
+	err = os.WriteFile(brokenPath, []byte(strings.Replace(string(original), `"plan": "Gold"`, `"plan": "Silver"`, 1)), 0666)
+	require.NoError(t, err)
+
+	out = &strings.Builder{}
+	passed, err = main.FlowTest(assetsPath, []string{brokenPath}, false, nil, out)
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Contains(t, out.String(), "  ✗ billing question\n      field 'plan': expected \"Silver\", got \"Gold\"\n")
+
+	// update should fix it
+	out = &strings.Builder{}
+	passed, err = main.FlowTest(assetsPath, []string{brokenPath}, true, nil, out)
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	updated, err := scenario.LoadSuite(brokenPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Gold", updated.Scenarios[0].Expect.Fields["plan"])
+
+	passed, err = main.FlowTest(assetsPath, []string{brokenPath}, false, nil, &strings.Builder{})
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	_, err = main.FlowTest(assetsPath, []string{"missing.json"}, false, nil, out)
+	assert.EqualError(t, err, "error reading scenarios file 'missing.json': open missing.json: no such file or directory")
+}
+
+func TestCoverage(t *testing.T) {
+	collector := coverage.NewCollector()
+
+	passed, err := main.FlowTest("../../test/scenario/testdata/assets.json", []string{"../../test/scenario/testdata/support.json"}, false, collector, &strings.Builder{})
+	require.NoError(t, err)
+	assert.True(t, passed)
+
+	dir := t.TempDir()
+	jsonPath, htmlPath := filepath.Join(dir, "coverage.json"), filepath.Join(dir, "coverage.html")
+
+	err = main.WriteCoverage(collector.Report(), jsonPath, htmlPath)
+	require.NoError(t, err)
+
+	reportJSON, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(reportJSON), `"sessions": 2`)
+
+	reportHTML, err := os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(reportHTML), "<h2>Support</h2>")
+}
@@ -0,0 +1,106 @@
+package main
+
+// go install github.com/nyaruka/goflow/cmd/flowviz
+// flowviz assets.json 76f0a02f-3b75-4b86-9064-e9195e1b3a02
+// flowviz -format dot assets.json 76f0a02f-3b75-4b86-9064-e9195e1b3a02 | dot -Tsvg > flow.svg
+// flowviz -visits report.json assets.json 76f0a02f-3b75-4b86-9064-e9195e1b3a02
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/definition/migrations"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/inspect/diagram"
+	"github.com/nyaruka/goflow/test/simulation"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: flowviz [flags] <assets.json> <flow_uuid>`
+
+func main() {
+	var format, flowURL, visitsPath string
+
+	flags := flag.NewFlagSet("", flag.ExitOnError)
+	flags.StringVar(&format, "format", "mermaid", "output format of diagram: mermaid or dot")
+	flags.StringVar(&flowURL, "flow-url", "", "URL template for links to other flows, where {uuid} is replaced by the flow UUID")
+	flags.StringVar(&visitsPath, "visits", "", "overlay visit counts from the segments of a flowsim JSON report")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	if len(args) != 2 {
+		fmt.Println(usage)
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if err := FlowViz(args[0], assets.FlowUUID(args[1]), format, flowURL, visitsPath, os.Stdout); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+// FlowViz renders a diagram of the given flow in the given assets file and writes it to out
+func FlowViz(assetsPath string, flowUUID assets.FlowUUID, format, flowURL, visitsPath string, out io.Writer) error {
+	source, err := static.LoadSource(assetsPath)
+	if err != nil {
+		return err
+	}
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, &migrations.Config{BaseMediaURL: "http://temba.io"})
+	if err != nil {
+		return errors.Wrap(err, "error reading assets")
+	}
+
+	flow, err := sa.Flows().Get(flowUUID)
+	if err != nil {
+		return err
+	}
+
+	options := &diagram.Options{FlowURL: flowURL}
+
+	if visitsPath != "" {
+		options.Visits, err = loadVisits(visitsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	d := diagram.New(flow, options)
+
+	switch format {
+	case "mermaid":
+		_, err := io.WriteString(out, d.Mermaid())
+		return err
+	case "dot":
+		_, err := io.WriteString(out, d.DOT())
+		return err
+	}
+	return errors.Errorf("unknown output format '%s'", format)
+}
+
+// loads visit counts from the segments of a report written by flowsim -json
+func loadVisits(path string) (*diagram.Visits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading visits file '%s'", path)
+	}
+
+	report := &simulation.Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, errors.Wrapf(err, "error reading visits file '%s'", path)
+	}
+
+	visits := diagram.NewVisits()
+	for _, s := range report.Segments {
+		visits.Add(s.ExitUUID, s.DestinationUUID, s.Count)
+	}
+	return visits, nil
+}
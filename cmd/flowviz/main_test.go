@@ -0,0 +1,40 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	main "github.com/nyaruka/goflow/cmd/flowviz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowViz(t *testing.T) {
+	assetsPath := "../../flows/inspect/diagram/testdata/assets.json"
+
+	out := &strings.Builder{}
+	err := main.FlowViz(assetsPath, "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "mermaid", "", "", out)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "%% Registration (76f0a02f-3b75-4b86-9064-e9195e1b3a02)\nflowchart TD\n"))
+
+	out = &strings.Builder{}
+	err = main.FlowViz(assetsPath, "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "dot", "https://textit.com/flow/editor/{uuid}/", "", out)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "digraph flow {"))
+	assert.Contains(t, out.String(), `URL="https://textit.com/flow/editor/8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2/"`)
+
+	out = &strings.Builder{}
+	err = main.FlowViz(assetsPath, "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "mermaid", "", "testdata/report.json", out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `n2 -->|"Red, Blue (2)"| n4`)
+	assert.Contains(t, out.String(), "linkStyle 2,4 stroke:#bbb,stroke-dasharray:3")
+
+	err = main.FlowViz(assetsPath, "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "svg", "", "", out)
+	assert.EqualError(t, err, "unknown output format 'svg'")
+
+	err = main.FlowViz(assetsPath, "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "mermaid", "", "testdata/xxx.json", out)
+	assert.EqualError(t, err, "error reading visits file 'testdata/xxx.json': open testdata/xxx.json: no such file or directory")
+
+	err = main.FlowViz(assetsPath, "a121f1af-7dfa-47af-9d22-9726372e2daa", "mermaid", "", "", out)
+	assert.EqualError(t, err, "no such flow with UUID 'a121f1af-7dfa-47af-9d22-9726372e2daa'")
+}
@@ -3,6 +3,7 @@ package contactql
 import (
 	"fmt"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/utils"
 	"github.com/pkg/errors"
 )
@@ -14,6 +15,7 @@ const (
 	ErrInvalidDate           = "invalid_date"           // `value` the value we tried to parse as a date
 	ErrInvalidLanguage       = "invalid_language"       // `value` the value we tried to parse as a language code
 	ErrInvalidGroup          = "invalid_group"          // `value` the value we tried to parse as a group name
+	ErrInvalidInValue        = "invalid_in_value"       // `property` the property key
 	ErrInvalidPartialName    = "invalid_partial_name"   // `min_token_length` the minimum length of token required for name contains condition
 	ErrInvalidPartialURN     = "invalid_partial_urn"    // `min_value_length` the minimum length of value required for URN contains condition
 	ErrUnsupportedContains   = "unsupported_contains"   // `property` the property key
@@ -25,9 +27,12 @@ const (
 
 // QueryError is used when an error is a result of an invalid query
 type QueryError struct {
-	msg   string
-	code  string
-	extra map[string]string
+	msg         string
+	code        string
+	extra       map[string]string
+	position    *utils.ErrorPosition
+	expected    []string
+	suggestions []string
 }
 
 // NewQueryError creates a new query error
@@ -43,6 +48,23 @@ func (e *QueryError) withExtra(k, v string) *QueryError {
 	return e
 }
 
+func (e *QueryError) withPosition(pos *utils.ErrorPosition) *QueryError {
+	e.position = pos
+	return e
+}
+
+func (e *QueryError) withExpected(expected []string) *QueryError {
+	e.expected = expected
+	return e
+}
+
+func (e *QueryError) withSuggestions(suggestions []string) *QueryError {
+	if len(suggestions) > 0 {
+		e.suggestions = suggestions
+	}
+	return e
+}
+
 // Error returns the error message
 func (e *QueryError) Error() string {
 	return e.msg
@@ -58,6 +80,42 @@ func (e *QueryError) Extra() map[string]string {
 	return e.extra
 }
 
+// Position returns the range of characters in the query where this error occurred, if known
+func (e *QueryError) Position() *utils.ErrorPosition {
+	return e.position
+}
+
+// Expected returns the tokens which would have been valid in place of an unexpected token
+func (e *QueryError) Expected() []string {
+	return e.expected
+}
+
+// Suggestions returns the nearest matching property keys or group names for an unknown property or invalid group
+func (e *QueryError) Suggestions() []string {
+	return e.suggestions
+}
+
+type queryErrorEnvelope struct {
+	Message     string               `json:"message"`
+	Code        string               `json:"code"`
+	Extra       map[string]string    `json:"extra,omitempty"`
+	Position    *utils.ErrorPosition `json:"position,omitempty"`
+	Expected    []string             `json:"expected,omitempty"`
+	Suggestions []string             `json:"suggestions,omitempty"`
+}
+
+// MarshalJSON marshals this query error into JSON
+func (e *QueryError) MarshalJSON() ([]byte, error) {
+	return jsonx.Marshal(&queryErrorEnvelope{
+		Message:     e.msg,
+		Code:        e.code,
+		Extra:       e.extra,
+		Position:    e.position,
+		Expected:    e.expected,
+		Suggestions: e.suggestions,
+	})
+}
+
 // IsQueryError is a utility to determine if the cause of an error was a query error
 func IsQueryError(err error) (bool, error) {
 	switch cause := errors.Cause(err).(type) {
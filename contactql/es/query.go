@@ -25,6 +25,8 @@ func nodeToElastic(env envs.Environment, resolver contactql.Resolver, node conta
 	switch n := node.(type) {
 	case *contactql.BoolCombination:
 		return boolCombinationToElastic(env, resolver, n)
+	case *contactql.Not:
+		return not(nodeToElastic(env, resolver, n.Child()))
 	case *contactql.Condition:
 		return conditionToElastic(env, resolver, n)
 	default:
@@ -46,6 +48,10 @@ func boolCombinationToElastic(env envs.Environment, resolver contactql.Resolver,
 }
 
 func conditionToElastic(env envs.Environment, resolver contactql.Resolver, c *contactql.Condition) elastic.Query {
+	if c.Operator() == contactql.OpIn {
+		return inConditionToElastic(env, resolver, c)
+	}
+
 	switch c.PropertyType() {
 	case contactql.PropertyTypeField:
 		return fieldConditionToElastic(env, resolver, c)
@@ -354,6 +360,64 @@ func schemeConditionToElastic(env envs.Environment, c *contactql.Condition) elas
 	}
 }
 
+// IN conditions become terms queries where values can be matched exactly, otherwise an OR of equality conditions
+func inConditionToElastic(env envs.Environment, resolver contactql.Resolver, c *contactql.Condition) elastic.Query {
+	lowerValues := make([]interface{}, len(c.Values()))
+	for i, v := range c.Values() {
+		lowerValues[i] = strings.ToLower(v)
+	}
+
+	switch c.PropertyType() {
+	case contactql.PropertyTypeField:
+		field := resolver.ResolveField(c.PropertyKey())
+		fieldType := field.Type()
+		fieldQuery := elastic.NewTermQuery("fields.field", field.UUID())
+
+		switch fieldType {
+		case assets.FieldTypeText:
+			return elastic.NewNestedQuery("fields", elastic.NewBoolQuery().Must(fieldQuery, elastic.NewTermsQuery("fields.text", lowerValues...)))
+		case assets.FieldTypeState, assets.FieldTypeDistrict, assets.FieldTypeWard:
+			name := fmt.Sprintf("fields.%s_keyword", string(fieldType))
+			return elastic.NewNestedQuery("fields", elastic.NewBoolQuery().Must(fieldQuery, elastic.NewTermsQuery(name, lowerValues...)))
+		}
+
+	case contactql.PropertyTypeAttribute:
+		switch c.PropertyKey() {
+		case contactql.AttributeUUID, contactql.AttributeLanguage:
+			return elastic.NewTermsQuery(c.PropertyKey(), lowerValues...)
+		case contactql.AttributeID:
+			return elastic.NewIdsQuery().Ids(c.Values()...)
+		case contactql.AttributeName:
+			values := make([]interface{}, len(c.Values()))
+			for i, v := range c.Values() {
+				values[i] = v
+			}
+			return elastic.NewTermsQuery("name.keyword", values...)
+		case contactql.AttributeURN:
+			return elastic.NewNestedQuery("urns", elastic.NewTermsQuery("urns.path.keyword", lowerValues...))
+		case contactql.AttributeGroup:
+			groupUUIDs := make([]interface{}, len(c.Values()))
+			for i, eq := range c.Equalities() {
+				groupUUIDs[i] = eq.ValueAsGroup(resolver).UUID()
+			}
+			return elastic.NewTermsQuery("groups", groupUUIDs...)
+		}
+
+	case contactql.PropertyTypeScheme:
+		return elastic.NewNestedQuery("urns", elastic.NewBoolQuery().Must(
+			elastic.NewTermsQuery("urns.path.keyword", lowerValues...),
+			elastic.NewTermQuery("urns.scheme", c.PropertyKey())),
+		)
+	}
+
+	// numbers and dates are matched by ranges so can't be combined into a single terms query
+	queries := make([]elastic.Query, len(c.Values()))
+	for i, eq := range c.Equalities() {
+		queries[i] = conditionToElastic(env, resolver, eq)
+	}
+	return elastic.NewBoolQuery().Should(queries...)
+}
+
 func textAttributeQuery(c *contactql.Condition, name string) elastic.Query {
 	value := strings.ToLower(c.Value())
 
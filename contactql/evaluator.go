@@ -31,6 +31,8 @@ func evaluateNode(env envs.Environment, resolver Resolver, node QueryNode, query
 	switch n := node.(type) {
 	case *BoolCombination:
 		return evaluateBoolCombination(env, resolver, n, queryable)
+	case *Not:
+		return !evaluateNode(env, resolver, n.Child(), queryable)
 	case *Condition:
 		return evaluateCondition(env, resolver, n, queryable)
 	default:
@@ -57,6 +59,16 @@ func evaluateBoolCombination(env envs.Environment, resolver Resolver, b *BoolCom
 }
 
 func evaluateCondition(env envs.Environment, resolver Resolver, c *Condition, queryable Queryable) bool {
+	// foo IN (x, y) is true if any value of foo is equal to any of x or y
+	if c.operator == OpIn {
+		for _, eq := range c.Equalities() {
+			if evaluateCondition(env, resolver, eq, queryable) {
+				return true
+			}
+		}
+		return false
+	}
+
 	// contacts can return multiple values per key, e.g. multiple phone numbers in a "tel = x" condition
 	vals := queryable.QueryProperty(env, c.PropertyKey(), c.PropertyType())
 
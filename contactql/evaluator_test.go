@@ -26,6 +26,7 @@ func TestEvaluateQuery(t *testing.T) {
 		"name":     []interface{}{"Bob Smithwick"},
 		"tel":      []interface{}{"+59313145145"},
 		"twitter":  []interface{}{"bob_smith"},
+		"mailto":   []interface{}{"bob@example.com", "bobby@example.com"},
 		"whatsapp": []interface{}{},
 		"gender":   []interface{}{"male"},
 		"age":      []interface{}{decimal.NewFromFloat(36)},
@@ -134,6 +135,31 @@ func TestEvaluateQuery(t *testing.T) {
 		{query: `age = 36 OR gender = female`, result: true},
 		{query: `age = 35 OR gender = female`, result: false},
 		{query: `(age = 36 OR gender = female) AND age > 35`, result: true},
+
+		// negations
+		{query: `NOT gender = female`, result: true},
+		{query: `NOT gender = male`, result: false},
+		{query: `NOT (age = 36 OR gender = female)`, result: false},
+		{query: `NOT (age = 35 OR gender = female)`, result: true},
+		{query: `age = 36 AND NOT gender = female`, result: true},
+		{query: `NOT xyz != ""`, result: true},
+
+		// IN conditions
+		{query: `district IN ("Gasabo", "Nyarugenge")`, result: true},
+		{query: `district IN (Nyarugenge, Kicukiro)`, result: false},
+		{query: `gender IN (MALE)`, result: true},
+		{query: `age IN (18, 36)`, result: true},
+		{query: `age IN (18, 35)`, result: false},
+		{query: `dob IN (1990/01/01, 1981/05/28)`, result: true},
+		{query: `xyz IN (a, b)`, result: false},
+		{query: `NOT xyz IN (a, b)`, result: true},
+
+		// multi-valued properties match IN if any value matches, and so don't match NOT IN
+		{query: `mailto IN ("bobby@example.com", "jim@example.com")`, result: true},
+		{query: `mailto IN ("jim@example.com")`, result: false},
+		{query: `NOT mailto IN ("bobby@example.com")`, result: false},
+		{query: `NOT mailto IN ("jim@example.com")`, result: true},
+		{query: `NOT mailto = "bobby@example.com"`, result: false},
 	}
 
 	resolver := contactql.NewMockResolver(map[string]assets.Field{
@@ -26,6 +26,18 @@ func (s *BaseContactQLListener) EnterParse(ctx *ParseContext) {}
 // ExitParse is called when production parse is exited.
 func (s *BaseContactQLListener) ExitParse(ctx *ParseContext) {}
 
+// EnterNegation is called when production negation is entered.
+func (s *BaseContactQLListener) EnterNegation(ctx *NegationContext) {}
+
+// ExitNegation is called when production negation is exited.
+func (s *BaseContactQLListener) ExitNegation(ctx *NegationContext) {}
+
+// EnterInCondition is called when production inCondition is entered.
+func (s *BaseContactQLListener) EnterInCondition(ctx *InConditionContext) {}
+
+// ExitInCondition is called when production inCondition is exited.
+func (s *BaseContactQLListener) ExitInCondition(ctx *InConditionContext) {}
+
 // EnterImplicitCondition is called when production implicitCondition is entered.
 func (s *BaseContactQLListener) EnterImplicitCondition(ctx *ImplicitConditionContext) {}
 
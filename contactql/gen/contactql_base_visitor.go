@@ -11,6 +11,14 @@ func (v *BaseContactQLVisitor) VisitParse(ctx *ParseContext) interface{} {
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseContactQLVisitor) VisitNegation(ctx *NegationContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
+func (v *BaseContactQLVisitor) VisitInCondition(ctx *InConditionContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseContactQLVisitor) VisitImplicitCondition(ctx *ImplicitConditionContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -10,6 +10,12 @@ type ContactQLListener interface {
 	// EnterParse is called when entering the parse production.
 	EnterParse(c *ParseContext)
 
+	// EnterNegation is called when entering the negation production.
+	EnterNegation(c *NegationContext)
+
+	// EnterInCondition is called when entering the inCondition production.
+	EnterInCondition(c *InConditionContext)
+
 	// EnterImplicitCondition is called when entering the implicitCondition production.
 	EnterImplicitCondition(c *ImplicitConditionContext)
 
@@ -37,6 +43,12 @@ type ContactQLListener interface {
 	// ExitParse is called when exiting the parse production.
 	ExitParse(c *ParseContext)
 
+	// ExitNegation is called when exiting the negation production.
+	ExitNegation(c *NegationContext)
+
+	// ExitInCondition is called when exiting the inCondition production.
+	ExitInCondition(c *InConditionContext)
+
 	// ExitImplicitCondition is called when exiting the implicitCondition production.
 	ExitImplicitCondition(c *ImplicitConditionContext)
 
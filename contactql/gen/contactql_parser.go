@@ -15,33 +15,40 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 11, 40, 4,
-	2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5, 3, 21, 10, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 7, 3, 31, 10, 3, 12, 3, 14, 3, 34, 11, 3, 3,
-	4, 3, 4, 5, 4, 38, 10, 4, 3, 4, 2, 3, 4, 5, 2, 4, 6, 2, 2, 2, 42, 2, 8,
-	3, 2, 2, 2, 4, 20, 3, 2, 2, 2, 6, 37, 3, 2, 2, 2, 8, 9, 5, 4, 3, 2, 9,
-	10, 7, 2, 2, 3, 10, 3, 3, 2, 2, 2, 11, 12, 8, 3, 1, 2, 12, 13, 7, 3, 2,
-	2, 13, 14, 5, 4, 3, 2, 14, 15, 7, 4, 2, 2, 15, 21, 3, 2, 2, 2, 16, 17,
-	7, 8, 2, 2, 17, 18, 7, 7, 2, 2, 18, 21, 5, 6, 4, 2, 19, 21, 5, 6, 4, 2,
-	20, 11, 3, 2, 2, 2, 20, 16, 3, 2, 2, 2, 20, 19, 3, 2, 2, 2, 21, 32, 3,
-	2, 2, 2, 22, 23, 12, 8, 2, 2, 23, 24, 7, 5, 2, 2, 24, 31, 5, 4, 3, 9, 25,
-	26, 12, 7, 2, 2, 26, 31, 5, 4, 3, 8, 27, 28, 12, 6, 2, 2, 28, 29, 7, 6,
-	2, 2, 29, 31, 5, 4, 3, 7, 30, 22, 3, 2, 2, 2, 30, 25, 3, 2, 2, 2, 30, 27,
-	3, 2, 2, 2, 31, 34, 3, 2, 2, 2, 32, 30, 3, 2, 2, 2, 32, 33, 3, 2, 2, 2,
-	33, 5, 3, 2, 2, 2, 34, 32, 3, 2, 2, 2, 35, 38, 7, 8, 2, 2, 36, 38, 7, 9,
-	2, 2, 37, 35, 3, 2, 2, 2, 37, 36, 3, 2, 2, 2, 38, 7, 3, 2, 2, 2, 6, 20,
-	30, 32, 37,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 14, 54,
+	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 7, 3, 25,
+	10, 3, 12, 3, 14, 3, 28, 11, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 5,
+	3, 36, 10, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 7, 3, 46,
+	10, 3, 12, 3, 14, 3, 49, 11, 3, 3, 4, 3, 4, 5, 4, 53, 10, 4, 2, 3, 4,
+	5, 2, 4, 6, 2, 3, 4, 2, 7, 8, 11, 11, 2, 60, 2, 8, 3, 2, 2, 2, 4, 35,
+	3, 2, 2, 2, 6, 52, 3, 2, 2, 2, 8, 9, 5, 4, 3, 2, 9, 10, 7, 2, 2, 3, 10,
+	3, 3, 2, 2, 2, 11, 12, 8, 3, 1, 2, 12, 13, 7, 7, 2, 2, 13, 36, 5, 4, 3,
+	10, 14, 15, 7, 3, 2, 2, 15, 16, 5, 4, 3, 2, 16, 17, 7, 4, 2, 2, 17, 36,
+	3, 2, 2, 2, 18, 19, 7, 11, 2, 2, 19, 20, 7, 8, 2, 2, 20, 21, 7, 3, 2,
+	2, 21, 26, 5, 6, 4, 2, 22, 23, 7, 9, 2, 2, 23, 25, 5, 6, 4, 2, 24, 22,
+	3, 2, 2, 2, 25, 28, 3, 2, 2, 2, 26, 24, 3, 2, 2, 2, 26, 27, 3, 2, 2, 2,
+	27, 29, 3, 2, 2, 2, 28, 26, 3, 2, 2, 2, 29, 30, 7, 4, 2, 2, 30, 36, 3,
+	2, 2, 2, 31, 32, 7, 11, 2, 2, 32, 33, 7, 10, 2, 2, 33, 36, 5, 6, 4, 2,
+	34, 36, 5, 6, 4, 2, 35, 11, 3, 2, 2, 2, 35, 14, 3, 2, 2, 2, 35, 18, 3,
+	2, 2, 2, 35, 31, 3, 2, 2, 2, 35, 34, 3, 2, 2, 2, 36, 47, 3, 2, 2, 2,
+	37, 38, 12, 9, 2, 2, 38, 39, 7, 5, 2, 2, 39, 46, 5, 4, 3, 10, 40, 41,
+	12, 8, 2, 2, 41, 46, 5, 4, 3, 9, 42, 43, 12, 7, 2, 2, 43, 44, 7, 6, 2,
+	2, 44, 46, 5, 4, 3, 8, 45, 37, 3, 2, 2, 2, 45, 40, 3, 2, 2, 2, 45, 42,
+	3, 2, 2, 2, 46, 49, 3, 2, 2, 2, 47, 45, 3, 2, 2, 2, 47, 48, 3, 2, 2, 2,
+	48, 5, 3, 2, 2, 2, 49, 47, 3, 2, 2, 2, 50, 53, 9, 2, 2, 2, 51, 53, 7,
+	12, 2, 2, 52, 50, 3, 2, 2, 2, 52, 51, 3, 2, 2, 2, 53, 7, 3, 2, 2, 2, 7,
+	26, 35, 45, 47, 52,
 }
 var deserializer = antlr.NewATNDeserializer(nil)
 var deserializedATN = deserializer.DeserializeFromUInt16(parserATN)
 
 var literalNames = []string{
-	"", "'('", "')'",
+	"", "'('", "')'", "", "", "", "", "','",
 }
 var symbolicNames = []string{
-	"", "LPAREN", "RPAREN", "AND", "OR", "COMPARATOR", "TEXT", "STRING", "WS",
-	"ERROR",
+	"", "LPAREN", "RPAREN", "AND", "OR", "NOT", "IN", "COMMA", "COMPARATOR",
+	"TEXT", "STRING", "WS", "ERROR",
 }
 
 var ruleNames = []string{
@@ -80,11 +87,14 @@ const (
 	ContactQLParserRPAREN     = 2
 	ContactQLParserAND        = 3
 	ContactQLParserOR         = 4
-	ContactQLParserCOMPARATOR = 5
-	ContactQLParserTEXT       = 6
-	ContactQLParserSTRING     = 7
-	ContactQLParserWS         = 8
-	ContactQLParserERROR      = 9
+	ContactQLParserNOT        = 5
+	ContactQLParserIN         = 6
+	ContactQLParserCOMMA      = 7
+	ContactQLParserCOMPARATOR = 8
+	ContactQLParserTEXT       = 9
+	ContactQLParserSTRING     = 10
+	ContactQLParserWS         = 11
+	ContactQLParserERROR      = 12
 )
 
 // ContactQLParser rules.
@@ -259,6 +269,147 @@ func (s *ExpressionContext) ToStringTree(ruleNames []string, recog antlr.Recogni
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
+type NegationContext struct {
+	*ExpressionContext
+}
+
+func NewNegationContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *NegationContext {
+	var p = new(NegationContext)
+
+	p.ExpressionContext = NewEmptyExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*ExpressionContext))
+
+	return p
+}
+
+func (s *NegationContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *NegationContext) NOT() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserNOT, 0)
+}
+
+func (s *NegationContext) Expression() IExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IExpressionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpressionContext)
+}
+
+func (s *NegationContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ContactQLListener); ok {
+		listenerT.EnterNegation(s)
+	}
+}
+
+func (s *NegationContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ContactQLListener); ok {
+		listenerT.ExitNegation(s)
+	}
+}
+
+func (s *NegationContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case ContactQLVisitor:
+		return t.VisitNegation(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
+type InConditionContext struct {
+	*ExpressionContext
+}
+
+func NewInConditionContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *InConditionContext {
+	var p = new(InConditionContext)
+
+	p.ExpressionContext = NewEmptyExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*ExpressionContext))
+
+	return p
+}
+
+func (s *InConditionContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *InConditionContext) TEXT() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserTEXT, 0)
+}
+
+func (s *InConditionContext) IN() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserIN, 0)
+}
+
+func (s *InConditionContext) LPAREN() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserLPAREN, 0)
+}
+
+func (s *InConditionContext) AllLiteral() []ILiteralContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*ILiteralContext)(nil)).Elem())
+	var tst = make([]ILiteralContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(ILiteralContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *InConditionContext) Literal(i int) ILiteralContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ILiteralContext)(nil)).Elem(), i)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(ILiteralContext)
+}
+
+func (s *InConditionContext) RPAREN() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserRPAREN, 0)
+}
+
+func (s *InConditionContext) AllCOMMA() []antlr.TerminalNode {
+	return s.GetTokens(ContactQLParserCOMMA)
+}
+
+func (s *InConditionContext) COMMA(i int) antlr.TerminalNode {
+	return s.GetToken(ContactQLParserCOMMA, i)
+}
+
+func (s *InConditionContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ContactQLListener); ok {
+		listenerT.EnterInCondition(s)
+	}
+}
+
+func (s *InConditionContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(ContactQLListener); ok {
+		listenerT.ExitInCondition(s)
+	}
+}
+
+func (s *InConditionContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case ContactQLVisitor:
+		return t.VisitInCondition(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
 type ImplicitConditionContext struct {
 	*ExpressionContext
 }
@@ -651,61 +802,119 @@ func (p *ContactQLParser) expression(_p int) (localctx IExpressionContext) {
 		}
 	}()
 
+	var _la int
+
 	var _alt int
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(18)
+	p.SetState(33)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 0, p.GetParserRuleContext()) {
+	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 1, p.GetParserRuleContext()) {
 	case 1:
-		localctx = NewExpressionGroupingContext(p, localctx)
+		localctx = NewNegationContext(p, localctx)
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 
 		{
 			p.SetState(10)
-			p.Match(ContactQLParserLPAREN)
+			p.Match(ContactQLParserNOT)
 		}
 		{
 			p.SetState(11)
-			p.expression(0)
+			p.expression(8)
 		}
+
+	case 2:
+		localctx = NewExpressionGroupingContext(p, localctx)
+		p.SetParserRuleContext(localctx)
+		_prevctx = localctx
 		{
 			p.SetState(12)
+			p.Match(ContactQLParserLPAREN)
+		}
+		{
+			p.SetState(13)
+			p.expression(0)
+		}
+		{
+			p.SetState(14)
 			p.Match(ContactQLParserRPAREN)
 		}
 
-	case 2:
+	case 3:
+		localctx = NewInConditionContext(p, localctx)
+		p.SetParserRuleContext(localctx)
+		_prevctx = localctx
+		{
+			p.SetState(16)
+			p.Match(ContactQLParserTEXT)
+		}
+		{
+			p.SetState(17)
+			p.Match(ContactQLParserIN)
+		}
+		{
+			p.SetState(18)
+			p.Match(ContactQLParserLPAREN)
+		}
+		{
+			p.SetState(19)
+			p.Literal()
+		}
+		p.SetState(24)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+
+		for _la == ContactQLParserCOMMA {
+			{
+				p.SetState(20)
+				p.Match(ContactQLParserCOMMA)
+			}
+			{
+				p.SetState(21)
+				p.Literal()
+			}
+
+			p.SetState(26)
+			p.GetErrorHandler().Sync(p)
+			_la = p.GetTokenStream().LA(1)
+		}
+		{
+			p.SetState(27)
+			p.Match(ContactQLParserRPAREN)
+		}
+
+	case 4:
 		localctx = NewConditionContext(p, localctx)
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(14)
+			p.SetState(29)
 			p.Match(ContactQLParserTEXT)
 		}
 		{
-			p.SetState(15)
+			p.SetState(30)
 			p.Match(ContactQLParserCOMPARATOR)
 		}
 		{
-			p.SetState(16)
+			p.SetState(31)
 			p.Literal()
 		}
 
-	case 3:
+	case 5:
 		localctx = NewImplicitConditionContext(p, localctx)
 		p.SetParserRuleContext(localctx)
 		_prevctx = localctx
 		{
-			p.SetState(17)
+			p.SetState(32)
 			p.Literal()
 		}
 
 	}
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(30)
+	p.SetState(45)
 	p.GetErrorHandler().Sync(p)
-	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
 
 	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
 		if _alt == 1 {
@@ -713,62 +922,62 @@ func (p *ContactQLParser) expression(_p int) (localctx IExpressionContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(28)
+			p.SetState(43)
 			p.GetErrorHandler().Sync(p)
-			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 1, p.GetParserRuleContext()) {
+			switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext()) {
 			case 1:
 				localctx = NewCombinationAndContext(p, NewExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ContactQLParserRULE_expression)
-				p.SetState(20)
+				p.SetState(35)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 6)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 6)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 7)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 7)", ""))
 				}
 				{
-					p.SetState(21)
+					p.SetState(36)
 					p.Match(ContactQLParserAND)
 				}
 				{
-					p.SetState(22)
-					p.expression(7)
+					p.SetState(37)
+					p.expression(8)
 				}
 
 			case 2:
 				localctx = NewCombinationImpicitAndContext(p, NewExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ContactQLParserRULE_expression)
-				p.SetState(23)
+				p.SetState(38)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 5)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 5)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 6)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 6)", ""))
 				}
 				{
-					p.SetState(24)
-					p.expression(6)
+					p.SetState(39)
+					p.expression(7)
 				}
 
 			case 3:
 				localctx = NewCombinationOrContext(p, NewExpressionContext(p, _parentctx, _parentState))
 				p.PushNewRecursionContext(localctx, _startState, ContactQLParserRULE_expression)
-				p.SetState(25)
+				p.SetState(40)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 4)) {
-					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 4)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 5)) {
+					panic(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 5)", ""))
 				}
 				{
-					p.SetState(26)
+					p.SetState(41)
 					p.Match(ContactQLParserOR)
 				}
 				{
-					p.SetState(27)
-					p.expression(5)
+					p.SetState(42)
+					p.expression(6)
 				}
 
 			}
 
 		}
-		p.SetState(32)
+		p.SetState(47)
 		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 2, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -890,6 +1099,14 @@ func (s *TextLiteralContext) TEXT() antlr.TerminalNode {
 	return s.GetToken(ContactQLParserTEXT, 0)
 }
 
+func (s *TextLiteralContext) NOT() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserNOT, 0)
+}
+
+func (s *TextLiteralContext) IN() antlr.TerminalNode {
+	return s.GetToken(ContactQLParserIN, 0)
+}
+
 func (s *TextLiteralContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(ContactQLListener); ok {
 		listenerT.EnterTextLiteral(s)
@@ -932,23 +1149,32 @@ func (p *ContactQLParser) Literal() (localctx ILiteralContext) {
 		}
 	}()
 
-	p.SetState(35)
+	var _la int
+
+	p.SetState(50)
 	p.GetErrorHandler().Sync(p)
 
 	switch p.GetTokenStream().LA(1) {
-	case ContactQLParserTEXT:
+	case ContactQLParserNOT, ContactQLParserIN, ContactQLParserTEXT:
 		localctx = NewTextLiteralContext(p, localctx)
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(33)
-			p.Match(ContactQLParserTEXT)
+			p.SetState(48)
+			_la = p.GetTokenStream().LA(1)
+
+			if !(((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<ContactQLParserNOT)|(1<<ContactQLParserIN)|(1<<ContactQLParserTEXT))) != 0) {
+				p.GetErrorHandler().RecoverInline(p)
+			} else {
+				p.GetErrorHandler().ReportMatch(p)
+				p.Consume()
+			}
 		}
 
 	case ContactQLParserSTRING:
 		localctx = NewStringLiteralContext(p, localctx)
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(34)
+			p.SetState(49)
 			p.Match(ContactQLParserSTRING)
 		}
 
@@ -976,13 +1202,13 @@ func (p *ContactQLParser) Sempred(localctx antlr.RuleContext, ruleIndex, predInd
 func (p *ContactQLParser) Expression_Sempred(localctx antlr.RuleContext, predIndex int) bool {
 	switch predIndex {
 	case 0:
-		return p.Precpred(p.GetParserRuleContext(), 6)
+		return p.Precpred(p.GetParserRuleContext(), 7)
 
 	case 1:
-		return p.Precpred(p.GetParserRuleContext(), 5)
+		return p.Precpred(p.GetParserRuleContext(), 6)
 
 	case 2:
-		return p.Precpred(p.GetParserRuleContext(), 4)
+		return p.Precpred(p.GetParserRuleContext(), 5)
 
 	default:
 		panic("No predicate with index: " + fmt.Sprint(predIndex))
@@ -10,6 +10,12 @@ type ContactQLVisitor interface {
 	// Visit a parse tree produced by ContactQLParser#parse.
 	VisitParse(ctx *ParseContext) interface{}
 
+	// Visit a parse tree produced by ContactQLParser#negation.
+	VisitNegation(ctx *NegationContext) interface{}
+
+	// Visit a parse tree produced by ContactQLParser#inCondition.
+	VisitInCondition(ctx *InConditionContext) interface{}
+
 	// Visit a parse tree produced by ContactQLParser#implicitCondition.
 	VisitImplicitCondition(ctx *ImplicitConditionContext) interface{}
 
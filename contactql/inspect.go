@@ -34,11 +34,18 @@ func Inspect(query *ContactQuery) *Inspection {
 			attributes[c.propKey] = true
 
 			if c.propKey == AttributeGroup {
-				if query.resolver != nil {
-					group := query.resolver.ResolveGroup(c.value)
-					addRef(assets.NewGroupReference(group.UUID(), group.Name()))
-				} else {
-					addRef(assets.NewVariableGroupReference(c.value))
+				values := []string{c.value}
+				if c.operator == OpIn {
+					values = c.values
+				}
+
+				for _, value := range values {
+					if query.resolver != nil {
+						group := query.resolver.ResolveGroup(value)
+						addRef(assets.NewGroupReference(group.UUID(), group.Name()))
+					} else {
+						addRef(assets.NewVariableGroupReference(value))
+					}
 				}
 			}
 		case PropertyTypeScheme:
@@ -83,6 +90,9 @@ func walk(node QueryNode, conditionCallback func(*Condition)) {
 			walk(n, conditionCallback)
 		}
 
+	case *Not:
+		walk(n.Child(), conditionCallback)
+
 	case *Condition:
 		conditionCallback(n)
 	}
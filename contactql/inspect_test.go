@@ -98,6 +98,21 @@ func TestInspect(t *testing.T) {
 				AllowAsGroup: false,
 			},
 		},
+		{
+			query:    "NOT group IN (U-reporters) AND NOT (gender IN (male, female) OR twitter = bobby)",
+			resolver: resolver,
+			inspection: &contactql.Inspection{
+				Attributes: []string{"group"},
+				Schemes:    []string{"twitter"},
+				Fields: []*assets.FieldReference{
+					assets.NewFieldReference("gender", "Gender"),
+				},
+				Groups: []*assets.GroupReference{
+					assets.NewGroupReference("4eeca453-f474-4767-bdd0-434b180223db", "U-Reporters"),
+				},
+				AllowAsGroup: false,
+			},
+		},
 	}
 
 	for _, tc := range tests {
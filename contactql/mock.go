@@ -1,6 +1,7 @@
 package contactql
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/nyaruka/goflow/assets"
@@ -12,7 +13,7 @@ type mockResolver struct {
 }
 
 // NewMockResolver creates a new mock resolver for fields and groups
-func NewMockResolver(fields map[string]assets.Field, groups map[string]assets.Group) Resolver {
+func NewMockResolver(fields map[string]assets.Field, groups map[string]assets.Group) SuggestionResolver {
 	return &mockResolver{
 		fields: fields,
 		groups: groups,
@@ -34,3 +35,21 @@ func (r *mockResolver) ResolveGroup(name string) assets.Group {
 	}
 	return group
 }
+
+func (r *mockResolver) FieldKeys() []string {
+	keys := make([]string, 0, len(r.fields))
+	for k := range r.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *mockResolver) GroupNames() []string {
+	names := make([]string, 0, len(r.groups))
+	for _, g := range r.groups {
+		names = append(names, g.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 	"github.com/nyaruka/goflow/assets"
@@ -27,6 +29,7 @@ const (
 	OpLessThan           Operator = "<"
 	OpGreaterThanOrEqual Operator = ">="
 	OpLessThanOrEqual    Operator = "<="
+	OpIn                 Operator = "IN"
 )
 
 // BoolOperator is a boolean operator (and or or)
@@ -69,12 +72,18 @@ type QueryNode interface {
 	simplify() QueryNode
 }
 
-// Condition represents a comparison between a keywed value on the contact and a provided value
+// Condition represents a comparison between a keywed value on the contact and a provided value, or for IN
+// conditions, a list of provided values
 type Condition struct {
 	propKey  string
 	propType PropertyType
 	operator Operator
 	value    string
+	values   []string
+
+	// where the key and each value occur in the original query text, if known
+	keyPos   *utils.ErrorPosition
+	valuePos []*utils.ErrorPosition
 }
 
 func newCondition(propKey string, propType PropertyType, operator Operator, value string) *Condition {
@@ -86,6 +95,38 @@ func newCondition(propKey string, propType PropertyType, operator Operator, valu
 	}
 }
 
+func newInCondition(propKey string, propType PropertyType, values []string) *Condition {
+	return &Condition{
+		propKey:  propKey,
+		propType: propType,
+		operator: OpIn,
+		values:   values,
+	}
+}
+
+func (c *Condition) withPositions(keyPos *utils.ErrorPosition, valuePos ...*utils.ErrorPosition) *Condition {
+	c.keyPos = keyPos
+	c.valuePos = valuePos
+	return c
+}
+
+// the position of the value, or for IN conditions, the position of the value at the given index
+func (c *Condition) valuePosition(i int) *utils.ErrorPosition {
+	if i < len(c.valuePos) {
+		return c.valuePos[i]
+	}
+	return nil
+}
+
+// the position of the entire condition from key to last value
+func (c *Condition) position() *utils.ErrorPosition {
+	start, end := c.keyPos, c.valuePosition(len(c.valuePos)-1)
+	if start == nil || end == nil {
+		return nil
+	}
+	return &utils.ErrorPosition{Start: start.Start, End: end.End}
+}
+
 // PropertyKey returns the key for the property being queried
 func (c *Condition) PropertyKey() string { return c.propKey }
 
@@ -98,6 +139,18 @@ func (c *Condition) Operator() Operator { return c.operator }
 // Value returns the value being compared against
 func (c *Condition) Value() string { return c.value }
 
+// Values returns the list of values being compared against if this is an IN condition
+func (c *Condition) Values() []string { return c.values }
+
+// Equalities returns the = conditions which this IN condition is equivalent to an OR combination of
+func (c *Condition) Equalities() []*Condition {
+	conditions := make([]*Condition, len(c.values))
+	for i, v := range c.values {
+		conditions[i] = newCondition(c.propKey, c.propType, OpEqual, v).withPositions(c.keyPos, c.valuePosition(i))
+	}
+	return conditions
+}
+
 // ValueAsNumber returns the value as a number if possible, or an error if not
 func (c *Condition) ValueAsNumber() (decimal.Decimal, error) {
 	return decimal.NewFromString(c.value)
@@ -137,27 +190,43 @@ func (c *Condition) validate(env envs.Environment, resolver Resolver) error {
 
 	valueType := c.resolveValueType(resolver)
 	if valueType == "" {
-		return NewQueryError(ErrUnknownProperty, "can't resolve '%s' to attribute, scheme or field", c.propKey).withExtra("property", c.propKey)
+		return NewQueryError(ErrUnknownProperty, "can't resolve '%s' to attribute, scheme or field", c.propKey).
+			withExtra("property", c.propKey).
+			withPosition(c.keyPos).
+			withSuggestions(suggestPropertyKeys(c.propKey, resolver))
+	}
+
+	// an IN condition is valid if each of its values would be valid in an equality condition
+	if c.operator == OpIn {
+		for _, eq := range c.Equalities() {
+			if eq.value == "" {
+				return NewQueryError(ErrInvalidInValue, "IN conditions can't include empty values").withExtra("property", c.propKey).withPosition(eq.valuePosition(0))
+			}
+			if err := eq.validate(env, resolver); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	switch c.operator {
 	case OpContains:
 		if c.propKey == AttributeName {
 			if len(tokenizeNameValue(c.value)) == 0 {
-				return NewQueryError(ErrInvalidPartialName, "contains operator on name requires token of minimum length %d", minNameTokenContainsLength).withExtra("min_token_length", strconv.Itoa(minNameTokenContainsLength))
+				return NewQueryError(ErrInvalidPartialName, "contains operator on name requires token of minimum length %d", minNameTokenContainsLength).withExtra("min_token_length", strconv.Itoa(minNameTokenContainsLength)).withPosition(c.valuePosition(0))
 			}
 		} else if c.propKey == AttributeURN || c.propType == PropertyTypeScheme {
 			if len(c.value) < minURNContainsLength {
-				return NewQueryError(ErrInvalidPartialURN, "contains operator on URN requires value of minimum length %d", minURNContainsLength).withExtra("min_value_length", strconv.Itoa(minURNContainsLength))
+				return NewQueryError(ErrInvalidPartialURN, "contains operator on URN requires value of minimum length %d", minURNContainsLength).withExtra("min_value_length", strconv.Itoa(minURNContainsLength)).withPosition(c.valuePosition(0))
 			}
 		} else {
 			// ~ can only be used with the name/urn attributes or actual URNs
-			return NewQueryError(ErrUnsupportedContains, "contains conditions can only be used with name or URN values").withExtra("property", c.propKey)
+			return NewQueryError(ErrUnsupportedContains, "contains conditions can only be used with name or URN values").withExtra("property", c.propKey).withPosition(c.position())
 		}
 
 	case OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual:
 		if valueType != assets.FieldTypeNumber && valueType != assets.FieldTypeDatetime {
-			return NewQueryError(ErrUnsupportedComparison, "comparisons with %s can only be used with date and number fields", c.operator).withExtra("property", c.propKey).withExtra("operator", string(c.operator))
+			return NewQueryError(ErrUnsupportedComparison, "comparisons with %s can only be used with date and number fields", c.operator).withExtra("property", c.propKey).withExtra("operator", string(c.operator)).withPosition(c.position())
 		}
 	}
 
@@ -165,31 +234,34 @@ func (c *Condition) validate(env envs.Environment, resolver Resolver) error {
 	if (c.operator == OpEqual || c.operator == OpNotEqual) && c.value == "" {
 		switch c.propKey {
 		case AttributeUUID, AttributeID, AttributeCreatedOn, AttributeGroup, AttributeTickets:
-			return NewQueryError(ErrUnsupportedSetCheck, "can't check whether '%s' is set or not set", c.propKey).withExtra("property", c.propKey).withExtra("operator", string(c.operator))
+			return NewQueryError(ErrUnsupportedSetCheck, "can't check whether '%s' is set or not set", c.propKey).withExtra("property", c.propKey).withExtra("operator", string(c.operator)).withPosition(c.position())
 		}
 	} else {
 		// check values are valid for the property type
 		if valueType == assets.FieldTypeNumber {
 			_, err := c.ValueAsNumber()
 			if err != nil {
-				return NewQueryError(ErrInvalidNumber, "can't convert '%s' to a number", c.value).withExtra("value", c.value)
+				return NewQueryError(ErrInvalidNumber, "can't convert '%s' to a number", c.value).withExtra("value", c.value).withPosition(c.valuePosition(0))
 			}
 		} else if valueType == assets.FieldTypeDatetime {
 			_, err := c.ValueAsDate(env)
 			if err != nil {
-				return NewQueryError(ErrInvalidDate, "can't convert '%s' to a date", c.value).withExtra("value", c.value)
+				return NewQueryError(ErrInvalidDate, "can't convert '%s' to a date", c.value).withExtra("value", c.value).withPosition(c.valuePosition(0))
 			}
 
 		} else if c.propKey == AttributeGroup && resolver != nil {
 			group := c.ValueAsGroup(resolver)
 			if group == nil {
-				return NewQueryError(ErrInvalidGroup, "'%s' is not a valid group name", c.value).withExtra("value", c.value)
+				return NewQueryError(ErrInvalidGroup, "'%s' is not a valid group name", c.value).
+					withExtra("value", c.value).
+					withPosition(c.valuePosition(0)).
+					withSuggestions(suggestGroupNames(c.value, resolver))
 			}
 		} else if c.propKey == AttributeLanguage {
 			if c.value != "" {
 				_, err := envs.ParseLanguage(c.value)
 				if err != nil {
-					return NewQueryError(ErrInvalidLanguage, "'%s' is not a valid language code", c.value).withExtra("value", c.value)
+					return NewQueryError(ErrInvalidLanguage, "'%s' is not a valid language code", c.value).withExtra("value", c.value).withPosition(c.valuePosition(0))
 				}
 			}
 		}
@@ -203,14 +275,54 @@ func (c *Condition) simplify() QueryNode {
 }
 
 func (c *Condition) String() string {
-	value := c.value
+	if c.operator == OpIn {
+		values := make([]string, len(c.values))
+		for i, v := range c.values {
+			values[i] = quoteValue(v)
+		}
+		return fmt.Sprintf(`%s IN (%s)`, c.propKey, strings.Join(values, ", "))
+	}
+
+	return fmt.Sprintf(`%s %s %s`, c.propKey, c.operator, quoteValue(c.value))
+}
 
+// quotes the given value unless it's a decimal
+func quoteValue(value string) string {
 	if !isNumberRegex.MatchString(value) {
-		// if not a decimal then quote
-		value = strconv.Quote(value)
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// Not is the negation of another node
+type Not struct {
+	child QueryNode
+}
+
+// NewNot creates a new negation of the given node
+func NewNot(child QueryNode) *Not {
+	return &Not{child: child}
+}
+
+// Child returns the node being negated
+func (n *Not) Child() QueryNode { return n.child }
+
+func (n *Not) validate(env envs.Environment, resolver Resolver) error {
+	return n.child.validate(env, resolver)
+}
+
+func (n *Not) simplify() QueryNode {
+	n.child = n.child.simplify()
+
+	// NOT NOT x is just x
+	if typed, isNot := n.child.(*Not); isNot {
+		return typed.child
 	}
+	return n
+}
 
-	return fmt.Sprintf(`%s %s %s`, c.propKey, c.operator, value)
+func (n *Not) String() string {
+	return "NOT " + n.child.String()
 }
 
 // BoolCombination is a AND or OR combination of multiple conditions
@@ -257,7 +369,7 @@ func (b *BoolCombination) simplify() QueryNode {
 				return b
 			}
 			newChilden = append(newChilden, typed.children...)
-		case *Condition:
+		default:
 			newChilden = append(newChilden, typed)
 		}
 	}
@@ -299,7 +411,9 @@ func (q *ContactQuery) String() string {
 // ParseQuery parses a ContactQL query from the given input. If resolver is provided then we validate against it
 // to ensure that fields and groups exist. If not provided then still validate what we can.
 func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQuery, error) {
-	// preprocess text before parsing
+	// preprocess text before parsing, remembering how many characters we trimmed so error positions can be
+	// reported relative to the original text
+	offset := utf8.RuneCountInString(text) - utf8.RuneCountInString(strings.TrimLeftFunc(text, unicode.IsSpace))
 	text = strings.TrimSpace(text)
 
 	// if query is a valid number, rewrite as a tel = query
@@ -309,7 +423,7 @@ func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQ
 		}
 	}
 
-	errListener := &errorListener{}
+	errListener := &errorListener{offset: offset}
 	input := antlr.NewInputStream(text)
 	lexer := gen.NewContactQLLexer(input)
 	stream := antlr.NewCommonTokenStream(lexer, 0)
@@ -324,7 +438,7 @@ func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQ
 		return nil, err
 	}
 
-	visitor := newVisitor(env)
+	visitor := newVisitor(env, offset)
 	rootNode := visitor.Visit(tree).(QueryNode)
 
 	if len(visitor.errors) > 0 {
@@ -343,7 +457,8 @@ func ParseQuery(env envs.Environment, text string, resolver Resolver) (*ContactQ
 type errorListener struct {
 	*antlr.DefaultErrorListener
 
-	errs []*QueryError
+	offset int
+	errs   []*QueryError
 }
 
 func (l *errorListener) Error() error {
@@ -363,6 +478,20 @@ func (l *errorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol
 		err = NewQueryError("", msg)
 	}
 
+	if parser, isParser := recognizer.(antlr.Parser); isParser {
+		err.withExpected(utils.ExpectedTokens(parser))
+	}
+
+	if token, isToken := offendingSymbol.(antlr.Token); isToken {
+		// EOF tokens have a stop before their start
+		start := token.GetStart()
+		end := utils.MaxInt(start, token.GetStop()+1)
+		err.withPosition(&utils.ErrorPosition{Start: l.offset + start, End: l.offset + end})
+	} else {
+		// lexer errors don't have an offending token but do give us the column
+		err.withPosition(&utils.ErrorPosition{Start: l.offset + column, End: l.offset + column + 1})
+	}
+
 	l.errs = append(l.errs, err)
 }
 
@@ -3,10 +3,12 @@ package contactql_test
 import (
 	"testing"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/assets/static"
 	"github.com/nyaruka/goflow/contactql"
 	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -133,6 +135,37 @@ func TestParseQuery(t *testing.T) {
 			resolver: resolver,
 		},
 
+		// negations bind tighter than AND and OR
+		{text: `NOT (group = "U-Reporters" OR language = "eng")`, parsed: `NOT (group = "U-Reporters" OR language = "eng")`, resolver: resolver},
+		{text: `not gender = male`, parsed: `NOT gender = "male"`, resolver: resolver},
+		{text: `NOT will`, parsed: `NOT name ~ "will"`, resolver: resolver},
+		{text: `will NOT felix`, parsed: `name ~ "will" AND NOT name ~ "felix"`, resolver: resolver},
+		{text: `NOT age > 18 AND gender = male`, parsed: `NOT age > 18 AND gender = "male"`, resolver: resolver},
+		{text: `NOT NOT gender = male`, parsed: `gender = "male"`, resolver: resolver},
+		{text: `NOT (age > 18 AND NOT gender = male)`, parsed: `NOT (age > 18 AND NOT gender = "male")`, resolver: resolver},
+		{text: `NOT xyz = 1`, err: "can't resolve 'xyz' to attribute, scheme or field", resolver: resolver},
+
+		// IN conditions
+		{text: `state IN (Pichincha, "Kigali")`, parsed: `state IN ("Pichincha", "Kigali")`, resolver: resolver},
+		{text: `age in (18,21)`, parsed: `age IN (18, 21)`, resolver: resolver},
+		{text: `group IN ("U-Reporters")`, parsed: `group IN ("U-Reporters")`, resolver: resolver},
+		{text: `gender IN (male) OR age > 18`, parsed: `gender IN ("male") OR age > 18`, resolver: resolver},
+		{text: `NOT tel IN (+250788000001, +250788000002)`, parsed: `NOT tel IN ("+250788000001", "+250788000002")`, resolver: resolver},
+		{text: `age IN (18, abc)`, err: "can't convert 'abc' to a number", resolver: resolver},
+		{text: `group IN ("U-Reporters", "Gamers")`, err: "'Gamers' is not a valid group name", resolver: resolver},
+		{text: `state IN (Pichincha, "")`, err: "IN conditions can't include empty values", resolver: resolver},
+		{text: `tel IN (233)`, err: "cannot query on redacted URNs", redactURNs: true, resolver: resolver},
+
+		// NOT and IN can still be used as values where a keyword isn't expected
+		{text: `name ~ in`, parsed: `name ~ "in"`, resolver: resolver},
+		{text: `name = not`, parsed: `name = "not"`, resolver: resolver},
+		{text: `bob in`, parsed: `name ~ "bob" AND name ~ "in"`, resolver: resolver},
+		{text: `not`, parsed: `name ~ "not"`, resolver: resolver},
+		{text: `in`, parsed: `name ~ "in"`, resolver: resolver},
+		{text: `bob not`, parsed: `name ~ "bob" AND name ~ "not"`, resolver: resolver},
+		{text: `gender IN (in, not)`, parsed: `gender IN ("in", "not")`, resolver: resolver},
+		{text: `NOT name = not`, parsed: `NOT name = "not"`, resolver: resolver},
+
 		{text: `xyz != ""`, err: "can't resolve 'xyz' to attribute, scheme or field", resolver: resolver},
 		{text: `group != "Gamers"`, err: "'Gamers' is not a valid group name", resolver: resolver},
 		{text: `language = "xxxx"`, err: "'xxxx' is not a valid language code", resolver: resolver},
@@ -236,6 +269,12 @@ func TestParseQuery(t *testing.T) {
 		} else {
 			assert.NoError(t, err, "unexpected error for '%s'", tc.text)
 			assert.Equal(t, tc.parsed, parsed.String(), "parse mismatch for '%s'", tc.text)
+
+			// check that the formatted query can be parsed back to the same query
+			reparsed, err := contactql.ParseQuery(env, parsed.String(), tc.resolver)
+			if assert.NoError(t, err, "unexpected error re-parsing '%s'", parsed.String()) {
+				assert.Equal(t, tc.parsed, reparsed.String(), "re-parse mismatch for '%s'", tc.text)
+			}
 		}
 	}
 }
@@ -249,19 +288,25 @@ func TestParsingErrors(t *testing.T) {
 	}{
 		{
 			query:    `$`,
-			errMsg:   "mismatched input '$' expecting {'(', TEXT, STRING}",
+			errMsg:   "mismatched input '$' expecting {'(', NOT, IN, TEXT, STRING}",
 			errCode:  "unexpected_token",
 			errExtra: map[string]string{"token": "$"},
 		},
 		{
 			query:    `name = `,
-			errMsg:   "mismatched input '<EOF>' expecting {TEXT, STRING}",
+			errMsg:   "mismatched input '<EOF>' expecting {NOT, IN, TEXT, STRING}",
 			errCode:  "unexpected_token",
 			errExtra: map[string]string{"token": "<EOF>"},
 		},
+		{
+			query:    `district IN ()`,
+			errMsg:   "mismatched input ')' expecting {'(', NOT, IN, TEXT, STRING}",
+			errCode:  "unexpected_token",
+			errExtra: map[string]string{"token": ")"},
+		},
 		{
 			query:    `name = "x`,
-			errMsg:   "extraneous input '\"' expecting {TEXT, STRING}",
+			errMsg:   "extraneous input '\"' expecting {NOT, IN, TEXT, STRING}",
 			errCode:  "",
 			errExtra: nil,
 		},
@@ -356,3 +401,58 @@ func TestParsingErrors(t *testing.T) {
 		assert.Equal(t, tc.errExtra, qerr.Extra())
 	}
 }
+
+func TestParsingErrorDetails(t *testing.T) {
+	tests := []struct {
+		query   string
+		errJSON string
+	}{
+		{
+			query:   `$`,
+			errJSON: `{"message": "mismatched input '$' expecting {'(', NOT, IN, TEXT, STRING}", "code": "unexpected_token", "extra": {"token": "$"}, "position": {"start": 0, "end": 1}, "expected": ["'('", "NOT", "IN", "TEXT", "STRING"]}`,
+		},
+		{
+			query:   `  name = `,
+			errJSON: `{"message": "mismatched input '<EOF>' expecting {NOT, IN, TEXT, STRING}", "code": "unexpected_token", "extra": {"token": "<EOF>"}, "position": {"start": 8, "end": 8}, "expected": ["NOT", "IN", "TEXT", "STRING"]}`,
+		},
+		{
+			query:   `age > 10 AND distrct = "Kigali"`,
+			errJSON: `{"message": "can't resolve 'distrct' to attribute, scheme or field", "code": "unknown_property", "extra": {"property": "distrct"}, "position": {"start": 13, "end": 20}, "suggestions": ["district"]}`,
+		},
+		{
+			query:   `nme = "Bob"`,
+			errJSON: `{"message": "can't resolve 'nme' to attribute, scheme or field", "code": "unknown_property", "extra": {"property": "nme"}, "position": {"start": 0, "end": 3}, "suggestions": ["name"]}`,
+		},
+		{
+			query:   `zzzzzzzz = 1`,
+			errJSON: `{"message": "can't resolve 'zzzzzzzz' to attribute, scheme or field", "code": "unknown_property", "extra": {"property": "zzzzzzzz"}, "position": {"start": 0, "end": 8}}`,
+		},
+		{
+			query:   `group = "Cool Kid"`,
+			errJSON: `{"message": "'Cool Kid' is not a valid group name", "code": "invalid_group", "extra": {"value": "Cool Kid"}, "position": {"start": 8, "end": 18}, "suggestions": ["Cool Kids"]}`,
+		},
+		{
+			query:   `age IN (12, "x", 14)`,
+			errJSON: `{"message": "can't convert 'x' to a number", "code": "invalid_number", "extra": {"value": "x"}, "position": {"start": 12, "end": 15}}`,
+		},
+		{
+			query:   `NOT (uuid > 123)`,
+			errJSON: `{"message": "comparisons with > can only be used with date and number fields", "code": "unsupported_comparison", "extra": {"operator": ">", "property": "uuid"}, "position": {"start": 5, "end": 15}}`,
+		},
+	}
+
+	env := envs.NewBuilder().WithDefaultCountry("US").Build()
+	resolver := contactql.NewMockResolver(map[string]assets.Field{
+		"age":      static.NewField(assets.FieldUUID("f1b5aea6-6586-41c7-9020-1a6326cc6565"), "age", "Age", assets.FieldTypeNumber),
+		"district": static.NewField(assets.FieldUUID("3810a485-3fda-4011-a589-7320c0b8dbef"), "district", "District", assets.FieldTypeText),
+	}, map[string]assets.Group{
+		"cool kids": static.NewGroup(assets.GroupUUID("8b3d3bd6-8e4a-4d6a-9e5a-5e8c04c4c1a6"), "Cool Kids", ""),
+	})
+
+	for _, tc := range tests {
+		_, err := contactql.ParseQuery(env, tc.query, resolver)
+		assert.Error(t, err, "expected error for '%s'", tc.query)
+
+		test.AssertEqualJSON(t, []byte(tc.errJSON), jsonx.MustMarshal(err), "error JSON mismatch for '%s'", tc.query)
+	}
+}
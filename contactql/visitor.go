@@ -10,6 +10,7 @@ import (
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/contactql/gen"
 	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/utils"
 )
 
 // an implicit condition like +123-124-6546 or 1234 will be interpreted as a tel ~ condition
@@ -54,16 +55,47 @@ type Resolver interface {
 	ResolveGroup(name string) assets.Group
 }
 
+// SuggestionResolver is a resolver which can also list the fields and groups it knows about, which allows errors
+// about unknown fields or groups to include suggestions of what the user might have meant
+type SuggestionResolver interface {
+	Resolver
+
+	FieldKeys() []string
+	GroupNames() []string
+}
+
+// maximum number of suggestions included in an error
+const maxSuggestions = 3
+
+func suggestPropertyKeys(key string, resolver Resolver) []string {
+	candidates := make([]string, 0, len(attributes))
+	for k := range attributes {
+		candidates = append(candidates, k)
+	}
+	if sr, ok := resolver.(SuggestionResolver); ok {
+		candidates = append(candidates, sr.FieldKeys()...)
+	}
+	return utils.Suggest(key, candidates, maxSuggestions)
+}
+
+func suggestGroupNames(name string, resolver Resolver) []string {
+	if sr, ok := resolver.(SuggestionResolver); ok {
+		return utils.Suggest(name, sr.GroupNames(), maxSuggestions)
+	}
+	return nil
+}
+
 type visitor struct {
 	gen.BaseContactQLVisitor
 
 	env    envs.Environment
+	offset int
 	errors []error
 }
 
-// creates a new ContactQL visitor
-func newVisitor(env envs.Environment) *visitor {
-	return &visitor{env: env}
+// creates a new ContactQL visitor, where offset is the number of characters trimmed from the start of the query
+func newVisitor(env envs.Environment, offset int) *visitor {
+	return &visitor{env: env, offset: offset}
 }
 
 // Visit the top level parse tree
@@ -79,23 +111,24 @@ func (v *visitor) VisitParse(ctx *gen.ParseContext) interface{} {
 // expression : TEXT
 func (v *visitor) VisitImplicitCondition(ctx *gen.ImplicitConditionContext) interface{} {
 	value := v.Visit(ctx.Literal()).(string)
+	valuePos := v.position(ctx.Literal())
 
 	asURN, _ := urns.Parse(value)
 
 	if v.env.RedactionPolicy() == envs.RedactionPolicyURNs {
 		num, err := strconv.Atoi(value)
 		if err == nil {
-			return newCondition(AttributeID, PropertyTypeAttribute, OpEqual, strconv.Itoa(num))
+			return newCondition(AttributeID, PropertyTypeAttribute, OpEqual, strconv.Itoa(num)).withPositions(nil, valuePos)
 		}
 	} else if asURN != urns.NilURN {
 		scheme, path, _, _ := asURN.ToParts()
 
-		return newCondition(scheme, PropertyTypeScheme, OpEqual, path)
+		return newCondition(scheme, PropertyTypeScheme, OpEqual, path).withPositions(nil, valuePos)
 
 	} else if implicitIsPhoneNumberRegex.MatchString(value) {
 		value = cleanPhoneNumberRegex.ReplaceAllLiteralString(value, "")
 
-		return newCondition(urns.TelScheme, PropertyTypeScheme, OpContains, value)
+		return newCondition(urns.TelScheme, PropertyTypeScheme, OpContains, value).withPositions(nil, valuePos)
 	}
 
 	// convert to contains condition only if we have the right tokens, otherwise make equals check
@@ -104,7 +137,7 @@ func (v *visitor) VisitImplicitCondition(ctx *gen.ImplicitConditionContext) inte
 		operator = OpEqual
 	}
 
-	return newCondition(AttributeName, PropertyTypeAttribute, operator, value)
+	return newCondition(AttributeName, PropertyTypeAttribute, operator, value).withPositions(nil, valuePos)
 }
 
 // expression : TEXT COMPARATOR literal
@@ -118,29 +151,56 @@ func (v *visitor) VisitCondition(ctx *gen.ConditionContext) interface{} {
 		operator = Operator(operatorText)
 	}
 
-	var propType PropertyType
+	keyPos := v.position(ctx.TEXT())
+	propType := v.resolvePropertyType(propKey, keyPos, value != "")
+
+	return newCondition(propKey, propType, operator, value).withPositions(keyPos, v.position(ctx.Literal()))
+}
+
+// expression : TEXT IN LPAREN literal (COMMA literal)* RPAREN
+func (v *visitor) VisitInCondition(ctx *gen.InConditionContext) interface{} {
+	propKey := strings.ToLower(ctx.TEXT().GetText())
 
+	literals := ctx.AllLiteral()
+	values := make([]string, len(literals))
+	valuePos := make([]*utils.ErrorPosition, len(literals))
+	for i, literal := range literals {
+		values[i] = v.Visit(literal).(string)
+		valuePos[i] = v.position(literal)
+	}
+
+	keyPos := v.position(ctx.TEXT())
+	propType := v.resolvePropertyType(propKey, keyPos, true)
+
+	return newInCondition(propKey, propType, values).withPositions(keyPos, valuePos...)
+}
+
+// determines whether the given key is an attribute, URN scheme or field
+func (v *visitor) resolvePropertyType(propKey string, keyPos *utils.ErrorPosition, hasValue bool) PropertyType {
 	// first try to match a fixed attribute
 	_, isAttribute := attributes[propKey]
 	if isAttribute {
-		propType = PropertyTypeAttribute
-
-		if propKey == AttributeURN && v.env.RedactionPolicy() == envs.RedactionPolicyURNs && value != "" {
-			v.addError(NewQueryError(ErrRedactedURNs, "cannot query on redacted URNs"))
+		if propKey == AttributeURN && v.env.RedactionPolicy() == envs.RedactionPolicyURNs && hasValue {
+			v.addError(NewQueryError(ErrRedactedURNs, "cannot query on redacted URNs").withPosition(keyPos))
 		}
 
+		return PropertyTypeAttribute
+
 	} else if urns.IsValidScheme(propKey) {
 		// second try to match a URN scheme
-		propType = PropertyTypeScheme
-
-		if v.env.RedactionPolicy() == envs.RedactionPolicyURNs && value != "" {
-			v.addError(NewQueryError(ErrRedactedURNs, "cannot query on redacted URNs"))
+		if v.env.RedactionPolicy() == envs.RedactionPolicyURNs && hasValue {
+			v.addError(NewQueryError(ErrRedactedURNs, "cannot query on redacted URNs").withPosition(keyPos))
 		}
-	} else {
-		propType = PropertyTypeField
+
+		return PropertyTypeScheme
 	}
 
-	return newCondition(propKey, propType, operator, value)
+	return PropertyTypeField
+}
+
+// expression : NOT expression
+func (v *visitor) VisitNegation(ctx *gen.NegationContext) interface{} {
+	return NewNot(v.Visit(ctx.Expression()).(QueryNode))
 }
 
 // expression : expression AND expression
@@ -169,7 +229,7 @@ func (v *visitor) VisitExpressionGrouping(ctx *gen.ExpressionGroupingContext) in
 	return v.Visit(ctx.Expression())
 }
 
-// literal : TEXT
+// literal : (TEXT | NOT | IN)
 func (v *visitor) VisitTextLiteral(ctx *gen.TextLiteralContext) interface{} {
 	return ctx.GetText()
 }
@@ -189,6 +249,18 @@ func (v *visitor) VisitStringLiteral(ctx *gen.StringLiteralContext) interface{}
 	return unquoted
 }
 
+// gets the position of the given parse tree node in the original query text
+func (v *visitor) position(tree antlr.ParseTree) *utils.ErrorPosition {
+	var start, stop antlr.Token
+	switch typed := tree.(type) {
+	case antlr.TerminalNode:
+		start, stop = typed.GetSymbol(), typed.GetSymbol()
+	case antlr.ParserRuleContext:
+		start, stop = typed.GetStart(), typed.GetStop()
+	}
+	return &utils.ErrorPosition{Start: v.offset + start.GetStart(), End: v.offset + stop.GetStop() + 1}
+}
+
 func (v *visitor) addError(err error) {
 	v.errors = append(v.errors, err)
 }
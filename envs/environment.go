@@ -35,8 +35,12 @@ type Environment interface {
 	DefaultCountry() Country
 	NumberFormat() *NumberFormat
 	RedactionPolicy() RedactionPolicy
+	SensitiveData() *SensitiveData
 	MaxValueLength() int
 
+	// MaskSensitiveData returns whether sensitive data should be masked in contexts built with this environment
+	MaskSensitiveData() bool
+
 	DefaultLanguage() Language
 	DefaultLocale() Locale
 
@@ -56,6 +60,7 @@ type environment struct {
 	defaultCountry   Country
 	numberFormat     *NumberFormat
 	redactionPolicy  RedactionPolicy
+	sensitiveData    *SensitiveData
 	maxValueLength   int
 }
 
@@ -66,7 +71,9 @@ func (e *environment) AllowedLanguages() []Language     { return e.allowedLangua
 func (e *environment) DefaultCountry() Country          { return e.defaultCountry }
 func (e *environment) NumberFormat() *NumberFormat      { return e.numberFormat }
 func (e *environment) RedactionPolicy() RedactionPolicy { return e.redactionPolicy }
+func (e *environment) SensitiveData() *SensitiveData    { return e.sensitiveData }
 func (e *environment) MaxValueLength() int              { return e.maxValueLength }
+func (e *environment) MaskSensitiveData() bool          { return false }
 
 // DefaultLanguage is the first allowed language
 func (e *environment) DefaultLanguage() Language {
@@ -105,6 +112,7 @@ type envEnvelope struct {
 	NumberFormat     *NumberFormat   `json:"number_format,omitempty"`
 	DefaultCountry   Country         `json:"default_country,omitempty" validate:"omitempty,country"`
 	RedactionPolicy  RedactionPolicy `json:"redaction_policy" validate:"omitempty,eq=none|eq=urns"`
+	SensitiveData    *SensitiveData  `json:"sensitive_data,omitempty"`
 	MaxValuelength   int             `json:"max_value_length"`
 }
 
@@ -124,6 +132,7 @@ func ReadEnvironment(data json.RawMessage) (Environment, error) {
 	env.defaultCountry = envelope.DefaultCountry
 	env.numberFormat = envelope.NumberFormat
 	env.redactionPolicy = envelope.RedactionPolicy
+	env.sensitiveData = envelope.SensitiveData
 	env.maxValueLength = envelope.MaxValuelength
 
 	tz, err := time.LoadLocation(envelope.Timezone)
@@ -144,6 +153,7 @@ func (e *environment) toEnvelope() *envEnvelope {
 		DefaultCountry:   e.defaultCountry,
 		NumberFormat:     e.numberFormat,
 		RedactionPolicy:  e.redactionPolicy,
+		SensitiveData:    e.sensitiveData,
 		MaxValuelength:   e.maxValueLength,
 	}
 }
@@ -215,6 +225,11 @@ func (b *EnvironmentBuilder) WithRedactionPolicy(redactionPolicy RedactionPolicy
 	return b
 }
 
+func (b *EnvironmentBuilder) WithSensitiveData(sensitiveData *SensitiveData) *EnvironmentBuilder {
+	b.env.sensitiveData = sensitiveData
+	return b
+}
+
 func (b *EnvironmentBuilder) WithMaxValueLength(maxValueLength int) *EnvironmentBuilder {
 	b.env.maxValueLength = maxValueLength
 	return b
@@ -71,6 +71,49 @@ func TestEnvironmentMarshaling(t *testing.T) {
 	assert.Equal(t, string(data), `{"date_format":"DD-MM-YYYY","time_format":"tt:mm:ss","timezone":"Africa/Kigali","allowed_languages":["eng","fra"],"number_format":{"decimal_symbol":".","digit_grouping_symbol":","},"default_country":"RW","redaction_policy":"none","max_value_length":640}`)
 }
 
+func TestSensitiveData(t *testing.T) {
+	env, err := envs.ReadEnvironment(json.RawMessage(`{
+		"timezone": "Africa/Kigali",
+		"sensitive_data": {
+			"fields": ["hiv_status"],
+			"results": ["Viral Load"],
+			"webhook_headers": ["X-Patient-ID"],
+			"webhook_body_paths": ["patient.diagnosis"]
+		}
+	}`))
+	require.NoError(t, err)
+
+	sensitive := env.SensitiveData()
+	assert.True(t, sensitive.IsField("hiv_status"))
+	assert.False(t, sensitive.IsField("age"))
+	assert.True(t, sensitive.IsResult("Viral Load"))
+	assert.True(t, sensitive.IsResult("viral_load"))
+	assert.False(t, sensitive.IsResult("Name"))
+	assert.Equal(t, []string{"viral_load"}, sensitive.ResultKeys())
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nX-Patient-ID: ****", sensitive.WebhookRedactor("****")("HTTP/1.1 200 OK\r\nX-Patient-ID: 1234"))
+	assert.Equal(t, `{"patient": {"diagnosis": "****"}}`, sensitive.BodyRedactor("****")(`{"patient": {"diagnosis": "flu"}}`))
+
+	data, err := jsonx.Marshal(env)
+	require.NoError(t, err)
+	assert.Equal(t, `{"date_format":"YYYY-MM-DD","time_format":"tt:mm","timezone":"Africa/Kigali","number_format":{"decimal_symbol":".","digit_grouping_symbol":","},"redaction_policy":"none","sensitive_data":{"fields":["hiv_status"],"results":["Viral Load"],"webhook_headers":["X-Patient-ID"],"webhook_body_paths":["patient.diagnosis"]},"max_value_length":640}`, string(data))
+
+	// sensitive data is only masked in contexts built with log environments
+	assert.False(t, env.MaskSensitiveData())
+	logEnv := envs.NewLogEnvironment(env)
+	assert.True(t, logEnv.MaskSensitiveData())
+	assert.Equal(t, sensitive, logEnv.SensitiveData())
+	assert.Equal(t, env.Timezone(), logEnv.Timezone())
+
+	// nothing is sensitive if environment doesn't describe any sensitive data
+	sensitive = envs.NewBuilder().Build().SensitiveData()
+	assert.Nil(t, sensitive)
+	assert.False(t, sensitive.IsField("hiv_status"))
+	assert.False(t, sensitive.IsResult("Viral Load"))
+	assert.Nil(t, sensitive.ResultKeys())
+	assert.Nil(t, sensitive.WebhookRedactor("****"))
+	assert.Nil(t, sensitive.BodyRedactor("****"))
+}
+
 func TestEnvironmentEqual(t *testing.T) {
 	env1, err := envs.ReadEnvironment(json.RawMessage(`{"date_format": "DD-MM-YYYY", "time_format": "tt:mm:ss", "timezone": "Africa/Kigali"}`))
 	require.NoError(t, err)
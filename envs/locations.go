@@ -233,7 +233,7 @@ func (h *LocationHierarchy) UnmarshalJSON(data []byte) error {
 type locationEnvelope struct {
 	Name     string              `json:"name" validate:"required"`
 	Aliases  []string            `json:"aliases,omitempty"`
-	Children []*locationEnvelope `json:"children,omitempty"`
+	Children []*locationEnvelope `json:"children,omitempty" jsonschema:"location"`
 }
 
 func locationFromEnvelope(envelope *locationEnvelope, currentLevel LocationLevel, parent *Location) *Location {
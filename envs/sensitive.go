@@ -0,0 +1,83 @@
+package envs
+
+import (
+	"github.com/nyaruka/goflow/utils"
+)
+
+// SensitiveData describes data which is masked wherever it leaves the engine, i.e. in contexts rendered for logs, in
+// the field, result and webhook events which record it, and in exported sessions, where messages received by waits for
+// sensitive results are also masked. Flows still see the real values.
+type SensitiveData struct {
+	Fields           []string `json:"fields,omitempty"`             // keys of contact fields
+	Results          []string `json:"results,omitempty"`            // names of run results
+	WebhookHeaders   []string `json:"webhook_headers,omitempty"`    // names of headers in webhook requests and responses
+	WebhookBodyPaths []string `json:"webhook_body_paths,omitempty"` // paths in JSON webhook request and response bodies
+}
+
+// IsField returns whether the contact field with the given key is sensitive
+func (d *SensitiveData) IsField(key string) bool {
+	if d == nil {
+		return false
+	}
+	for _, f := range d.Fields {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsResult returns whether the run result with the given name is sensitive
+func (d *SensitiveData) IsResult(name string) bool {
+	if d == nil {
+		return false
+	}
+	key := utils.Snakify(name)
+	for _, r := range d.Results {
+		if utils.Snakify(r) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ResultKeys returns the snakified keys of the sensitive run results
+func (d *SensitiveData) ResultKeys() []string {
+	if d == nil {
+		return nil
+	}
+	keys := make([]string, len(d.Results))
+	for i, r := range d.Results {
+		keys[i] = utils.Snakify(r)
+	}
+	return keys
+}
+
+// WebhookRedactor returns a redactor for dumps of webhook requests and responses, or nil if nothing in them is sensitive
+func (d *SensitiveData) WebhookRedactor(mask string) utils.Redactor {
+	if d == nil || (len(d.WebhookHeaders) == 0 && len(d.WebhookBodyPaths) == 0) {
+		return nil
+	}
+	return utils.NewHTTPRedactor(mask, d.WebhookHeaders, d.WebhookBodyPaths)
+}
+
+// BodyRedactor returns a redactor for JSON webhook bodies, or nil if nothing in them is sensitive
+func (d *SensitiveData) BodyRedactor(mask string) utils.Redactor {
+	if d == nil || len(d.WebhookBodyPaths) == 0 {
+		return nil
+	}
+	return utils.NewJSONRedactor(mask, d.WebhookBodyPaths...)
+}
+
+// an environment for building contexts which will be rendered in logs
+type logEnvironment struct {
+	Environment
+}
+
+// NewLogEnvironment creates an environment for building contexts which will be rendered in logs, in which values that
+// the given environment describes as sensitive are masked
+func NewLogEnvironment(env Environment) Environment {
+	return &logEnvironment{env}
+}
+
+func (e *logEnvironment) MaskSensitiveData() bool { return true }
@@ -14,7 +14,13 @@ type Escaping func(string) string
 
 // Parse parses an expression
 func Parse(expression string, contextCallback func([]string)) (Expression, error) {
-	errListener := NewErrorListener(expression)
+	return parse(expression, 0, contextCallback)
+}
+
+// parses an expression which starts at the given offset in some larger text, e.g. a template, so that error positions
+// are relative to that text
+func parse(expression string, offset int, contextCallback func([]string)) (Expression, error) {
+	errListener := &ErrorListener{expression: expression, offset: offset}
 
 	input := antlr.NewInputStream(expression)
 	lexer := gen.NewExcellent3Lexer(input)
@@ -29,41 +35,14 @@ func Parse(expression string, contextCallback func([]string)) (Expression, error
 		return nil, errListener.Errors()[0]
 	}
 
-	visitor := &visitor{contextCallback: contextCallback}
+	visitor := &visitor{offset: offset, contextCallback: contextCallback}
 	output := visitor.Visit(tree)
 	return toExpression(output), nil
 }
 
 // EvaluateTemplate evaluates the passed in template
 func EvaluateTemplate(env envs.Environment, ctx *types.XObject, template string, escaping Escaping) (string, error) {
-	var buf strings.Builder
-
-	err := VisitTemplate(template, ctx.Properties(), func(tokenType XTokenType, token string) error {
-		switch tokenType {
-		case BODY:
-			buf.WriteString(token)
-		case IDENTIFIER, EXPRESSION:
-			value := EvaluateExpression(env, ctx, token)
-
-			// if we got an error, return that
-			if types.IsXError(value) {
-				return value.(error)
-			}
-
-			// if not, stringify value and append to the output
-			asText, _ := types.ToXText(env, value)
-			asString := asText.Native()
-
-			if escaping != nil {
-				asString = escaping(asString)
-			}
-
-			buf.WriteString(asString)
-		}
-		return nil
-	})
-
-	return buf.String(), err
+	return GetTemplate(template, ctx.Properties()).Evaluate(env, ctx, escaping)
 }
 
 // EvaluateTemplateValue is equivalent to EvaluateTemplate except in the case where the template contains
@@ -71,25 +50,8 @@ func EvaluateTemplate(env envs.Environment, ctx *types.XObject, template string,
 // the typed value from EvaluateExpression instead of stringifying the result.
 func EvaluateTemplateValue(env envs.Environment, ctx *types.XObject, template string) (types.XValue, error) {
 	template = strings.TrimSpace(template)
-	scanner := NewXScanner(strings.NewReader(template), ctx.Properties())
-
-	// parse our first token
-	tokenType, token := scanner.Scan()
-
-	// try to scan to our next token
-	nextTT, _ := scanner.Scan()
-
-	// if we only have an identifier or an expression, evaluate it on its own
-	if nextTT == EOF {
-		switch tokenType {
-		case IDENTIFIER, EXPRESSION:
-			return EvaluateExpression(env, ctx, token), nil
-		}
-	}
 
-	// otherwise fallback to full template evaluation
-	asStr, err := EvaluateTemplate(env, ctx, template, nil)
-	return types.NewXText(asStr), err
+	return GetTemplate(template, ctx.Properties()).EvaluateValue(env, ctx)
 }
 
 // EvaluateExpression evalutes the passed in Excellent expression, returning the typed value it evaluates to,
@@ -171,7 +133,7 @@ func VisitTemplate(template string, allowedTopLevels []string, callback func(XTo
 				repr = "@(" + token + ")"
 			}
 
-			errors.Add(repr, err.Error())
+			errors.AddError(repr, err)
 		}
 	}
 
@@ -183,14 +145,5 @@ func VisitTemplate(template string, allowedTopLevels []string, callback func(XTo
 
 // HasExpressions returns whether the given template contains any expressions or identifiers
 func HasExpressions(template string, allowedTopLevels []string) bool {
-	found := false
-	VisitTemplate(template, allowedTopLevels, func(tokenType XTokenType, token string) error {
-		switch tokenType {
-		case IDENTIFIER, EXPRESSION:
-			found = true
-			return nil
-		}
-		return nil
-	})
-	return found
+	return GetTemplate(template, allowedTopLevels).HasExpressions()
 }
@@ -4,11 +4,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/excellent"
 	"github.com/nyaruka/goflow/excellent/functions"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/utils"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -404,6 +406,7 @@ func TestEvaluationErrors(t *testing.T) {
 
 		// function call errors
 		{`@(FOO())`, `error evaluating @(FOO()): foo is not a function`},
+		{`@(uper("abc"))`, `error evaluating @(uper("abc")): uper is not a function`},
 		{`@(count(1))`, `error evaluating @(count(1)): error calling count(...): value isn't countable`},
 		{`@(word_count())`, `error evaluating @(word_count()): error calling word_count(...): need 1 to 2 argument(s), got 0`},
 		{`@(word_count("a", "b", "c"))`, `error evaluating @(word_count("a", "b", "c")): error calling word_count(...): need 1 to 2 argument(s), got 3`},
@@ -420,6 +423,84 @@ func TestEvaluationErrors(t *testing.T) {
 	}
 }
 
+func TestErrorDetails(t *testing.T) {
+	env := envs.NewBuilder().Build()
+	ctx := types.NewXObject(map[string]types.XValue{
+		"foo": types.NewXText("bar"),
+	})
+
+	tcs := []struct {
+		expression string
+		errJSON    string
+	}{
+		{
+			expression: `(foo +)`,
+			errJSON:    `{"message": "syntax error at )", "code": "syntax", "position": {"start": 6, "end": 7}, "expected": ["'('", "'-'", "TEXT", "INTEGER", "DECIMAL", "TRUE", "FALSE", "NULL", "NAME"]}`,
+		},
+		{
+			expression: `1 + `,
+			errJSON:    `{"message": "syntax error at ", "code": "syntax", "position": {"start": 4, "end": 4}, "expected": ["'('", "'-'", "TEXT", "INTEGER", "DECIMAL", "TRUE", "FALSE", "NULL", "NAME"]}`,
+		},
+		{
+			expression: `uper(foo)`,
+			errJSON:    `{"message": "uper is not a function", "code": "unknown_function", "extra": {"function": "uper"}, "position": {"start": 0, "end": 4}, "suggestions": ["upper"]}`,
+		},
+		{
+			expression: `xxxxxxxx(foo)`,
+			errJSON:    `{"message": "xxxxxxxx is not a function", "code": "unknown_function", "extra": {"function": "xxxxxxxx"}, "position": {"start": 0, "end": 8}}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		result := excellent.EvaluateExpression(env, ctx, tc.expression)
+		assert.True(t, types.IsXError(result), "expected error for expression '%s'", tc.expression)
+
+		var exprErr *excellent.ExpressionError
+		if assert.True(t, errors.As(result.(error), &exprErr), "expected expression error for '%s'", tc.expression) {
+			assert.Implements(t, (*utils.RichError)(nil), exprErr)
+			test.AssertEqualJSON(t, []byte(tc.errJSON), jsonx.MustMarshal(exprErr), "error JSON mismatch for '%s'", tc.expression)
+		}
+	}
+}
+
+func TestTemplateErrorDetails(t *testing.T) {
+	env := envs.NewBuilder().Build()
+	ctx := types.NewXObject(map[string]types.XValue{
+		"foo": types.NewXText("bar"),
+	})
+
+	tcs := []struct {
+		template string
+		errJSON  []string
+	}{
+		{
+			template: `Hi @(1 + * 2)`,
+			errJSON:  []string{`{"message": "syntax error at * 2", "code": "syntax", "position": {"start": 9, "end": 10}, "expected": ["'('", "'-'", "TEXT", "INTEGER", "DECIMAL", "TRUE", "FALSE", "NULL", "NAME"]}`},
+		},
+		{
+			template: `@@ ✓ @foo @(uper(foo)) and @(xxxxxxxx())`,
+			errJSON: []string{
+				`{"message": "uper is not a function", "code": "unknown_function", "extra": {"function": "uper"}, "position": {"start": 12, "end": 16}, "suggestions": ["upper"]}`,
+				`{"message": "xxxxxxxx is not a function", "code": "unknown_function", "extra": {"function": "xxxxxxxx"}, "position": {"start": 29, "end": 37}}`,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		_, err := excellent.EvaluateTemplate(env, ctx, tc.template, nil)
+
+		var templateErrs *excellent.TemplateErrors
+		if assert.True(t, errors.As(err, &templateErrs), "expected template errors for '%s'", tc.template) && assert.Len(t, templateErrs.Errors(), len(tc.errJSON)) {
+			for i, templateErr := range templateErrs.Errors() {
+				var exprErr *excellent.ExpressionError
+				if assert.True(t, errors.As(templateErr, &exprErr), "expected expression error for '%s'", tc.template) {
+					test.AssertEqualJSON(t, []byte(tc.errJSON[i]), jsonx.MustMarshal(exprErr), "error JSON mismatch for '%s'", tc.template)
+				}
+			}
+		}
+	}
+}
+
 func TestHasExpressions(t *testing.T) {
 	topLevels := []string{"foo"}
 
@@ -0,0 +1,107 @@
+package excellent
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultTemplateCacheSize is the maximum number of compiled templates held by the default template cache
+const DefaultTemplateCacheSize = 10000
+
+// the current *TemplateCache, which can be swapped while other goroutines are evaluating templates
+var templateCache atomic.Value
+
+func init() {
+	templateCache.Store(NewTemplateCache(DefaultTemplateCacheSize))
+}
+
+// SetTemplateCache sets the cache used for compiled templates by the evaluation functions in this package. Setting it
+// to nil disables caching so that templates are compiled on every evaluation. It's safe to call this concurrently with
+// evaluations, which will use either the old or the new cache.
+func SetTemplateCache(cache *TemplateCache) {
+	templateCache.Store(cache)
+}
+
+// GetTemplate gets the compiled version of the given template from the template cache, compiling it if necessary
+func GetTemplate(template string, allowedTopLevels []string) *Template {
+	return templateCache.Load().(*TemplateCache).Get(template, allowedTopLevels)
+}
+
+// TemplateCache is a bounded cache of compiled templates which evicts the least recently used templates when it is
+// full. It is safe for concurrent use.
+type TemplateCache struct {
+	size    int
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cacheEntry struct {
+	key      string
+	template *Template
+}
+
+// NewTemplateCache creates a new template cache which holds up to the given number of templates
+func NewTemplateCache(size int) *TemplateCache {
+	return &TemplateCache{size: size, entries: make(map[string]*list.Element, size), lru: list.New()}
+}
+
+// Get gets the compiled version of the given template, compiling it and adding it to the cache if necessary. If the
+// cache is nil, the template is just compiled.
+func (c *TemplateCache) Get(template string, allowedTopLevels []string) *Template {
+	if c == nil {
+		return CompileTemplate(template, allowedTopLevels)
+	}
+
+	// how a template is scanned depends on the allowed top-levels so they need to be part of the key
+	key := strings.Join(allowedTopLevels, ",") + "\x00" + template
+
+	c.mutex.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.mutex.Unlock()
+		return e.Value.(*cacheEntry).template
+	}
+	c.mutex.Unlock()
+
+	// compile outside of the lock so that other goroutines aren't blocked by parsing
+	compiled := CompileTemplate(template, allowedTopLevels)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// another goroutine may have compiled the same template in the meantime
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).template
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, template: compiled})
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	return compiled
+}
+
+// Len returns the number of templates in the cache
+func (c *TemplateCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
+
+// Clear removes all templates from the cache
+func (c *TemplateCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element, c.size)
+	c.lru.Init()
+}
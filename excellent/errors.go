@@ -5,20 +5,25 @@ import (
 	"strings"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/excellent/functions"
 	"github.com/nyaruka/goflow/utils"
-	"github.com/pkg/errors"
 )
 
 // TemplateError is an error which occurs during evaluation of an expression
 type TemplateError struct {
 	expression string
 	message    string
+	err        error
 }
 
 func (e TemplateError) Error() string {
 	return fmt.Sprintf("error evaluating %s: %s", e.expression, e.message)
 }
 
+// Unwrap returns the underlying error if there is one, e.g. an *ExpressionError whose position is within the template
+func (e TemplateError) Unwrap() error { return e.err }
+
 // TemplateErrors represents the list of all errors encountered during evaluation of a template
 type TemplateErrors struct {
 	errors []*TemplateError
@@ -34,6 +39,16 @@ func (e *TemplateErrors) Add(expression, message string) {
 	e.errors = append(e.errors, &TemplateError{expression: expression, message: message})
 }
 
+// AddError adds the given error for the given expression
+func (e *TemplateErrors) AddError(expression string, err error) {
+	e.errors = append(e.errors, &TemplateError{expression: expression, message: err.Error(), err: err})
+}
+
+// Errors returns the individual errors
+func (e *TemplateErrors) Errors() []*TemplateError {
+	return e.errors
+}
+
 // HasErrors returns whether there are errors
 func (e *TemplateErrors) HasErrors() bool {
 	return len(e.errors) > 0
@@ -48,11 +63,92 @@ func (e *TemplateErrors) Error() string {
 	return strings.Join(messages, ", ")
 }
 
+// error codes with values included in extra
+const (
+	ErrSyntax          = "syntax"
+	ErrUnknownFunction = "unknown_function" // `function` the function name
+)
+
+// ExpressionError is an error in an expression which can report which part of the expression caused it, e.g.
+// a syntax error or a call to a function which doesn't exist
+type ExpressionError struct {
+	msg         string
+	code        string
+	extra       map[string]string
+	position    *utils.ErrorPosition
+	expected    []string
+	suggestions []string
+}
+
+// Error returns the error message
+func (e *ExpressionError) Error() string { return e.msg }
+
+// Code returns a code representing this error condition
+func (e *ExpressionError) Code() string { return e.code }
+
+// Extra returns additional data about the error
+func (e *ExpressionError) Extra() map[string]string { return e.extra }
+
+// Position returns the range of characters in the expression where this error occurred, if known
+func (e *ExpressionError) Position() *utils.ErrorPosition { return e.position }
+
+// Expected returns the tokens which would have been valid in place of an unexpected token
+func (e *ExpressionError) Expected() []string { return e.expected }
+
+// Suggestions returns the nearest matching function names for an unknown function
+func (e *ExpressionError) Suggestions() []string { return e.suggestions }
+
+// creates a new error for a call to a function which doesn't exist
+func newUnknownFunctionError(name string, position *utils.ErrorPosition) *ExpressionError {
+	return &ExpressionError{
+		msg:         fmt.Sprintf("%s is not a function", name),
+		code:        ErrUnknownFunction,
+		extra:       map[string]string{"function": name},
+		position:    position,
+		suggestions: suggestFunctionNames(name),
+	}
+}
+
+// maximum number of suggestions included in an error
+const maxSuggestions = 3
+
+func suggestFunctionNames(name string) []string {
+	suggestions := utils.Suggest(name, functions.Names(), maxSuggestions)
+	if len(suggestions) == 0 {
+		return nil
+	}
+	return suggestions
+}
+
+type expressionErrorEnvelope struct {
+	Message     string               `json:"message"`
+	Code        string               `json:"code"`
+	Extra       map[string]string    `json:"extra,omitempty"`
+	Position    *utils.ErrorPosition `json:"position,omitempty"`
+	Expected    []string             `json:"expected,omitempty"`
+	Suggestions []string             `json:"suggestions,omitempty"`
+}
+
+// MarshalJSON marshals this expression error into JSON
+func (e *ExpressionError) MarshalJSON() ([]byte, error) {
+	return jsonx.Marshal(&expressionErrorEnvelope{
+		Message:     e.msg,
+		Code:        e.code,
+		Extra:       e.extra,
+		Position:    e.position,
+		Expected:    e.expected,
+		Suggestions: e.suggestions,
+	})
+}
+
+var _ utils.RichError = (*ExpressionError)(nil)
+
 // ErrorListener records syntax errors
 type ErrorListener struct {
 	*antlr.DefaultErrorListener
 
 	expression string
+	offset     int
 	errors     []error
 }
 
@@ -73,5 +169,18 @@ func (l *ErrorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol
 	lineOfError := lines[line-1]
 	contextOfError := lineOfError[column:utils.MinInt(column+10, len(lineOfError))]
 
-	l.errors = append(l.errors, errors.Errorf("syntax error at %s", contextOfError))
+	err := &ExpressionError{msg: fmt.Sprintf("syntax error at %s", contextOfError), code: ErrSyntax}
+
+	if token, isToken := offendingSymbol.(antlr.Token); isToken {
+		// EOF tokens have a stop before their start
+		start := token.GetStart()
+		end := utils.MaxInt(start, token.GetStop()+1)
+		err.position = &utils.ErrorPosition{Start: l.offset + start, End: l.offset + end}
+	}
+
+	if parser, isParser := recognizer.(antlr.Parser); isParser {
+		err.expected = utils.ExpectedTokens(parser)
+	}
+
+	l.errors = append(l.errors, err)
 }
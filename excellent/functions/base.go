@@ -1,6 +1,7 @@
 package functions
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/nyaruka/goflow/excellent/types"
@@ -18,3 +19,13 @@ func RegisterXFunction(name string, f types.XFunc) {
 func Lookup(name string) *types.XFunction {
 	return XFUNCTIONS[strings.ToLower(name)]
 }
+
+// Names returns the sorted names of all registered functions
+func Names() []string {
+	names := make([]string, 0, len(XFUNCTIONS))
+	for name := range XFUNCTIONS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -45,6 +45,10 @@ type xscanner struct {
 
 // NewXScanner returns a new instance of our excellent scanner
 func NewXScanner(r io.Reader, identifierTopLevels []string) Scanner {
+	return newXScanner(r, identifierTopLevels)
+}
+
+func newXScanner(r io.Reader, identifierTopLevels []string) *xscanner {
 	return &xscanner{
 		input:               newInput(bufio.NewReader(r)),
 		identifierTopLevels: identifierTopLevels,
@@ -52,6 +56,11 @@ func NewXScanner(r io.Reader, identifierTopLevels []string) Scanner {
 	}
 }
 
+// returns the number of characters consumed so far, i.e. the offset of the next token
+func (s *xscanner) offset() int {
+	return s.input.offset
+}
+
 func (s *xscanner) SetUnescapeBody(unescape bool) {
 	s.unescapeBody = unescape
 }
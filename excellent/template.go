@@ -0,0 +1,145 @@
+package excellent
+
+import (
+	"strings"
+
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+)
+
+// Template is a template which has been scanned and had all of its expressions parsed, so that it can be evaluated
+// repeatedly without being re-parsed. Templates are immutable and can be shared between goroutines.
+type Template struct {
+	source string
+	parts  []*templatePart
+}
+
+// a single token in a compiled template
+type templatePart struct {
+	tokenType   XTokenType
+	token       string
+	expression  Expression
+	err         error
+	contextRefs [][]string
+}
+
+// CompileTemplate scans the given template and parses each identifier and expression in it. Parsing errors aren't
+// returned here but when the template is evaluated, as they would have been had the template not been compiled.
+func CompileTemplate(template string, allowedTopLevels []string) *Template {
+	t := &Template{source: template}
+	scanner := newXScanner(strings.NewReader(template), allowedTopLevels)
+
+	for {
+		start := scanner.offset()
+		tokenType, token := scanner.Scan()
+		if tokenType == EOF {
+			break
+		}
+
+		part := &templatePart{tokenType: tokenType, token: token}
+
+		if tokenType == IDENTIFIER || tokenType == EXPRESSION {
+			// error positions are relative to the template so skip the @ or @( before the expression itself
+			offset := start + 1
+			if tokenType == EXPRESSION {
+				offset++
+			}
+
+			part.expression, part.err = parse(token, offset, func(path []string) {
+				part.contextRefs = append(part.contextRefs, append([]string(nil), path...))
+			})
+		}
+
+		t.parts = append(t.parts, part)
+	}
+
+	return t
+}
+
+// String returns the source of this template
+func (t *Template) String() string { return t.source }
+
+// Evaluate evaluates this template in the given context
+func (t *Template) Evaluate(env envs.Environment, ctx *types.XObject, escaping Escaping) (string, error) {
+	var buf strings.Builder
+	var errors *TemplateErrors
+
+	for _, part := range t.parts {
+		switch part.tokenType {
+		case BODY:
+			buf.WriteString(part.token)
+		case IDENTIFIER, EXPRESSION:
+			value := part.evaluate(env, ctx)
+
+			// if we got an error, record that
+			if types.IsXError(value) {
+				if errors == nil {
+					errors = NewTemplateErrors()
+				}
+				errors.AddError(part.repr(), value.(error))
+				continue
+			}
+
+			// if not, stringify value and append to the output
+			asText, _ := types.ToXText(env, value)
+			asString := asText.Native()
+
+			if escaping != nil {
+				asString = escaping(asString)
+			}
+
+			buf.WriteString(asString)
+		}
+	}
+
+	if errors != nil {
+		return buf.String(), errors
+	}
+	return buf.String(), nil
+}
+
+// EvaluateValue is equivalent to Evaluate except in the case where the template contains a single identifier or
+// expression, in which case we return the typed value it evaluates to instead of stringifying the result.
+func (t *Template) EvaluateValue(env envs.Environment, ctx *types.XObject) (types.XValue, error) {
+	if len(t.parts) == 1 && (t.parts[0].tokenType == IDENTIFIER || t.parts[0].tokenType == EXPRESSION) {
+		return t.parts[0].evaluate(env, ctx), nil
+	}
+
+	asStr, err := t.Evaluate(env, ctx, nil)
+	return types.NewXText(asStr), err
+}
+
+// HasExpressions returns whether this template contains any identifiers or expressions
+func (t *Template) HasExpressions() bool {
+	for _, part := range t.parts {
+		if part.tokenType == IDENTIFIER || part.tokenType == EXPRESSION {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitContextRefs calls the given callback with each context path that was visited when the expressions in this
+// template were parsed, in the same order as they would have been passed to the context callback of Parse
+func (t *Template) VisitContextRefs(callback func([]string)) {
+	for _, part := range t.parts {
+		for _, ref := range part.contextRefs {
+			callback(ref)
+		}
+	}
+}
+
+func (p *templatePart) evaluate(env envs.Environment, ctx *types.XObject) types.XValue {
+	if p.err != nil {
+		return types.NewXError(p.err)
+	}
+
+	return p.expression.Evaluate(env, NewScope(ctx, nil))
+}
+
+func (p *templatePart) repr() string {
+	if p.tokenType == IDENTIFIER {
+		return "@" + p.token
+	}
+	return "@(" + p.token + ")"
+}
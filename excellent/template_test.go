@@ -0,0 +1,183 @@
+package excellent_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent"
+	"github.com/nyaruka/goflow/excellent/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	env := envs.NewBuilder().Build()
+	ctx := types.NewXObject(map[string]types.XValue{
+		"foo": types.NewXText("bar"),
+		"num": types.NewXNumberFromInt(3),
+	})
+	topLevels := ctx.Properties()
+
+	tpl := excellent.CompileTemplate("Hi @foo, @(num * 2) is @(num +)@bar", topLevels)
+	assert.Equal(t, "Hi @foo, @(num * 2) is @(num +)@bar", tpl.String())
+	assert.True(t, tpl.HasExpressions())
+
+	// errors are returned when evaluating, same as uncompiled evaluation
+	output, err := tpl.Evaluate(env, ctx, nil)
+	assert.Equal(t, "Hi bar, 6 is @bar", output)
+	assert.EqualError(t, err, "error evaluating @(num +): syntax error at ")
+
+	// compiled templates can be evaluated repeatedly with different contexts
+	output, err = tpl.Evaluate(env, types.NewXObject(map[string]types.XValue{"foo": types.NewXText("zed"), "num": types.NewXNumberFromInt(5)}), nil)
+	assert.Equal(t, "Hi zed, 10 is @bar", output)
+	assert.Error(t, err)
+
+	// single expression templates evaluate to typed values
+	value, err := excellent.CompileTemplate("@(num * 2)", topLevels).EvaluateValue(env, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewXNumberFromInt(6), value)
+
+	value, err = excellent.CompileTemplate("@foo @num", topLevels).EvaluateValue(env, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewXText("bar 3"), value)
+
+	value, err = excellent.CompileTemplate("", topLevels).EvaluateValue(env, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewXText(""), value)
+
+	assert.False(t, excellent.CompileTemplate("no expressions @bar", topLevels).HasExpressions())
+
+	// context references seen when parsing are recorded
+	var refs [][]string
+	excellent.CompileTemplate("@foo.x and @(upper(num.y)) @(x +)", topLevels).VisitContextRefs(func(p []string) { refs = append(refs, p) })
+	assert.Equal(t, [][]string{{"foo"}, {"foo", "x"}, {"upper"}, {"num"}, {"num", "y"}}, refs)
+}
+
+func TestTemplateCache(t *testing.T) {
+	cache := excellent.NewTemplateCache(2)
+
+	tpl1 := cache.Get("@foo", []string{"foo"})
+	assert.Same(t, tpl1, cache.Get("@foo", []string{"foo"}))
+	assert.Equal(t, 1, cache.Len())
+
+	// same template text with different top-levels is scanned differently so is a different entry
+	tpl2 := cache.Get("@foo", []string{"bar"})
+	assert.NotSame(t, tpl1, tpl2)
+	assert.True(t, tpl1.HasExpressions())
+	assert.False(t, tpl2.HasExpressions())
+	assert.Equal(t, 2, cache.Len())
+
+	// use first template so that second is the least recently used, and gets evicted by a third template
+	cache.Get("@foo", []string{"foo"})
+	cache.Get("@(1 + 2)", nil)
+	assert.Equal(t, 2, cache.Len())
+	assert.Same(t, tpl1, cache.Get("@foo", []string{"foo"}))
+	assert.NotSame(t, tpl2, cache.Get("@foo", []string{"bar"}))
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+	assert.NotSame(t, tpl1, cache.Get("@foo", []string{"foo"}))
+
+	// a nil cache just compiles templates
+	var nilCache *excellent.TemplateCache
+	assert.NotSame(t, nilCache.Get("@foo", []string{"foo"}), nilCache.Get("@foo", []string{"foo"}))
+}
+
+func TestTemplateCacheConcurrency(t *testing.T) {
+	env := envs.NewBuilder().Build()
+	cache := excellent.NewTemplateCache(10)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ctx := types.NewXObject(map[string]types.XValue{"num": types.NewXNumberFromInt(i)})
+
+			for j := 0; j < 50; j++ {
+				output, err := cache.Get(fmt.Sprintf("@(num + %d)", j%15), []string{"num"}).Evaluate(env, ctx, nil)
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprint(i+j%15), output)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, cache.Len())
+}
+
+func TestSetTemplateCacheConcurrency(t *testing.T) {
+	defer excellent.SetTemplateCache(excellent.NewTemplateCache(excellent.DefaultTemplateCacheSize))
+
+	env := envs.NewBuilder().Build()
+	wg := &sync.WaitGroup{}
+
+	// swapping the cache while templates are being evaluated is safe
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				excellent.SetTemplateCache(nil)
+			} else {
+				excellent.SetTemplateCache(excellent.NewTemplateCache(5))
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			ctx := types.NewXObject(map[string]types.XValue{"num": types.NewXNumberFromInt(3)})
+
+			output, err := excellent.EvaluateTemplate(env, ctx, "@(num + 1)", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "4", output)
+		}()
+	}
+
+	wg.Wait()
+}
+
+var benchmarkTemplates = []string{
+	"Hi @contact.name, your balance is @(format_number(contact.fields.balance * 1.15, 2))",
+	"@(if(contact.fields.age >= 18, \"adult\", \"minor\"))",
+	"@(upper(left(contact.name, 3))) @(word_count(\"one two three\")) @contact.fields.age",
+}
+
+func BenchmarkEvaluateTemplate(b *testing.B) {
+	env := envs.NewBuilder().Build()
+	ctx := types.NewXObject(map[string]types.XValue{
+		"contact": types.NewXObject(map[string]types.XValue{
+			"name": types.NewXText("Ryan Lewis"),
+			"fields": types.NewXObject(map[string]types.XValue{
+				"age":     types.NewXNumberFromInt(37),
+				"balance": types.RequireXNumberFromString("123.45"),
+			}),
+		}),
+	})
+
+	bench := func(b *testing.B, cache *excellent.TemplateCache) {
+		excellent.SetTemplateCache(cache)
+		defer excellent.SetTemplateCache(excellent.NewTemplateCache(excellent.DefaultTemplateCacheSize))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			for _, tpl := range benchmarkTemplates {
+				excellent.EvaluateTemplate(env, ctx, tpl, nil)
+			}
+		}
+	}
+
+	b.Run("uncached", func(b *testing.B) { bench(b, nil) })
+	b.Run("cached", func(b *testing.B) { bench(b, excellent.NewTemplateCache(100)) })
+}
@@ -15,11 +15,6 @@ func FindContextRefsInTemplate(template string, allowedTopLevels []string, callb
 		}
 	}
 
-	return excellent.VisitTemplate(template, allowedTopLevels, func(tokenType excellent.XTokenType, token string) error {
-		switch tokenType {
-		case excellent.IDENTIFIER, excellent.EXPRESSION:
-			excellent.Parse(token, wrapped)
-		}
-		return nil
-	})
+	excellent.GetTemplate(template, allowedTopLevels).VisitContextRefs(wrapped)
+	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/nyaruka/goflow/excellent/functions"
 	"github.com/nyaruka/goflow/excellent/operators"
 	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
 )
 
 // Expression is the base interface of all syntax elements
@@ -78,9 +79,17 @@ func (x *ArrayLookup) String() string {
 type FunctionCall struct {
 	function Expression
 	params   []Expression
+	position *utils.ErrorPosition
 }
 
 func (x *FunctionCall) Evaluate(env envs.Environment, scope *Scope) types.XValue {
+	// if function is referenced by a name that doesn't exist, error with suggestions of what might have been meant
+	if ref, isRef := x.function.(*ContextReference); isRef {
+		if _, exists := scope.Get(ref.name); !exists {
+			return types.NewXError(newUnknownFunctionError(ref.String(), x.position))
+		}
+	}
+
 	funcVal := x.function.Evaluate(env, scope)
 	if types.IsXError(funcVal) {
 		return funcVal
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,6 +33,7 @@ func TestParse(t *testing.T) {
 			parsed: &FunctionCall{
 				function: &ContextReference{name: "upper"},
 				params:   []Expression{&TextLiteral{val: types.NewXText("abc")}},
+				position: &utils.ErrorPosition{Start: 0, End: 5},
 			},
 		},
 		{
@@ -41,6 +43,7 @@ func TestParse(t *testing.T) {
 				body: &FunctionCall{
 					function: &ContextReference{name: "upper"},
 					params:   []Expression{&ContextReference{name: "x"}},
+					position: &utils.ErrorPosition{Start: 7, End: 12},
 				},
 			},
 		},
@@ -53,10 +56,12 @@ func TestParse(t *testing.T) {
 						body: &FunctionCall{
 							function: &ContextReference{name: "upper"},
 							params:   []Expression{&ContextReference{name: "x"}},
+							position: &utils.ErrorPosition{Start: 8, End: 13},
 						},
 					},
 				},
-				params: []Expression{&TextLiteral{val: types.NewXText("abc")}},
+				params:   []Expression{&TextLiteral{val: types.NewXText("abc")}},
+				position: &utils.ErrorPosition{Start: 0, End: 17},
 			},
 		},
 	}
@@ -30,6 +30,8 @@ type XObject struct {
 	def            XValue
 	props          map[string]XValue
 	source         func() map[string]XValue
+	lazyNames      []string
+	resolve        func(string) XValue
 	marshalDefault bool
 }
 
@@ -45,6 +47,15 @@ func NewXLazyObject(source func() map[string]XValue) *XObject {
 	}
 }
 
+// NewXLazyPropertyObject returns a new object with the given property names, whose values are only resolved, by
+// calling the resolve function, when they are first accessed. Resolved values are memoized by the object.
+func NewXLazyPropertyObject(names []string, resolve func(string) XValue) *XObject {
+	return &XObject{
+		lazyNames: names,
+		resolve:   resolve,
+	}
+}
+
 // Describe returns a representation of this type for error messages
 func (x *XObject) Describe() string { return "object" }
 
@@ -140,12 +151,25 @@ func (x *XObject) String() string {
 
 // Count is called when the length of this object is requested in an expression
 func (x *XObject) Count() int {
+	if x.resolve != nil {
+		return len(x.lazyNames)
+	}
 	return len(x.properties())
 }
 
 // Get retrieves the named property
 func (x *XObject) Get(key string) (XValue, bool) {
 	key = strings.ToLower(key)
+
+	if x.resolve != nil {
+		for _, p := range x.lazyNames {
+			if strings.ToLower(p) == key {
+				return x.resolveProperty(p), true
+			}
+		}
+		return nil, false
+	}
+
 	for p, v := range x.properties() {
 		if strings.ToLower(p) == key {
 			return v, true
@@ -158,8 +182,12 @@ func (x *XObject) Get(key string) (XValue, bool) {
 // Properties returns the sorted property names of this object
 func (x *XObject) Properties() []string {
 	names := make([]string, 0, x.Count())
-	for name := range x.properties() {
-		names = append(names, name)
+	if x.resolve != nil {
+		names = append(names, x.lazyNames...)
+	} else {
+		for name := range x.properties() {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
 	return names
@@ -197,9 +225,27 @@ func (x *XObject) Equals(o XValue) bool {
 
 func (x *XObject) properties() map[string]XValue {
 	x.ensureInitialized()
+
+	// if properties are lazy, iterating over them requires that they are all resolved
+	if x.resolve != nil && len(x.props) < len(x.lazyNames) {
+		for _, p := range x.lazyNames {
+			x.resolveProperty(p)
+		}
+	}
 	return x.props
 }
 
+func (x *XObject) resolveProperty(name string) XValue {
+	x.ensureInitialized()
+
+	v, resolved := x.props[name]
+	if !resolved {
+		v = x.resolve(name)
+		x.props[name] = v
+	}
+	return v
+}
+
 // Default returns the default value for this
 func (x *XObject) Default() XValue {
 	x.ensureInitialized()
@@ -216,7 +262,10 @@ func (x *XObject) hasDefault() bool {
 }
 
 func (x *XObject) ensureInitialized() {
-	if x.props == nil {
+	if x.props == nil && x.resolve != nil {
+		x.def = x
+		x.props = make(map[string]XValue, len(x.lazyNames))
+	} else if x.props == nil {
 		props := x.source()
 
 		x.def = x
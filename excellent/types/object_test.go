@@ -143,6 +143,57 @@ func TestXLazyObject(t *testing.T) {
 	assert.Equal(t, types.NewXText(`{"bar":123,"foo":"abc","zed":false}`), asJSON)
 }
 
+func TestXLazyPropertyObject(t *testing.T) {
+	env := envs.NewBuilder().Build()
+	resolved := make([]string, 0)
+
+	object := types.NewXLazyPropertyObject([]string{"foo", "bar", "zed"}, func(name string) types.XValue {
+		resolved = append(resolved, name)
+
+		switch name {
+		case "foo":
+			return types.NewXText("abc")
+		case "bar":
+			return types.NewXNumberFromInt(123)
+		}
+		return nil
+	})
+
+	// count and property names don't require resolving any values
+	assert.Equal(t, 3, object.Count())
+	assert.Equal(t, []string{"bar", "foo", "zed"}, object.Properties())
+	assert.Equal(t, []string{}, resolved)
+
+	// values are resolved when they're accessed, and only once
+	v, exists := object.Get("FOO")
+	assert.True(t, exists)
+	assert.Equal(t, types.NewXText("abc"), v)
+
+	v, _ = object.Get("foo")
+	assert.Equal(t, types.NewXText("abc"), v)
+	assert.Equal(t, []string{"foo"}, resolved)
+
+	v, exists = object.Get("zed")
+	assert.True(t, exists)
+	assert.Nil(t, v)
+
+	_, exists = object.Get("xxx")
+	assert.False(t, exists)
+	assert.Equal(t, []string{"foo", "zed"}, resolved)
+
+	// rendering requires resolving everything
+	assert.Equal(t, `{bar: 123, foo: abc, zed: }`, object.Render())
+	assert.Equal(t, "bar: 123\nfoo: abc\nzed: ", object.Format(env))
+	assert.Equal(t, []string{"foo", "zed", "bar"}, resolved)
+
+	asJSON, _ := types.ToXJSON(object)
+	assert.Equal(t, types.NewXText(`{"bar":123,"foo":"abc","zed":null}`), asJSON)
+	assert.Equal(t, []string{"foo", "zed", "bar"}, resolved)
+
+	assert.True(t, object.Truthy())
+	assert.True(t, object.Equals(types.NewXObject(map[string]types.XValue{"foo": types.NewXText("abc"), "bar": types.NewXNumberFromInt(123), "zed": nil})))
+}
+
 func TestToXObject(t *testing.T) {
 	var tests = []struct {
 		value    types.XValue
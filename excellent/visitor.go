@@ -8,12 +8,16 @@ import (
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 	"github.com/nyaruka/goflow/excellent/gen"
 	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
 )
 
 // visitor which evaluates each part of an expression as a value
 type visitor struct {
 	gen.BaseExcellent3Visitor
 
+	// offset of the expression in the text it came from
+	offset int
+
 	// tracks where we are in the context
 	currContext     []string
 	contextCallback func([]string)
@@ -92,7 +96,11 @@ func (v *visitor) VisitFunctionCall(ctx *gen.FunctionCallContext) interface{} {
 		params, _ = v.Visit(ctx.Parameters()).([]Expression)
 	}
 
-	return &FunctionCall{function: function, params: params}
+	// record where the function is referenced for errors
+	start, stop := ctx.Atom().GetStart(), ctx.Atom().GetStop()
+	position := &utils.ErrorPosition{Start: v.offset + start.GetStart(), End: v.offset + stop.GetStop() + 1}
+
+	return &FunctionCall{function: function, params: params, position: position}
 }
 
 // VisitFunctionParameters deals with the parameters to a function call
@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/urns"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
@@ -157,9 +156,9 @@ func (a *baseAction) evaluateMessageCatalog(run flows.FlowRun, languages []envs.
 
 // helper to save a run result and log it as an event
 func (a *baseAction) saveResult(run flows.FlowRun, step flows.Step, name, value, category, categoryLocalized string, input string, extra json.RawMessage, logEvent flows.EventCallback) {
-	result := flows.NewResult(name, value, category, categoryLocalized, step.NodeUUID(), input, extra, dates.Now())
+	result := flows.NewResult(name, value, category, categoryLocalized, step.NodeUUID(), input, extra, run.Session().Engine().Clock().Now())
 	run.SaveResult(result)
-	logEvent(events.NewRunResultChanged(result))
+	logEvent(events.NewRunResultChanged(result, run.Environment().SensitiveData()))
 }
 
 // helper to save a run result based on a webhook call and log it as an event
@@ -671,6 +671,8 @@ func TestConstructors(t *testing.T) {
 			actions.NewEnterFlow(
 				actionUUID,
 				assets.NewFlowReference(assets.FlowUUID("fece6eac-9127-4343-9269-56e88f391562"), "Parent"),
+				map[string]string{"age": "@results.age"},
+				map[string]string{"score": "Parent Score"},
 				true, // terminal
 			),
 			`{
@@ -680,6 +682,12 @@ func TestConstructors(t *testing.T) {
 				"uuid": "fece6eac-9127-4343-9269-56e88f391562",
 				"name": "Parent"
 			},
+			"params": {
+				"age": "@results.age"
+			},
+			"outputs": {
+				"score": "Parent Score"
+			},
 			"terminal": true
 		}`,
 		},
@@ -128,7 +128,7 @@ func (a *CallResthookAction) Execute(run flows.FlowRun, step flows.Step, logModi
 		}
 		if call != nil {
 			calls = append(calls, call)
-			logEvent(events.NewWebhookCalled(call, callStatus(call, nil, true), a.Resthook))
+			logEvent(events.NewWebhookCalled(call, callStatus(call, nil, true), a.Resthook, run.Environment().SensitiveData()))
 		}
 	}
 
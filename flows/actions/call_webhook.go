@@ -148,7 +148,7 @@ func (a *CallWebhookAction) call(run flows.FlowRun, step flows.Step, url, method
 
 		status := callStatus(call, err, false)
 
-		logEvent(events.NewWebhookCalled(call, status, ""))
+		logEvent(events.NewWebhookCalled(call, status, "", run.Environment().SensitiveData()))
 
 		if a.ResultName != "" {
 			a.saveWebhookResult(run, step, a.ResultName, call, status, logEvent)
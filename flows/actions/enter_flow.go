@@ -2,6 +2,7 @@ package actions
 
 import (
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
 
@@ -17,12 +18,18 @@ const TypeEnterFlow string = "enter_flow"
 
 // EnterFlowAction can be used to start a contact down another flow. The current flow will pause until the subflow exits or expires.
 //
+// If the subflow declares parameters, values for them can be passed as templates in `params` and will be available
+// in the subflow as `@params`. When the subflow completes, its declared outputs are saved as results on the current
+// run, using the output names unless they are mapped to other result names in `outputs`.
+//
 // A [event:flow_entered] event will be created to record that the flow was started.
 //
 //   {
 //     "uuid": "8eebd020-1af5-431c-b943-aa670fc74da9",
 //     "type": "enter_flow",
 //     "flow": {"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Collect Language"},
+//     "params": {"default_language": "@contact.language"},
+//     "outputs": {"language": "Preferred Language"},
 //     "terminal": false
 //   }
 //
@@ -32,14 +39,18 @@ type EnterFlowAction struct {
 	universalAction
 
 	Flow     *assets.FlowReference `json:"flow" validate:"required"`
+	Params   map[string]string     `json:"params,omitempty" engine:"evaluated"`
+	Outputs  map[string]string     `json:"outputs,omitempty"`
 	Terminal bool                  `json:"terminal,omitempty"`
 }
 
 // NewEnterFlow creates a new start flow action
-func NewEnterFlow(uuid flows.ActionUUID, flow *assets.FlowReference, terminal bool) *EnterFlowAction {
+func NewEnterFlow(uuid flows.ActionUUID, flow *assets.FlowReference, params map[string]string, outputs map[string]string, terminal bool) *EnterFlowAction {
 	return &EnterFlowAction{
 		baseAction: newBaseAction(TypeEnterFlow, uuid),
 		Flow:       flow,
+		Params:     params,
+		Outputs:    outputs,
 		Terminal:   terminal,
 	}
 }
@@ -59,7 +70,57 @@ func (a *EnterFlowAction) Execute(run flows.FlowRun, step flows.Step, logModifie
 		return nil
 	}
 
-	run.Session().PushFlow(flow, run, a.Terminal)
+	params := a.evaluateParams(run, flow, logEvent)
+
+	run.Session().PushFlow(flow, run, a.Terminal, params)
 	logEvent(events.NewFlowEntered(a.Flow, run.UUID(), a.Terminal))
 	return nil
 }
+
+// evaluates our arguments for the parameters declared by the given flow
+func (a *EnterFlowAction) evaluateParams(run flows.FlowRun, flow flows.Flow, logEvent flows.EventCallback) *types.XObject {
+	if len(flow.Params()) == 0 {
+		return nil
+	}
+
+	values := make(map[string]types.XValue, len(flow.Params()))
+
+	for _, param := range flow.Params() {
+		arg, hasArg := a.Params[param.Key]
+		if !hasArg {
+			if param.Required {
+				logEvent(events.NewErrorf("missing required parameter '%s' for %s", param.Key, flow.Reference()))
+			}
+			values[param.Key] = nil
+			continue
+		}
+
+		value, err := run.EvaluateTemplateValue(arg)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		converted, xerr := param.Convert(run.Environment(), value)
+		if xerr != nil {
+			logEvent(events.NewError(errors.Wrapf(xerr, "invalid value for parameter '%s'", param.Key)))
+			converted = nil
+		}
+
+		values[param.Key] = converted
+	}
+
+	return types.NewXObject(values)
+}
+
+// Subflow returns a reference to the flow this action enters
+func (a *EnterFlowAction) Subflow() *assets.FlowReference { return a.Flow }
+
+// OutputResultName returns the name of the result that the given output of the subflow should be saved as
+func (a *EnterFlowAction) OutputResultName(output *flows.FlowOutput) string {
+	if name := a.Outputs[output.Key]; name != "" {
+		return name
+	}
+	return output.Name
+}
+
+var _ flows.SubflowAction = (*EnterFlowAction)(nil)
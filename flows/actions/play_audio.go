@@ -62,7 +62,7 @@ func (a *PlayAudioAction) Execute(run flows.FlowRun, step flows.Step, logModifie
 	connection := run.Session().Trigger().Connection()
 
 	// if we have an audio URL, turn it into a message
-	msg := flows.NewIVRMsgOut(connection.URN(), connection.Channel(), "", envs.NilLanguage, evaluatedAudioURL)
+	msg := flows.NewIVRMsgOut(flows.MsgUUID(run.Session().Engine().UUIDs().Next()), connection.URN(), connection.Channel(), "", envs.NilLanguage, evaluatedAudioURL)
 	logEvent(events.NewIVRCreated(msg))
 
 	return nil
@@ -136,11 +136,11 @@ func (a *SendMsgCatalogAction) Execute(run flows.FlowRun, step flows.Step, logMo
 				status = flows.CallStatusResponseError
 				if c.TraceWeniGPT != nil {
 					callWeniGPT := &flows.WebhookCall{Trace: c.TraceWeniGPT}
-					logEvent(events.NewWebhookCalled(callWeniGPT, status, ""))
+					logEvent(events.NewWebhookCalled(callWeniGPT, status, "", run.Environment().SensitiveData()))
 				}
 				if c.TraceSentenx != nil {
 					callSentenx := &flows.WebhookCall{Trace: c.TraceSentenx}
-					logEvent(events.NewWebhookCalled(callSentenx, status, ""))
+					logEvent(events.NewWebhookCalled(callSentenx, status, "", run.Environment().SensitiveData()))
 				}
 
 				a.saveResult(run, step, a.ResultName, fmt.Sprintf("%s", err), CategoryFailure, "", "", nil, logEvent)
@@ -151,11 +151,11 @@ func (a *SendMsgCatalogAction) Execute(run flows.FlowRun, step flows.Step, logMo
 			status = flows.CallStatusSuccess
 			if c.TraceWeniGPT != nil {
 				callWeniGPT := &flows.WebhookCall{Trace: c.TraceWeniGPT}
-				logEvent(events.NewWebhookCalled(callWeniGPT, status, ""))
+				logEvent(events.NewWebhookCalled(callWeniGPT, status, "", run.Environment().SensitiveData()))
 			}
 			if c.TraceSentenx != nil {
 				callSentenx := &flows.WebhookCall{Trace: c.TraceSentenx}
-				logEvent(events.NewWebhookCalled(callSentenx, status, ""))
+				logEvent(events.NewWebhookCalled(callSentenx, status, "", run.Environment().SensitiveData()))
 			}
 
 			a.saveResult(run, step, a.ResultName, string(c.ResponseJSON), CategorySuccess, "", "", c.ResponseJSON, logEvent)
@@ -165,14 +165,14 @@ func (a *SendMsgCatalogAction) Execute(run flows.FlowRun, step flows.Step, logMo
 			a.saveResult(run, step, a.ResultName, "", CategorySuccess, "", "", nil, logEvent)
 		}
 
-		msg := flows.NewMsgCatalogOut(dest.URN.URN(), channelRef, evaluatedHeader, evaluatedBody, evaluatedFooter, a.ProductViewSettings.Action, evaluatedSearch, products, a.AutomaticSearch, a.Topic, a.SendCatalog)
+		msg := flows.NewMsgCatalogOut(flows.MsgUUID(run.Session().Engine().UUIDs().Next()), dest.URN.URN(), channelRef, evaluatedHeader, evaluatedBody, evaluatedFooter, a.ProductViewSettings.Action, evaluatedSearch, products, a.AutomaticSearch, a.Topic, a.SendCatalog)
 		logEvent(events.NewMsgCatalogCreated(msg))
 	}
 
 	// if we couldn't find a destination, create a msg without a URN or channel and it's up to the caller
 	// to handle that as they want
 	if len(destinations) == 0 {
-		msg := flows.NewMsgCatalogOut(urns.NilURN, nil, evaluatedHeader, evaluatedBody, evaluatedFooter, a.ProductViewSettings.Action, evaluatedSearch, products, a.AutomaticSearch, a.Topic, a.SendCatalog)
+		msg := flows.NewMsgCatalogOut(flows.MsgUUID(run.Session().Engine().UUIDs().Next()), urns.NilURN, nil, evaluatedHeader, evaluatedBody, evaluatedFooter, a.ProductViewSettings.Action, evaluatedSearch, products, a.AutomaticSearch, a.Topic, a.SendCatalog)
 		logEvent(events.NewMsgCatalogCreated(msg))
 	}
 	return nil
@@ -0,0 +1,182 @@
+// Package batch starts sessions for many contacts at once, e.g. when a flow is started for a large group, using a
+// bounded pool of workers which share the same engine and session assets.
+//
+// Engines and session assets can be shared by sessions in different goroutines, and each session modifies its own copy
+// of the contact from its trigger. Process-wide settings such as routers.SetZeroshotToken should be configured before
+// any sessions are started, and engines which use the process-wide sources of dates, UUIDs and random numbers require
+// that those sources are safe for concurrent use. Seeded sources should instead be set on the engine itself.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+)
+
+// TriggerBuilder builds the trigger to start a session for the given contact
+type TriggerBuilder func(*flows.Contact) flows.Trigger
+
+// Result is the outcome of starting a session for a single contact. Err is set if the session couldn't be started,
+// in which case Session and Sprint may also be set if the error occurred after the session was created.
+type Result struct {
+	Index   int
+	Contact *flows.Contact
+	Session flows.Session
+	Sprint  flows.Sprint
+	Err     error
+}
+
+// PanicError is the error for a contact whose session panicked while being started. The stack of the panicking
+// goroutine is kept so that the cause can be found without taking down the whole batch.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic starting session: %v", e.Value)
+}
+
+// a contact read from the input stream, with its position in that stream
+type item struct {
+	index   int
+	contact *flows.Contact
+}
+
+// Runner starts sessions for a stream of contacts on a bounded pool of workers
+type Runner struct {
+	engine  flows.Engine
+	assets  flows.SessionAssets
+	trigger TriggerBuilder
+	workers int
+}
+
+// NewRunner creates a new batch runner which will start sessions with the given engine, assets and triggers using
+// the given number of workers
+func NewRunner(eng flows.Engine, sa flows.SessionAssets, trigger TriggerBuilder, workers int) *Runner {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Runner{engine: eng, assets: sa, trigger: trigger, workers: workers}
+}
+
+// Run starts a session for each contact read from contacts and returns a channel of results, which are sent in the
+// order that sessions finish, rather than the order of the contacts. The results channel is closed once the contacts
+// channel has been closed and all of its contacts processed, or once the context is cancelled.
+//
+// The results channel is unbuffered, so workers wait for each result to be received before reading another contact,
+// meaning that a slow consumer slows down the reading of contacts rather than results accumulating in memory.
+//
+// When the context is cancelled, no more contacts are read, and sessions which are in progress are finished but their
+// results are discarded if they can't be sent immediately. Callers should either drain the results channel or cancel
+// the context to allow the workers to exit.
+func (r *Runner) Run(ctx context.Context, contacts <-chan *flows.Contact) <-chan *Result {
+	items := make(chan item)
+	results := make(chan *Result)
+
+	// number the contacts as they're read so that results can be matched to their position in the input
+	go func() {
+		defer close(items)
+
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case contact, ok := <-contacts:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case items <- item{index: i, contact: contact}:
+				}
+			}
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(r.workers)
+
+	for w := 0; w < r.workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for it := range items {
+				result := r.start(it)
+
+				select {
+				case <-ctx.Done():
+					return
+				case results <- result:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// RunAll starts a session for each of the given contacts and returns the results in the same order as the contacts.
+// If the context is cancelled before all sessions are started, the results for the remaining contacts will be nil.
+func (r *Runner) RunAll(ctx context.Context, contacts []*flows.Contact) []*Result {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	input := make(chan *flows.Contact)
+	go func() {
+		defer close(input)
+
+		for _, contact := range contacts {
+			select {
+			case <-ctx.Done():
+				return
+			case input <- contact:
+			}
+		}
+	}()
+
+	all := make([]*Result, len(contacts))
+	for result := range r.Run(ctx, input) {
+		all[result.Index] = result
+	}
+	return all
+}
+
+// starts the session for a single contact, converting any panic into a PanicError so that one bad contact doesn't take
+// down the whole batch
+func (r *Runner) start(it item) (result *Result) {
+	result = &Result{Index: it.index, Contact: it.contact}
+
+	defer func() {
+		if p := recover(); p != nil {
+			result.Err = &PanicError{Value: p, Stack: debug.Stack()}
+		}
+	}()
+
+	if it.contact == nil {
+		result.Err = errors.New("contact can't be nil")
+		return result
+	}
+
+	trigger := r.trigger(it.contact)
+	if trigger == nil {
+		result.Err = errors.New("no trigger for contact")
+		return result
+	}
+
+	result.Session, result.Sprint, result.Err = r.engine.NewSession(r.assets, trigger)
+	return result
+}
@@ -0,0 +1,248 @@
+package batch_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/batch"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var twoQuestions = assets.NewFlowReference("615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "Two Questions")
+
+func loadAssets(t *testing.T, path, serverURL string) flows.SessionAssets {
+	assetsJSON, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	sa, err := test.CreateSessionAssets(assetsJSON, serverURL)
+	require.NoError(t, err)
+	return sa
+}
+
+func newContacts(t *testing.T, sa flows.SessionAssets, count int) []*flows.Contact {
+	contacts := make([]*flows.Contact, count)
+	for i := range contacts {
+		contactJSON := fmt.Sprintf(`{
+			"uuid": "%s",
+			"id": %d,
+			"name": "Contact %d",
+			"language": "eng",
+			"status": "active",
+			"urns": ["tel:+1206555%04d"],
+			"fields": {"first_name": {"text": "Bob"}, "state": {"text": "Rwanda > Kigali City", "state": "Rwanda > Kigali City"}},
+			"created_on": "2018-06-20T11:40:30.123456789Z"
+		}`, uuids.New(), i+1, i+1, i)
+
+		contact, err := flows.ReadContact(sa, []byte(contactJSON), assets.IgnoreMissing)
+		require.NoError(t, err)
+		contacts[i] = contact
+	}
+	return contacts
+}
+
+func manualTrigger(flow *assets.FlowReference) batch.TriggerBuilder {
+	env := envs.NewBuilder().Build()
+
+	return func(contact *flows.Contact) flows.Trigger {
+		return triggers.NewBuilder(env, flow, contact).Manual().Build()
+	}
+}
+
+func TestRunner(t *testing.T) {
+	sa := loadAssets(t, "../../test/testdata/runner/two_questions.json", "")
+	contacts := newContacts(t, sa, 50)
+
+	runner := batch.NewRunner(engine.NewBuilder().Build(), sa, manualTrigger(twoQuestions), 4)
+	results := runner.RunAll(context.Background(), contacts)
+
+	require.Equal(t, 50, len(results))
+
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, contacts[i], result.Contact)
+		assert.Equal(t, contacts[i].UUID(), result.Session.Contact().UUID())
+		assert.Equal(t, flows.SessionStatusWaiting, result.Session.Status())
+
+		// each session only sees its own contact
+		assert.Contains(t, string(jsonx.MustMarshal(result.Sprint.Events())), fmt.Sprintf("Hi Contact %d!", i+1))
+	}
+
+	// sessions can then be resumed concurrently
+	wg := &sync.WaitGroup{}
+	for _, result := range results {
+		wg.Add(1)
+		go func(session flows.Session) {
+			defer wg.Done()
+
+			msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), session.Contact().URNs()[0].URN(), nil, "Red", nil)
+			_, err := session.Resume(resumes.NewMsg(nil, nil, msg))
+			assert.NoError(t, err)
+		}(result.Session)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Equal(t, flows.SessionStatusWaiting, result.Session.Status())
+		assert.Equal(t, "Red", result.Session.Runs()[0].Results().Get("favorite_color").Value)
+	}
+}
+
+func TestRunnerErrors(t *testing.T) {
+	sa := loadAssets(t, "../../test/testdata/runner/two_questions.json", "")
+	contacts := newContacts(t, sa, 3)
+	contacts[1] = nil
+
+	runner := batch.NewRunner(engine.NewBuilder().Build(), sa, manualTrigger(twoQuestions), 2)
+	results := runner.RunAll(context.Background(), contacts)
+
+	assert.NoError(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, "contact can't be nil")
+	assert.NoError(t, results[2].Err)
+
+	// a trigger for a flow which doesn't exist
+	missing := assets.NewFlowReference("d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c", "Missing")
+	runner = batch.NewRunner(engine.NewBuilder().Build(), sa, manualTrigger(missing), 2)
+	results = runner.RunAll(context.Background(), contacts[:1])
+	assert.EqualError(t, results[0].Err, "unable to load flow[uuid=d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c,name=Missing]: no such flow with UUID 'd9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c'")
+
+	// no trigger or a panic when building one only fail that contact
+	runner = batch.NewRunner(engine.NewBuilder().Build(), sa, func(c *flows.Contact) flows.Trigger {
+		if c.ID() == 1 {
+			return nil
+		}
+		panic("boom")
+	}, 2)
+	results = runner.RunAll(context.Background(), []*flows.Contact{contacts[0], contacts[2]})
+	assert.EqualError(t, results[0].Err, "no trigger for contact")
+	assert.EqualError(t, results[1].Err, "panic starting session: boom")
+
+	// the stack of the panic is included so that it can be logged
+	var panicErr *batch.PanicError
+	if assert.True(t, errors.As(results[1].Err, &panicErr)) {
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "runner_test.go")
+	}
+}
+
+func TestRunnerCancellation(t *testing.T) {
+	sa := loadAssets(t, "../../test/testdata/runner/two_questions.json", "")
+
+	all := newContacts(t, sa, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a producer which keeps sending contacts until it's told to stop
+	contacts := make(chan *flows.Contact)
+	go func() {
+		defer close(contacts)
+		for _, c := range all {
+			select {
+			case <-ctx.Done():
+				return
+			case contacts <- c:
+			}
+		}
+	}()
+
+	runner := batch.NewRunner(engine.NewBuilder().Build(), sa, manualTrigger(twoQuestions), 4)
+	results := runner.Run(ctx, contacts)
+
+	received := 0
+	for range results {
+		received++
+		if received == 10 {
+			cancel()
+		}
+	}
+
+	// channel is closed soon after cancellation without all the contacts being processed
+	assert.GreaterOrEqual(t, received, 10)
+	assert.Less(t, received, 1000)
+
+	// a runner with an already cancelled context doesn't start any sessions
+	results = runner.Run(ctx, make(chan *flows.Contact))
+	_, ok := <-results
+	assert.False(t, ok)
+}
+
+func TestRunnerBackpressure(t *testing.T) {
+	sa := loadAssets(t, "../../test/testdata/runner/two_questions.json", "")
+	all := newContacts(t, sa, 100)
+
+	var read int32
+	contacts := make(chan *flows.Contact)
+	go func() {
+		defer close(contacts)
+		for _, c := range all {
+			contacts <- c
+			atomic.AddInt32(&read, 1)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := batch.NewRunner(engine.NewBuilder().Build(), sa, manualTrigger(twoQuestions), 3)
+	results := runner.Run(ctx, contacts)
+
+	// without any results being received, only one contact per worker plus one waiting to be handed to a worker can
+	// have been read
+	time.Sleep(100 * time.Millisecond)
+	assert.LessOrEqual(t, atomic.LoadInt32(&read), int32(4))
+
+	received := 0
+	for range results {
+		received++
+	}
+	assert.Equal(t, 100, received)
+	assert.Equal(t, int32(100), atomic.LoadInt32(&read))
+}
+
+// run with -race to check that sessions started concurrently don't share any mutable state
+func TestRunnerConcurrency(t *testing.T) {
+	server := test.NewTestHTTPServer(0)
+	defer server.Close()
+
+	sa := loadAssets(t, "../../test/testdata/runner/all_actions.json", server.URL)
+	contacts := newContacts(t, sa, 200)
+	env := envs.NewBuilder().WithAllowedLanguages([]envs.Language{"eng"}).Build()
+	flow := assets.NewFlowReference("8ca44c09-791d-453a-9799-a70dd3303306", "All Actions")
+
+	trigger := func(contact *flows.Contact) flows.Trigger {
+		msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), urns.URN("tel:+12065551212"), nil, contact.Name(), nil)
+		return triggers.NewBuilder(env, flow, contact).Msg(msg).Build()
+	}
+
+	runner := batch.NewRunner(test.NewEngine(), sa, trigger, 16)
+	results := runner.RunAll(context.Background(), contacts)
+
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, flows.SessionStatusCompleted, result.Session.Status())
+
+		// each session only sees and modifies its own contact
+		assert.Contains(t, string(jsonx.MustMarshal(result.Sprint.Events())), fmt.Sprintf("Hi Contact %d, are you ready", i+1))
+		assert.Equal(t, "Jeff Jefferson", result.Session.Contact().Name())
+		assert.Equal(t, "m", result.Session.Runs()[0].Results().Get("gender").Value)
+	}
+}
@@ -0,0 +1,333 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+)
+
+// ChangeType is the type of a change between two flow definitions
+type ChangeType string
+
+// the types of changes we report
+const (
+	ChangeTypePropertyChanged    ChangeType = "property_changed"
+	ChangeTypeNodeAdded          ChangeType = "node_added"
+	ChangeTypeNodeRemoved        ChangeType = "node_removed"
+	ChangeTypeActionAdded        ChangeType = "action_added"
+	ChangeTypeActionRemoved      ChangeType = "action_removed"
+	ChangeTypeActionChanged      ChangeType = "action_changed"
+	ChangeTypeActionMoved        ChangeType = "action_moved"
+	ChangeTypeRouterAdded        ChangeType = "router_added"
+	ChangeTypeRouterRemoved      ChangeType = "router_removed"
+	ChangeTypeRouterChanged      ChangeType = "router_changed"
+	ChangeTypeExitAdded          ChangeType = "exit_added"
+	ChangeTypeExitRemoved        ChangeType = "exit_removed"
+	ChangeTypeExitRewired        ChangeType = "exit_rewired"
+	ChangeTypeTranslationAdded   ChangeType = "translation_added"
+	ChangeTypeTranslationRemoved ChangeType = "translation_removed"
+	ChangeTypeTranslationChanged ChangeType = "translation_changed"
+)
+
+// the flow level properties which we compare
+var flowProperties = []string{"name", "language", "type", "expire_after_minutes"}
+
+// Change is a single semantic difference between two flow definitions
+type Change struct {
+	Type       ChangeType       `json:"type"`
+	NodeUUID   flows.NodeUUID   `json:"node_uuid,omitempty"`
+	ActionUUID flows.ActionUUID `json:"action_uuid,omitempty"`
+	ActionType string           `json:"action_type,omitempty"`
+	ExitUUID   flows.ExitUUID   `json:"exit_uuid,omitempty"`
+	Language   envs.Language    `json:"language,omitempty"`
+	ItemUUID   uuids.UUID       `json:"item_uuid,omitempty"`
+	Property   string           `json:"property,omitempty"`
+	Old        json.RawMessage  `json:"old,omitempty"`
+	New        json.RawMessage  `json:"new,omitempty"`
+}
+
+// String returns a human readable description of this change
+func (c *Change) String() string {
+	switch c.Type {
+	case ChangeTypePropertyChanged:
+		return fmt.Sprintf("~ flow %s: %s → %s", c.Property, fmtRaw(c.Old), fmtRaw(c.New))
+	case ChangeTypeNodeAdded:
+		return fmt.Sprintf("+ node %s", c.NodeUUID)
+	case ChangeTypeNodeRemoved:
+		return fmt.Sprintf("- node %s", c.NodeUUID)
+	case ChangeTypeActionAdded:
+		return fmt.Sprintf("+ node %s action %s (%s)", c.NodeUUID, c.ActionUUID, c.ActionType)
+	case ChangeTypeActionRemoved:
+		return fmt.Sprintf("- node %s action %s (%s)", c.NodeUUID, c.ActionUUID, c.ActionType)
+	case ChangeTypeActionChanged:
+		return fmt.Sprintf("~ node %s action %s (%s) %s: %s → %s", c.NodeUUID, c.ActionUUID, c.ActionType, c.Property, fmtRaw(c.Old), fmtRaw(c.New))
+	case ChangeTypeActionMoved:
+		return fmt.Sprintf("~ node %s action %s (%s) moved: position %s → %s", c.NodeUUID, c.ActionUUID, c.ActionType, c.Old, c.New)
+	case ChangeTypeRouterAdded:
+		return fmt.Sprintf("+ node %s router", c.NodeUUID)
+	case ChangeTypeRouterRemoved:
+		return fmt.Sprintf("- node %s router", c.NodeUUID)
+	case ChangeTypeRouterChanged:
+		return fmt.Sprintf("~ node %s router %s: %s → %s", c.NodeUUID, c.Property, fmtRaw(c.Old), fmtRaw(c.New))
+	case ChangeTypeExitAdded:
+		return fmt.Sprintf("+ node %s exit %s → %s", c.NodeUUID, c.ExitUUID, fmtRaw(c.New))
+	case ChangeTypeExitRemoved:
+		return fmt.Sprintf("- node %s exit %s", c.NodeUUID, c.ExitUUID)
+	case ChangeTypeExitRewired:
+		return fmt.Sprintf("~ node %s exit %s rewired: %s → %s", c.NodeUUID, c.ExitUUID, fmtRaw(c.Old), fmtRaw(c.New))
+	case ChangeTypeTranslationAdded:
+		return fmt.Sprintf("+ translation [%s] %s %s: %s", c.Language, c.ItemUUID, c.Property, fmtRaw(c.New))
+	case ChangeTypeTranslationRemoved:
+		return fmt.Sprintf("- translation [%s] %s %s: %s", c.Language, c.ItemUUID, c.Property, fmtRaw(c.Old))
+	case ChangeTypeTranslationChanged:
+		return fmt.Sprintf("~ translation [%s] %s %s: %s → %s", c.Language, c.ItemUUID, c.Property, fmtRaw(c.Old), fmtRaw(c.New))
+	}
+	return string(c.Type)
+}
+
+func fmtRaw(r json.RawMessage) string {
+	if len(r) == 0 {
+		return "(none)"
+	}
+	return string(r)
+}
+
+// Diff is the set of semantic changes between two flow definitions
+type Diff struct {
+	Changes []*Change `json:"changes"`
+}
+
+// IsEmpty returns whether there are no changes in this diff
+func (d *Diff) IsEmpty() bool { return len(d.Changes) == 0 }
+
+// Format returns a human readable version of this diff
+func (d *Diff) Format() string {
+	lines := make([]string, len(d.Changes))
+	for i, c := range d.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Compare generates a semantic diff between an old and a new version of a flow
+func Compare(old, new flows.Flow) (*Diff, error) {
+	d := &Diff{Changes: make([]*Change, 0)}
+	add := func(c *Change) { d.Changes = append(d.Changes, c) }
+
+	oldFlow, err := toGeneric(old)
+	if err != nil {
+		return nil, err
+	}
+	newFlow, err := toGeneric(new)
+	if err != nil {
+		return nil, err
+	}
+	oldObj, newObj := oldFlow.(map[string]interface{}), newFlow.(map[string]interface{})
+
+	for _, p := range flowProperties {
+		o, n := getKey(oldObj, p), getKey(newObj, p)
+		if !equal(o, n) {
+			add(&Change{Type: ChangeTypePropertyChanged, Property: p, Old: toRaw(o), New: toRaw(n)})
+		}
+	}
+
+	if err := compareNodes(old, new, add); err != nil {
+		return nil, err
+	}
+
+	compareLocalization(getKey(oldObj, "localization"), getKey(newObj, "localization"), add)
+
+	return d, nil
+}
+
+func compareNodes(old, new flows.Flow, add func(*Change)) error {
+	for _, newNode := range new.Nodes() {
+		oldNode := old.GetNode(newNode.UUID())
+		if oldNode == nil {
+			add(&Change{Type: ChangeTypeNodeAdded, NodeUUID: newNode.UUID()})
+			continue
+		}
+		if err := compareNode(oldNode, newNode, add); err != nil {
+			return err
+		}
+	}
+
+	for _, oldNode := range old.Nodes() {
+		if new.GetNode(oldNode.UUID()) == nil {
+			add(&Change{Type: ChangeTypeNodeRemoved, NodeUUID: oldNode.UUID()})
+		}
+	}
+	return nil
+}
+
+func compareNode(old, new flows.Node, add func(*Change)) error {
+	nodeUUID := new.UUID()
+
+	if err := compareActions(nodeUUID, old.Actions(), new.Actions(), add); err != nil {
+		return err
+	}
+	if err := compareRouters(nodeUUID, old.Router(), new.Router(), add); err != nil {
+		return err
+	}
+	compareExits(nodeUUID, old.Exits(), new.Exits(), add)
+	return nil
+}
+
+func compareActions(nodeUUID flows.NodeUUID, old, new []flows.Action, add func(*Change)) error {
+	oldByUUID := make(map[flows.ActionUUID]flows.Action, len(old))
+	for _, a := range old {
+		oldByUUID[a.UUID()] = a
+	}
+	newByUUID := make(map[flows.ActionUUID]flows.Action, len(new))
+	for _, a := range new {
+		newByUUID[a.UUID()] = a
+	}
+
+	// positions of actions which exist in both versions, relative to each other
+	oldPositions := make(map[flows.ActionUUID]int)
+	for _, a := range old {
+		if newByUUID[a.UUID()] != nil {
+			oldPositions[a.UUID()] = len(oldPositions)
+		}
+	}
+	newPositions := make(map[flows.ActionUUID]int)
+	for _, a := range new {
+		if oldByUUID[a.UUID()] != nil {
+			newPositions[a.UUID()] = len(newPositions)
+		}
+	}
+
+	for _, newAction := range new {
+		oldAction := oldByUUID[newAction.UUID()]
+		if oldAction == nil {
+			add(&Change{Type: ChangeTypeActionAdded, NodeUUID: nodeUUID, ActionUUID: newAction.UUID(), ActionType: newAction.Type(), New: jsonx.MustMarshal(newAction)})
+			continue
+		}
+
+		if oldPositions[newAction.UUID()] != newPositions[newAction.UUID()] {
+			add(&Change{
+				Type:       ChangeTypeActionMoved,
+				NodeUUID:   nodeUUID,
+				ActionUUID: newAction.UUID(),
+				ActionType: newAction.Type(),
+				Old:        jsonx.MustMarshal(oldPositions[newAction.UUID()]),
+				New:        jsonx.MustMarshal(newPositions[newAction.UUID()]),
+			})
+		}
+
+		o, err := toGeneric(oldAction)
+		if err != nil {
+			return err
+		}
+		n, err := toGeneric(newAction)
+		if err != nil {
+			return err
+		}
+		walkDiff("", o, n, func(path string, ov, nv interface{}) {
+			add(&Change{Type: ChangeTypeActionChanged, NodeUUID: nodeUUID, ActionUUID: newAction.UUID(), ActionType: newAction.Type(), Property: path, Old: toRaw(ov), New: toRaw(nv)})
+		})
+	}
+
+	for _, oldAction := range old {
+		if newByUUID[oldAction.UUID()] == nil {
+			add(&Change{Type: ChangeTypeActionRemoved, NodeUUID: nodeUUID, ActionUUID: oldAction.UUID(), ActionType: oldAction.Type(), Old: jsonx.MustMarshal(oldAction)})
+		}
+	}
+	return nil
+}
+
+func compareRouters(nodeUUID flows.NodeUUID, old, new flows.Router, add func(*Change)) error {
+	if old == nil && new == nil {
+		return nil
+	}
+	if old == nil {
+		add(&Change{Type: ChangeTypeRouterAdded, NodeUUID: nodeUUID, New: jsonx.MustMarshal(new)})
+		return nil
+	}
+	if new == nil {
+		add(&Change{Type: ChangeTypeRouterRemoved, NodeUUID: nodeUUID, Old: jsonx.MustMarshal(old)})
+		return nil
+	}
+
+	o, err := toGeneric(old)
+	if err != nil {
+		return err
+	}
+	n, err := toGeneric(new)
+	if err != nil {
+		return err
+	}
+	walkDiff("", o, n, func(path string, ov, nv interface{}) {
+		add(&Change{Type: ChangeTypeRouterChanged, NodeUUID: nodeUUID, Property: path, Old: toRaw(ov), New: toRaw(nv)})
+	})
+	return nil
+}
+
+func compareExits(nodeUUID flows.NodeUUID, old, new []flows.Exit, add func(*Change)) {
+	oldByUUID := make(map[flows.ExitUUID]flows.Exit, len(old))
+	for _, e := range old {
+		oldByUUID[e.UUID()] = e
+	}
+	newByUUID := make(map[flows.ExitUUID]flows.Exit, len(new))
+	for _, e := range new {
+		newByUUID[e.UUID()] = e
+	}
+
+	for _, newExit := range new {
+		oldExit := oldByUUID[newExit.UUID()]
+		if oldExit == nil {
+			add(&Change{Type: ChangeTypeExitAdded, NodeUUID: nodeUUID, ExitUUID: newExit.UUID(), New: destinationRaw(newExit)})
+		} else if oldExit.DestinationUUID() != newExit.DestinationUUID() {
+			add(&Change{Type: ChangeTypeExitRewired, NodeUUID: nodeUUID, ExitUUID: newExit.UUID(), Old: destinationRaw(oldExit), New: destinationRaw(newExit)})
+		}
+	}
+	for _, oldExit := range old {
+		if newByUUID[oldExit.UUID()] == nil {
+			add(&Change{Type: ChangeTypeExitRemoved, NodeUUID: nodeUUID, ExitUUID: oldExit.UUID(), Old: destinationRaw(oldExit)})
+		}
+	}
+}
+
+func destinationRaw(e flows.Exit) json.RawMessage {
+	if e.DestinationUUID() == "" {
+		return nil
+	}
+	return jsonx.MustMarshal(e.DestinationUUID())
+}
+
+// compares localizations which in generic form are language -> item UUID -> property -> texts
+func compareLocalization(old, new interface{}, add func(*Change)) {
+	oldLangs, _ := old.(map[string]interface{})
+	newLangs, _ := new.(map[string]interface{})
+
+	for _, lang := range unionKeys(oldLangs, newLangs) {
+		oldItems, _ := getKey(oldLangs, lang).(map[string]interface{})
+		newItems, _ := getKey(newLangs, lang).(map[string]interface{})
+
+		for _, itemUUID := range unionKeys(oldItems, newItems) {
+			oldProps, _ := getKey(oldItems, itemUUID).(map[string]interface{})
+			newProps, _ := getKey(newItems, itemUUID).(map[string]interface{})
+
+			for _, prop := range unionKeys(oldProps, newProps) {
+				o, n := getKey(oldProps, prop), getKey(newProps, prop)
+				c := &Change{Language: envs.Language(lang), ItemUUID: uuids.UUID(itemUUID), Property: prop, Old: toRaw(o), New: toRaw(n)}
+
+				if o == absent {
+					c.Type = ChangeTypeTranslationAdded
+				} else if n == absent {
+					c.Type = ChangeTypeTranslationRemoved
+				} else if !equal(o, n) {
+					c.Type = ChangeTypeTranslationChanged
+				} else {
+					continue
+				}
+				add(c)
+			}
+		}
+	}
+}
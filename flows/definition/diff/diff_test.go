@@ -0,0 +1,100 @@
+package diff_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/definition/diff"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFlow(t *testing.T, path string) flows.Flow {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	flow, err := definition.ReadFlow(data, nil)
+	require.NoError(t, err)
+	return flow
+}
+
+func TestCompare(t *testing.T) {
+	base := readFlow(t, "testdata/base.json")
+	ours := readFlow(t, "testdata/ours.json")
+	theirs := readFlow(t, "testdata/theirs.json")
+
+	// comparing a flow to itself gives no changes
+	d, err := diff.Compare(base, base)
+	require.NoError(t, err)
+	assert.True(t, d.IsEmpty())
+	assert.Equal(t, "", d.Format())
+
+	d, err = diff.Compare(base, ours)
+	require.NoError(t, err)
+	assert.Equal(t, `~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 action a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 (send_msg) text: "What is your favorite color?" → "What's your favorite color?"
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 exit 3f9e8d7c-6b5a-4c3d-9e1f-0a1b2c3d4e71 rewired: (none) → "3d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f03"
++ node 3d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f03
++ translation [spa] a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 quick_replies: ["Rojo","Azul"]
+~ translation [spa] a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 text: ["¿Cuál es tu color favorito?"] → ["¿Cuál es su color favorito?"]`, d.Format())
+
+	d, err = diff.Compare(base, theirs)
+	require.NoError(t, err)
+	assert.Equal(t, `~ flow name: "Favorites" → "Favourites"
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 action a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 (send_msg) quick_replies: ["Red","Blue"] → ["Red","Blue","Green"]
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 router cases[8b7c6d5e-4f3a-4b2c-9d1e-0f9a8b7c6d91]: (none) → {"arguments":["rouge"],"category_uuid":"5d8cfa24-7c7c-4b2e-8f0e-38f4f0f3a2b1","type":"has_any_word","uuid":"8b7c6d5e-4f3a-4b2c-9d1e-0f9a8b7c6d91"}
+- node 2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02 action c5e4a3b2-9d8c-4b7e-8a6f-2d3e4f5a6b41 (add_contact_groups)`, d.Format())
+
+	// and in reverse
+	d, err = diff.Compare(ours, base)
+	require.NoError(t, err)
+	assert.Equal(t, []diff.ChangeType{
+		diff.ChangeTypeActionChanged,
+		diff.ChangeTypeExitRewired,
+		diff.ChangeTypeNodeRemoved,
+		diff.ChangeTypeTranslationRemoved,
+		diff.ChangeTypeTranslationChanged,
+	}, changeTypes(d))
+
+	test.AssertEqualJSON(t, []byte(`{
+		"type": "exit_rewired",
+		"node_uuid": "1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01",
+		"exit_uuid": "3f9e8d7c-6b5a-4c3d-9e1f-0a1b2c3d4e71",
+		"old": "3d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f03"
+	}`), jsonx.MustMarshal(d.Changes[1]), "JSON mismatch")
+}
+
+func TestCompareMovedActions(t *testing.T) {
+	base := readFlow(t, "testdata/base.json")
+
+	reordered, err := definition.ReadFlow(test.JSONReplace(jsonx.MustMarshal(base), []string{"nodes", "[1]", "actions"}, []byte(`[
+		{
+			"uuid": "c5e4a3b2-9d8c-4b7e-8a6f-2d3e4f5a6b41",
+			"type": "add_contact_groups",
+			"groups": [{"uuid": "b7cf0d83-f1c9-411c-96fd-c511a4cfa86d", "name": "Red Lovers"}]
+		},
+		{
+			"uuid": "b4d3f2e1-8c7b-4a6d-9f5e-1c2d3e4f5a31",
+			"type": "send_msg",
+			"text": "Red it is!"
+		}
+	]`)), nil)
+	require.NoError(t, err)
+
+	d, err := diff.Compare(base, reordered)
+	require.NoError(t, err)
+	assert.Equal(t, `~ node 2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02 action c5e4a3b2-9d8c-4b7e-8a6f-2d3e4f5a6b41 (add_contact_groups) moved: position 1 → 0
+~ node 2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02 action b4d3f2e1-8c7b-4a6d-9f5e-1c2d3e4f5a31 (send_msg) moved: position 0 → 1`, d.Format())
+}
+
+func changeTypes(d *diff.Diff) []diff.ChangeType {
+	types := make([]diff.ChangeType, len(d.Changes))
+	for i := range d.Changes {
+		types[i] = d.Changes[i].Type
+	}
+	return types
+}
@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/nyaruka/gocommon/jsonx"
+)
+
+// marker for a key or item which doesn't exist on one side of a comparison
+type absentValue struct{}
+
+var absent = absentValue{}
+
+// converts anything marshalable to generic JSON values (maps, slices, json.Number, string, bool, nil)
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := jsonx.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var g interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// converts a generic value back to JSON, returning nil for absent values
+func toRaw(v interface{}) json.RawMessage {
+	if v == absent {
+		return nil
+	}
+	return jsonx.MustMarshal(v)
+}
+
+// checks whether two generic values are equal
+func equal(v1, v2 interface{}) bool {
+	return reflect.DeepEqual(v1, v2)
+}
+
+// gets the value of the given key in a generic object, or absent if it doesn't exist
+func getKey(obj map[string]interface{}, key string) interface{} {
+	if v, exists := obj[key]; exists {
+		return v
+	}
+	return absent
+}
+
+// gets the union of keys in the given objects in sorted order
+func unionKeys(objs ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, o := range objs {
+		for k := range o {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// if the given value is a list of objects which all have UUIDs, returns those objects and their UUIDs
+func asUUIDList(v interface{}) ([]map[string]interface{}, []string, bool) {
+	list, isList := v.([]interface{})
+	if !isList {
+		return nil, nil, false
+	}
+	items := make([]map[string]interface{}, len(list))
+	uuids := make([]string, len(list))
+	seen := make(map[string]bool, len(list))
+	for i := range list {
+		obj, isObj := list[i].(map[string]interface{})
+		if !isObj {
+			return nil, nil, false
+		}
+		uuid, isStr := obj["uuid"].(string)
+		if !isStr || uuid == "" || seen[uuid] {
+			return nil, nil, false
+		}
+		seen[uuid] = true
+		items[i] = obj
+		uuids[i] = uuid
+	}
+	return items, uuids, true
+}
+
+// walks two generic values, calling the callback for each leaf path where they differ. Objects are compared
+// key by key and lists of objects with UUIDs are compared item by item.
+func walkDiff(path string, v1, v2 interface{}, fn func(string, interface{}, interface{})) {
+	if equal(v1, v2) {
+		return
+	}
+
+	o1, isObj1 := v1.(map[string]interface{})
+	o2, isObj2 := v2.(map[string]interface{})
+	if isObj1 && isObj2 {
+		for _, k := range unionKeys(o1, o2) {
+			walkDiff(joinPath(path, k), getKey(o1, k), getKey(o2, k), fn)
+		}
+		return
+	}
+
+	items1, uuids1, isUUIDs1 := asUUIDList(v1)
+	items2, uuids2, isUUIDs2 := asUUIDList(v2)
+	if isUUIDs1 && isUUIDs2 && (len(items1) > 0 || len(items2) > 0) {
+		byUUID1 := make(map[string]interface{}, len(items1))
+		for i := range items1 {
+			byUUID1[uuids1[i]] = items1[i]
+		}
+		byUUID2 := make(map[string]interface{}, len(items2))
+		for i := range items2 {
+			byUUID2[uuids2[i]] = items2[i]
+		}
+		for _, u := range uuids1 {
+			walkDiff(itemPath(path, u), byUUID1[u], valueOrAbsent(byUUID2, u), fn)
+		}
+		for _, u := range uuids2 {
+			if _, seen := byUUID1[u]; !seen {
+				walkDiff(itemPath(path, u), absent, byUUID2[u], fn)
+			}
+		}
+		return
+	}
+
+	fn(path, v1, v2)
+}
+
+func valueOrAbsent(m map[string]interface{}, key string) interface{} {
+	if v, exists := m[key]; exists {
+		return v
+	}
+	return absent
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func itemPath(path, uuid string) string {
+	return fmt.Sprintf("%s[%s]", path, uuid)
+}
@@ -0,0 +1,174 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+
+	"github.com/pkg/errors"
+)
+
+// Conflict is a place where both sides of a merge changed the same thing in different ways. Conflicts are
+// resolved in favor of our side.
+type Conflict struct {
+	Path   string          `json:"path"`
+	Base   json.RawMessage `json:"base,omitempty"`
+	Ours   json.RawMessage `json:"ours,omitempty"`
+	Theirs json.RawMessage `json:"theirs,omitempty"`
+}
+
+// String returns a human readable description of this conflict
+func (c *Conflict) String() string {
+	return fmt.Sprintf("! %s: base=%s ours=%s theirs=%s", c.Path, fmtRaw(c.Base), fmtRaw(c.Ours), fmtRaw(c.Theirs))
+}
+
+// Merge does a three-way merge of two flows which were both derived from the given base flow. Objects are merged
+// key by key, and lists of things with UUIDs (nodes, actions, exits, categories, cases) are merged item by item.
+// Where both sides changed the same value differently, a conflict is reported and our version is used. An error
+// is returned if the merged definition isn't a valid flow.
+func Merge(base, ours, theirs flows.Flow) (flows.Flow, []*Conflict, error) {
+	b, err := toGeneric(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := toGeneric(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := toGeneric(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// revisions will always differ so don't merge them, but take the highest revision
+	for _, f := range []interface{}{b, o, t} {
+		delete(f.(map[string]interface{}), "revision")
+	}
+
+	conflicts := make([]*Conflict, 0)
+	onConflict := func(path string, b, o, t interface{}) {
+		conflicts = append(conflicts, &Conflict{Path: path, Base: toRaw(b), Ours: toRaw(o), Theirs: toRaw(t)})
+	}
+
+	merged := merge3("", b, o, t, onConflict).(map[string]interface{})
+
+	revision := ours.Revision()
+	if theirs.Revision() > revision {
+		revision = theirs.Revision()
+	}
+	merged["revision"] = revision
+
+	flow, err := definition.ReadFlow(jsonx.MustMarshal(merged), nil)
+	if err != nil {
+		return nil, conflicts, errors.Wrap(err, "merged definition is invalid")
+	}
+
+	return flow, conflicts, nil
+}
+
+// merges three generic values, returning absent if the merged value should be removed
+func merge3(path string, base, ours, theirs interface{}, onConflict func(string, interface{}, interface{}, interface{})) interface{} {
+	if equal(ours, theirs) || equal(base, theirs) {
+		return ours
+	}
+	if equal(base, ours) {
+		return theirs
+	}
+
+	// both sides changed this value, so see if we can merge at a lower level
+	oursObj, isObj1 := ours.(map[string]interface{})
+	theirsObj, isObj2 := theirs.(map[string]interface{})
+	if isObj1 && isObj2 {
+		baseObj, _ := base.(map[string]interface{})
+		return mergeObjects(path, baseObj, oursObj, theirsObj, onConflict)
+	}
+
+	if _, _, isUUIDs := asUUIDList(ours); isUUIDs {
+		if _, _, isUUIDs := asUUIDList(theirs); isUUIDs {
+			if _, _, isUUIDs := asUUIDList(base); isUUIDs || base == absent {
+				return mergeUUIDLists(path, base, ours, theirs, onConflict)
+			}
+		}
+	}
+
+	onConflict(path, base, ours, theirs)
+	return ours
+}
+
+func mergeObjects(path string, base, ours, theirs map[string]interface{}, onConflict func(string, interface{}, interface{}, interface{})) map[string]interface{} {
+	merged := make(map[string]interface{}, len(ours))
+
+	for _, k := range unionKeys(base, ours, theirs) {
+		v := merge3(joinPath(path, k), getKey(base, k), getKey(ours, k), getKey(theirs, k), onConflict)
+		if v != absent {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func mergeUUIDLists(path string, base, ours, theirs interface{}, onConflict func(string, interface{}, interface{}, interface{})) []interface{} {
+	baseItems, baseUUIDs, _ := asUUIDList(base)
+	oursItems, oursUUIDs, _ := asUUIDList(ours)
+	theirsItems, theirsUUIDs, _ := asUUIDList(theirs)
+
+	baseByUUID := make(map[string]interface{}, len(baseItems))
+	for i := range baseItems {
+		baseByUUID[baseUUIDs[i]] = baseItems[i]
+	}
+	oursByUUID := make(map[string]interface{}, len(oursItems))
+	for i := range oursItems {
+		oursByUUID[oursUUIDs[i]] = oursItems[i]
+	}
+	theirsByUUID := make(map[string]interface{}, len(theirsItems))
+	for i := range theirsItems {
+		theirsByUUID[theirsUUIDs[i]] = theirsItems[i]
+	}
+
+	// start with our ordering, and insert items only added by them after their predecessor on their side
+	order := append([]string(nil), oursUUIDs...)
+	for i, u := range theirsUUIDs {
+		_, inBase := baseByUUID[u]
+		_, inOurs := oursByUUID[u]
+		if inBase || inOurs {
+			continue
+		}
+
+		insertAt := 0
+		for j := i - 1; j >= 0; j-- {
+			if idx := indexOf(order, theirsUUIDs[j]); idx >= 0 {
+				insertAt = idx + 1
+				break
+			}
+		}
+		order = append(order[:insertAt], append([]string{u}, order[insertAt:]...)...)
+	}
+
+	// items removed by us still need merging to detect conflicts with changes made by them
+	for _, u := range baseUUIDs {
+		if _, inOurs := oursByUUID[u]; !inOurs {
+			merge3(itemPath(path, u), baseByUUID[u], absent, valueOrAbsent(theirsByUUID, u), onConflict)
+		}
+	}
+
+	merged := make([]interface{}, 0, len(order))
+	for _, u := range order {
+		v := merge3(itemPath(path, u), valueOrAbsent(baseByUUID, u), valueOrAbsent(oursByUUID, u), valueOrAbsent(theirsByUUID, u), onConflict)
+		if v != absent {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+func indexOf(s []string, v string) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
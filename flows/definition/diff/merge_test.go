@@ -0,0 +1,65 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/definition/diff"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	base := readFlow(t, "testdata/base.json")
+	ours := readFlow(t, "testdata/ours.json")
+	theirs := readFlow(t, "testdata/theirs.json")
+	conflicting := readFlow(t, "testdata/conflicting.json")
+
+	// merging with no changes on their side gives us our flow
+	merged, conflicts, err := diff.Merge(base, ours, base)
+	require.NoError(t, err)
+	assert.Len(t, conflicts, 0)
+	test.AssertEqualJSON(t, jsonx.MustMarshal(ours), jsonx.MustMarshal(merged), "merged flow mismatch")
+
+	// merging non-overlapping changes gives us a flow with both sets of changes
+	merged, conflicts, err = diff.Merge(base, ours, theirs)
+	require.NoError(t, err)
+	assert.Len(t, conflicts, 0)
+	assert.Equal(t, 3, merged.Revision())
+
+	d, err := diff.Compare(base, merged)
+	require.NoError(t, err)
+	assert.Equal(t, `~ flow name: "Favorites" → "Favourites"
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 action a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 (send_msg) quick_replies: ["Red","Blue"] → ["Red","Blue","Green"]
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 action a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 (send_msg) text: "What is your favorite color?" → "What's your favorite color?"
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 router cases[8b7c6d5e-4f3a-4b2c-9d1e-0f9a8b7c6d91]: (none) → {"arguments":["rouge"],"category_uuid":"5d8cfa24-7c7c-4b2e-8f0e-38f4f0f3a2b1","type":"has_any_word","uuid":"8b7c6d5e-4f3a-4b2c-9d1e-0f9a8b7c6d91"}
+~ node 1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01 exit 3f9e8d7c-6b5a-4c3d-9e1f-0a1b2c3d4e71 rewired: (none) → "3d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f03"
+- node 2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02 action c5e4a3b2-9d8c-4b7e-8a6f-2d3e4f5a6b41 (add_contact_groups)
++ node 3d6e7f8a-9b0c-4d1e-8f2a-3b4c5d6e7f03
++ translation [spa] a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 quick_replies: ["Rojo","Azul"]
+~ translation [spa] a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21 text: ["¿Cuál es tu color favorito?"] → ["¿Cuál es su color favorito?"]`, d.Format())
+
+	// merging changes to the same value gives us a conflict which is resolved in our favor
+	merged, conflicts, err = diff.Merge(base, ours, conflicting)
+	require.NoError(t, err)
+	assert.Equal(t, 4, merged.Revision())
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, `! nodes[1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01].actions[a3c2e1b6-7a4b-4a3e-9e0c-0f2d2bcb6e21].text: base="What is your favorite color?" ours="What's your favorite color?" theirs="Which color do you like best?"`, conflicts[0].String())
+
+	d, err = diff.Compare(ours, merged)
+	require.NoError(t, err)
+	assert.True(t, d.IsEmpty())
+
+	// if we point an exit at a node which they remove, the merged flow is invalid
+	rewired, err := definition.ReadFlow(test.JSONReplace(jsonx.MustMarshal(base), []string{"nodes", "[0]", "exits", "[1]", "destination_uuid"}, []byte(`"2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02"`)), nil)
+	require.NoError(t, err)
+	removed, err := definition.ReadFlow(test.JSONDelete(test.JSONDelete(jsonx.MustMarshal(base), []string{"nodes", "[0]", "exits", "[0]", "destination_uuid"}), []string{"nodes", "[1]"}), nil)
+	require.NoError(t, err)
+
+	merged, _, err = diff.Merge(base, rewired, removed)
+	assert.Nil(t, merged)
+	assert.EqualError(t, err, "merged definition is invalid: invalid node[uuid=1b4e5e2a-b0bd-4b3e-9b1a-8d2a0e1f6c01]: destination 2c5d6e7f-8a9b-4c0d-9e1f-2a3b4c5d6e02 of exit[uuid=3f9e8d7c-6b5a-4c3d-9e1f-0a1b2c3d4e71] isn't a known node")
+}
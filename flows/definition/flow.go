@@ -19,7 +19,7 @@ import (
 )
 
 // CurrentSpecVersion is the flow spec version supported by this library
-var CurrentSpecVersion = semver.MustParse("13.1.0")
+var CurrentSpecVersion = semver.MustParse("13.2.0")
 
 // IsVersionSupported checks the given version is supported
 func IsVersionSupported(v *semver.Version) bool {
@@ -37,6 +37,8 @@ type flow struct {
 	revision           int
 	expireAfterMinutes int
 	localization       flows.Localization
+	params             []*flows.FlowParam
+	outputs            []*flows.FlowOutput
 	nodes              []flows.Node
 
 	// optional properties not used by engine itself
@@ -47,7 +49,7 @@ type flow struct {
 }
 
 // NewFlow creates a new flow
-func NewFlow(uuid assets.FlowUUID, name string, language envs.Language, flowType flows.FlowType, revision int, expireAfterMinutes int, localization flows.Localization, nodes []flows.Node, ui json.RawMessage) (flows.Flow, error) {
+func NewFlow(uuid assets.FlowUUID, name string, language envs.Language, flowType flows.FlowType, revision int, expireAfterMinutes int, localization flows.Localization, params []*flows.FlowParam, outputs []*flows.FlowOutput, nodes []flows.Node, ui json.RawMessage) (flows.Flow, error) {
 	f := &flow{
 		uuid:               uuid,
 		name:               name,
@@ -57,6 +59,8 @@ func NewFlow(uuid assets.FlowUUID, name string, language envs.Language, flowType
 		revision:           revision,
 		expireAfterMinutes: expireAfterMinutes,
 		localization:       localization,
+		params:             params,
+		outputs:            outputs,
 		nodes:              nodes,
 		nodeMap:            make(map[flows.NodeUUID]flows.Node, len(nodes)),
 		ui:                 ui,
@@ -82,10 +86,29 @@ func (f *flow) Type() flows.FlowType                   { return f.flowType }
 func (f *flow) ExpireAfterMinutes() int                { return f.expireAfterMinutes }
 func (f *flow) Nodes() []flows.Node                    { return f.nodes }
 func (f *flow) Localization() flows.Localization       { return f.localization }
+func (f *flow) Params() []*flows.FlowParam             { return f.params }
+func (f *flow) Outputs() []*flows.FlowOutput           { return f.outputs }
 func (f *flow) UI() json.RawMessage                    { return f.ui }
 func (f *flow) GetNode(uuid flows.NodeUUID) flows.Node { return f.nodeMap[uuid] }
 
 func (f *flow) validate() error {
+	// parameter and output keys must be unique
+	seenParams := make(map[string]bool)
+	for _, p := range f.params {
+		if seenParams[p.Key] {
+			return errors.Errorf("parameter key '%s' isn't unique", p.Key)
+		}
+		seenParams[p.Key] = true
+	}
+
+	seenOutputs := make(map[string]bool)
+	for _, o := range f.outputs {
+		if seenOutputs[o.Key] {
+			return errors.Errorf("output key '%s' isn't unique", o.Key)
+		}
+		seenOutputs[o.Key] = true
+	}
+
 	// track UUIDs used by nodes and actions to ensure that they are unique
 	seenUUIDs := make(map[uuids.UUID]bool)
 
@@ -154,6 +177,9 @@ func (f *flow) ExtractTemplates() []string {
 	for _, n := range f.nodes {
 		n.EnumerateTemplates(f.Localization(), include)
 	}
+	for _, o := range f.outputs {
+		include(nil, nil, "", o.Value)
+	}
 
 	return templates
 }
@@ -261,6 +287,14 @@ func (f *flow) extract() ([]flows.ExtractedTemplate, []flows.ExtractedReference,
 		})
 	}
 
+	// output values aren't part of any node but can still reference parent results
+	for _, o := range f.outputs {
+		_, prs := inspect.ExtractFromTemplate(o.Value)
+		for _, r := range prs {
+			parentRefs[r] = true
+		}
+	}
+
 	return templates, assetRefs, utils.StringSetKeys(parentRefs)
 }
 
@@ -301,13 +335,15 @@ var _ flows.Flow = (*flow)(nil)
 type flowEnvelope struct {
 	migrations.Header13
 
-	Language           envs.Language   `json:"language" validate:"required"`
-	Type               flows.FlowType  `json:"type" validate:"required,flow_type"`
-	Revision           int             `json:"revision"`
-	ExpireAfterMinutes int             `json:"expire_after_minutes"`
-	Localization       localization    `json:"localization"`
-	Nodes              []*node         `json:"nodes"`
-	UI                 json.RawMessage `json:"_ui,omitempty"`
+	Language           envs.Language       `json:"language" validate:"required"`
+	Type               flows.FlowType      `json:"type" validate:"required,flow_type"`
+	Revision           int                 `json:"revision"`
+	ExpireAfterMinutes int                 `json:"expire_after_minutes"`
+	Localization       localization        `json:"localization"`
+	Params             []*flows.FlowParam  `json:"parameters,omitempty" validate:"omitempty,dive"`
+	Outputs            []*flows.FlowOutput `json:"outputs,omitempty" validate:"omitempty,dive"`
+	Nodes              []*node             `json:"nodes" jsonschema:"node"`
+	UI                 json.RawMessage     `json:"_ui,omitempty"`
 }
 
 // ReadFlow a flow definition from the passed in byte array, migrating it to the spec version of the engine if necessary
@@ -339,7 +375,7 @@ func ReadFlow(data json.RawMessage, migrationConfig *migrations.Config) (flows.F
 		e.Localization = make(localization)
 	}
 
-	return NewFlow(e.UUID, e.Name, e.Language, e.Type, e.Revision, e.ExpireAfterMinutes, e.Localization, nodes, e.UI)
+	return NewFlow(e.UUID, e.Name, e.Language, e.Type, e.Revision, e.ExpireAfterMinutes, e.Localization, e.Params, e.Outputs, nodes, e.UI)
 }
 
 // MarshalJSON marshals this flow into JSON
@@ -355,6 +391,8 @@ func (f *flow) MarshalJSON() ([]byte, error) {
 		Revision:           f.revision,
 		ExpireAfterMinutes: f.expireAfterMinutes,
 		Localization:       f.localization.(localization),
+		Params:             f.params,
+		Outputs:            f.outputs,
 		Nodes:              make([]*node, len(f.nodes)),
 		UI:                 f.ui,
 	}
@@ -51,6 +51,14 @@ func TestBrokenFlows(t *testing.T) {
 			"duplicate_node_uuid.json",
 			"node UUID a58be63b-907d-4a1a-856b-0bb5579d7507 isn't unique",
 		},
+		{
+			"duplicate_param_key.json",
+			"parameter key 'age' isn't unique",
+		},
+		{
+			"invalid_param_type.json",
+			"field 'parameters[0].type' is not a valid parameter type",
+		},
 		{
 			"invalid_flow_type.json",
 			"field 'type' is not a valid flow type",
@@ -71,6 +79,10 @@ func TestBrokenFlows(t *testing.T) {
 			"invalid_timeout_category.json",
 			"invalid node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: invalid router: timeout category 13fea3d4-b925-495b-b593-1c9e905e700d is not a valid category",
 		},
+		{
+			"invalid_until_category.json",
+			"invalid node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: invalid router: wait until category 13fea3d4-b925-495b-b593-1c9e905e700d is not a valid category",
+		},
 		{
 			"invalid_wait_by_flow_type.json",
 			"invalid node[uuid=a58be63b-907d-4a1a-856b-0bb5579d7507]: invalid router: wait type 'msg' is not allowed in a flow of type 'messaging_background'",
@@ -117,6 +129,21 @@ func TestNewFlow(t *testing.T) {
     "revision": 123,
     "expire_after_minutes": 30,
     "localization": {},
+    "parameters": [
+        {
+            "key": "age",
+            "name": "Age",
+            "type": "number",
+            "required": true
+        }
+    ],
+    "outputs": [
+        {
+            "key": "likes_beer",
+            "name": "Likes Beer",
+            "value": "@results.response_1.category"
+        }
+    ],
     "nodes": [
         {
             "uuid": "a58be63b-907d-4a1a-856b-0bb5579d7507",
@@ -210,6 +237,8 @@ func TestNewFlow(t *testing.T) {
 		123, // revision
 		30,  // expires after minutes
 		definition.NewLocalization(),
+		[]*flows.FlowParam{flows.NewFlowParam("age", "Age", flows.ParamTypeNumber, true)},
+		[]*flows.FlowOutput{flows.NewFlowOutput("likes_beer", "Likes Beer", "@results.response_1.category")},
 		[]flows.Node{
 			definition.NewNode(
 				flows.NodeUUID("a58be63b-907d-4a1a-856b-0bb5579d7507"),
@@ -8,6 +8,7 @@ import (
 
 func init() {
 	registerMigration(semver.MustParse("13.1.0"), Migrate13_1)
+	registerMigration(semver.MustParse("13.2.0"), Migrate13_2)
 }
 
 // Migrate13_1 adds UUID to send_msg templating
@@ -24,3 +25,8 @@ func Migrate13_1(f Flow) (Flow, error) {
 	}
 	return f, nil
 }
+
+// Migrate13_2 doesn't change anything as 13.2 only adds the optional parameters and outputs properties to flows
+func Migrate13_2(f Flow) (Flow, error) {
+	return f, nil
+}
@@ -143,9 +143,9 @@ func (n *node) EnumerateLocalizables(include func(uuids.UUID, string, []string,
 
 type nodeEnvelope struct {
 	UUID    flows.NodeUUID    `json:"uuid"               validate:"required,uuid4"`
-	Actions []json.RawMessage `json:"actions,omitempty"`
-	Router  json.RawMessage   `json:"router,omitempty"`
-	Exits   []*exit           `json:"exits"              validate:"required,min=1"`
+	Actions []json.RawMessage `json:"actions,omitempty"                             jsonschema:"action"`
+	Router  json.RawMessage   `json:"router,omitempty"                              jsonschema:"router"`
+	Exits   []*exit           `json:"exits"              validate:"required,min=1" jsonschema:"exit"`
 }
 
 // UnmarshalJSON unmarshals a flow node from the given JSON
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/contactql"
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/definition"
@@ -23,6 +24,7 @@ type sessionAssets struct {
 	locations        *flows.LocationAssets
 	msgCatalog       *flows.MsgCatalogAssets
 	resthooks        *flows.ResthookAssets
+	schedules        *flows.ScheduleAssets
 	templates        *flows.TemplateAssets
 	ticketers        *flows.TicketerAssets
 	topics           *flows.TopicAssets
@@ -30,6 +32,7 @@ type sessionAssets struct {
 }
 
 var _ flows.SessionAssets = (*sessionAssets)(nil)
+var _ contactql.SuggestionResolver = (*sessionAssets)(nil)
 
 // NewSessionAssets creates a new session assets instance with the provided base URLs
 func NewSessionAssets(env envs.Environment, source assets.Source, migrationConfig *migrations.Config) (flows.SessionAssets, error) {
@@ -73,6 +76,10 @@ func NewSessionAssets(env envs.Environment, source assets.Source, migrationConfi
 	if err != nil {
 		return nil, err
 	}
+	schedules, err := source.Schedules()
+	if err != nil {
+		return nil, err
+	}
 	templates, err := source.Templates()
 	if err != nil {
 		return nil, err
@@ -106,6 +113,7 @@ func NewSessionAssets(env envs.Environment, source assets.Source, migrationConfi
 		locations:        flows.NewLocationAssets(locations),
 		msgCatalog:       flows.NewMsgCatalogAssets(msgCatalog),
 		resthooks:        flows.NewResthookAssets(resthooks),
+		schedules:        flows.NewScheduleAssets(schedules),
 		templates:        flows.NewTemplateAssets(templates),
 		ticketers:        flows.NewTicketerAssets(ticketers),
 		topics:           flows.NewTopicAssets(topics),
@@ -124,6 +132,7 @@ func (s *sessionAssets) Groups() *flows.GroupAssets                     { return
 func (s *sessionAssets) Labels() *flows.LabelAssets                     { return s.labels }
 func (s *sessionAssets) Locations() *flows.LocationAssets               { return s.locations }
 func (s *sessionAssets) Resthooks() *flows.ResthookAssets               { return s.resthooks }
+func (s *sessionAssets) Schedules() *flows.ScheduleAssets               { return s.schedules }
 func (s *sessionAssets) Templates() *flows.TemplateAssets               { return s.templates }
 func (s *sessionAssets) Ticketers() *flows.TicketerAssets               { return s.ticketers }
 func (s *sessionAssets) Topics() *flows.TopicAssets                     { return s.topics }
@@ -144,3 +153,19 @@ func (s *sessionAssets) ResolveGroup(name string) assets.Group {
 	}
 	return g
 }
+func (s *sessionAssets) FieldKeys() []string {
+	all := s.Fields().All()
+	keys := make([]string, len(all))
+	for i, f := range all {
+		keys[i] = f.Key()
+	}
+	return keys
+}
+func (s *sessionAssets) GroupNames() []string {
+	all := s.Groups().All()
+	names := make([]string, len(all))
+	for i, g := range all {
+		names[i] = g.Name()
+	}
+	return names
+}
@@ -2,15 +2,24 @@ package engine
 
 import (
 	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
 
+	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
+
+	"github.com/shopspring/decimal"
 )
 
 // an instance of the engine
 type engine struct {
 	services             *services
+	clock                dates.NowSource
+	uuids                uuids.Generator
+	random               flows.RandomSource
 	maxStepsPerSprint    int
 	maxResumesPerSession int
 	maxTemplateChars     int
@@ -19,7 +28,7 @@ type engine struct {
 // NewSession creates a new session
 func (e *engine) NewSession(sa flows.SessionAssets, trigger flows.Trigger) (flows.Session, flows.Sprint, error) {
 	s := &session{
-		uuid:       flows.SessionUUID(uuids.New()),
+		uuid:       flows.SessionUUID(e.uuids.Next()),
 		engine:     e,
 		assets:     sa,
 		trigger:    trigger,
@@ -38,10 +47,13 @@ func (e *engine) ReadSession(sa flows.SessionAssets, data json.RawMessage, missi
 	return readSession(e, sa, data, missing)
 }
 
-func (e *engine) Services() flows.Services  { return e.services }
-func (e *engine) MaxStepsPerSprint() int    { return e.maxStepsPerSprint }
-func (e *engine) MaxResumesPerSession() int { return e.maxResumesPerSession }
-func (e *engine) MaxTemplateChars() int     { return e.maxTemplateChars }
+func (e *engine) Services() flows.Services   { return e.services }
+func (e *engine) Clock() dates.NowSource     { return e.clock }
+func (e *engine) UUIDs() uuids.Generator     { return e.uuids }
+func (e *engine) Random() flows.RandomSource { return e.random }
+func (e *engine) MaxStepsPerSprint() int     { return e.maxStepsPerSprint }
+func (e *engine) MaxResumesPerSession() int  { return e.maxResumesPerSession }
+func (e *engine) MaxTemplateChars() int      { return e.maxTemplateChars }
 
 var _ flows.Engine = (*engine)(nil)
 
@@ -51,7 +63,9 @@ var _ flows.Engine = (*engine)(nil)
 
 // Builder is a builder for engine configs
 type Builder struct {
-	eng *engine
+	eng      *engine
+	recorder *ServiceRecorder
+	replayer *ServiceReplayer
 }
 
 // NewBuilder creates a new engine builder
@@ -59,6 +73,9 @@ func NewBuilder() *Builder {
 	return &Builder{
 		eng: &engine{
 			services:             newEmptyServices(),
+			clock:                flows.GlobalClock,
+			uuids:                flows.GlobalUUIDs,
+			random:               flows.GlobalRandom,
 			maxStepsPerSprint:    100,
 			maxResumesPerSession: 500,
 			maxTemplateChars:     10000,
@@ -106,6 +123,24 @@ func (b *Builder) WithMsgCatalogServiceFactory(f MsgCatalogServiceFactory) *Buil
 	return b
 }
 
+// WithClock sets the clock used for all times in sessions, instead of the process-wide source of dates.Now()
+func (b *Builder) WithClock(clock dates.NowSource) *Builder {
+	b.eng.clock = &syncedClock{source: clock}
+	return b
+}
+
+// WithUUIDGenerator sets the generator used for all UUIDs in sessions, instead of the process-wide generator of uuids.New()
+func (b *Builder) WithUUIDGenerator(generator uuids.Generator) *Builder {
+	b.eng.uuids = &syncedUUIDs{generator: generator}
+	return b
+}
+
+// WithRandom sets the source of random numbers used in sessions, instead of the process-wide generator of the random package
+func (b *Builder) WithRandom(rnd *rand.Rand) *Builder {
+	b.eng.random = &syncedRandom{rnd: rnd}
+	return b
+}
+
 // WithMaxStepsPerSprint sets the maximum number of steps allowed in a single sprint
 func (b *Builder) WithMaxStepsPerSprint(max int) *Builder {
 	b.eng.maxStepsPerSprint = max
@@ -124,5 +159,71 @@ func (b *Builder) WithMaxTemplateChars(max int) *Builder {
 	return b
 }
 
+// WithServiceRecorder sets a recorder which will record all calls to the services of the engine
+func (b *Builder) WithServiceRecorder(recorder *ServiceRecorder) *Builder {
+	b.recorder = recorder
+	return b
+}
+
+// WithServiceReplayer sets a replayer which will replace the services of the engine with recorded calls
+func (b *Builder) WithServiceReplayer(replayer *ServiceReplayer) *Builder {
+	b.replayer = replayer
+	return b
+}
+
 // Build returns the final engine
-func (b *Builder) Build() flows.Engine { return b.eng }
+func (b *Builder) Build() flows.Engine {
+	if b.replayer == nil && b.recorder == nil {
+		return b.eng
+	}
+
+	// copy the engine so that the service factories of the builder aren't wrapped more than once
+	eng := *b.eng
+	if b.replayer != nil {
+		eng.services = b.replayer.services()
+	}
+	if b.recorder != nil {
+		eng.services = b.recorder.wrap(eng.services)
+	}
+	return &eng
+}
+
+//------------------------------------------------------------------------------------------
+// Sources
+//------------------------------------------------------------------------------------------
+
+// sources like sequential clocks and seeded generators aren't safe for concurrent use, so we lock them as the same
+// engine can be used for sessions in different goroutines
+
+type syncedClock struct {
+	source dates.NowSource
+	mutex  sync.Mutex
+}
+
+func (c *syncedClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.source.Now()
+}
+
+type syncedUUIDs struct {
+	generator uuids.Generator
+	mutex     sync.Mutex
+}
+
+func (g *syncedUUIDs) Next() uuids.UUID {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.generator.Next()
+}
+
+type syncedRandom struct {
+	rnd   *rand.Rand
+	mutex sync.Mutex
+}
+
+func (r *syncedRandom) Decimal() decimal.Decimal {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return decimal.NewFromFloat(r.rnd.Float64())
+}
@@ -1,14 +1,27 @@
 package engine_test
 
 import (
+	"fmt"
+	"math/rand"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
 	"github.com/nyaruka/goflow/services/webhooks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuilder(t *testing.T) {
@@ -17,6 +30,9 @@ func TestBuilder(t *testing.T) {
 
 	assert.Equal(t, 123, eng.MaxStepsPerSprint())
 	assert.Equal(t, 567, eng.MaxResumesPerSession())
+	assert.Equal(t, flows.GlobalClock, eng.Clock())
+	assert.Equal(t, flows.GlobalUUIDs, eng.UUIDs())
+	assert.Equal(t, flows.GlobalRandom, eng.Random())
 
 	_, err := eng.Services().Email(nil)
 	assert.EqualError(t, err, "no email service factory configured")
@@ -40,3 +56,68 @@ func TestBuilder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, webhookSvc, svc)
 }
+
+func TestDeterministicSources(t *testing.T) {
+	source, err := static.LoadSource("../../test/testdata/runner/two_questions.json")
+	require.NoError(t, err)
+
+	sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+	require.NoError(t, err)
+
+	contactJSON := []byte(`{"uuid": "5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f", "name": "Ryan Lewis", "language": "eng", "status": "active", "urns": ["tel:+12065551212"], "created_on": "2018-06-20T11:40:30.123456789Z"}`)
+	triggerJSON := `{"type": "manual", "flow": {"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Two Questions"}, "contact": %s, "triggered_on": "2018-10-18T14:20:30.000000Z"}`
+	resumeJSON := `{"type": "msg", "msg": {"uuid": "9bf91c2b-ce58-4cef-aacc-281e03f69ab5", "urn": "tel:+12065551212", "text": "Teal"}, "resumed_on": "2018-10-18T14:21:30.000000Z"}`
+
+	runSession := func() ([]byte, []byte) {
+		eng := engine.NewBuilder().
+			WithClock(dates.NewSequentialNowSource(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC))).
+			WithUUIDGenerator(uuids.NewSeededGenerator(1234)).
+			WithRandom(rand.New(rand.NewSource(1234))).
+			Build()
+
+		contact, err := flows.ReadContact(sa, contactJSON, assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		trigger, err := triggers.ReadTrigger(sa, []byte(fmt.Sprintf(triggerJSON, jsonx.MustMarshal(contact))), assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		session, sprint1, err := eng.NewSession(sa, trigger)
+		require.NoError(t, err)
+
+		resume, err := resumes.ReadResume(sa, []byte(resumeJSON), assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		sprint2, err := session.Resume(resume)
+		require.NoError(t, err)
+
+		return jsonx.MustMarshal(session), jsonx.MustMarshal(append(sprint1.Events(), sprint2.Events()...))
+	}
+
+	// run sessions concurrently in engines with the same sources
+	sessionsJSON := make([][]byte, 4)
+	eventsJSON := make([][]byte, 4)
+	wg := &sync.WaitGroup{}
+	for i := range sessionsJSON {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sessionsJSON[i], eventsJSON[i] = runSession()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < len(sessionsJSON); i++ {
+		assert.Equal(t, string(sessionsJSON[0]), string(sessionsJSON[i]))
+		assert.Equal(t, string(eventsJSON[0]), string(eventsJSON[i]))
+	}
+
+	// and the session only uses the engine's sources
+	session := &struct {
+		UUID uuids.UUID `json:"uuid"`
+	}{}
+	jsonx.MustUnmarshal(sessionsJSON[0], session)
+
+	assert.Equal(t, uuids.NewSeededGenerator(1234).Next(), session.UUID)
+	assert.Contains(t, string(eventsJSON[0]), `"created_on":"2022-03-01T12:00:`)
+	assert.NotContains(t, string(eventsJSON[0]), `"created_on":"2018-`)
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/buger/jsonparser"
+)
+
+// the properties of a result which are masked if the result is sensitive
+var sensitiveResultProperties = []string{"value", "category", "category_localized", "input", "extra"}
+
+// the properties of a message which are masked if it's the input to a sensitive result
+var sensitiveMsgProperties = []string{"text", "attachments"}
+
+// ExportSession marshals the given session to JSON for exporting, e.g. for support or analysis, with the sensitive data
+// described by its environment masked, including the messages received by waits which save sensitive results. Unlike sessions marshaled with json.Marshal, exported sessions can't be resumed.
+func ExportSession(s flows.Session) ([]byte, error) {
+	marshaled, err := jsonx.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive := s.Environment().SensitiveData()
+	if sensitive == nil {
+		return marshaled, nil
+	}
+
+	paths := make([]string, 0)
+
+	// the contact can appear in the session, and in the trigger and in the summary of its parent run if it has one, and
+	// its field values are objects with a property for each value type
+	for _, key := range sensitive.Fields {
+		for _, prefix := range []string{"contact", "trigger.contact", "trigger.run.contact"} {
+			paths = append(paths, prefix+".fields."+key+".*")
+		}
+	}
+
+	for _, prefix := range []string{"runs", "trigger.run"} {
+		for _, key := range sensitive.ResultKeys() {
+			for _, prop := range sensitiveResultProperties {
+				paths = append(paths, prefix+".results."+key+"."+prop)
+			}
+		}
+
+		// results of webhook calls have the response as their extra
+		for _, bodyPath := range sensitive.WebhookBodyPaths {
+			paths = append(paths, prefix+".results.*.extra."+bodyPath)
+		}
+	}
+
+	redacted := []byte(utils.NewJSONRedactor(flows.RedactionMask, paths...)(string(marshaled)))
+
+	return redactSensitiveInputs(s, redacted), nil
+}
+
+// messages received by waits which save sensitive results are masked in the events of their steps, and as the session
+// input if that's one of them, since their text becomes the input of the result
+func redactSensitiveInputs(s flows.Session, marshaled []byte) []byte {
+	sensitive := s.Environment().SensitiveData()
+	redactMsg := utils.NewJSONRedactor(flows.RedactionMask, sensitiveMsgProperties...)
+	redactedMsgs := make(map[flows.InputUUID]bool)
+
+	redactAt := func(data []byte, redact utils.Redactor, keys ...string) []byte {
+		value, _, _, err := jsonparser.Get(data, keys...)
+		if err != nil {
+			return data
+		}
+		if updated, err := jsonparser.Set(data, []byte(redact(string(value))), keys...); err == nil {
+			return updated
+		}
+		return data
+	}
+
+	for i, run := range s.Runs() {
+		sensitiveSteps := make(map[flows.StepUUID]bool)
+		for _, step := range run.Path() {
+			node := run.Flow().GetNode(step.NodeUUID())
+			if node != nil && node.Router() != nil && node.Router().Wait() != nil && sensitive.IsResult(node.Router().ResultName()) {
+				sensitiveSteps[step.UUID()] = true
+			}
+		}
+
+		for j, event := range run.Events() {
+			if received, isReceived := event.(*events.MsgReceivedEvent); isReceived && sensitiveSteps[event.StepUUID()] {
+				marshaled = redactAt(marshaled, redactMsg, "runs", fmt.Sprintf("[%d]", i), "events", fmt.Sprintf("[%d]", j), "msg")
+				redactedMsgs[flows.InputUUID(received.Msg.UUID())] = true
+			}
+		}
+	}
+
+	// msg inputs have the UUIDs of their messages
+	if s.Input() != nil && redactedMsgs[s.Input().UUID()] {
+		marshaled = redactAt(marshaled, redactMsg, "input")
+	}
+
+	return marshaled
+}
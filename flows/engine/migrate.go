@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+)
+
+// NodeMapping maps the UUIDs of nodes in one revision of a flow to the UUIDs of their equivalent nodes in another
+type NodeMapping map[flows.NodeUUID]flows.NodeUUID
+
+// InferNodeMapping infers a node mapping between two revisions of a flow. Nodes which exist in both revisions are
+// mapped to themselves. Remaining nodes in the old revision are mapped to nodes which only exist in the new revision
+// and are at the same position in the editor, i.e. they've replaced them. If either revision doesn't have editor
+// positions for all its nodes, remaining nodes are instead mapped to the node at the same index in the new revision.
+func InferNodeMapping(oldFlow, newFlow flows.Flow) NodeMapping {
+	mapping := make(NodeMapping, len(oldFlow.Nodes()))
+	used := make(map[flows.NodeUUID]bool, len(newFlow.Nodes()))
+
+	for _, node := range oldFlow.Nodes() {
+		if newFlow.GetNode(node.UUID()) != nil {
+			mapping[node.UUID()] = node.UUID()
+			used[node.UUID()] = true
+		}
+	}
+
+	oldPositions, newPositions := nodePositions(oldFlow), nodePositions(newFlow)
+	byPosition := oldPositions != nil && newPositions != nil
+
+	for i, node := range oldFlow.Nodes() {
+		if _, mapped := mapping[node.UUID()]; mapped {
+			continue
+		}
+
+		for j, candidate := range newFlow.Nodes() {
+			samePlace := j == i
+			if byPosition {
+				samePlace = newPositions[candidate.UUID()] == oldPositions[node.UUID()]
+			}
+
+			if samePlace && oldFlow.GetNode(candidate.UUID()) == nil && !used[candidate.UUID()] {
+				mapping[node.UUID()] = candidate.UUID()
+				used[candidate.UUID()] = true
+				break
+			}
+		}
+	}
+
+	return mapping
+}
+
+type nodePosition struct {
+	Left int `json:"left"`
+	Top  int `json:"top"`
+}
+
+// gets the editor positions of the nodes of the given flow, or nil if it doesn't have positions for all its nodes
+func nodePositions(flow flows.Flow) map[flows.NodeUUID]nodePosition {
+	if len(flow.UI()) == 0 {
+		return nil
+	}
+
+	ui := &struct {
+		Nodes map[flows.NodeUUID]struct {
+			Position *nodePosition `json:"position"`
+		} `json:"nodes"`
+	}{}
+	if err := json.Unmarshal(flow.UI(), ui); err != nil {
+		return nil
+	}
+
+	positions := make(map[flows.NodeUUID]nodePosition, len(flow.Nodes()))
+	for _, n := range flow.Nodes() {
+		position := ui.Nodes[n.UUID()].Position
+		if position == nil {
+			return nil
+		}
+		positions[n.UUID()] = *position
+	}
+	return positions
+}
+
+// MigrationReport is the result of migrating a set of sessions to a new revision of a flow
+type MigrationReport struct {
+	// Failed are the errors for the sessions which couldn't be migrated and were left unchanged
+	Failed map[flows.SessionUUID]error
+
+	// Unmapped are the path steps of migrated sessions which are on nodes that don't exist in the new revision
+	Unmapped map[flows.SessionUUID][]flows.Step
+}
+
+// MigrateSessions migrates the given waiting sessions to a new revision of a flow, and reports which sessions couldn't
+// be migrated, and which steps of the migrated sessions couldn't be mapped to the new revision.
+func MigrateSessions(sessions []flows.Session, oldFlow, newFlow flows.Flow, mapping NodeMapping) *MigrationReport {
+	report := &MigrationReport{
+		Failed:   make(map[flows.SessionUUID]error),
+		Unmapped: make(map[flows.SessionUUID][]flows.Step),
+	}
+
+	for _, s := range sessions {
+		unmapped, err := MigrateSession(s, oldFlow, newFlow, mapping)
+		if err != nil {
+			report.Failed[s.UUID()] = err
+		} else if len(unmapped) > 0 {
+			report.Unmapped[s.UUID()] = unmapped
+		}
+	}
+
+	return report
+}
+
+// MigrateSession migrates a waiting session to a new revision of a flow so that it can be resumed on that revision.
+// Nodes in the old revision are mapped using the given mapping, or to the node with the same UUID in the new revision
+// if they're not included in the mapping. Exits are mapped by UUID or by position. The session is only changed if
+// every active run in the flow can be mapped to a node in the new revision, and the node that the session is waiting
+// at has the same type of wait.
+//
+// Earlier steps in the paths of runs may be on nodes which have been removed in the new revision. These steps are left
+// pointing at those nodes, and are returned so that callers can tell which history doesn't match the new revision.
+func MigrateSession(fs flows.Session, oldFlow, newFlow flows.Flow, mapping NodeMapping) ([]flows.Step, error) {
+	s, isSession := fs.(*session)
+	if !isSession {
+		return nil, errors.Errorf("can't migrate session of type %T", fs)
+	}
+	if oldFlow.UUID() != newFlow.UUID() {
+		return nil, errors.Errorf("can't migrate from flow %s to a different flow %s", oldFlow.UUID(), newFlow.UUID())
+	}
+	if s.status != flows.SessionStatusWaiting {
+		return nil, errors.Errorf("can't migrate session with status '%s'", s.status)
+	}
+
+	nodes, exits := resolveMapping(oldFlow, newFlow, mapping)
+
+	// check that every run which can still be resumed is at a node which exists in the new revision
+	clearTimeout := false
+	for _, run := range s.runs {
+		if run.FlowReference().UUID != oldFlow.UUID() || (run.Status() != flows.RunStatusActive && run.Status() != flows.RunStatusWaiting) {
+			continue
+		}
+
+		path := run.Path()
+		if len(path) == 0 {
+			continue
+		}
+
+		current := path[len(path)-1].NodeUUID()
+		newUUID, mapped := nodes[current]
+		if !mapped {
+			return nil, errors.Errorf("node %s of run %s has no equivalent in the new revision", current, run.UUID())
+		}
+
+		if run.Status() == flows.RunStatusWaiting {
+			var err error
+			if clearTimeout, err = checkWait(s.wait, newFlow.GetNode(newUUID)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var unmapped []flows.Step
+
+	for _, run := range s.runs {
+		if run.FlowReference().UUID == oldFlow.UUID() {
+			unmapped = append(unmapped, run.Migrate(newFlow, nodes, exits)...)
+		}
+	}
+
+	if clearTimeout {
+		s.wait.ClearTimeout()
+	}
+	return unmapped, nil
+}
+
+// resolves the node and exit mappings to use between two revisions of a flow
+func resolveMapping(oldFlow, newFlow flows.Flow, mapping NodeMapping) (map[flows.NodeUUID]flows.NodeUUID, map[flows.ExitUUID]flows.ExitUUID) {
+	nodes := make(map[flows.NodeUUID]flows.NodeUUID, len(oldFlow.Nodes()))
+	exits := make(map[flows.ExitUUID]flows.ExitUUID)
+
+	for _, oldNode := range oldFlow.Nodes() {
+		newUUID, mapped := mapping[oldNode.UUID()]
+		if !mapped {
+			newUUID = oldNode.UUID()
+		}
+
+		newNode := newFlow.GetNode(newUUID)
+		if newNode == nil {
+			continue
+		}
+
+		nodes[oldNode.UUID()] = newNode.UUID()
+
+		newExits := make(map[flows.ExitUUID]bool, len(newNode.Exits()))
+		for _, e := range newNode.Exits() {
+			newExits[e.UUID()] = true
+		}
+
+		for i, e := range oldNode.Exits() {
+			if newExits[e.UUID()] {
+				exits[e.UUID()] = e.UUID()
+			} else if i < len(newNode.Exits()) {
+				exits[e.UUID()] = newNode.Exits()[i].UUID()
+			}
+		}
+	}
+
+	return nodes, exits
+}
+
+// checks that an activated wait can be migrated to the wait on the given node in the new revision, and returns whether
+// its timeout needs to be cleared because the new wait doesn't have one
+func checkWait(activated flows.ActivatedWait, node flows.Node) (bool, error) {
+	if node.Router() == nil || node.Router().Wait() == nil {
+		return false, errors.Errorf("node %s has no wait in the new revision", node.UUID())
+	}
+
+	wait := node.Router().Wait()
+	if activated == nil || wait.Type() != activated.Type() {
+		return false, errors.Errorf("can't migrate wait to node %s which has a wait of type '%s'", node.UUID(), wait.Type())
+	}
+
+	return activated.TimeoutSeconds() != nil && !node.Router().AllowTimeout(), nil
+}
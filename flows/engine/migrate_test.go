@@ -0,0 +1,155 @@
+package engine_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/definition"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateSession(t *testing.T) {
+	testFile, err := os.ReadFile("testdata/migrate.json")
+	require.NoError(t, err)
+
+	revisions := make(map[string]json.RawMessage)
+	jsonx.MustUnmarshal(testFile, &revisions)
+
+	assetsFor := func(rev string) []byte {
+		return []byte(fmt.Sprintf(`{"flows": [%s]}`, string(revisions[rev])))
+	}
+	readFlow := func(rev string) flows.Flow {
+		flow, err := definition.ReadFlow(revisions[rev], nil)
+		require.NoError(t, err)
+		return flow
+	}
+	startSessionOn := func(rev string) flows.Session {
+		session, _ := test.NewSessionBuilder().WithAssets(assetsFor(rev)).WithFlow("5d8e0f56-1c1c-4b8a-a6a6-2a8f3c2d6e11").MustBuild()
+		require.Equal(t, flows.SessionStatusWaiting, session.Status())
+		return session
+	}
+	startSession := func() flows.Session { return startSessionOn("original") }
+	lastMsg := func(sprint flows.Sprint) string {
+		evts := sprint.Events()
+		return evts[len(evts)-1].(*events.MsgCreatedEvent).Msg.Text()
+	}
+
+	original := readFlow("original")
+
+	// without migrating, resuming on a revision where the waiting node has a new UUID fails
+	session, sprint, err := test.ResumeSession(startSession(), assetsFor("rewired"), "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusFailed, session.Status())
+	assert.Equal(t, "failure", sprint.Events()[0].Type())
+
+	// inferred mapping matches rewired node by its index as the new revision doesn't have editor positions
+	rewired := readFlow("rewired")
+	mapping := engine.InferNodeMapping(original, rewired)
+	assert.Equal(t, engine.NodeMapping{
+		"3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01": "c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e03",
+		"8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02": "8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02",
+	}, mapping)
+
+	session = startSession()
+	waitingStep := session.Runs()[0].Path()[0]
+	unmapped, err := engine.MigrateSession(session, original, rewired, mapping)
+	require.NoError(t, err)
+	assert.Len(t, unmapped, 0)
+
+	assert.Equal(t, flows.NodeUUID("c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e03"), session.Runs()[0].Path()[0].NodeUUID())
+	assert.Equal(t, waitingStep.UUID(), session.Runs()[0].Path()[0].UUID())
+	assert.Equal(t, 600, *session.Wait().TimeoutSeconds())
+
+	session, sprint, err = test.ResumeSession(session, assetsFor("rewired"), "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "Thanks Bob, welcome!", lastMsg(sprint))
+
+	// without editor positions, inferred mapping picks the wrong node if a node has moved, but it can be mapped explicitly
+	moved := readFlow("moved")
+	assert.Equal(t, engine.NodeMapping{
+		"3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01": "9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c04",
+		"8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02": "8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02",
+	}, engine.InferNodeMapping(original, moved))
+
+	session = startSession()
+	_, err = engine.MigrateSession(session, original, moved, engine.InferNodeMapping(original, moved))
+	assert.EqualError(t, err, "node 9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c04 has no wait in the new revision")
+
+	_, err = engine.MigrateSession(session, original, moved, engine.NodeMapping{"3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01": "c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e03"})
+	require.NoError(t, err)
+
+	session, sprint, err = test.ResumeSession(session, assetsFor("moved"), "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, "Thanks Bob, you moved!", lastMsg(sprint))
+
+	// with editor positions, inferred mapping matches the replacement node at the same position, whatever its index
+	reordered := readFlow("reordered")
+	assert.Equal(t, engine.NodeMapping{
+		"3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01": "c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e03",
+		"8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02": "8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02",
+	}, engine.InferNodeMapping(original, reordered))
+
+	session = startSession()
+	_, err = engine.MigrateSession(session, original, reordered, engine.InferNodeMapping(original, reordered))
+	require.NoError(t, err)
+
+	session, sprint, err = test.ResumeSession(session, assetsFor("reordered"), "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "Thanks Bob, you were reordered!", lastMsg(sprint))
+
+	// and nodes with no replacement at their position aren't mapped
+	assert.Equal(t, engine.NodeMapping{
+		"c2d3e4f5-a6b7-4c8d-9e0f-1a2b3c4d5e03": "3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01",
+		"8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02": "8e7f6a5b-4c3d-4e2f-9a1b-0c9d8e7f6a02",
+	}, engine.InferNodeMapping(reordered, original))
+
+	completed := session
+
+	// if new wait has no timeout, timeout is removed from the activated wait
+	session = startSession()
+	_, err = engine.MigrateSession(session, original, readFlow("no_timeout"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, session.Wait().TimeoutSeconds())
+	assert.Equal(t, "msg", session.Wait().Type())
+
+	// earlier steps on nodes which have been removed are left as they are and reported
+	session = startSessionOn("greeting")
+	greetingStep := session.Runs()[0].Path()[0]
+
+	report := engine.MigrateSessions([]flows.Session{session}, readFlow("greeting"), original, nil)
+	assert.Equal(t, 0, len(report.Failed))
+	assert.Equal(t, map[flows.SessionUUID][]flows.Step{session.UUID(): {greetingStep}}, report.Unmapped)
+	assert.Equal(t, flows.NodeUUID("4f6e5d4c-3b2a-4190-8f7e-6d5c4b3a2f05"), session.Runs()[0].Path()[0].NodeUUID())
+
+	session, sprint, err = test.ResumeSession(session, assetsFor("original"), "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	// other sessions can't be migrated, and are reported as failures and left unchanged
+	sessions := []flows.Session{startSession(), startSession()}
+
+	report = engine.MigrateSessions(sessions, original, readFlow("no_wait"), nil)
+	assert.Equal(t, 2, len(report.Failed))
+	assert.EqualError(t, report.Failed[sessions[0].UUID()], "node 3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01 has no wait in the new revision")
+
+	report = engine.MigrateSessions(sessions, original, readFlow("removed"), nil)
+	assert.Equal(t, 2, len(report.Failed))
+	assert.EqualError(t, report.Failed[sessions[1].UUID()], fmt.Sprintf("node 3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01 of run %s has no equivalent in the new revision", sessions[1].Runs()[0].UUID()))
+	assert.Equal(t, 1, sessions[0].Runs()[0].Flow().Revision())
+	assert.Equal(t, flows.NodeUUID("3a9d7c1e-5b2f-4e8a-9d0c-1f2e3d4c5b01"), sessions[0].Runs()[0].Path()[0].NodeUUID())
+
+	// sessions which aren't waiting can't be migrated
+	_, err = engine.MigrateSession(completed, original, rewired, nil)
+	assert.EqualError(t, err, "can't migrate session with status 'completed'")
+}
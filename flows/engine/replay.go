@@ -0,0 +1,764 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// names of services in recorded calls
+const (
+	serviceEmail           = "email"
+	serviceWebhook         = "webhook"
+	serviceClassification  = "classification"
+	serviceTicket          = "ticket"
+	serviceAirtime         = "airtime"
+	serviceExternalService = "external_service"
+	serviceMsgCatalog      = "msg_catalog"
+)
+
+// ServiceCall is a recorded call to a service. Calls without a request are recorded failures to create the service.
+type ServiceCall struct {
+	Service  string           `json:"service"`
+	Request  json.RawMessage  `json:"request,omitempty"`
+	Response json.RawMessage  `json:"response,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	HTTPLogs []*flows.HTTPLog `json:"http_logs,omitempty"`
+}
+
+// ServiceBundle is a portable recording of all the service calls made by a session, which can be replayed to
+// re-execute that session without calling any real services
+type ServiceBundle struct {
+	Calls []*ServiceCall `json:"calls"`
+}
+
+// ReadServiceBundle reads a service bundle from the given JSON
+func ReadServiceBundle(data []byte) (*ServiceBundle, error) {
+	b := &ServiceBundle{}
+	if err := jsonx.Unmarshal(data, b); err != nil {
+		return nil, errors.Wrap(err, "unable to read service bundle")
+	}
+	return b, nil
+}
+
+//------------------------------------------------------------------------------------------
+// Recording
+//------------------------------------------------------------------------------------------
+
+// ServiceRecorder records the requests and responses of all service calls made by an engine
+type ServiceRecorder struct {
+	calls []*ServiceCall
+	mutex sync.Mutex
+}
+
+// NewServiceRecorder creates a new service recorder
+func NewServiceRecorder() *ServiceRecorder {
+	return &ServiceRecorder{calls: make([]*ServiceCall, 0, 10)}
+}
+
+// Bundle returns a bundle of the calls recorded so far
+func (r *ServiceRecorder) Bundle() *ServiceBundle {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	calls := make([]*ServiceCall, len(r.calls))
+	copy(calls, r.calls)
+	return &ServiceBundle{Calls: calls}
+}
+
+func (r *ServiceRecorder) record(service string, request, response interface{}, err error, logs []*flows.HTTPLog) {
+	call := &ServiceCall{Service: service, HTTPLogs: logs}
+	if request != nil {
+		call.Request = jsonx.MustMarshal(request)
+	}
+	if response != nil && !reflect.ValueOf(response).IsNil() {
+		call.Response = jsonx.MustMarshal(response)
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+// wraps the given service factories so that the services they create are recorded
+func (r *ServiceRecorder) wrap(s *services) *services {
+	return &services{
+		email: func(session flows.Session) (flows.EmailService, error) {
+			svc, err := s.email(session)
+			if err != nil {
+				r.record(serviceEmail, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingEmailService{r, svc}, nil
+		},
+		webhook: func(session flows.Session) (flows.WebhookService, error) {
+			svc, err := s.webhook(session)
+			if err != nil {
+				r.record(serviceWebhook, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingWebhookService{r, svc}, nil
+		},
+		classification: func(session flows.Session, classifier *flows.Classifier) (flows.ClassificationService, error) {
+			svc, err := s.classification(session, classifier)
+			if err != nil {
+				r.record(serviceClassification, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingClassificationService{r, svc, classifier}, nil
+		},
+		ticket: func(session flows.Session, ticketer *flows.Ticketer) (flows.TicketService, error) {
+			svc, err := s.ticket(session, ticketer)
+			if err != nil {
+				r.record(serviceTicket, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingTicketService{r, svc, ticketer}, nil
+		},
+		airtime: func(session flows.Session) (flows.AirtimeService, error) {
+			svc, err := s.airtime(session)
+			if err != nil {
+				r.record(serviceAirtime, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingAirtimeService{r, svc}, nil
+		},
+		externalService: func(session flows.Session, externalService *flows.ExternalService) (flows.ExternalServiceService, error) {
+			svc, err := s.externalService(session, externalService)
+			if err != nil {
+				r.record(serviceExternalService, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingExternalServiceService{r, svc, externalService}, nil
+		},
+		msgCatalog: func(session flows.Session, msgCatalog *flows.MsgCatalog) (flows.MsgCatalogService, error) {
+			svc, err := s.msgCatalog(session, msgCatalog)
+			if err != nil {
+				r.record(serviceMsgCatalog, nil, nil, err, nil)
+				return nil, err
+			}
+			return &recordingMsgCatalogService{r, svc, msgCatalog}, nil
+		},
+	}
+}
+
+// returns a callback which captures HTTP logs as well as passing them on
+func captureHTTPLogs(logHTTP flows.HTTPLogCallback) (*flows.HTTPLogger, flows.HTTPLogCallback) {
+	logger := &flows.HTTPLogger{}
+	return logger, func(l *flows.HTTPLog) {
+		logger.Log(l)
+		logHTTP(l)
+	}
+}
+
+type recordingEmailService struct {
+	recorder *ServiceRecorder
+	service  flows.EmailService
+}
+
+func (s *recordingEmailService) Send(session flows.Session, addresses []string, subject, body string) error {
+	err := s.service.Send(session, addresses, subject, body)
+	s.recorder.record(serviceEmail, &emailRequest{addresses, subject, body}, nil, err, nil)
+	return err
+}
+
+type recordingWebhookService struct {
+	recorder *ServiceRecorder
+	service  flows.WebhookService
+}
+
+func (s *recordingWebhookService) Call(session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	recorded, err := newWebhookRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := s.service.Call(session, request)
+	s.recorder.record(serviceWebhook, recorded, newWebhookResponse(call), err, nil)
+	return call, err
+}
+
+type recordingClassificationService struct {
+	recorder   *ServiceRecorder
+	service    flows.ClassificationService
+	classifier *flows.Classifier
+}
+
+func (s *recordingClassificationService) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	logger, logHTTP := captureHTTPLogs(logHTTP)
+	classification, err := s.service.Classify(session, input, logHTTP)
+	s.recorder.record(serviceClassification, &classificationRequest{s.classifier.Reference(), input}, classification, err, logger.Logs)
+	return classification, err
+}
+
+type recordingTicketService struct {
+	recorder *ServiceRecorder
+	service  flows.TicketService
+	ticketer *flows.Ticketer
+}
+
+func (s *recordingTicketService) Open(session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (*flows.Ticket, error) {
+	logger, logHTTP := captureHTTPLogs(logHTTP)
+	ticket, err := s.service.Open(session, topic, body, assignee, logHTTP)
+	s.recorder.record(serviceTicket, &ticketRequest{s.ticketer.Reference(), topic.Reference(), body, assignee.Reference()}, ticket, err, logger.Logs)
+	return ticket, err
+}
+
+type recordingAirtimeService struct {
+	recorder *ServiceRecorder
+	service  flows.AirtimeService
+}
+
+func (s *recordingAirtimeService) Transfer(session flows.Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP flows.HTTPLogCallback) (*flows.AirtimeTransfer, error) {
+	logger, logHTTP := captureHTTPLogs(logHTTP)
+	transfer, err := s.service.Transfer(session, sender, recipient, amounts, logHTTP)
+	s.recorder.record(serviceAirtime, &airtimeRequest{sender, recipient, amounts}, transfer, err, logger.Logs)
+	return transfer, err
+}
+
+type recordingExternalServiceService struct {
+	recorder        *ServiceRecorder
+	service         flows.ExternalServiceService
+	externalService *flows.ExternalService
+}
+
+func (s *recordingExternalServiceService) Call(session flows.Session, callAction assets.ExternalServiceCallAction, params []assets.ExternalServiceParam, logHTTP flows.HTTPLogCallback) (*flows.ExternalServiceCall, error) {
+	logger, logHTTP := captureHTTPLogs(logHTTP)
+	call, err := s.service.Call(session, callAction, params, logHTTP)
+	s.recorder.record(serviceExternalService, &externalServiceRequest{s.externalService.Reference(), callAction, params}, call, err, logger.Logs)
+	return call, err
+}
+
+type recordingMsgCatalogService struct {
+	recorder   *ServiceRecorder
+	service    flows.MsgCatalogService
+	msgCatalog *flows.MsgCatalog
+}
+
+func (s *recordingMsgCatalogService) Call(session flows.Session, params assets.MsgCatalogParam, logHTTP flows.HTTPLogCallback) (*flows.MsgCatalogCall, error) {
+	logger, logHTTP := captureHTTPLogs(logHTTP)
+	call, err := s.service.Call(session, params, logHTTP)
+	s.recorder.record(serviceMsgCatalog, &msgCatalogRequest{s.msgCatalog.Reference(), params}, newMsgCatalogResponse(call), err, logger.Logs)
+	return call, err
+}
+
+//------------------------------------------------------------------------------------------
+// Replaying
+//------------------------------------------------------------------------------------------
+
+// ServiceReplayer provides services which return the responses in a service bundle instead of calling real
+// services. Calls are matched to recorded calls to the same service in order, and any differences between the
+// requests made and the requests recorded are reported as warnings, as these mean the session has diverged from
+// the recorded session, e.g. because the flow has changed.
+type ServiceReplayer struct {
+	calls    []*ServiceCall
+	used     []bool
+	warnings []string
+	mutex    sync.Mutex
+}
+
+// NewServiceReplayer creates a new service replayer for the given bundle
+func NewServiceReplayer(bundle *ServiceBundle) *ServiceReplayer {
+	return &ServiceReplayer{calls: bundle.Calls, used: make([]bool, len(bundle.Calls))}
+}
+
+// Warnings returns warnings about divergence from the recorded session, including recorded calls which weren't replayed
+func (r *ServiceReplayer) Warnings() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	warnings := make([]string, len(r.warnings), len(r.warnings)+len(r.calls))
+	copy(warnings, r.warnings)
+
+	for i, call := range r.calls {
+		if !r.used[i] {
+			warnings = append(warnings, fmt.Sprintf("recorded call #%d to %s service was never replayed", i+1, call.Service))
+		}
+	}
+	return warnings
+}
+
+func (r *ServiceReplayer) warnf(format string, a ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, a...))
+}
+
+// finds the next unused call for the given service, returning its index or -1
+func (r *ServiceReplayer) nextCall(service string) int {
+	for i, call := range r.calls {
+		if !r.used[i] && call.Service == service {
+			return i
+		}
+	}
+	return -1
+}
+
+// replays a service factory, returning the recorded error if creating the service failed when it was recorded
+func (r *ServiceReplayer) replayFactory(service string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if i := r.nextCall(service); i >= 0 && r.calls[i].Request == nil {
+		r.used[i] = true
+		return errors.New(r.calls[i].Error)
+	}
+	return nil
+}
+
+// replays a call to the given service, returning the recorded call
+func (r *ServiceReplayer) replay(service string, request interface{}, logHTTP flows.HTTPLogCallback) (*ServiceCall, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i := r.nextCall(service)
+	if i < 0 || r.calls[i].Request == nil {
+		r.warnf("%s service was called but there are no more recorded calls to it", service)
+		return nil, errors.Errorf("no recorded call to %s service to replay", service)
+	}
+
+	// calls to other services should have been made first
+	for j := 0; j < i; j++ {
+		if !r.used[j] {
+			r.warnf("recorded call #%d to %s service was replayed before recorded call #%d to %s service", i+1, service, j+1, r.calls[j].Service)
+			break
+		}
+	}
+
+	r.used[i] = true
+	call := r.calls[i]
+
+	if !jsonEqual(jsonx.MustMarshal(request), call.Request) {
+		r.warnf("request for call #%d to %s service differs from the recorded request", i+1, service)
+	}
+
+	if logHTTP != nil {
+		for _, l := range call.HTTPLogs {
+			logHTTP(l)
+		}
+	}
+
+	return call, nil
+}
+
+// gets the error of a recorded call
+func (c *ServiceCall) err() error {
+	if c.Error != "" {
+		return errors.New(c.Error)
+	}
+	return nil
+}
+
+// unmarshals the response of a recorded call into the given value
+func (c *ServiceCall) unmarshalResponse(v interface{}) error {
+	return errors.Wrapf(jsonx.Unmarshal(c.Response, v), "unable to read recorded %s response", c.Service)
+}
+
+// returns replaying services
+func (r *ServiceReplayer) services() *services {
+	return &services{
+		email: func(flows.Session) (flows.EmailService, error) {
+			if err := r.replayFactory(serviceEmail); err != nil {
+				return nil, err
+			}
+			return &replayingEmailService{r}, nil
+		},
+		webhook: func(flows.Session) (flows.WebhookService, error) {
+			if err := r.replayFactory(serviceWebhook); err != nil {
+				return nil, err
+			}
+			return &replayingWebhookService{r}, nil
+		},
+		classification: func(session flows.Session, classifier *flows.Classifier) (flows.ClassificationService, error) {
+			if err := r.replayFactory(serviceClassification); err != nil {
+				return nil, err
+			}
+			return &replayingClassificationService{r, classifier}, nil
+		},
+		ticket: func(session flows.Session, ticketer *flows.Ticketer) (flows.TicketService, error) {
+			if err := r.replayFactory(serviceTicket); err != nil {
+				return nil, err
+			}
+			return &replayingTicketService{r, ticketer}, nil
+		},
+		airtime: func(flows.Session) (flows.AirtimeService, error) {
+			if err := r.replayFactory(serviceAirtime); err != nil {
+				return nil, err
+			}
+			return &replayingAirtimeService{r}, nil
+		},
+		externalService: func(session flows.Session, externalService *flows.ExternalService) (flows.ExternalServiceService, error) {
+			if err := r.replayFactory(serviceExternalService); err != nil {
+				return nil, err
+			}
+			return &replayingExternalServiceService{r, externalService}, nil
+		},
+		msgCatalog: func(session flows.Session, msgCatalog *flows.MsgCatalog) (flows.MsgCatalogService, error) {
+			if err := r.replayFactory(serviceMsgCatalog); err != nil {
+				return nil, err
+			}
+			return &replayingMsgCatalogService{r, msgCatalog}, nil
+		},
+	}
+}
+
+type replayingEmailService struct {
+	replayer *ServiceReplayer
+}
+
+func (s *replayingEmailService) Send(session flows.Session, addresses []string, subject, body string) error {
+	call, err := s.replayer.replay(serviceEmail, &emailRequest{addresses, subject, body}, nil)
+	if err != nil {
+		return err
+	}
+	return call.err()
+}
+
+type replayingWebhookService struct {
+	replayer *ServiceReplayer
+}
+
+func (s *replayingWebhookService) Call(session flows.Session, request *http.Request) (*flows.WebhookCall, error) {
+	recorded, err := newWebhookRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := s.replayer.replay(serviceWebhook, recorded, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	response := &webhookResponse{}
+	if err := call.unmarshalResponse(response); err != nil {
+		return nil, err
+	}
+
+	webhookCall, err := response.toCall(request)
+	if err != nil {
+		return nil, err
+	}
+	return webhookCall, call.err()
+}
+
+type replayingClassificationService struct {
+	replayer   *ServiceReplayer
+	classifier *flows.Classifier
+}
+
+func (s *replayingClassificationService) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	call, err := s.replayer.replay(serviceClassification, &classificationRequest{s.classifier.Reference(), input}, logHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	classification := &flows.Classification{}
+	if err := call.unmarshalResponse(classification); err != nil {
+		return nil, err
+	}
+	return classification, call.err()
+}
+
+type replayingTicketService struct {
+	replayer *ServiceReplayer
+	ticketer *flows.Ticketer
+}
+
+func (s *replayingTicketService) Open(session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (*flows.Ticket, error) {
+	call, err := s.replayer.replay(serviceTicket, &ticketRequest{s.ticketer.Reference(), topic.Reference(), body, assignee.Reference()}, logHTTP)
+	if err != nil {
+		return nil, err
+	}
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	// the ticket, including the UUID the ticketing service gave it, comes from the recording
+	ticket, err := flows.ReadTicket(session.Assets(), call.Response, assets.IgnoreMissing)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read recorded ticket response")
+	}
+	return ticket, call.err()
+}
+
+type replayingAirtimeService struct {
+	replayer *ServiceReplayer
+}
+
+func (s *replayingAirtimeService) Transfer(session flows.Session, sender urns.URN, recipient urns.URN, amounts map[string]decimal.Decimal, logHTTP flows.HTTPLogCallback) (*flows.AirtimeTransfer, error) {
+	call, err := s.replayer.replay(serviceAirtime, &airtimeRequest{sender, recipient, amounts}, logHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	transfer := &flows.AirtimeTransfer{}
+	if err := call.unmarshalResponse(transfer); err != nil {
+		return nil, err
+	}
+	return transfer, call.err()
+}
+
+type replayingExternalServiceService struct {
+	replayer        *ServiceReplayer
+	externalService *flows.ExternalService
+}
+
+func (s *replayingExternalServiceService) Call(session flows.Session, callAction assets.ExternalServiceCallAction, params []assets.ExternalServiceParam, logHTTP flows.HTTPLogCallback) (*flows.ExternalServiceCall, error) {
+	call, err := s.replayer.replay(serviceExternalService, &externalServiceRequest{s.externalService.Reference(), callAction, params}, logHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	serviceCall := &flows.ExternalServiceCall{}
+	if err := call.unmarshalResponse(serviceCall); err != nil {
+		return nil, err
+	}
+	return serviceCall, call.err()
+}
+
+type replayingMsgCatalogService struct {
+	replayer   *ServiceReplayer
+	msgCatalog *flows.MsgCatalog
+}
+
+func (s *replayingMsgCatalogService) Call(session flows.Session, params assets.MsgCatalogParam, logHTTP flows.HTTPLogCallback) (*flows.MsgCatalogCall, error) {
+	call, err := s.replayer.replay(serviceMsgCatalog, &msgCatalogRequest{s.msgCatalog.Reference(), params}, logHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(call.Response) == 0 {
+		return nil, call.err()
+	}
+
+	response := &msgCatalogResponse{}
+	if err := call.unmarshalResponse(response); err != nil {
+		return nil, err
+	}
+
+	catalogCall, err := response.toCall()
+	if err != nil {
+		return nil, err
+	}
+	return catalogCall, call.err()
+}
+
+//------------------------------------------------------------------------------------------
+// Recorded requests and responses
+//------------------------------------------------------------------------------------------
+
+type emailRequest struct {
+	Addresses []string `json:"addresses"`
+	Subject   string   `json:"subject"`
+	Body      string   `json:"body"`
+}
+
+type webhookRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// creates a recorded webhook request, restoring the body of the request after reading it
+func newWebhookRequest(request *http.Request) (*webhookRequest, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(request.Body); err != nil {
+			return nil, errors.Wrap(err, "unable to read webhook request body")
+		}
+		request.Body.Close()
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return &webhookRequest{Method: request.Method, URL: request.URL.String(), Body: string(body)}, nil
+}
+
+type classificationRequest struct {
+	Classifier *assets.ClassifierReference `json:"classifier"`
+	Input      string                      `json:"input"`
+}
+
+type ticketRequest struct {
+	Ticketer *assets.TicketerReference `json:"ticketer"`
+	Topic    *assets.TopicReference    `json:"topic,omitempty"`
+	Body     string                    `json:"body"`
+	Assignee *assets.UserReference     `json:"assignee,omitempty"`
+}
+
+type airtimeRequest struct {
+	Sender    urns.URN                   `json:"sender"`
+	Recipient urns.URN                   `json:"recipient"`
+	Amounts   map[string]decimal.Decimal `json:"amounts"`
+}
+
+type externalServiceRequest struct {
+	ExternalService *assets.ExternalServiceReference `json:"external_service"`
+	CallAction      assets.ExternalServiceCallAction `json:"call_action"`
+	Params          []assets.ExternalServiceParam    `json:"params"`
+}
+
+type msgCatalogRequest struct {
+	MsgCatalog *assets.MsgCatalogReference `json:"msg_catalog"`
+	Params     assets.MsgCatalogParam      `json:"params"`
+}
+
+// a recorded HTTP trace
+type recordedTrace struct {
+	Method        string    `json:"method"`
+	URL           string    `json:"url"`
+	RequestTrace  []byte    `json:"request_trace"`
+	ResponseTrace []byte    `json:"response_trace,omitempty"`
+	ResponseBody  []byte    `json:"response_body,omitempty"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Retries       int       `json:"retries"`
+}
+
+func newRecordedTrace(t *httpx.Trace) *recordedTrace {
+	if t == nil {
+		return nil
+	}
+	return &recordedTrace{
+		Method:        t.Request.Method,
+		URL:           t.Request.URL.String(),
+		RequestTrace:  t.RequestTrace,
+		ResponseTrace: t.ResponseTrace,
+		ResponseBody:  t.ResponseBody,
+		StartTime:     t.StartTime,
+		EndTime:       t.EndTime,
+		Retries:       t.Retries,
+	}
+}
+
+// converts this recorded trace back to a trace, using the given request if it's provided
+func (t *recordedTrace) toTrace(request *http.Request) (*httpx.Trace, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	var err error
+	if request == nil {
+		if request, err = http.NewRequest(t.Method, t.URL, nil); err != nil {
+			return nil, errors.Wrap(err, "unable to recreate recorded request")
+		}
+	}
+
+	trace := &httpx.Trace{
+		Request:       request,
+		RequestTrace:  t.RequestTrace,
+		ResponseTrace: t.ResponseTrace,
+		ResponseBody:  t.ResponseBody,
+		StartTime:     t.StartTime,
+		EndTime:       t.EndTime,
+		Retries:       t.Retries,
+	}
+
+	if len(t.ResponseTrace) > 0 {
+		if trace.Response, err = http.ReadResponse(bufio.NewReader(bytes.NewReader(t.ResponseTrace)), request); err != nil {
+			return nil, errors.Wrap(err, "unable to recreate recorded response")
+		}
+		trace.Response.Body = io.NopCloser(bytes.NewReader(t.ResponseBody))
+	}
+
+	return trace, nil
+}
+
+type webhookResponse struct {
+	Trace           *recordedTrace `json:"trace"`
+	ResponseJSON    []byte         `json:"response_json,omitempty"`
+	ResponseCleaned bool           `json:"response_cleaned,omitempty"`
+}
+
+func newWebhookResponse(c *flows.WebhookCall) *webhookResponse {
+	if c == nil {
+		return nil
+	}
+	return &webhookResponse{Trace: newRecordedTrace(c.Trace), ResponseJSON: c.ResponseJSON, ResponseCleaned: c.ResponseCleaned}
+}
+
+func (r *webhookResponse) toCall(request *http.Request) (*flows.WebhookCall, error) {
+	trace, err := r.Trace.toTrace(request)
+	if err != nil {
+		return nil, err
+	}
+	return &flows.WebhookCall{Trace: trace, ResponseJSON: r.ResponseJSON, ResponseCleaned: r.ResponseCleaned}, nil
+}
+
+type msgCatalogResponse struct {
+	ResponseJSON       []byte         `json:"response_json,omitempty"`
+	ProductRetailerIDs []string       `json:"product_retailer_ids,omitempty"`
+	TraceWeniGPT       *recordedTrace `json:"trace_wenigpt,omitempty"`
+	TraceSentenx       *recordedTrace `json:"trace_sentenx,omitempty"`
+}
+
+func newMsgCatalogResponse(c *flows.MsgCatalogCall) *msgCatalogResponse {
+	if c == nil {
+		return nil
+	}
+	return &msgCatalogResponse{
+		ResponseJSON:       c.ResponseJSON,
+		ProductRetailerIDs: c.ProductRetailerIDS,
+		TraceWeniGPT:       newRecordedTrace(c.TraceWeniGPT),
+		TraceSentenx:       newRecordedTrace(c.TraceSentenx),
+	}
+}
+
+func (r *msgCatalogResponse) toCall() (*flows.MsgCatalogCall, error) {
+	traceWeniGPT, err := r.TraceWeniGPT.toTrace(nil)
+	if err != nil {
+		return nil, err
+	}
+	traceSentenx, err := r.TraceSentenx.toTrace(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &flows.MsgCatalogCall{
+		ResponseJSON:       r.ResponseJSON,
+		ProductRetailerIDS: r.ProductRetailerIDs,
+		TraceWeniGPT:       traceWeniGPT,
+		TraceSentenx:       traceSentenx,
+	}, nil
+}
+
+// checks whether two JSON documents are equivalent, ignoring formatting
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
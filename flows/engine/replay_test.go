@@ -0,0 +1,159 @@
+package engine_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplayServices(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	assetsFile, err := os.ReadFile("testdata/replay.json")
+	require.NoError(t, err)
+
+	assetsJSON := string(assetsFile)
+
+	// runs a session with the given assets on the given engine builder
+	runSession := func(assetsJSON string, builder *engine.Builder) ([]byte, []flows.Event) {
+		source, err := static.NewSource([]byte(assetsJSON))
+		require.NoError(t, err)
+
+		sa, err := engine.NewSessionAssets(envs.NewBuilder().Build(), source, nil)
+		require.NoError(t, err)
+
+		eng := builder.
+			WithClock(dates.NewSequentialNowSource(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC))).
+			WithUUIDGenerator(uuids.NewSeededGenerator(1234)).
+			Build()
+
+		trigger, err := triggers.ReadTrigger(sa, []byte(`{
+			"type": "manual",
+			"flow": {"uuid": "2d7d3f4e-8c1b-4a9e-b5d6-7f0e1c2a3b41", "name": "Support"},
+			"contact": {"uuid": "5d76d86b-3bb9-4d5a-b822-c9d86f5d8e4f", "name": "Ryan Lewis", "status": "active", "created_on": "2018-06-20T11:40:30.123456789Z"},
+			"triggered_on": "2018-10-18T14:20:30.000000Z"
+		}`), assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		session, sprint1, err := eng.NewSession(sa, trigger)
+		require.NoError(t, err)
+
+		resume, err := resumes.ReadResume(sa, []byte(`{
+			"type": "msg",
+			"msg": {"uuid": "9bf91c2b-ce58-4cef-aacc-281e03f69ab5", "urn": "tel:+12065551212", "text": "Where is my order?"},
+			"resumed_on": "2018-10-18T14:21:30.000000Z"
+		}`), assets.PanicOnMissing)
+		require.NoError(t, err)
+
+		sprint2, err := session.Resume(resume)
+		require.NoError(t, err)
+
+		return jsonx.MustMarshal(session), append(sprint1.Events(), sprint2.Events()...)
+	}
+
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"http://example.com/orders?q=Where%20is%20my%20order%3F": {
+			httpx.NewMockResponse(200, nil, `{"status": "shipped"}`),
+		},
+	}))
+
+	// record a session that calls webhook, classification, ticket and email services
+	recorder := engine.NewServiceRecorder()
+	recordedSession, recordedEvents := runSession(assetsJSON, test.NewEngineBuilder().WithServiceRecorder(recorder))
+
+	bundleJSON := jsonx.MustMarshal(recorder.Bundle())
+	bundle, err := engine.ReadServiceBundle(bundleJSON)
+	require.NoError(t, err)
+
+	services := make([]string, len(bundle.Calls))
+	for i, call := range bundle.Calls {
+		services[i] = call.Service
+	}
+	assert.Equal(t, []string{"webhook", "classification", "ticket", "email"}, services)
+	assert.Equal(t, 1, len(bundle.Calls[1].HTTPLogs))
+
+	// replay it with no real services and no HTTP mocks, so any real HTTP request will panic
+	httpx.SetRequestor(httpx.NewMockRequestor(nil))
+
+	replayer := engine.NewServiceReplayer(bundle)
+	replayedSession, replayedEvents := runSession(assetsJSON, engine.NewBuilder().WithServiceReplayer(replayer))
+
+	assert.Equal(t, string(recordedSession), string(replayedSession))
+	assert.Equal(t, string(jsonx.MustMarshal(recordedEvents)), string(jsonx.MustMarshal(replayedEvents)))
+	assert.Equal(t, []string{}, replayer.Warnings())
+
+	lastEvent := replayedEvents[len(replayedEvents)-1].(*events.MsgCreatedEvent)
+	assert.Equal(t, "Your order is shipped", lastEvent.Msg.Text())
+
+	// replay it against a changed flow where the webhook URL has changed and the email action has been removed
+	changedAssetsJSON := strings.Replace(assetsJSON, "http://example.com/orders", "http://example.com/v2/orders", 1)
+	changedAssetsJSON = strings.Replace(changedAssetsJSON, `"type": "send_email"`, `"type": "add_input_labels", "labels": []`, 1)
+
+	replayer = engine.NewServiceReplayer(bundle)
+	_, replayedEvents = runSession(changedAssetsJSON, engine.NewBuilder().WithServiceReplayer(replayer))
+
+	assert.Equal(t, []string{
+		"request for call #1 to webhook service differs from the recorded request",
+		"recorded call #4 to email service was never replayed",
+	}, replayer.Warnings())
+
+	// responses were still replayed
+	lastEvent = replayedEvents[len(replayedEvents)-1].(*events.MsgCreatedEvent)
+	assert.Equal(t, "Your order is shipped", lastEvent.Msg.Text())
+
+	// replay it with a bundle that's missing calls, which also changes the ticket body as that uses the webhook result
+	replayer = engine.NewServiceReplayer(&engine.ServiceBundle{Calls: bundle.Calls[2:]})
+	_, replayedEvents = runSession(assetsJSON, engine.NewBuilder().WithServiceReplayer(replayer))
+
+	assert.Equal(t, []string{
+		"webhook service was called but there are no more recorded calls to it",
+		"classification service was called but there are no more recorded calls to it",
+		"request for call #1 to ticket service differs from the recorded request",
+	}, replayer.Warnings())
+
+	assert.Contains(t, errorTexts(replayedEvents), "no recorded call to classification service to replay")
+
+	// recorded failures to create services are replayed
+	recorder = engine.NewServiceRecorder()
+	_, recordedEvents = runSession(assetsJSON, engine.NewBuilder().WithServiceRecorder(recorder))
+
+	bundle = recorder.Bundle()
+	assert.Equal(t, 4, len(bundle.Calls))
+	assert.Equal(t, "no webhook service factory configured", bundle.Calls[0].Error)
+	assert.Nil(t, bundle.Calls[0].Request)
+
+	replayer = engine.NewServiceReplayer(bundle)
+	_, replayedEvents = runSession(assetsJSON, engine.NewBuilder().WithServiceReplayer(replayer))
+
+	assert.Equal(t, []string{}, replayer.Warnings())
+	assert.Equal(t, errorTexts(recordedEvents), errorTexts(replayedEvents))
+	assert.Contains(t, errorTexts(replayedEvents), "no email service factory configured")
+}
+
+func errorTexts(evts []flows.Event) []string {
+	texts := make([]string, 0)
+	for _, e := range evts {
+		if e.Type() == events.TypeError {
+			texts = append(texts, e.(*events.ErrorEvent).Text)
+		}
+	}
+	return texts
+}
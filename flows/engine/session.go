@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"strings"
 
@@ -25,6 +26,7 @@ type pushedFlow struct {
 	flow      flows.Flow
 	parentRun flows.FlowRun
 	terminal  bool
+	params    *types.XObject
 }
 
 type session struct {
@@ -78,8 +80,8 @@ func (s *session) SetInput(input flows.Input) {
 
 func (s *session) BatchStart() bool { return s.batchStart }
 
-func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool) {
-	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal}
+func (s *session) PushFlow(flow flows.Flow, parentRun flows.FlowRun, terminal bool, params *types.XObject) {
+	s.pushedFlow = &pushedFlow{flow: flow, parentRun: parentRun, terminal: terminal, params: params}
 }
 
 func (s *session) Runs() []flows.FlowRun { return s.runs }
@@ -125,12 +127,13 @@ func (s *session) ParentRun() flows.RunSummary {
 func (s *session) Status() flows.SessionStatus { return s.status }
 func (s *session) Wait() flows.ActivatedWait   { return s.wait }
 
+// CurrentContext gets the context of the current run for rendering in logs, so sensitive data is masked
 func (s *session) CurrentContext() *types.XObject {
 	run := s.currentRun()
 	if run == nil {
 		return nil
 	}
-	return types.NewXObject(run.RootContext(s.env))
+	return types.NewXObject(run.RootContext(envs.NewLogEnvironment(s.env)))
 }
 
 // looks through this session's run for the one that was last modified
@@ -176,12 +179,14 @@ func (s *session) start(trigger flows.Trigger) (flows.Sprint, error) {
 		return sprint, err
 	}
 
-	if err := s.trigger.Initialize(s, sprint.logEvent); err != nil {
+	logEvent := s.sprintLogger(sprint)
+
+	if err := s.trigger.Initialize(s, logEvent); err != nil {
 		return sprint, err
 	}
 
 	// ensure groups are correct
-	s.ensureQueryBasedGroups(sprint.logEvent)
+	s.ensureQueryBasedGroups(logEvent)
 
 	// off to the races...
 	if err := s.continueUntilWait(sprint, nil, nil, nil, "", nil, trigger); err != nil {
@@ -257,9 +262,18 @@ func (s *session) tryToResume(sprint *sprint, waitingRun flows.FlowRun, resume f
 
 	// try to end our wait which will return and log an error if it can't be ended with this resume
 	if err := node.Router().Wait().End(resume); err != nil {
-		sprint.logEvent(events.NewError(err))
+		s.sprintLogger(sprint)(events.NewError(err))
 		return nil
 	}
+
+	// callback resumes must have the token of the wait they are resuming
+	if callback, isCallback := resume.(*resumes.CallbackResume); isCallback {
+		if activated, isActivated := s.wait.(*waits.ActivatedCallbackWait); isActivated && subtle.ConstantTimeCompare([]byte(activated.Token()), []byte(callback.Token())) != 1 {
+			s.sprintLogger(sprint)(events.NewErrorf("can't resume callback wait with token '%s' as it doesn't match the wait's token", callback.Token()))
+			return nil
+		}
+	}
+
 	s.wait = nil
 	s.status = flows.SessionStatusActive
 	s.currentResume = resume
@@ -275,9 +289,7 @@ func (s *session) tryToResume(sprint *sprint, waitingRun flows.FlowRun, resume f
 	// ensure groups are correct
 	s.ensureQueryBasedGroups(logEvent)
 
-	_, isTimeout := resume.(*resumes.WaitTimeoutResume)
-
-	exit, operand, err := s.findResumeExit(sprint, waitingRun, isTimeout)
+	exit, operand, err := s.findResumeExit(sprint, waitingRun, resume)
 	if err != nil {
 		return err
 	}
@@ -287,7 +299,7 @@ func (s *session) tryToResume(sprint *sprint, waitingRun flows.FlowRun, resume f
 }
 
 // finds the exit from a the current node in a run that may have been waiting or a parent paused for a child subflow
-func (s *session) findResumeExit(sprint *sprint, run flows.FlowRun, isTimeout bool) (flows.Exit, string, error) {
+func (s *session) findResumeExit(sprint *sprint, run flows.FlowRun, resume flows.Resume) (flows.Exit, string, error) {
 	// we might have no immediate destination in this run, but continueUntilWait can resume a parent run
 	if run.Status() != flows.RunStatusActive {
 		return nil, "", nil
@@ -303,7 +315,7 @@ func (s *session) findResumeExit(sprint *sprint, run flows.FlowRun, isTimeout bo
 	}
 
 	// see if this node can now pick a destination
-	return s.pickNodeExit(sprint, run, node, step, isTimeout, logEvent)
+	return s.pickNodeExit(sprint, run, node, step, resume, logEvent)
 }
 
 // the main flow execution loop
@@ -325,7 +337,7 @@ func (s *session) continueUntilWait(sprint *sprint, currentRun flows.FlowRun, no
 
 			// create a new run for it
 			flow := s.pushedFlow.flow
-			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun)
+			currentRun = runs.NewRun(s, s.pushedFlow.flow, currentRun, s.pushedFlow.params)
 			s.addRun(currentRun)
 
 			// our destination is the first node in that flow... if such a node exists
@@ -345,7 +357,7 @@ func (s *session) continueUntilWait(sprint *sprint, currentRun flows.FlowRun, no
 			if destination != "" {
 				destNode := currentRun.Flow().GetNode(destination)
 				if destNode != nil {
-					sprint.logSegment(currentRun.Flow(), node, exit, operand, destNode)
+					sprint.logSegment(currentRun.Flow(), node, exit, operand, destNode, s.engine.Clock().Now())
 				}
 			}
 
@@ -375,7 +387,11 @@ func (s *session) continueUntilWait(sprint *sprint, currentRun flows.FlowRun, no
 						return errors.New("can't resume parent run with missing flow asset")
 					}
 
-					if exit, operand, err = s.findResumeExit(sprint, currentRun, false); err != nil {
+					if childRun.Status() == flows.RunStatusCompleted {
+						s.returnOutputs(sprint, childRun, currentRun)
+					}
+
+					if exit, operand, err = s.findResumeExit(sprint, currentRun, nil); err != nil {
 						failure(sprint, currentRun, step, errors.Wrapf(err, "can't resume run as node no longer exists"))
 					}
 				} else {
@@ -483,20 +499,63 @@ func (s *session) visitNode(sprint *sprint, run flows.FlowRun, node flows.Node,
 	}
 
 	// use our node's router to determine where to go next
-	exit, operand, err := s.pickNodeExit(sprint, run, node, step, false, logEvent)
+	exit, operand, err := s.pickNodeExit(sprint, run, node, step, nil, logEvent)
 	return step, exit, operand, err
 }
 
-// picks the exit to use on the given node
-func (s *session) pickNodeExit(sprint *sprint, run flows.FlowRun, node flows.Node, step flows.Step, isTimeout bool, logEvent flows.EventCallback) (flows.Exit, string, error) {
+// saves the outputs declared by the flow of a completed child run as results on its parent run
+func (s *session) returnOutputs(sprint *sprint, child flows.FlowRun, parent flows.FlowRun) {
+	if child.Flow() == nil || len(child.Flow().Outputs()) == 0 {
+		return
+	}
+
+	step, node, err := parent.PathLocation()
+	if err != nil {
+		return
+	}
+	logEvent := func(e flows.Event) {
+		parent.LogEvent(step, e)
+		sprint.logEvent(e)
+	}
+
+	// find the action which started the child as it may map outputs to different result names
+	var enter flows.SubflowAction
+	for _, action := range node.Actions() {
+		if a, isSubflow := action.(flows.SubflowAction); isSubflow && a.Subflow().UUID == child.Flow().UUID() {
+			enter = a
+		}
+	}
+
+	for _, output := range child.Flow().Outputs() {
+		value, err := child.EvaluateTemplate(output.Value)
+		if err != nil {
+			logEvent(events.NewError(err))
+		}
+
+		name := output.Name
+		if enter != nil {
+			name = enter.OutputResultName(output)
+		}
+
+		result := flows.NewResult(name, value, "", "", step.NodeUUID(), "", nil, s.engine.Clock().Now())
+		parent.SaveResult(result)
+		logEvent(events.NewRunResultChanged(result, s.env.SensitiveData()))
+	}
+}
+
+// picks the exit to use on the given node, taking into account the resume if we're resuming from a wait
+func (s *session) pickNodeExit(sprint *sprint, run flows.FlowRun, node flows.Node, step flows.Step, resume flows.Resume, logEvent flows.EventCallback) (flows.Exit, string, error) {
 	var exitUUID flows.ExitUUID
 	var operand string
 	var err error
 
 	if node.Router() != nil {
-		if isTimeout {
+		switch resume.(type) {
+		case *resumes.WaitTimeoutResume:
 			exitUUID, err = node.Router().RouteTimeout(run, step, logEvent)
-		} else {
+		case *resumes.WaitUntilResume:
+			exitUUID, err = node.Router().RouteWaitUntil(run, step, logEvent)
+		default:
 			exitUUID, operand, err = node.Router().Route(run, step, logEvent)
 		}
 
@@ -551,6 +610,15 @@ func (s *session) countWaits() int {
 	return waits
 }
 
+// returns a callback for events which are logged to the sprint without a run, and so need to be stamped here rather
+// than by the run with the engine's clock
+func (s *session) sprintLogger(sp *sprint) flows.EventCallback {
+	return func(e flows.Event) {
+		events.Stamp(e, s.engine.Clock())
+		sp.logEvent(e)
+	}
+}
+
 // utility to fail the session and log a failure event
 func failure(sp *sprint, run flows.FlowRun, step flows.Step, err error) {
 	event := events.NewFailure(err)
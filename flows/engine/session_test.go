@@ -409,3 +409,132 @@ func TestFindStep(t *testing.T) {
 	assert.Nil(t, run)
 	assert.Nil(t, step)
 }
+
+func TestSubflowParamsAndOutputs(t *testing.T) {
+	assetsJSON, err := os.ReadFile("testdata/subflow_params.json")
+	require.NoError(t, err)
+
+	session, sprint := test.NewSessionBuilder().WithAssets(assetsJSON).WithFlow("a2d9c4f1-7b3e-4e8a-9c1d-5f6b7a8e9d01").MustBuild()
+
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+	require.Equal(t, 2, len(session.Runs()))
+
+	// child run has converted arguments as its params
+	child := session.Runs()[1]
+	since := types.NewXDateTime(time.Date(2020, 1, 15, 10, 30, 0, 0, time.UTC))
+	test.AssertXEqual(t, types.NewXObject(map[string]types.XValue{"name": types.NewXText("Bob"), "attempts": types.NewXNumberFromInt(2), "since": since}), child.Params())
+	assert.Equal(t, "Hi Bob, what is your favorite color?", sprint.Events()[1].(*events.MsgCreatedEvent).Msg.Text())
+
+	// resume after reloading the session so params have to survive being marshaled
+	session, sprint, err = test.ResumeSession(session, assetsJSON, "Blue")
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	// params are converted back to their declared types when the session is read
+	reloadedSince, _ := session.Runs()[1].Params().Get("since")
+	test.AssertXEqual(t, since, reloadedSince)
+
+	// outputs saved on the parent using the mapped name for color and the output name for attempts
+	parent := session.Runs()[0]
+	assert.Equal(t, "Blue", parent.Results().Get("favorite_color").Value)
+	assert.Equal(t, "2", parent.Results().Get("attempts_used").Value)
+	assert.Nil(t, parent.Results().Get("color"))
+
+	lastEvent := sprint.Events()[len(sprint.Events())-1]
+	assert.Equal(t, "You like Blue after 2 attempts", lastEvent.(*events.MsgCreatedEvent).Msg.Text())
+}
+
+func TestSensitiveData(t *testing.T) {
+	assetsJSON, err := os.ReadFile("testdata/sensitive_data.json")
+	require.NoError(t, err)
+
+	env := envs.NewBuilder().WithSensitiveData(&envs.SensitiveData{Fields: []string{"national_id"}, Results: []string{"Diagnosis", "Symptoms"}}).Build()
+
+	session, sprint1 := test.NewSessionBuilder().WithEnvironment(env).WithAssets(assetsJSON).WithFlow("7e4a1c2b-5d3f-4a8e-9b6c-1f2e3d4c5b61").MustBuild()
+	require.Equal(t, flows.SessionStatusWaiting, session.Status())
+
+	// flows still see the real values
+	lastEvent := sprint1.Events()[len(sprint1.Events())-2]
+	assert.Equal(t, "ID 123-45-6789, diagnosis Malaria at Clinic", lastEvent.(*events.MsgCreatedEvent).Msg.Text())
+
+	msg := flows.NewMsgIn(flows.MsgUUID(uuids.New()), "tel:+593979123456", nil, "Fever and cough", nil)
+	sprint2, err := session.Resume(resumes.NewMsg(nil, nil, msg))
+	require.NoError(t, err)
+	require.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	// the received message is still in the event for callers to handle
+	assert.Equal(t, "Fever and cough", sprint2.Events()[0].(*events.MsgReceivedEvent).Msg.Text())
+
+	// but they're masked in the context for logs
+	context := session.CurrentContext()
+
+	fieldsContext, _ := context.Get("fields")
+	nationalID, _ := fieldsContext.(*types.XObject).Get("national_id")
+	assert.Equal(t, types.NewXText(flows.RedactionMask), nationalID)
+
+	resultsContext, _ := context.Get("results")
+	diagnosis, _ := resultsContext.(*types.XObject).Get("diagnosis")
+	diagnosisValue, _ := diagnosis.(*types.XObject).Get("value")
+	diagnosisCategory, _ := diagnosis.(*types.XObject).Get("category")
+	assert.Equal(t, types.NewXText(flows.RedactionMask), diagnosisValue)
+	assert.Equal(t, types.NewXText(flows.RedactionMask), diagnosisCategory)
+
+	visit, _ := resultsContext.(*types.XObject).Get("visit")
+	visitValue, _ := visit.(*types.XObject).Get("value")
+	assert.Equal(t, types.NewXText("Clinic"), visitValue)
+
+	// and in the contact_field_changed and run_result_changed events
+	for _, e := range append(sprint1.Events(), sprint2.Events()...) {
+		switch typed := e.(type) {
+		case *events.ContactFieldChangedEvent:
+			assert.Equal(t, types.NewXText(flows.RedactionMask), typed.Value.Text)
+		case *events.RunResultChangedEvent:
+			if typed.Name == "Diagnosis" {
+				assert.Equal(t, flows.RedactionMask, typed.Value)
+				assert.Equal(t, flows.RedactionMask, typed.Category)
+			}
+		}
+	}
+
+	// and in exported sessions
+	exported, err := engine.ExportSession(session)
+	require.NoError(t, err)
+
+	marshaled, err := jsonx.Marshal(session)
+	require.NoError(t, err)
+
+	var exportedData, marshaledData map[string]interface{}
+	require.NoError(t, json.Unmarshal(exported, &exportedData))
+	require.NoError(t, json.Unmarshal(marshaled, &marshaledData))
+
+	exportedRun := exportedData["runs"].([]interface{})[0].(map[string]interface{})
+	exportedResults := exportedRun["results"].(map[string]interface{})
+	assert.Equal(t, flows.RedactionMask, exportedData["contact"].(map[string]interface{})["fields"].(map[string]interface{})["national_id"].(map[string]interface{})["text"])
+	assert.Equal(t, flows.RedactionMask, exportedResults["diagnosis"].(map[string]interface{})["value"])
+	assert.Equal(t, flows.RedactionMask, exportedResults["diagnosis"].(map[string]interface{})["category"])
+	assert.Equal(t, "Clinic", exportedResults["visit"].(map[string]interface{})["value"])
+	assert.Equal(t, flows.RedactionMask, exportedResults["symptoms"].(map[string]interface{})["input"])
+
+	// including the run events
+	eventsByType := make(map[string]map[string]interface{})
+	for _, e := range exportedRun["events"].([]interface{}) {
+		eventsByType[e.(map[string]interface{})["type"].(string)] = e.(map[string]interface{})
+	}
+	assert.Equal(t, flows.RedactionMask, eventsByType["contact_field_changed"]["value"].(map[string]interface{})["text"])
+	assert.Equal(t, flows.RedactionMask, eventsByType["msg_received"]["msg"].(map[string]interface{})["text"])
+	assert.Equal(t, flows.RedactionMask, exportedData["input"].(map[string]interface{})["text"])
+
+	marshaledRun := marshaledData["runs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Malaria", marshaledRun["results"].(map[string]interface{})["diagnosis"].(map[string]interface{})["value"])
+
+	// without sensitive data, sessions are exported as is
+	session, _ = test.NewSessionBuilder().WithAssets(assetsJSON).WithFlow("7e4a1c2b-5d3f-4a8e-9b6c-1f2e3d4c5b61").MustBuild()
+	_, err = session.Resume(resumes.NewMsg(nil, nil, msg))
+	require.NoError(t, err)
+
+	exported, err = engine.ExportSession(session)
+	require.NoError(t, err)
+	marshaled, err = jsonx.Marshal(session)
+	require.NoError(t, err)
+	assert.Equal(t, string(marshaled), string(exported))
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/flows"
 )
@@ -79,14 +78,14 @@ func (s *sprint) logEvent(e flows.Event) {
 	s.events = append(s.events, e)
 }
 
-func (s *sprint) logSegment(flow flows.Flow, node flows.Node, exit flows.Exit, operand string, dest flows.Node) {
+func (s *sprint) logSegment(flow flows.Flow, node flows.Node, exit flows.Exit, operand string, dest flows.Node, t time.Time) {
 	s.segments = append(s.segments, &segment{
 		flow:        flow,
 		node:        node,
 		exit:        exit,
 		operand:     operand,
 		destination: dest,
-		time:        dates.Now(),
+		time:        t,
 	})
 }
 
@@ -86,10 +86,10 @@ func TestSprint(t *testing.T) {
 	dates.SetNowSource(dates.NewSequentialNowSource(time.Date(2021, 12, 8, 10, 13, 30, 0, time.UTC)))
 
 	sprint := newEmptySprint()
-	sprint.logSegment(flow, node1, node1Exit1, "yes", node2)
+	sprint.logSegment(flow, node1, node1Exit1, "yes", node2, dates.Now())
 	sprint.logModifier(mod1)
 	sprint.logEvent(event1)
-	sprint.logSegment(flow, node2, node2Exit1, "", node3)
+	sprint.logSegment(flow, node2, node2Exit1, "", node3, dates.Now())
 	sprint.logModifier(mod2)
 	sprint.logEvent(event2)
 
@@ -18,6 +18,11 @@ func registerType(name string, initFunc func() flows.Event) {
 	registeredTypes[name] = initFunc
 }
 
+// RegisteredTypes gets the registered types of event
+func RegisteredTypes() map[string](func() flows.Event) {
+	return registeredTypes
+}
+
 // base of all event types
 type baseEvent struct {
 	Type_      string         `json:"type" validate:"required"`
@@ -42,6 +47,16 @@ func (e *baseEvent) StepUUID() flows.StepUUID { return e.StepUUID_ }
 // SetStepUUID sets the UUID of the step in the path where this event occurred
 func (e *baseEvent) SetStepUUID(stepUUID flows.StepUUID) { e.StepUUID_ = stepUUID }
 
+func (e *baseEvent) setCreatedOn(createdOn time.Time) { e.CreatedOn_ = createdOn }
+
+// Stamp sets the created on time of the given event from the given clock. Events are created with a time from the
+// process-wide clock so this only changes them if the clock is something else, e.g. an engine's own clock.
+func Stamp(e flows.Event, clock dates.NowSource) {
+	if stampable, ok := e.(interface{ setCreatedOn(time.Time) }); ok && clock != flows.GlobalClock {
+		stampable.setCreatedOn(clock.Now())
+	}
+}
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
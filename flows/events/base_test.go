@@ -182,6 +182,7 @@ func TestEventMarshaling(t *testing.T) {
 			events.NewContactFieldChanged(
 				gender,
 				flows.NewValue(types.NewXText("male"), nil, nil, "", "", ""),
+				nil,
 			),
 			`{
 				"created_on": "2018-10-18T14:20:30.000123456Z",
@@ -199,6 +200,7 @@ func TestEventMarshaling(t *testing.T) {
 			events.NewContactFieldChanged(
 				gender,
 				nil, // value being cleared
+				nil,
 			),
 			`{
 				"created_on": "2018-10-18T14:20:30.000123456Z",
@@ -210,6 +212,24 @@ func TestEventMarshaling(t *testing.T) {
 				"value": null
 			}`,
 		},
+		{
+			events.NewContactFieldChanged(
+				gender,
+				flows.NewValue(types.NewXText("male"), nil, nil, "", "", ""),
+				&envs.SensitiveData{Fields: []string{"gender"}},
+			),
+			`{
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"field": {
+					"key": "gender",
+					"name": "Gender"
+				},
+				"type": "contact_field_changed",
+				"value": {
+					"text": "****************"
+				}
+			}`,
+		},
 		{
 			events.NewContactGroupsChanged(
 				[]*flows.Group{session.Assets().Groups().FindByName("Customers")},
@@ -423,6 +443,7 @@ func TestEventMarshaling(t *testing.T) {
 		{
 			events.NewIVRCreated(
 				flows.NewMsgOut(
+					flows.MsgUUID(uuids.New()),
 					urns.URN("tel:+12345678900"),
 					assets.NewChannelReference(assets.ChannelUUID("57f1078f-88aa-46f4-a59a-948a5739c03d"), "My Android Phone"),
 					"Hi there",
@@ -481,6 +502,31 @@ func TestEventMarshaling(t *testing.T) {
 				"urn": "tel:+1234567890"
 			}`,
 		},
+		{
+			events.NewCallbackWait(&timeout),
+			`{
+				"type": "callback_wait",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"timeout_seconds": 500
+			}`,
+		},
+		{
+			events.NewCallbackReceived("8720f157-ca1c-432f-9c0b-2014ddc77094", []byte(`{"status":"paid"}`)),
+			`{
+				"type": "callback_received",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"token": "8720f157-ca1c-432f-9c0b-2014ddc77094",
+				"payload": {"status": "paid"}
+			}`,
+		},
+		{
+			events.NewUntilWait(time.Date(2018, 10, 19, 9, 0, 0, 0, tz)),
+			`{
+				"type": "until_wait",
+				"created_on": "2018-10-18T14:20:30.000123456Z",
+				"resume_on": "2018-10-19T09:00:00+02:00"
+			}`,
+		},
 		{
 			events.NewSessionTriggered(
 				assets.NewFlowReference(assets.FlowUUID("e4d441f0-24e3-4627-85fb-1e99e733baf0"), "Collect Age"),
@@ -625,6 +671,22 @@ func TestReadEvent(t *testing.T) {
 
 }
 
+func TestStamp(t *testing.T) {
+	defer dates.SetNowSource(dates.DefaultNowSource)
+	dates.SetNowSource(dates.NewFixedNowSource(time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	event := events.NewContactNameChanged("Bob")
+	assert.Equal(t, time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC), event.CreatedOn())
+
+	// stamping with the global clock leaves the time as it was created
+	events.Stamp(event, flows.GlobalClock)
+	assert.Equal(t, time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC), event.CreatedOn())
+
+	// stamping with another clock takes the time from that clock
+	events.Stamp(event, dates.NewFixedNowSource(time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC), event.CreatedOn())
+}
+
 func TestWebhookCalledEventTrimming(t *testing.T) {
 	defer httpx.SetRequestor(httpx.DefaultRequestor)
 
@@ -643,7 +705,7 @@ func TestWebhookCalledEventTrimming(t *testing.T) {
 	assert.Equal(t, 42, len(call.ResponseTrace))
 	assert.Equal(t, 20000, len(call.ResponseBody))
 
-	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "")
+	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "", nil)
 
 	assert.Equal(t, "http://temba.io/", event.URL)
 	assert.Equal(t, 10000, len(event.Request))
@@ -667,7 +729,7 @@ func TestWebhookCalledEventValid(t *testing.T) {
 	call, err := svc.Call(nil, request)
 	require.NoError(t, err)
 
-	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "")
+	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "", nil)
 
 	assert.Equal(t, "http://temba.io/", event.URL)
 	assert.Equal(t, "HTTP/1.0 200 OK\r\nContent-Length: 14\r\nHeader: hello\r\n\r\n{\"foo\": \"bar\"}", event.Response)
@@ -690,7 +752,7 @@ func TestWebhookCalledEventNullChar(t *testing.T) {
 	call, err := svc.Call(nil, request)
 	require.NoError(t, err)
 
-	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "")
+	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "", nil)
 
 	// actual null will have been stripped, escaped null will remain
 	assert.Equal(t, "http://temba.io/", event.URL)
@@ -714,7 +776,7 @@ func TestWebhookCalledEventBadUTF8(t *testing.T) {
 	call, err := svc.Call(nil, request)
 	require.NoError(t, err)
 
-	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "")
+	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "", nil)
 
 	assert.Equal(t, "http://temba.io/", event.URL)
 	assert.Equal(t, "HTTP/1.0 200 OK\r\nContent-Length: 13\r\nBad-Header: �\r\n\r\n...", event.Response)
@@ -722,6 +784,59 @@ func TestWebhookCalledEventBadUTF8(t *testing.T) {
 	assert.Equal(t, events.ExtractionCleaned, event.Extraction)
 }
 
+func TestSensitiveDataInEvents(t *testing.T) {
+	defer httpx.SetRequestor(httpx.DefaultRequestor)
+
+	httpx.SetRequestor(httpx.NewMockRequestor(map[string][]httpx.MockResponse{
+		"http://temba.io/": {
+			httpx.NewMockResponse(200, map[string]string{"X-Patient-ID": "1234"}, `{"patient": {"diagnosis": "flu"}, "ok": true}`),
+		},
+	}))
+
+	sensitive := &envs.SensitiveData{
+		Results:          []string{"HIV Status"},
+		WebhookHeaders:   []string{"Authorization", "X-Patient-ID"},
+		WebhookBodyPaths: []string{"patient.diagnosis"},
+	}
+
+	request, _ := http.NewRequest("POST", "http://temba.io/", strings.NewReader(`{"patient": {"diagnosis": "flu"}}`))
+	request.Header.Set("Authorization", "Token 123")
+
+	svc := webhooks.NewService(http.DefaultClient, nil, nil, nil, 1024*1024)
+	call, err := svc.Call(nil, request)
+	require.NoError(t, err)
+
+	event := events.NewWebhookCalled(call, flows.CallStatusSuccess, "", sensitive)
+
+	assert.Equal(t, "POST / HTTP/1.1\r\nHost: temba.io\r\nUser-Agent: Go-http-client/1.1\r\nContent-Length: 33\r\nAuthorization: ****************\r\nAccept-Encoding: gzip\r\n\r\n{\"patient\": {\"diagnosis\": \"****************\"}}", event.Request)
+	assert.Equal(t, "HTTP/1.0 200 OK\r\nContent-Length: 45\r\nX-Patient-Id: ****************\r\n\r\n{\"patient\": {\"diagnosis\": \"****************\"}, \"ok\": true}", event.Response)
+
+	// the call itself isn't modified
+	assert.Equal(t, `{"patient": {"diagnosis": "flu"}, "ok": true}`, string(call.ResponseJSON))
+
+	createdOn := time.Date(2021, 10, 18, 12, 0, 0, 0, time.UTC)
+	result := flows.NewResult("HIV Status", "positive", "Positive", "Positivo", "", "yes", nil, createdOn)
+
+	resultEvent := events.NewRunResultChanged(result, sensitive)
+	assert.Equal(t, "HIV Status", resultEvent.Name)
+	assert.Equal(t, "****************", resultEvent.Value)
+	assert.Equal(t, "****************", resultEvent.Category)
+	assert.Equal(t, "****************", resultEvent.CategoryLocalized)
+	assert.Equal(t, "****************", resultEvent.Input)
+	assert.Equal(t, "positive", result.Value) // the result itself isn't modified
+
+	// results which aren't sensitive only have sensitive webhook data masked in their extra
+	result = flows.NewResult("Lookup", "200", "Success", "", "", "POST http://temba.io/", []byte(`{"patient": {"diagnosis": "flu"}, "ok": true}`), createdOn)
+
+	resultEvent = events.NewRunResultChanged(result, sensitive)
+	assert.Equal(t, "200", resultEvent.Value)
+	assert.Equal(t, "Success", resultEvent.Category)
+	assert.Equal(t, `{"patient": {"diagnosis": "****************"}, "ok": true}`, string(resultEvent.Extra))
+
+	resultEvent = events.NewRunResultChanged(result, nil)
+	assert.Equal(t, `{"patient": {"diagnosis": "flu"}, "ok": true}`, string(resultEvent.Extra))
+}
+
 func TestDeprecatedEvents(t *testing.T) {
 	eventJSON := []byte(`{
 		"type": "classifier_called",
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeCallbackReceived, func() flows.Event { return &CallbackReceivedEvent{} })
+}
+
+// TypeCallbackReceived is the type of our callback received event
+const TypeCallbackReceived string = "callback_received"
+
+// CallbackReceivedEvent events are created when a session is resumed by a callback from an external system.
+//
+//   {
+//     "type": "callback_received",
+//     "created_on": "2019-01-02T15:04:05Z",
+//     "token": "8720f157-ca1c-432f-9c0b-2014ddc77094",
+//     "payload": {"status": "paid", "amount": 25}
+//   }
+//
+// @event callback_received
+type CallbackReceivedEvent struct {
+	baseEvent
+
+	Token   string          `json:"token"             validate:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewCallbackReceived returns a new callback received event
+func NewCallbackReceived(token string, payload json.RawMessage) *CallbackReceivedEvent {
+	return &CallbackReceivedEvent{
+		baseEvent: newBaseEvent(TypeCallbackReceived),
+		Token:     token,
+		Payload:   payload,
+	}
+}
+
+var _ flows.Event = (*CallbackReceivedEvent)(nil)
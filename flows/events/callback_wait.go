@@ -0,0 +1,40 @@
+package events
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeCallbackWait, func() flows.Event { return &CallbackWaitEvent{} })
+}
+
+// TypeCallbackWait is the type of our callback wait event
+const TypeCallbackWait string = "callback_wait"
+
+// CallbackWaitEvent events are created when a flow pauses waiting for a callback from an external system. The caller
+// should resume the flow with a `callback` resume with the token of the session's wait when the callback is received.
+// The token is secret so isn't included in this event. If a timeout is set, then the caller should resume the flow
+// after the number of seconds in the timeout if no callback has been received.
+//
+//   {
+//     "type": "callback_wait",
+//     "created_on": "2019-01-02T15:04:05Z",
+//     "timeout_seconds": 3600
+//   }
+//
+// @event callback_wait
+type CallbackWaitEvent struct {
+	baseEvent
+
+	TimeoutSeconds *int `json:"timeout_seconds,omitempty"`
+}
+
+// NewCallbackWait returns a new callback wait with the passed in timeout
+func NewCallbackWait(timeoutSeconds *int) *CallbackWaitEvent {
+	return &CallbackWaitEvent{
+		baseEvent:      newBaseEvent(TypeCallbackWait),
+		TimeoutSeconds: timeoutSeconds,
+	}
+}
+
+var _ flows.Event = (*CallbackWaitEvent)(nil)
@@ -2,6 +2,7 @@ package events
 
 import (
 	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 )
 
@@ -13,7 +14,7 @@ func init() {
 const TypeContactFieldChanged string = "contact_field_changed"
 
 // ContactFieldChangedEvent events are created when a custom field value of the contact has been changed.
-// A null values indicates that the field value has been cleared.
+// A null values indicates that the field value has been cleared, and the value of a sensitive field is masked.
 //
 //   {
 //     "type": "contact_field_changed",
@@ -31,7 +32,11 @@ type ContactFieldChangedEvent struct {
 }
 
 // NewContactFieldChanged returns a new save to contact event
-func NewContactFieldChanged(field *flows.Field, value *flows.Value) *ContactFieldChangedEvent {
+func NewContactFieldChanged(field *flows.Field, value *flows.Value, sensitive *envs.SensitiveData) *ContactFieldChangedEvent {
+	if sensitive.IsField(field.Key()) {
+		value = value.Redacted()
+	}
+
 	return &ContactFieldChangedEvent{
 		baseEvent: newBaseEvent(TypeContactFieldChanged),
 		Field:     field.Reference(),
@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 )
 
@@ -40,8 +41,11 @@ type RunResultChangedEvent struct {
 	Extra             json.RawMessage `json:"extra,omitempty"`
 }
 
-// NewRunResultChanged returns a new save result event for the passed in values
-func NewRunResultChanged(result *flows.Result) *RunResultChangedEvent {
+// NewRunResultChanged returns a new save result event for the passed in values, with its values masked if the result
+// is sensitive
+func NewRunResultChanged(result *flows.Result, sensitive *envs.SensitiveData) *RunResultChangedEvent {
+	result = result.Redacted(sensitive)
+
 	return &RunResultChangedEvent{
 		baseEvent:         newBaseEvent(TypeRunResultChanged),
 		Name:              result.Name,
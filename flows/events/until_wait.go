@@ -0,0 +1,40 @@
+package events
+
+import (
+	"time"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeUntilWait, func() flows.Event { return &UntilWaitEvent{} })
+}
+
+// TypeUntilWait is the type of our until wait event
+const TypeUntilWait string = "until_wait"
+
+// UntilWaitEvent events are created when a flow pauses until a specific date and time. The caller should
+// resume the flow with a `wait_until` resume once that time is reached.
+//
+//   {
+//     "type": "until_wait",
+//     "created_on": "2019-01-02T15:04:05Z",
+//     "resume_on": "2019-01-03T09:00:00-05:00"
+//   }
+//
+// @event until_wait
+type UntilWaitEvent struct {
+	baseEvent
+
+	ResumeOn time.Time `json:"resume_on" validate:"required"`
+}
+
+// NewUntilWait returns a new until wait with the passed in resume time
+func NewUntilWait(resumeOn time.Time) *UntilWaitEvent {
+	return &UntilWaitEvent{
+		baseEvent: newBaseEvent(TypeUntilWait),
+		ResumeOn:  resumeOn,
+	}
+}
+
+var _ flows.Event = (*UntilWaitEvent)(nil)
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/flows"
 )
 
@@ -47,8 +48,8 @@ type WebhookCalledEvent struct {
 	Extraction Extraction `json:"extraction"`
 }
 
-// NewWebhookCalled returns a new webhook called event
-func NewWebhookCalled(call *flows.WebhookCall, status flows.CallStatus, resthook string) *WebhookCalledEvent {
+// NewWebhookCalled returns a new webhook called event, with any sensitive headers and body values masked in its trace
+func NewWebhookCalled(call *flows.WebhookCall, status flows.CallStatus, resthook string, sensitive *envs.SensitiveData) *WebhookCalledEvent {
 	extraction := ExtractionNone
 	if len(call.ResponseBody) > 0 {
 		if len(call.ResponseJSON) > 0 {
@@ -64,7 +65,7 @@ func NewWebhookCalled(call *flows.WebhookCall, status flows.CallStatus, resthook
 
 	return &WebhookCalledEvent{
 		baseEvent:  newBaseEvent(TypeWebhookCalled),
-		HTTPTrace:  flows.NewHTTPTrace(call.Trace, status),
+		HTTPTrace:  flows.NewHTTPTrace(call.Trace, status, sensitive.WebhookRedactor(flows.RedactionMask)),
 		Resthook:   resthook,
 		Extraction: extraction,
 	}
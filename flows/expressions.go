@@ -39,10 +39,12 @@ var RunContextTopLevels = []string{
 	"input",
 	"legacy_extra",
 	"node",
+	"params",
 	"parent",
 	"results",
 	"resume",
 	"run",
+	"schedules",
 	"ticket",
 	"trigger",
 	"urns",
@@ -69,6 +69,20 @@ func (v *Value) Equals(o *Value) bool {
 	return v.Text.Equals(o.Text) && dateEqual && numEqual && v.State == o.State && v.District == o.District && v.Ward == o.Ward
 }
 
+// Redacted returns a copy of this value with its text and locations masked and its parsed datetime and number
+// removed, since those can't hold a mask
+func (v *Value) Redacted() *Value {
+	if v == nil {
+		return nil
+	}
+	return &Value{
+		Text:     types.NewXText(RedactionMask),
+		State:    envs.LocationPath(redactIfSet(string(v.State))),
+		District: envs.LocationPath(redactIfSet(string(v.District))),
+		Ward:     envs.LocationPath(redactIfSet(string(v.Ward))),
+	}
+}
+
 // FieldValue represents a field and a set of values for that field
 type FieldValue struct {
 	field *Field
@@ -282,6 +296,9 @@ func (f FieldValues) Context(env envs.Environment) map[string]types.XValue {
 
 	for k, v := range f {
 		val := v.ToXValue(env)
+		if env.MaskSensitiveData() && env.SensitiveData().IsField(string(k)) && !utils.IsNil(val) {
+			val = types.NewXText(RedactionMask)
+		}
 		entries[string(k)] = val
 
 		if !utils.IsNil(val) {
@@ -83,6 +83,8 @@ func CheckReference(sa flows.SessionAssets, ref assets.Reference) bool {
 		return sa.Groups().Get(typed.UUID) != nil
 	case *assets.LabelReference:
 		return sa.Labels().Get(typed.UUID) != nil
+	case *assets.ScheduleReference:
+		return sa.Schedules().Get(typed.UUID) != nil
 	case *assets.TemplateReference:
 		return sa.Templates().Get(typed.UUID) != nil
 	case *assets.TicketerReference:
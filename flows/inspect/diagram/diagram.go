@@ -0,0 +1,312 @@
+// Package diagram renders flows as Mermaid flowcharts or Graphviz DOT graphs, e.g. for documentation or code review.
+// Nodes are labeled with summaries of their actions and routers, and edges with the names of the categories which
+// route to them. Subflows and flows started in new sessions are shown as linked nodes, and visit counts gathered from
+// the segments of sprints can be overlaid on nodes and edges.
+package diagram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+// Options are the options for rendering a diagram
+type Options struct {
+	// Visits are visit counts to overlay on the diagram
+	Visits *Visits
+
+	// FlowURL is a URL template for links to other flows, where {uuid} is replaced by the flow UUID
+	FlowURL string
+}
+
+// Node is a node of a flow in a diagram
+type Node struct {
+	ID     string
+	UUID   flows.NodeUUID
+	Lines  []string
+	Router bool
+	Visits int
+}
+
+// Subflow is another flow which is entered or started from this flow
+type Subflow struct {
+	ID   string
+	Flow *assets.FlowReference
+	URL  string
+}
+
+// Edge is a link between two nodes, or between a node and a subflow
+type Edge struct {
+	From    string
+	To      string
+	Label   string
+	Visits  int
+	Subflow bool
+}
+
+// Diagram is a renderable model of a single flow
+type Diagram struct {
+	Flow     *assets.FlowReference
+	Nodes    []*Node
+	Subflows []*Subflow
+	Edges    []*Edge
+
+	// whether visit counts are overlaid
+	hasVisits bool
+}
+
+// New creates a new diagram of the given flow
+func New(flow flows.Flow, options *Options) *Diagram {
+	if options == nil {
+		options = &Options{}
+	}
+
+	d := &Diagram{
+		Flow:      flow.Reference(),
+		Nodes:     make([]*Node, 0, len(flow.Nodes())),
+		Subflows:  make([]*Subflow, 0),
+		Edges:     make([]*Edge, 0),
+		hasVisits: options.Visits != nil,
+	}
+
+	ordered := orderNodes(flow)
+	nodeIDs := make(map[flows.NodeUUID]string, len(ordered))
+	for i, node := range ordered {
+		nodeIDs[node.UUID()] = fmt.Sprintf("n%d", i+1)
+	}
+
+	subflowIDs := make(map[assets.FlowUUID]string)
+	addSubflow := func(ref *assets.FlowReference) string {
+		if id, exists := subflowIDs[ref.UUID]; exists {
+			return id
+		}
+		id := fmt.Sprintf("f%d", len(subflowIDs)+1)
+		subflowIDs[ref.UUID] = id
+
+		url := ""
+		if options.FlowURL != "" {
+			url = strings.ReplaceAll(options.FlowURL, "{uuid}", string(ref.UUID))
+		}
+		d.Subflows = append(d.Subflows, &Subflow{ID: id, Flow: ref, URL: url})
+		return id
+	}
+
+	for _, node := range ordered {
+		n := &Node{ID: nodeIDs[node.UUID()], UUID: node.UUID(), Lines: make([]string, 0), Router: node.Router() != nil}
+
+		for _, action := range node.Actions() {
+			n.Lines = append(n.Lines, summarizeAction(action))
+
+			switch typed := action.(type) {
+			case *actions.EnterFlowAction:
+				d.Edges = append(d.Edges, &Edge{From: n.ID, To: addSubflow(typed.Flow), Subflow: true})
+			case *actions.StartSessionAction:
+				d.Edges = append(d.Edges, &Edge{From: n.ID, To: addSubflow(typed.Flow), Label: "new session", Subflow: true})
+			}
+		}
+		if node.Router() != nil {
+			n.Lines = append(n.Lines, summarizeRouter(node.Router())...)
+		}
+
+		// an edge for each exit which goes to another node, labeled with the categories which use that exit
+		for _, exit := range node.Exits() {
+			if exit.DestinationUUID() == "" || nodeIDs[exit.DestinationUUID()] == "" {
+				continue
+			}
+
+			edge := &Edge{From: n.ID, To: nodeIDs[exit.DestinationUUID()], Label: categoryNames(node.Router(), exit.UUID())}
+			if options.Visits != nil {
+				edge.Visits = options.Visits.segment(exit.UUID(), exit.DestinationUUID())
+			}
+			d.Edges = append(d.Edges, edge)
+		}
+
+		d.Nodes = append(d.Nodes, n)
+	}
+
+	if options.Visits != nil {
+		for _, n := range d.Nodes {
+			n.Visits = options.Visits.node(flow, n.UUID)
+		}
+	}
+
+	return d
+}
+
+// Mermaid renders this diagram as a Mermaid flowchart
+func (d *Diagram) Mermaid() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%%%% %s (%s)\n", d.Flow.Name, d.Flow.UUID)
+	b.WriteString("flowchart TD\n")
+
+	for _, n := range d.Nodes {
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", n.ID, escapeMermaid(d.nodeLines(n), "<br/>"))
+	}
+	for _, s := range d.Subflows {
+		fmt.Fprintf(b, "  %s[[\"%s\"]]\n", s.ID, escapeMermaid([]string{s.Flow.Name}, "<br/>"))
+		if s.URL != "" {
+			fmt.Fprintf(b, "  click %s \"%s\"\n", s.ID, s.URL)
+		}
+	}
+
+	unvisited := make([]string, 0)
+
+	for i, e := range d.Edges {
+		arrow := "-->"
+		if e.Subflow {
+			arrow = "-.->"
+		}
+
+		label := d.edgeLabel(e)
+		if label != "" {
+			fmt.Fprintf(b, "  %s %s|\"%s\"| %s\n", e.From, arrow, escapeMermaid([]string{label}, ""), e.To)
+		} else {
+			fmt.Fprintf(b, "  %s %s %s\n", e.From, arrow, e.To)
+		}
+
+		if d.hasVisits && !e.Subflow && e.Visits == 0 {
+			unvisited = append(unvisited, fmt.Sprint(i))
+		}
+	}
+
+	if len(unvisited) > 0 {
+		fmt.Fprintf(b, "  linkStyle %s stroke:#bbb,stroke-dasharray:3\n", strings.Join(unvisited, ","))
+	}
+
+	return b.String()
+}
+
+// DOT renders this diagram in Graphviz DOT format
+func (d *Diagram) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph flow {\n")
+	fmt.Fprintf(b, "  label=%q;\n", d.Flow.Name)
+	b.WriteString("  labelloc=t;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, n := range d.Nodes {
+		attrs := fmt.Sprintf("label=%q", strings.Join(d.nodeLines(n), "\n"))
+		if n.Router {
+			attrs += ", style=rounded"
+		}
+		fmt.Fprintf(b, "  %s [%s];\n", n.ID, attrs)
+	}
+	for _, s := range d.Subflows {
+		attrs := fmt.Sprintf("label=%q, peripheries=2", s.Flow.Name)
+		if s.URL != "" {
+			attrs += fmt.Sprintf(", URL=%q", s.URL)
+		}
+		fmt.Fprintf(b, "  %s [%s];\n", s.ID, attrs)
+	}
+
+	for _, e := range d.Edges {
+		attrs := make([]string, 0, 3)
+		if label := d.edgeLabel(e); label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=%q", label))
+		}
+		if e.Subflow {
+			attrs = append(attrs, "style=dashed")
+		} else if d.hasVisits && e.Visits == 0 {
+			attrs = append(attrs, "style=dashed", "color=gray")
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(b, "  %s -> %s [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(b, "  %s -> %s;\n", e.From, e.To)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (d *Diagram) nodeLines(n *Node) []string {
+	lines := n.Lines
+	if len(lines) == 0 {
+		lines = []string{"(empty)"}
+	}
+	if d.hasVisits {
+		lines = append(lines[:len(lines):len(lines)], fmt.Sprintf("visits: %d", n.Visits))
+	}
+	return lines
+}
+
+func (d *Diagram) edgeLabel(e *Edge) string {
+	if !d.hasVisits || e.Subflow {
+		return e.Label
+	}
+	if e.Label == "" {
+		return fmt.Sprintf("(%d)", e.Visits)
+	}
+	return fmt.Sprintf("%s (%d)", e.Label, e.Visits)
+}
+
+// gets the names of the categories of the given router which use the given exit
+func categoryNames(router flows.Router, exitUUID flows.ExitUUID) string {
+	if router == nil {
+		return ""
+	}
+
+	names := make([]string, 0, 1)
+	for _, c := range router.Categories() {
+		if c.ExitUUID() == exitUUID {
+			names = append(names, c.Name())
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// orders the nodes of a flow by their position in the editor, top to bottom and then left to right, if the flow has
+// positions for all its nodes, with the first node always first as that's where the flow starts
+func orderNodes(flow flows.Flow) []flows.Node {
+	nodes := append([]flows.Node(nil), flow.Nodes()...)
+	if len(nodes) < 2 || len(flow.UI()) == 0 {
+		return nodes
+	}
+
+	ui := &struct {
+		Nodes map[flows.NodeUUID]struct {
+			Position *struct {
+				Left int `json:"left"`
+				Top  int `json:"top"`
+			} `json:"position"`
+		} `json:"nodes"`
+	}{}
+	if err := json.Unmarshal(flow.UI(), ui); err != nil {
+		return nodes
+	}
+	for _, n := range nodes {
+		if ui.Nodes[n.UUID()].Position == nil {
+			return nodes
+		}
+	}
+
+	rest := nodes[1:]
+	sort.SliceStable(rest, func(i, j int) bool {
+		pi, pj := ui.Nodes[rest[i].UUID()].Position, ui.Nodes[rest[j].UUID()].Position
+		if pi.Top != pj.Top {
+			return pi.Top < pj.Top
+		}
+		return pi.Left < pj.Left
+	})
+	return nodes
+}
+
+// escapes text for use in a quoted Mermaid label, where characters like quotes must be replaced by entity codes
+func escapeMermaid(lines []string, sep string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.ReplaceAll(line, "#", "#35;")
+		line = strings.ReplaceAll(line, "\"", "#quot;")
+		line = strings.ReplaceAll(line, "<", "#lt;")
+		line = strings.ReplaceAll(line, ">", "#gt;")
+		escaped[i] = line
+	}
+	return strings.Join(escaped, sep)
+}
@@ -0,0 +1,141 @@
+package diagram_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/inspect/diagram"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagram(t *testing.T) {
+	flow, err := test.LoadFlowFromAssets(envs.NewBuilder().Build(), "testdata/assets.json", "76f0a02f-3b75-4b86-9064-e9195e1b3a02")
+	require.NoError(t, err)
+
+	d := diagram.New(flow, &diagram.Options{FlowURL: "https://textit.com/flow/editor/{uuid}/"})
+
+	assert.Equal(t, "Registration", d.Flow.Name)
+	assert.Len(t, d.Nodes, 4)
+	assert.Len(t, d.Subflows, 2)
+
+	// nodes are ordered by their position in the editor
+	assert.Equal(t, flows.NodeUUID("a58be63b-907d-4a1a-856b-0bb5579d7507"), d.Nodes[0].UUID)
+	assert.Equal(t, flows.NodeUUID("d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c02"), d.Nodes[1].UUID)
+	assert.Equal(t, flows.NodeUUID("d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c04"), d.Nodes[2].UUID)
+	assert.Equal(t, flows.NodeUUID("d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c03"), d.Nodes[3].UUID)
+
+	assert.Equal(t, []string{"switch: @input.text", "wait: msg (timeout 600s)", "result: Color"}, d.Nodes[1].Lines)
+	assert.Equal(t, &diagram.Subflow{
+		ID:   "f1",
+		Flow: assets.NewFlowReference("c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53", "Campaign"),
+		URL:  "https://textit.com/flow/editor/c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53/",
+	}, d.Subflows[0])
+
+	assert.Equal(t, `%% Registration (76f0a02f-3b75-4b86-9064-e9195e1b3a02)
+flowchart TD
+  n1["send_msg: Hi @contact.name! What is your favorite…"]
+  n2["switch: @input.text<br/>wait: msg (timeout 600s)<br/>result: Color"]
+  n3["send_msg: Sorry, #quot;@input.text#quot; isn't a color we k…<br/>start_session: Campaign"]
+  n4["enter_flow: Profile<br/>switch: @child.run.status"]
+  f1[["Campaign"]]
+  click f1 "https://textit.com/flow/editor/c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53/"
+  f2[["Profile"]]
+  click f2 "https://textit.com/flow/editor/8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2/"
+  n1 --> n2
+  n2 -->|"Red, Blue"| n4
+  n2 -->|"Other"| n3
+  n3 -.->|"new session"| f1
+  n3 --> n2
+  n4 -.-> f2
+`, d.Mermaid())
+
+	assert.Equal(t, `digraph flow {
+  label="Registration";
+  labelloc=t;
+  node [shape=box];
+  n1 [label="send_msg: Hi @contact.name! What is your favorite…"];
+  n2 [label="switch: @input.text\nwait: msg (timeout 600s)\nresult: Color", style=rounded];
+  n3 [label="send_msg: Sorry, \"@input.text\" isn't a color we k…\nstart_session: Campaign"];
+  n4 [label="enter_flow: Profile\nswitch: @child.run.status", style=rounded];
+  f1 [label="Campaign", peripheries=2, URL="https://textit.com/flow/editor/c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53/"];
+  f2 [label="Profile", peripheries=2, URL="https://textit.com/flow/editor/8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2/"];
+  n1 -> n2;
+  n2 -> n4 [label="Red, Blue"];
+  n2 -> n3 [label="Other"];
+  n3 -> f1 [label="new session", style=dashed];
+  n3 -> n2;
+  n4 -> f2 [style=dashed];
+}
+`, d.DOT())
+}
+
+func TestDiagramWithVisits(t *testing.T) {
+	assetsJSON, err := os.ReadFile("testdata/assets.json")
+	require.NoError(t, err)
+
+	session, sprint, err := test.NewSessionBuilder().WithAssets(assetsJSON).WithFlow("76f0a02f-3b75-4b86-9064-e9195e1b3a02").Build()
+	require.NoError(t, err)
+
+	visits := diagram.NewVisits()
+	visits.AddSprint(sprint)
+
+	session, sprint, err = test.ResumeSession(session, assetsJSON, "green")
+	require.NoError(t, err)
+	visits.AddSprint(sprint)
+
+	session, sprint, err = test.ResumeSession(session, assetsJSON, "red")
+	require.NoError(t, err)
+	visits.AddSprint(sprint)
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+
+	d := diagram.New(session.Runs()[0].Flow(), &diagram.Options{Visits: visits})
+
+	assert.Equal(t, []int{1, 2, 1, 1}, []int{d.Nodes[0].Visits, d.Nodes[1].Visits, d.Nodes[2].Visits, d.Nodes[3].Visits})
+
+	assert.Equal(t, `%% Registration (76f0a02f-3b75-4b86-9064-e9195e1b3a02)
+flowchart TD
+  n1["send_msg: Hi @contact.name! What is your favorite…<br/>visits: 1"]
+  n2["switch: @input.text<br/>wait: msg (timeout 600s)<br/>result: Color<br/>visits: 2"]
+  n3["send_msg: Sorry, #quot;@input.text#quot; isn't a color we k…<br/>start_session: Campaign<br/>visits: 1"]
+  n4["enter_flow: Profile<br/>switch: @child.run.status<br/>visits: 1"]
+  f1[["Campaign"]]
+  f2[["Profile"]]
+  n1 -->|"(1)"| n2
+  n2 -->|"Red, Blue (1)"| n4
+  n2 -->|"Other (1)"| n3
+  n3 -.->|"new session"| f1
+  n3 -->|"(1)"| n2
+  n4 -.-> f2
+`, d.Mermaid())
+
+	// edges that weren't visited are grayed out
+	visits = diagram.NewVisits()
+	visits.Add("e8de3a1d-1ba7-4cd5-8f60-fa89b2bb5ca4", "d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c02", 3)
+	visits.Add("0c2a4b6d-8e0f-4a1b-9c3d-5e7f9a1b3c01", "d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c03", 2)
+
+	d = diagram.New(session.Runs()[0].Flow(), &diagram.Options{Visits: visits})
+
+	assert.Equal(t, []int{3, 3, 0, 2}, []int{d.Nodes[0].Visits, d.Nodes[1].Visits, d.Nodes[2].Visits, d.Nodes[3].Visits})
+	assert.Contains(t, d.Mermaid(), "  linkStyle 2,4 stroke:#bbb,stroke-dasharray:3\n")
+	assert.Contains(t, d.DOT(), "  n2 -> n3 [label=\"Other (0)\", style=dashed, color=gray];\n")
+	assert.Contains(t, d.DOT(), "  n3 -> n2 [label=\"(0)\", style=dashed, color=gray];\n")
+}
+
+func TestDiagramWithoutUI(t *testing.T) {
+	flow, err := test.LoadFlowFromAssets(envs.NewBuilder().Build(), "testdata/assets.json", "8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2")
+	require.NoError(t, err)
+
+	d := diagram.New(flow, nil)
+
+	assert.Equal(t, `%% Profile (8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2)
+flowchart TD
+  n1["set_contact_name: @(title(contact.name))"]
+`, d.Mermaid())
+}
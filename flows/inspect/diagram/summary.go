@@ -0,0 +1,125 @@
+package diagram
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+	"github.com/nyaruka/goflow/flows/routers"
+)
+
+// the maximum length of the detail in an action or router summary
+const maxDetailLength = 40
+
+// summarizes an action as its type and the most important thing about it, e.g. the text of a message
+func summarizeAction(action flows.Action) string {
+	detail := ""
+
+	switch a := action.(type) {
+	case *actions.SendMsgAction:
+		detail = a.Text
+	case *actions.SayMsgAction:
+		detail = a.Text
+	case *actions.SendBroadcastAction:
+		detail = a.Text
+	case *actions.SendEmailAction:
+		detail = a.Subject
+	case *actions.PlayAudioAction:
+		detail = a.AudioURL
+	case *actions.CallWebhookAction:
+		detail = a.Method + " " + a.URL
+	case *actions.CallResthookAction:
+		detail = a.Resthook
+	case *actions.CallClassifierAction:
+		detail = a.Classifier.Name
+	case *actions.OpenTicketAction:
+		detail = a.Ticketer.Name
+	case *actions.EnterFlowAction:
+		detail = a.Flow.Name
+	case *actions.StartSessionAction:
+		detail = a.Flow.Name
+	case *actions.AddContactGroupsAction:
+		detail = groupNames(a.Groups)
+	case *actions.RemoveContactGroupsAction:
+		if a.AllGroups {
+			detail = "all groups"
+		} else {
+			detail = groupNames(a.Groups)
+		}
+	case *actions.AddInputLabelsAction:
+		names := make([]string, len(a.Labels))
+		for i, l := range a.Labels {
+			names[i] = l.Name
+		}
+		detail = strings.Join(names, ", ")
+	case *actions.AddContactURNAction:
+		detail = a.Scheme + ":" + a.Path
+	case *actions.SetContactFieldAction:
+		detail = fmt.Sprintf("%s = %s", a.Field.Name, a.Value)
+	case *actions.SetContactNameAction:
+		detail = a.Name
+	case *actions.SetContactLanguageAction:
+		detail = a.Language
+	case *actions.SetContactStatusAction:
+		detail = string(a.Status)
+	case *actions.SetContactChannelAction:
+		if a.Channel != nil {
+			detail = a.Channel.Name
+		}
+	case *actions.SetRunResultAction:
+		detail = fmt.Sprintf("%s = %s", a.Name, a.Value)
+	}
+
+	return summary(action.Type(), detail)
+}
+
+// summarizes a router as its type and operand, any wait, and the result it saves
+func summarizeRouter(router flows.Router) []string {
+	detail := ""
+
+	switch r := router.(type) {
+	case *routers.SwitchRouter:
+		detail = r.Operand()
+	case *routers.SmartRouter:
+		detail = r.Operand()
+	case *routers.ScheduleRouter:
+		detail = r.Schedule().Name
+	}
+
+	lines := []string{summary(router.Type(), detail)}
+
+	if router.Wait() != nil {
+		wait := "wait: " + router.Wait().Type()
+		if router.Wait().Timeout() != nil {
+			wait += fmt.Sprintf(" (timeout %ds)", router.Wait().Timeout().Seconds())
+		}
+		lines = append(lines, wait)
+	}
+	if router.ResultName() != "" {
+		lines = append(lines, "result: "+router.ResultName())
+	}
+
+	return lines
+}
+
+func summary(typeName, detail string) string {
+	detail = strings.Join(strings.Fields(detail), " ")
+	if detail == "" {
+		return typeName
+	}
+	if utf8.RuneCountInString(detail) > maxDetailLength {
+		detail = string([]rune(detail)[:maxDetailLength-1]) + "…"
+	}
+	return typeName + ": " + detail
+}
+
+func groupNames(groups []*assets.GroupReference) string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Name
+	}
+	return strings.Join(names, ", ")
+}
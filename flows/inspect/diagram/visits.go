@@ -0,0 +1,57 @@
+package diagram
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+type segmentKey struct {
+	exit flows.ExitUUID
+	dest flows.NodeUUID
+}
+
+// Visits are counts of the segments taken between nodes, e.g. from the sprints of real sessions or a simulation
+type Visits struct {
+	segments map[segmentKey]int
+	incoming map[flows.NodeUUID]int
+}
+
+// NewVisits creates a new empty set of visit counts
+func NewVisits() *Visits {
+	return &Visits{
+		segments: make(map[segmentKey]int),
+		incoming: make(map[flows.NodeUUID]int),
+	}
+}
+
+// Add adds the given count for the segment from the given exit to the given node
+func (v *Visits) Add(exitUUID flows.ExitUUID, destUUID flows.NodeUUID, count int) {
+	v.segments[segmentKey{exitUUID, destUUID}] += count
+	v.incoming[destUUID] += count
+}
+
+// AddSprint adds the segments of the given sprint
+func (v *Visits) AddSprint(sprint flows.Sprint) {
+	for _, seg := range sprint.Segments() {
+		v.Add(seg.Exit().UUID(), seg.Destination().UUID(), 1)
+	}
+}
+
+// gets the count for the segment from the given exit to the given node
+func (v *Visits) segment(exitUUID flows.ExitUUID, destUUID flows.NodeUUID) int {
+	return v.segments[segmentKey{exitUUID, destUUID}]
+}
+
+// gets the count for the given node, which is the greater of the segments into it and the segments out of it, as
+// entering a flow isn't a segment and nor is stopping at a node
+func (v *Visits) node(flow flows.Flow, nodeUUID flows.NodeUUID) int {
+	outgoing := 0
+	if node := flow.GetNode(nodeUUID); node != nil {
+		for _, exit := range node.Exits() {
+			outgoing += v.segment(exit.UUID(), exit.DestinationUUID())
+		}
+	}
+	if v.incoming[nodeUUID] > outgoing {
+		return v.incoming[nodeUUID]
+	}
+	return outgoing
+}
@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+	"github.com/nyaruka/goflow/flows/inspect"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+)
+
+// NodeID identifies an asset in the graph as type:identity, e.g. flow:8f4c9d1e-... or field:gender
+type NodeID string
+
+// NewNodeID creates a new node ID for the given asset reference
+func NewNodeID(ref assets.Reference) NodeID {
+	return NodeID(fmt.Sprintf("%s:%s", ref.Type(), ref.Identity()))
+}
+
+// Node is an asset in the dependency graph
+type Node struct {
+	ID      NodeID `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// EdgeType is the type of a dependency between a flow and an asset
+type EdgeType string
+
+// the types of dependency edges
+const (
+	EdgeTypeEnterFlow    EdgeType = "enter_flow"
+	EdgeTypeStartSession EdgeType = "start_session"
+	EdgeTypeWrites       EdgeType = "writes"
+	EdgeTypeUses         EdgeType = "uses"
+)
+
+// Edge is a dependency of a flow on another asset
+type Edge struct {
+	From NodeID   `json:"from"`
+	To   NodeID   `json:"to"`
+	Type EdgeType `json:"type"`
+}
+
+// ResultWriter is a flow which can write a result
+type ResultWriter struct {
+	Flow      *assets.FlowReference `json:"flow"`
+	Name      string                `json:"name"`
+	NodeUUIDs []flows.NodeUUID      `json:"node_uuids"`
+}
+
+// Impact is the set of flows affected by removing an asset
+type Impact struct {
+	Direct   []*assets.FlowReference `json:"direct"`
+	Indirect []*assets.FlowReference `json:"indirect"`
+}
+
+// Graph is the dependency graph of all flows in an asset source and the assets they depend on
+type Graph struct {
+	Nodes   []*Node                    `json:"nodes"`
+	Edges   []*Edge                    `json:"edges"`
+	Results map[string][]*ResultWriter `json:"results"`
+
+	nodesByID map[NodeID]*Node
+	edgesSeen map[Edge]bool
+	flowRefs  map[NodeID]*assets.FlowReference
+}
+
+// Build builds the dependency graph for all the flows in the given session assets. The asset source must be able
+// to list its flows.
+func Build(sa flows.SessionAssets) (*Graph, error) {
+	lister, canList := sa.Source().(assets.FlowLister)
+	if !canList {
+		return nil, errors.New("asset source can't list flows")
+	}
+	flowAssets, err := lister.Flows()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing flows")
+	}
+
+	g := &Graph{
+		Nodes:     make([]*Node, 0),
+		Edges:     make([]*Edge, 0),
+		Results:   make(map[string][]*ResultWriter),
+		nodesByID: make(map[NodeID]*Node),
+		edgesSeen: make(map[Edge]bool),
+		flowRefs:  make(map[NodeID]*assets.FlowReference),
+	}
+
+	for _, fa := range flowAssets {
+		flow, err := sa.Flows().Get(fa.UUID())
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading flow %s", fa.UUID())
+		}
+		g.addFlow(sa, flow)
+	}
+
+	sort.SliceStable(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+
+	return g, nil
+}
+
+func (g *Graph) addFlow(sa flows.SessionAssets, flow flows.Flow) {
+	from := g.addNode(flow.Reference(), flow.Name(), false)
+
+	addRef := func(a flows.Action, ref assets.Reference) {
+		if ref == nil || ref.Variable() {
+			return
+		}
+		to := g.addNode(ref, assetName(sa, ref), !inspect.CheckReference(sa, ref))
+		g.addEdge(from, to, edgeType(a, ref))
+	}
+
+	for _, node := range flow.Nodes() {
+		node.EnumerateTemplates(flow.Localization(), func(a flows.Action, r flows.Router, l envs.Language, t string) {
+			refs, _ := inspect.ExtractFromTemplate(t)
+			for _, ref := range refs {
+				addRef(nil, ref)
+			}
+		})
+		node.EnumerateDependencies(flow.Localization(), func(a flows.Action, r flows.Router, l envs.Language, ref assets.Reference) {
+			addRef(a, ref)
+		})
+	}
+
+	for _, spec := range flow.Inspect(sa).Results {
+		nodeUUIDs := make([]flows.NodeUUID, len(spec.NodeUUIDs))
+		for i := range spec.NodeUUIDs {
+			nodeUUIDs[i] = flows.NodeUUID(spec.NodeUUIDs[i])
+		}
+		g.Results[spec.Key] = append(g.Results[spec.Key], &ResultWriter{Flow: flow.Reference(), Name: spec.Name, NodeUUIDs: nodeUUIDs})
+	}
+}
+
+func (g *Graph) addNode(ref assets.Reference, name string, missing bool) NodeID {
+	id := NewNodeID(ref)
+
+	if existing := g.nodesByID[id]; existing != nil {
+		return id
+	}
+
+	node := &Node{ID: id, Type: ref.Type(), Name: name, Missing: missing}
+	g.Nodes = append(g.Nodes, node)
+	g.nodesByID[id] = node
+
+	if flowRef, isFlow := ref.(*assets.FlowReference); isFlow {
+		g.flowRefs[id] = flowRef
+	}
+	return id
+}
+
+func (g *Graph) addEdge(from, to NodeID, typ EdgeType) {
+	e := Edge{From: from, To: to, Type: typ}
+	if !g.edgesSeen[e] {
+		g.edgesSeen[e] = true
+		g.Edges = append(g.Edges, &e)
+	}
+}
+
+// gets the name of the referenced asset, falling back to the name in the reference
+func assetName(sa flows.SessionAssets, ref assets.Reference) string {
+	switch typed := ref.(type) {
+	case *assets.FieldReference:
+		if f := sa.Fields().Get(typed.Key); f != nil {
+			return f.Name()
+		}
+	case *assets.GlobalReference:
+		if g := sa.Globals().Get(typed.Key); g != nil {
+			return g.Name()
+		}
+	}
+
+	name, _ := jsonparser.GetString(jsonx.MustMarshal(ref), "name")
+	return name
+}
+
+func edgeType(a flows.Action, ref assets.Reference) EdgeType {
+	if a != nil {
+		switch a.Type() {
+		case actions.TypeEnterFlow:
+			return EdgeTypeEnterFlow
+		case actions.TypeStartSession:
+			if ref.Type() == "flow" {
+				return EdgeTypeStartSession
+			}
+		case actions.TypeSetContactField:
+			return EdgeTypeWrites
+		}
+	}
+	return EdgeTypeUses
+}
+
+// Node gets the node with the given ID or nil if it isn't in the graph
+func (g *Graph) Node(id NodeID) *Node { return g.nodesByID[id] }
+
+// Dependents returns the flows which directly depend on the given asset
+func (g *Graph) Dependents(id NodeID) []*assets.FlowReference {
+	dependents := make([]*assets.FlowReference, 0)
+	seen := make(map[NodeID]bool)
+	for _, e := range g.Edges {
+		if e.To == id && !seen[e.From] {
+			seen[e.From] = true
+			dependents = append(dependents, g.flowRefs[e.From])
+		}
+	}
+	sortFlowRefs(dependents)
+	return dependents
+}
+
+// Dependencies returns the edges from the given flow to the assets it depends on
+func (g *Graph) Dependencies(id NodeID) []*Edge {
+	deps := make([]*Edge, 0)
+	for _, e := range g.Edges {
+		if e.From == id {
+			deps = append(deps, e)
+		}
+	}
+	return deps
+}
+
+// Impact returns the flows which would break if the given asset was removed. Flows which depend on the asset
+// directly are direct impacts, and flows which enter those flows as subflows or start sessions in them are
+// indirect impacts.
+func (g *Graph) Impact(id NodeID) *Impact {
+	direct := g.Dependents(id)
+
+	affected := map[NodeID]bool{id: true}
+	queue := make([]NodeID, 0, len(direct))
+	for _, f := range direct {
+		affected[NewNodeID(f)] = true
+		queue = append(queue, NewNodeID(f))
+	}
+
+	indirect := make([]*assets.FlowReference, 0)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, e := range g.Edges {
+			if e.To == current && (e.Type == EdgeTypeEnterFlow || e.Type == EdgeTypeStartSession) && !affected[e.From] {
+				affected[e.From] = true
+				indirect = append(indirect, g.flowRefs[e.From])
+				queue = append(queue, e.From)
+			}
+		}
+	}
+	sortFlowRefs(indirect)
+
+	return &Impact{Direct: direct, Indirect: indirect}
+}
+
+// ResultWriters returns the flows which can write the result with the given key
+func (g *Graph) ResultWriters(key string) []*ResultWriter {
+	writers := g.Results[key]
+	if writers == nil {
+		return []*ResultWriter{}
+	}
+	return writers
+}
+
+// DOT renders this graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	for _, n := range g.Nodes {
+		label := n.Name
+		if label == "" {
+			label = strings.TrimPrefix(string(n.ID), n.Type+":")
+		}
+		attrs := fmt.Sprintf("label=%q", fmt.Sprintf("%s\n%s", n.Type, label))
+		if n.Type == "flow" {
+			attrs += ", shape=box"
+		} else {
+			attrs += ", shape=ellipse"
+		}
+		if n.Missing {
+			attrs += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(b, "  %q [%s];\n", n.ID, attrs)
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Type)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func sortFlowRefs(refs []*assets.FlowReference) {
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+}
@@ -0,0 +1,106 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/inspect/graph"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nonListingSource struct {
+	*static.StaticSource
+}
+
+func (s *nonListingSource) Flows() {}
+
+func TestGraph(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "testdata/assets.json")
+	require.NoError(t, err)
+
+	g, err := graph.Build(sa)
+	require.NoError(t, err)
+
+	test.AssertEqualJSON(t, []byte(`{
+		"nodes": [
+			{"id": "field:age", "type": "field", "name": "Age"},
+			{"id": "field:gender", "type": "field", "name": "Gender"},
+			{"id": "flow:8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "type": "flow", "name": "Profile"},
+			{"id": "flow:a4e6d3b9-6f1e-4c0a-8d9e-1c2b3a4d5e64", "type": "flow", "name": "Unrelated"},
+			{"id": "flow:c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53", "type": "flow", "name": "Campaign"},
+			{"id": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "type": "flow", "name": "Registration"},
+			{"id": "global:org_name", "type": "global", "name": "Org Name"},
+			{"id": "group:1e1ce1e1-9288-4e01-8f3e-3d2c1a0b9f81", "type": "group", "name": "Testers"},
+			{"id": "group:2e2ce2e2-9288-4e01-8f3e-3d2c1a0b9f82", "type": "group", "name": "Deleted Group", "missing": true}
+		],
+		"edges": [
+			{"from": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "to": "field:age", "type": "uses"},
+			{"from": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "to": "global:org_name", "type": "uses"},
+			{"from": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "to": "field:gender", "type": "writes"},
+			{"from": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "to": "flow:8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "type": "enter_flow"},
+			{"from": "flow:8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "to": "group:1e1ce1e1-9288-4e01-8f3e-3d2c1a0b9f81", "type": "uses"},
+			{"from": "flow:8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "to": "group:2e2ce2e2-9288-4e01-8f3e-3d2c1a0b9f82", "type": "uses"},
+			{"from": "flow:c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53", "to": "group:1e1ce1e1-9288-4e01-8f3e-3d2c1a0b9f81", "type": "uses"},
+			{"from": "flow:c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53", "to": "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "type": "start_session"}
+		],
+		"results": {
+			"name": [
+				{
+					"flow": {"uuid": "f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "name": "Registration"},
+					"name": "Name",
+					"node_uuids": ["d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c02"]
+				},
+				{
+					"flow": {"uuid": "8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "name": "Profile"},
+					"name": "Name",
+					"node_uuids": ["d9a1b3c5-0e2f-4a6b-8c7d-9e0f1a2b3c04"]
+				}
+			]
+		}
+	}`), jsonx.MustMarshal(g), "graph JSON mismatch")
+
+	registration := assets.NewFlowReference("f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0", "Registration")
+	profile := assets.NewFlowReference("8a3c8a9f-2c64-4bd7-9b1b-1d6d30e5b7a2", "Profile")
+	campaign := assets.NewFlowReference("c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53", "Campaign")
+
+	assert.Equal(t, "Gender", g.Node("field:gender").Name)
+	assert.Nil(t, g.Node("field:unknown"))
+
+	assert.Equal(t, []*assets.FlowReference{registration}, g.Dependents("field:gender"))
+	assert.Equal(t, []*assets.FlowReference{campaign, profile}, g.Dependents("group:1e1ce1e1-9288-4e01-8f3e-3d2c1a0b9f81"))
+	assert.Equal(t, []*assets.FlowReference{}, g.Dependents("field:unknown"))
+	assert.Len(t, g.Dependencies("flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0"), 4)
+
+	// deleting a field breaks the flow that uses it, and the flows that start that flow
+	assert.Equal(t, &graph.Impact{Direct: []*assets.FlowReference{registration}, Indirect: []*assets.FlowReference{campaign}}, g.Impact("field:gender"))
+
+	// deleting a subflow breaks the flows that enter it
+	assert.Equal(t, &graph.Impact{Direct: []*assets.FlowReference{registration}, Indirect: []*assets.FlowReference{campaign}}, g.Impact(graph.NewNodeID(profile)))
+
+	// deleting something unused breaks nothing
+	assert.Equal(t, &graph.Impact{Direct: []*assets.FlowReference{}, Indirect: []*assets.FlowReference{}}, g.Impact("flow:a4e6d3b9-6f1e-4c0a-8d9e-1c2b3a4d5e64"))
+
+	assert.Len(t, g.ResultWriters("name"), 2)
+	assert.Equal(t, registration, g.ResultWriters("name")[0].Flow)
+	assert.Equal(t, []*graph.ResultWriter{}, g.ResultWriters("age"))
+
+	dot := g.DOT()
+	assert.Contains(t, dot, "digraph dependencies {\n")
+	assert.Contains(t, dot, `  "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0" [label="flow\nRegistration", shape=box];`)
+	assert.Contains(t, dot, `  "group:2e2ce2e2-9288-4e01-8f3e-3d2c1a0b9f82" [label="group\nDeleted Group", shape=ellipse, style=dashed, color=red];`)
+	assert.Contains(t, dot, `  "flow:c6b1f3f4-8a34-4c55-9d1d-2b3b0a9d6e53" -> "flow:f2a6a0ff-4b5c-4b4a-9a7b-37b9e7f5c1d0" [label="start_session"];`)
+
+	// source must be able to list flows
+	sa, err = engine.NewSessionAssets(envs.NewBuilder().Build(), &nonListingSource{static.NewEmptySource()}, nil)
+	require.NoError(t, err)
+
+	_, err = graph.Build(sa)
+	assert.EqualError(t, err, "asset source can't list flows")
+}
@@ -0,0 +1,77 @@
+package issues
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeDeadEndWait, DeadEndWaitCheck)
+}
+
+// TypeDeadEndWait is our type for a dead end wait issue
+const TypeDeadEndWait string = "dead_end_wait"
+
+// DeadEndWait is an exit from a node with a wait which doesn't go anywhere, meaning the flow ends as soon as the
+// contact responds
+type DeadEndWait struct {
+	baseIssue
+
+	ExitUUID flows.ExitUUID `json:"exit_uuid"`
+}
+
+func newDeadEndWait(nodeUUID flows.NodeUUID, exitUUID flows.ExitUUID, categories []string) *DeadEndWait {
+	return &DeadEndWait{
+		baseIssue: newBaseIssue(
+			TypeDeadEndWait,
+			nodeUUID,
+			"",
+			"",
+			fmt.Sprintf("exit for %s after a wait has no destination", strings.Join(categories, ", ")),
+		),
+		ExitUUID: exitUUID,
+	}
+}
+
+// DeadEndWaitCheck checks for exits from nodes with waits which have no destination. Waits which save a result and
+// where every exit ends the flow are normal, e.g. the last question of a survey.
+func DeadEndWaitCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	for _, node := range flow.Nodes() {
+		router := node.Router()
+		if router == nil || router.Wait() == nil {
+			continue
+		}
+		if router.ResultName() != "" && !anyExitHasDestination(node) {
+			continue
+		}
+
+		for _, e := range node.Exits() {
+			if e.DestinationUUID() != "" {
+				continue
+			}
+
+			categories := make([]string, 0, 1)
+			for _, c := range router.Categories() {
+				if c.ExitUUID() == e.UUID() {
+					categories = append(categories, fmt.Sprintf("'%s'", c.Name()))
+				}
+			}
+
+			// exits that aren't used by any category can never be taken
+			if len(categories) > 0 {
+				report(newDeadEndWait(node.UUID(), e.UUID(), categories))
+			}
+		}
+	}
+}
+
+func anyExitHasDestination(node flows.Node) bool {
+	for _, e := range node.Exits() {
+		if e.DestinationUUID() != "" {
+			return true
+		}
+	}
+	return false
+}
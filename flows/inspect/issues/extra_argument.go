@@ -0,0 +1,79 @@
+package issues
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+func init() {
+	registerType(TypeExtraArgument, ExtraArgumentCheck)
+}
+
+// TypeExtraArgument is our type for an extra argument issue
+const TypeExtraArgument string = "extra_argument"
+
+// ExtraArgument is an enter_flow action which passes an argument that doesn't match any parameter of the flow
+type ExtraArgument struct {
+	baseIssue
+
+	Flow  *assets.FlowReference `json:"flow"`
+	Param string                `json:"param"`
+}
+
+func newExtraArgument(nodeUUID flows.NodeUUID, actionUUID flows.ActionUUID, flow *assets.FlowReference, param string) *ExtraArgument {
+	return &ExtraArgument{
+		baseIssue: newBaseIssue(
+			TypeExtraArgument,
+			nodeUUID,
+			actionUUID,
+			"",
+			fmt.Sprintf("argument '%s' doesn't match any parameter of flow '%s'", param, flow.Name),
+		),
+		Flow:  flow,
+		Param: param,
+	}
+}
+
+// ExtraArgumentCheck checks for enter_flow actions which pass arguments that the flow doesn't declare as parameters
+func ExtraArgumentCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	// skip check if we don't have assets
+	if sa == nil {
+		return
+	}
+
+	for _, node := range flow.Nodes() {
+		for _, a := range node.Actions() {
+			enter, isEnter := a.(*actions.EnterFlowAction)
+			if !isEnter || len(enter.Params) == 0 {
+				continue
+			}
+
+			subflow, err := sa.Flows().Get(enter.Flow.UUID)
+			if err != nil {
+				continue // missing dependency check will report this
+			}
+
+			declared := make(map[string]bool, len(subflow.Params()))
+			for _, param := range subflow.Params() {
+				declared[param.Key] = true
+			}
+
+			// sort argument keys so that issues are reported in a consistent order
+			keys := make([]string, 0, len(enter.Params))
+			for key := range enter.Params {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				if !declared[key] {
+					report(newExtraArgument(node.UUID(), a.UUID(), subflow.Reference(), key))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package issues
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+func init() {
+	registerType(TypeFlowRecursion, FlowRecursionCheck)
+}
+
+// TypeFlowRecursion is our type for a flow recursion issue
+const TypeFlowRecursion string = "flow_recursion"
+
+// FlowRecursion is an enter_flow action which leads back to the flow it's in, either directly or via other flows
+type FlowRecursion struct {
+	baseIssue
+
+	Path []*assets.FlowReference `json:"path"`
+}
+
+func newFlowRecursion(nodeUUID flows.NodeUUID, actionUUID flows.ActionUUID, path []*assets.FlowReference) *FlowRecursion {
+	names := make([]string, len(path))
+	for i := range path {
+		names[i] = fmt.Sprintf("'%s'", path[i].Name)
+	}
+
+	return &FlowRecursion{
+		baseIssue: newBaseIssue(
+			TypeFlowRecursion,
+			nodeUUID,
+			actionUUID,
+			"",
+			fmt.Sprintf("entering flow leads back to this flow: %s", strings.Join(names, " → ")),
+		),
+		Path: path,
+	}
+}
+
+// FlowRecursionCheck checks for enter_flow actions which can lead back to the current flow
+func FlowRecursionCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	// skip check if we don't have assets
+	if sa == nil {
+		return
+	}
+
+	for _, node := range flow.Nodes() {
+		for _, a := range node.Actions() {
+			enter, isEnter := a.(*actions.EnterFlowAction)
+			if !isEnter || enter.Terminal {
+				continue
+			}
+
+			visited := map[assets.FlowUUID]bool{}
+			path := findPathToFlow(sa, enter.Flow, flow.UUID(), visited)
+			if path != nil {
+				report(newFlowRecursion(node.UUID(), a.UUID(), append([]*assets.FlowReference{flow.Reference()}, path...)))
+			}
+		}
+	}
+}
+
+// looks for a path of non-terminal enter_flow actions from the given flow to the target flow
+func findPathToFlow(sa flows.SessionAssets, from *assets.FlowReference, target assets.FlowUUID, visited map[assets.FlowUUID]bool) []*assets.FlowReference {
+	if from.UUID == target {
+		return []*assets.FlowReference{from}
+	}
+	if visited[from.UUID] {
+		return nil
+	}
+	visited[from.UUID] = true
+
+	flow, err := sa.Flows().Get(from.UUID)
+	if err != nil {
+		return nil
+	}
+
+	for _, node := range flow.Nodes() {
+		for _, a := range node.Actions() {
+			enter, isEnter := a.(*actions.EnterFlowAction)
+			if isEnter && !enter.Terminal {
+				if path := findPathToFlow(sa, enter.Flow, target, visited); path != nil {
+					return append([]*assets.FlowReference{flow.Reference()}, path...)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package issues
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+func init() {
+	registerType(TypeInfiniteLoop, InfiniteLoopCheck)
+}
+
+// TypeInfiniteLoop is our type for an infinite loop issue
+const TypeInfiniteLoop string = "infinite_loop"
+
+// InfiniteLoop is a cycle of nodes which contains no wait and can't be exited, so will always hit the limit on the
+// number of steps in a sprint
+type InfiniteLoop struct {
+	baseIssue
+
+	Nodes []flows.NodeUUID `json:"nodes"`
+}
+
+func newInfiniteLoop(nodeUUIDs []flows.NodeUUID) *InfiniteLoop {
+	return &InfiniteLoop{
+		baseIssue: newBaseIssue(
+			TypeInfiniteLoop,
+			nodeUUIDs[0],
+			"",
+			"",
+			fmt.Sprintf("loop of %d node(s) has no wait and no way out", len(nodeUUIDs)),
+		),
+		Nodes: nodeUUIDs,
+	}
+}
+
+// InfiniteLoopCheck checks for cycles in the flow graph which contain no waits and have no exits leading out of them
+func InfiniteLoopCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	// nodes with waits or which enter other flows can pause the session, so we exclude them from the graph
+	canPause := func(n flows.Node) bool {
+		if n.Router() != nil && n.Router().Wait() != nil {
+			return true
+		}
+		for _, a := range n.Actions() {
+			if a.Type() == actions.TypeEnterFlow {
+				return true
+			}
+		}
+		return false
+	}
+
+	successors := make(map[flows.NodeUUID][]flows.NodeUUID, len(flow.Nodes()))
+	for _, n := range flow.Nodes() {
+		if canPause(n) {
+			continue
+		}
+		for _, e := range n.Exits() {
+			dest := flow.GetNode(e.DestinationUUID())
+			if dest != nil && !canPause(dest) {
+				successors[n.UUID()] = append(successors[n.UUID()], dest.UUID())
+			}
+		}
+	}
+
+	for _, component := range stronglyConnected(flow.Nodes(), successors) {
+		if isTrap(flow, component) {
+			report(newInfiniteLoop(component))
+		}
+	}
+}
+
+// checks whether the given set of nodes forms a cycle which can't be left
+func isTrap(flow flows.Flow, component []flows.NodeUUID) bool {
+	members := make(map[flows.NodeUUID]bool, len(component))
+	for _, n := range component {
+		members[n] = true
+	}
+
+	isCycle := len(component) > 1
+	for _, n := range component {
+		for _, e := range flow.GetNode(n).Exits() {
+			if !members[e.DestinationUUID()] {
+				return false
+			}
+			if e.DestinationUUID() == n {
+				isCycle = true
+			}
+		}
+	}
+	return isCycle
+}
+
+// finds the strongly connected components of the given graph using Tarjan's algorithm, with each component's nodes
+// in flow order
+func stronglyConnected(nodes []flows.Node, successors map[flows.NodeUUID][]flows.NodeUUID) [][]flows.NodeUUID {
+	order := make(map[flows.NodeUUID]int, len(nodes))
+	for i, n := range nodes {
+		order[n.UUID()] = i
+	}
+
+	index := make(map[flows.NodeUUID]int)
+	lowLink := make(map[flows.NodeUUID]int)
+	onStack := make(map[flows.NodeUUID]bool)
+	stack := make([]flows.NodeUUID, 0)
+	components := make([][]flows.NodeUUID, 0)
+
+	var visit func(flows.NodeUUID)
+	visit = func(v flows.NodeUUID) {
+		index[v] = len(index)
+		lowLink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range successors[v] {
+			if _, visited := index[w]; !visited {
+				visit(w)
+				if lowLink[w] < lowLink[v] {
+					lowLink[v] = lowLink[w]
+				}
+			} else if onStack[w] && index[w] < lowLink[v] {
+				lowLink[v] = index[w]
+			}
+		}
+
+		if lowLink[v] == index[v] {
+			component := make([]flows.NodeUUID, 0)
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+
+			sort.Slice(component, func(i, j int) bool { return order[component[i]] < order[component[j]] })
+			components = append(components, component)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, visited := index[n.UUID()]; !visited {
+			visit(n.UUID())
+		}
+	}
+	return components
+}
@@ -0,0 +1,66 @@
+package issues
+
+import (
+	"fmt"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/actions"
+)
+
+func init() {
+	registerType(TypeMissingArgument, MissingArgumentCheck)
+}
+
+// TypeMissingArgument is our type for a missing argument issue
+const TypeMissingArgument string = "missing_argument"
+
+// MissingArgument is an enter_flow action which doesn't pass an argument for a required parameter of the flow
+type MissingArgument struct {
+	baseIssue
+
+	Flow  *assets.FlowReference `json:"flow"`
+	Param string                `json:"param"`
+}
+
+func newMissingArgument(nodeUUID flows.NodeUUID, actionUUID flows.ActionUUID, flow *assets.FlowReference, param string) *MissingArgument {
+	return &MissingArgument{
+		baseIssue: newBaseIssue(
+			TypeMissingArgument,
+			nodeUUID,
+			actionUUID,
+			"",
+			fmt.Sprintf("missing argument for required parameter '%s' of flow '%s'", param, flow.Name),
+		),
+		Flow:  flow,
+		Param: param,
+	}
+}
+
+// MissingArgumentCheck checks for enter_flow actions which don't pass arguments for required parameters
+func MissingArgumentCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	// skip check if we don't have assets
+	if sa == nil {
+		return
+	}
+
+	for _, node := range flow.Nodes() {
+		for _, a := range node.Actions() {
+			enter, isEnter := a.(*actions.EnterFlowAction)
+			if !isEnter {
+				continue
+			}
+
+			subflow, err := sa.Flows().Get(enter.Flow.UUID)
+			if err != nil {
+				continue // missing dependency check will report this
+			}
+
+			for _, param := range subflow.Params() {
+				if _, hasArg := enter.Params[param.Key]; param.Required && !hasArg {
+					report(newMissingArgument(node.UUID(), a.UUID(), subflow.Reference(), param.Key))
+				}
+			}
+		}
+	}
+}
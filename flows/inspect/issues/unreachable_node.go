@@ -0,0 +1,59 @@
+package issues
+
+import (
+	"github.com/nyaruka/goflow/flows"
+)
+
+func init() {
+	registerType(TypeUnreachableNode, UnreachableNodeCheck)
+}
+
+// TypeUnreachableNode is our type for an unreachable node issue
+const TypeUnreachableNode string = "unreachable_node"
+
+// UnreachableNode is a node which can't be reached from the entry node of the flow
+type UnreachableNode struct {
+	baseIssue
+}
+
+func newUnreachableNode(nodeUUID flows.NodeUUID) *UnreachableNode {
+	return &UnreachableNode{
+		baseIssue: newBaseIssue(
+			TypeUnreachableNode,
+			nodeUUID,
+			"",
+			"",
+			"node can't be reached from the entry of the flow",
+		),
+	}
+}
+
+// UnreachableNodeCheck checks for nodes which can't be reached from the first node in the flow
+func UnreachableNodeCheck(sa flows.SessionAssets, flow flows.Flow, tpls []flows.ExtractedTemplate, refs []flows.ExtractedReference, report func(flows.Issue)) {
+	nodes := flow.Nodes()
+	if len(nodes) == 0 {
+		return
+	}
+
+	reachable := map[flows.NodeUUID]bool{nodes[0].UUID(): true}
+	queue := []flows.Node{nodes[0]}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, e := range node.Exits() {
+			dest := flow.GetNode(e.DestinationUUID())
+			if dest != nil && !reachable[dest.UUID()] {
+				reachable[dest.UUID()] = true
+				queue = append(queue, dest)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if !reachable[node.UUID()] {
+			report(newUnreachableNode(node.UUID()))
+		}
+	}
+}
@@ -82,6 +82,7 @@ func TestTemplatePaths(t *testing.T) {
 		"$.nodes[*].actions[@.type=\"call_webhook\"].body",
 		"$.nodes[*].actions[@.type=\"call_webhook\"].headers[*]",
 		"$.nodes[*].actions[@.type=\"call_webhook\"].url",
+		"$.nodes[*].actions[@.type=\"enter_flow\"].params[*]",
 		"$.nodes[*].actions[@.type=\"open_ticket\"].assignee.email_match",
 		"$.nodes[*].actions[@.type=\"open_ticket\"].body",
 		"$.nodes[*].actions[@.type=\"play_audio\"].audio_url",
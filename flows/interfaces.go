@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/contactql"
@@ -111,6 +112,7 @@ type SessionAssets interface {
 	Locations() *LocationAssets
 	MsgCatalogs() *MsgCatalogAssets
 	Resthooks() *ResthookAssets
+	Schedules() *ScheduleAssets
 	Templates() *TemplateAssets
 	Ticketers() *TicketerAssets
 	Topics() *TopicAssets
@@ -134,6 +136,8 @@ type Flow interface {
 	Type() FlowType
 	ExpireAfterMinutes() int
 	Localization() Localization
+	Params() []*FlowParam
+	Outputs() []*FlowOutput
 	UI() json.RawMessage
 	Nodes() []Node
 	GetNode(uuid NodeUUID) Node
@@ -171,6 +175,14 @@ type Action interface {
 	Validate() error
 }
 
+// SubflowAction is an action which enters a subflow whose outputs are saved as results on the parent run
+type SubflowAction interface {
+	Action
+
+	Subflow() *assets.FlowReference
+	OutputResultName(*FlowOutput) string
+}
+
 // Category is how routers map results to exits
 type Category interface {
 	Localizable
@@ -192,6 +204,7 @@ type Router interface {
 	AllowTimeout() bool
 	Route(FlowRun, Step, EventCallback) (ExitUUID, string, error)
 	RouteTimeout(FlowRun, Step, EventCallback) (ExitUUID, error)
+	RouteWaitUntil(FlowRun, Step, EventCallback) (ExitUUID, error)
 
 	EnumerateTemplates(Localization, func(envs.Language, string))
 	EnumerateDependencies(Localization, func(envs.Language, assets.Reference))
@@ -227,6 +240,7 @@ type ActivatedWait interface {
 	utils.Typed
 
 	TimeoutSeconds() *int
+	ClearTimeout()
 }
 
 // Hint tells the caller what type of input the flow is expecting
@@ -328,6 +342,9 @@ type Engine interface {
 	ReadSession(SessionAssets, json.RawMessage, assets.MissingCallback) (Session, error)
 
 	Services() Services
+	Clock() dates.NowSource
+	UUIDs() uuids.Generator
+	Random() RandomSource
 	MaxStepsPerSprint() int
 	MaxResumesPerSession() int
 	MaxTemplateChars() int
@@ -371,7 +388,7 @@ type Session interface {
 	Trigger() Trigger
 	CurrentResume() Resume
 	BatchStart() bool
-	PushFlow(Flow, FlowRun, bool)
+	PushFlow(Flow, FlowRun, bool, *types.XObject)
 	Wait() ActivatedWait
 
 	Resume(Resume) (Sprint, error)
@@ -407,12 +424,14 @@ type FlowRun interface {
 	Session() Session
 	SaveResult(*Result)
 	SetStatus(RunStatus)
+	Params() *types.XObject
 	Webhook() types.XValue
 	SetWebhook(types.XValue)
 
 	CreateStep(Node) Step
 	Path() []Step
 	PathLocation() (Step, Node, error)
+	CallbackToken() string
 
 	LogEvent(Step, Event)
 	LogError(Step, error)
@@ -439,6 +458,7 @@ type FlowRun interface {
 	ResetExpiration(*time.Time)
 	ExitedOn() *time.Time
 	Exit(RunStatus)
+	Migrate(Flow, map[NodeUUID]NodeUUID, map[ExitUUID]ExitUUID) []Step
 }
 
 // LegacyExtraContributor is something which contributes results for constructing @legacy_extra
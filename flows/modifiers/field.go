@@ -49,7 +49,7 @@ func (m *FieldModifier) Apply(env envs.Environment, sa flows.SessionAssets, cont
 
 	if !newValue.Equals(oldValue) {
 		contact.Fields().Set(m.field, newValue)
-		log(events.NewContactFieldChanged(m.field, newValue))
+		log(events.NewContactFieldChanged(m.field, newValue, env.SensitiveData()))
 		ReevaluateGroups(env, sa, contact, log)
 	}
 }
@@ -2,7 +2,6 @@ package flows
 
 import (
 	"github.com/nyaruka/gocommon/urns"
-	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
 	"github.com/nyaruka/goflow/envs"
 )
@@ -26,10 +25,10 @@ type MsgCatalog struct {
 	assets.MsgCatalog
 }
 
-func NewMsgCatalogOut(urn urns.URN, channel *assets.ChannelReference, header, body, footer, action, productSearch string, products []string, smart bool, topic MsgTopic, sendCatalog bool) *MsgCatalogOut {
+func NewMsgCatalogOut(uuid MsgUUID, urn urns.URN, channel *assets.ChannelReference, header, body, footer, action, productSearch string, products []string, smart bool, topic MsgTopic, sendCatalog bool) *MsgCatalogOut {
 	return &MsgCatalogOut{
 		BaseMsg: BaseMsg{
-			UUID_:    MsgUUID(uuids.New()),
+			UUID_:    uuid,
 			URN_:     urn,
 			Channel_: channel,
 		},
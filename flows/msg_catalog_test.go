@@ -18,6 +18,7 @@ func TestMsgCatalogOut(t *testing.T) {
 	defer uuids.SetGenerator(uuids.DefaultGenerator)
 
 	msg := flows.NewMsgCatalogOut(
+		flows.MsgUUID(uuids.New()),
 		urns.URN("tel:+1234567890"),
 		assets.NewChannelReference(assets.ChannelUUID("61f38f46-a856-4f90-899e-905691784159"), "My Android"),
 		"header",
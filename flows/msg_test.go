@@ -64,6 +64,7 @@ func TestMsgOut(t *testing.T) {
 	defer uuids.SetGenerator(uuids.DefaultGenerator)
 
 	msg := flows.NewMsgOut(
+		flows.MsgUUID(uuids.New()),
 		urns.URN("tel:+1234567890"),
 		assets.NewChannelReference(assets.ChannelUUID("61f38f46-a856-4f90-899e-905691784159"), "My Android"),
 		"Hi there",
@@ -95,6 +96,7 @@ func TestIVRMsgOut(t *testing.T) {
 	defer uuids.SetGenerator(uuids.DefaultGenerator)
 
 	msg := flows.NewIVRMsgOut(
+		flows.MsgUUID(uuids.New()),
 		urns.URN("tel:+1234567890"),
 		assets.NewChannelReference(assets.ChannelUUID("61f38f46-a856-4f90-899e-905691784159"), "My Android"),
 		"Hi there",
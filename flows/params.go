@@ -0,0 +1,64 @@
+package flows
+
+import (
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+func init() {
+	utils.RegisterValidatorAlias("param_type", "eq=text|eq=number|eq=datetime|eq=any", func(validator.FieldError) string {
+		return "is not a valid parameter type"
+	})
+}
+
+// ParamType is the type of value a flow parameter accepts
+type ParamType string
+
+// possible parameter types
+const (
+	ParamTypeText     ParamType = "text"
+	ParamTypeNumber   ParamType = "number"
+	ParamTypeDatetime ParamType = "datetime"
+	ParamTypeAny      ParamType = "any"
+)
+
+// FlowParam is an input parameter declared by a flow which can be passed by an enter_flow action
+type FlowParam struct {
+	Key      string    `json:"key" validate:"required"`
+	Name     string    `json:"name" validate:"required"`
+	Type     ParamType `json:"type" validate:"required,param_type"`
+	Required bool      `json:"required,omitempty"`
+}
+
+// NewFlowParam creates a new flow parameter
+func NewFlowParam(key, name string, type_ ParamType, required bool) *FlowParam {
+	return &FlowParam{Key: key, Name: name, Type: type_, Required: required}
+}
+
+// Convert converts the given value to the type of this parameter
+func (p *FlowParam) Convert(env envs.Environment, value types.XValue) (types.XValue, types.XError) {
+	switch p.Type {
+	case ParamTypeText:
+		return types.ToXText(env, value)
+	case ParamTypeNumber:
+		return types.ToXNumber(env, value)
+	case ParamTypeDatetime:
+		return types.ToXDateTime(env, value)
+	}
+	return value, nil
+}
+
+// FlowOutput is an output value declared by a flow which is returned to the parent run when the flow completes
+type FlowOutput struct {
+	Key   string `json:"key" validate:"required"`
+	Name  string `json:"name" validate:"required"`
+	Value string `json:"value" engine:"evaluated"`
+}
+
+// NewFlowOutput creates a new flow output
+func NewFlowOutput(key, name, value string) *FlowOutput {
+	return &FlowOutput{Key: key, Name: name, Value: value}
+}
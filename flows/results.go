@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/utils"
@@ -53,6 +54,10 @@ func NewResult(name string, value string, category string, categoryLocalized str
 //
 // @context result
 func (r *Result) Context(env envs.Environment) map[string]types.XValue {
+	if env.MaskSensitiveData() {
+		r = r.Redacted(env.SensitiveData())
+	}
+
 	categoryLocalized := r.CategoryLocalized
 	if categoryLocalized == "" {
 		categoryLocalized = r.Category
@@ -74,6 +79,36 @@ func (r *Result) Context(env envs.Environment) map[string]types.XValue {
 	}
 }
 
+// Redacted returns a copy of this result with its values masked if it's sensitive, and sensitive webhook data in its
+// extra masked, or this result if nothing in it is sensitive
+func (r *Result) Redacted(sensitive *envs.SensitiveData) *Result {
+	if sensitive.IsResult(r.Name) {
+		redacted := *r
+		redacted.Value = RedactionMask
+		redacted.Category = redactIfSet(r.Category)
+		redacted.CategoryLocalized = redactIfSet(r.CategoryLocalized)
+		redacted.Input = redactIfSet(r.Input)
+		if len(r.Extra) > 0 {
+			redacted.Extra = jsonx.MustMarshal(RedactionMask)
+		}
+		return &redacted
+	}
+
+	if redact := sensitive.BodyRedactor(RedactionMask); redact != nil && len(r.Extra) > 0 {
+		redacted := *r
+		redacted.Extra = json.RawMessage(redact(string(r.Extra)))
+		return &redacted
+	}
+	return r
+}
+
+func redactIfSet(s string) string {
+	if s != "" {
+		return RedactionMask
+	}
+	return s
+}
+
 // Results is our wrapper around a map of snakified result names to result objects
 type Results map[string]*Result
 
@@ -104,7 +139,7 @@ func (r Results) Get(key string) *Result {
 // Context returns the properties available in expressions
 func (r Results) Context(env envs.Environment) map[string]types.XValue {
 	entries := make(map[string]types.XValue, len(r)+1)
-	entries["__default__"] = types.NewXText(r.format())
+	entries["__default__"] = types.NewXText(r.format(env))
 
 	for k, v := range r {
 		entries[k] = Context(env, v)
@@ -112,9 +147,12 @@ func (r Results) Context(env envs.Environment) map[string]types.XValue {
 	return entries
 }
 
-func (r Results) format() string {
+func (r Results) format(env envs.Environment) string {
 	lines := make([]string, 0, len(r))
 	for _, v := range r {
+		if env.MaskSensitiveData() {
+			v = v.Redacted(env.SensitiveData())
+		}
 		lines = append(lines, fmt.Sprintf("%s: %s", v.Name, v.Value))
 	}
 
@@ -84,14 +84,16 @@ func (r *baseResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) {
 
 // Context is the schema of trigger objects in the context, across all types
 type Context struct {
-	type_ string
-	dial  types.XValue
+	type_    string
+	dial     types.XValue
+	callback types.XValue
 }
 
 func (c *Context) asMap() map[string]types.XValue {
 	return map[string]types.XValue{
-		"type": types.NewXText(c.type_),
-		"dial": c.dial,
+		"type":     types.NewXText(c.type_),
+		"dial":     c.dial,
+		"callback": c.callback,
 	}
 }
 
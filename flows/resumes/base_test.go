@@ -173,8 +173,9 @@ func TestResumeContext(t *testing.T) {
 	)
 
 	assert.Equal(t, map[string]types.XValue{
-		"type": types.NewXText("msg"),
-		"dial": nil,
+		"type":     types.NewXText("msg"),
+		"dial":     nil,
+		"callback": nil,
 	}, resume.Context(env))
 
 	resume = resumes.NewDial(env, nil, flows.NewDial(flows.DialStatusNoAnswer, 5))
@@ -182,4 +183,13 @@ func TestResumeContext(t *testing.T) {
 
 	assert.Equal(t, types.NewXText("dial"), context["type"])
 	assert.NotNil(t, context["dial"])
+
+	resume = resumes.NewCallback(env, nil, "9688d21d-95aa-4bed-afc7-f31b35731a3d", []byte(`{"status": "paid"}`))
+	context = resume.Context(env)
+
+	assert.Equal(t, types.NewXText("callback"), context["type"])
+	test.AssertXEqual(t, types.NewXObject(map[string]types.XValue{
+		"token":   types.NewXText("9688d21d-95aa-4bed-afc7-f31b35731a3d"),
+		"payload": types.NewXObject(map[string]types.XValue{"status": types.NewXText("paid")}),
+	}), context["callback"])
 }
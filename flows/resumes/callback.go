@@ -0,0 +1,114 @@
+package resumes
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	registerType(TypeCallback, readCallbackResume)
+}
+
+// TypeCallback is the type for callback resumes
+const TypeCallback string = "callback"
+
+// CallbackResume is used when a session waiting on a callback wait is resumed by an external system. The token
+// must match the token of the wait, and the payload can be any JSON.
+//
+//   {
+//     "type": "callback",
+//     "resumed_on": "2021-01-20T12:18:30Z",
+//     "token": "8720f157-ca1c-432f-9c0b-2014ddc77094",
+//     "payload": {"status": "paid", "amount": 25}
+//   }
+//
+// @resume callback
+type CallbackResume struct {
+	baseResume
+
+	token   string
+	payload json.RawMessage
+}
+
+// NewCallback creates a new callback resume
+func NewCallback(env envs.Environment, contact *flows.Contact, token string, payload json.RawMessage) *CallbackResume {
+	return &CallbackResume{
+		baseResume: newBaseResume(TypeCallback, env, contact),
+		token:      token,
+		payload:    payload,
+	}
+}
+
+// Token returns the token of the wait being resumed
+func (r *CallbackResume) Token() string { return r.token }
+
+// Payload returns the JSON payload of the callback
+func (r *CallbackResume) Payload() json.RawMessage { return r.payload }
+
+// Apply applies our state changes and saves any events to the run
+func (r *CallbackResume) Apply(run flows.FlowRun, logEvent flows.EventCallback) {
+	logEvent(events.NewCallbackReceived(r.token, r.payload))
+
+	r.baseResume.Apply(run, logEvent)
+}
+
+// Context for callback resumes additionally exposes the callback token and payload
+func (r *CallbackResume) Context(env envs.Environment) map[string]types.XValue {
+	var payload types.XValue
+	if len(r.payload) > 0 {
+		payload = types.JSONToXValue(r.payload)
+	}
+
+	c := r.context()
+	c.callback = types.NewXObject(map[string]types.XValue{
+		"token":   types.NewXText(r.token),
+		"payload": payload,
+	})
+	return c.asMap()
+}
+
+var _ flows.Resume = (*CallbackResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type callbackResumeEnvelope struct {
+	baseResumeEnvelope
+
+	Token   string          `json:"token"             validate:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func readCallbackResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &callbackResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &CallbackResume{token: e.Token, payload: e.Payload}
+
+	if err := r.unmarshal(sessionAssets, &e.baseResumeEnvelope, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *CallbackResume) MarshalJSON() ([]byte, error) {
+	e := &callbackResumeEnvelope{Token: r.token, Payload: r.payload}
+
+	if err := r.marshal(&e.baseResumeEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
@@ -0,0 +1,78 @@
+package resumes
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	registerType(TypeWaitUntil, readWaitUntilResume)
+}
+
+// TypeWaitUntil is the type for resuming a session when the time a wait was waiting until has been reached
+const TypeWaitUntil string = "wait_until"
+
+// WaitUntilResume is used when a session is resumed because the time that an until wait was waiting for has
+// been reached.
+//
+//   {
+//     "type": "wait_until",
+//     "contact": {
+//       "uuid": "9f7ede93-4b16-4692-80ad-b7dc54a1cd81",
+//       "name": "Bob",
+//       "created_on": "2018-01-01T12:00:00.000000Z",
+//       "language": "fra",
+//       "fields": {"gender": {"text": "Male"}},
+//       "groups": []
+//     },
+//     "resumed_on": "2000-01-01T00:00:00.000000000-00:00"
+//   }
+//
+// @resume wait_until
+type WaitUntilResume struct {
+	baseResume
+}
+
+// NewWaitUntil creates a new wait until resume with the passed in values
+func NewWaitUntil(env envs.Environment, contact *flows.Contact) *WaitUntilResume {
+	return &WaitUntilResume{
+		baseResume: newBaseResume(TypeWaitUntil, env, contact),
+	}
+}
+
+var _ flows.Resume = (*WaitUntilResume)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+func readWaitUntilResume(sessionAssets flows.SessionAssets, data json.RawMessage, missing assets.MissingCallback) (flows.Resume, error) {
+	e := &baseResumeEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &WaitUntilResume{}
+
+	if err := r.unmarshal(sessionAssets, e, missing); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this resume into JSON
+func (r *WaitUntilResume) MarshalJSON() ([]byte, error) {
+	e := &baseResumeEnvelope{}
+
+	if err := r.marshal(e); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
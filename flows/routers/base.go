@@ -12,6 +12,7 @@ import (
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
 	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
 	"github.com/nyaruka/goflow/flows/routers/waits"
 	"github.com/nyaruka/goflow/utils"
 
@@ -109,6 +110,11 @@ func (r *baseRouter) validate(flow flows.Flow, exits []flows.Exit) error {
 		}
 	}
 
+	// check wait until category is valid
+	if until, isUntil := r.wait.(*waits.UntilWait); isUntil && !r.isValidCategory(until.CategoryUUID()) {
+		return errors.Errorf("wait until category %s is not a valid category", until.CategoryUUID())
+	}
+
 	if r.wait != nil && !flow.Type().Allows(r.wait) {
 		return errors.Errorf("wait type '%s' is not allowed in a flow of type '%s'", r.wait.Type(), flow.Type())
 	}
@@ -155,6 +161,18 @@ func (r *baseRouter) RouteTimeout(run flows.FlowRun, step flows.Step, logEvent f
 	return r.routeToCategory(run, step, r.wait.Timeout().CategoryUUID(), dates.FormatISO(timedOutOn), "", nil, logEvent)
 }
 
+// RouteWaitUntil routes in the case that this router's wait was resumed because the time it was waiting until was reached
+func (r *baseRouter) RouteWaitUntil(run flows.FlowRun, step flows.Step, logEvent flows.EventCallback) (flows.ExitUUID, error) {
+	until, isUntil := r.wait.(*waits.UntilWait)
+	if !isUntil {
+		return "", errors.New("can't call route wait until on router without a wait until")
+	}
+
+	resumedOn := run.Session().CurrentResume().ResumedOn()
+
+	return r.routeToCategory(run, step, until.CategoryUUID(), dates.FormatISO(resumedOn), "", nil, logEvent)
+}
+
 func (r *baseRouter) routeToCategory(run flows.FlowRun, step flows.Step, categoryUUID flows.CategoryUUID, match string, operand string, extra *types.XObject, logEvent flows.EventCallback) (flows.ExitUUID, error) {
 	// router failed to pick a category
 	if categoryUUID == "" {
@@ -174,6 +192,12 @@ func (r *baseRouter) routeToCategory(run flows.FlowRun, step flows.Step, categor
 		return "", errors.Errorf("category %s is not a valid category", categoryUUID)
 	}
 
+	// if this is the resume of a callback wait, the callback payload is saved as the extra of the result, along with
+	// any extra from the matching case which takes precedence
+	if payload := r.callbackPayload(run); payload != nil {
+		extra = mergeExtras(payload, extra)
+	}
+
 	// save result if we have a result name
 	if r.resultName != "" {
 		// localize the category name
@@ -183,23 +207,51 @@ func (r *baseRouter) routeToCategory(run flows.FlowRun, step flows.Step, categor
 		if extra != nil {
 			extraJSON, _ = jsonx.Marshal(extra)
 		}
-		result := flows.NewResult(r.resultName, match, category.Name(), localizedCategory, step.NodeUUID(), operand, extraJSON, dates.Now())
+		result := flows.NewResult(r.resultName, match, category.Name(), localizedCategory, step.NodeUUID(), operand, extraJSON, run.Session().Engine().Clock().Now())
 		run.SaveResult(result)
-		logEvent(events.NewRunResultChanged(result))
+		logEvent(events.NewRunResultChanged(result, run.Environment().SensitiveData()))
 	}
 
 	return category.ExitUUID(), nil
 }
 
+// gets the payload of the callback resuming this router's wait, if it has a callback wait and it's an object
+func (r *baseRouter) callbackPayload(run flows.FlowRun) *types.XObject {
+	if _, isCallback := r.wait.(*waits.CallbackWait); !isCallback {
+		return nil
+	}
+
+	callback, isCallback := run.Session().CurrentResume().(*resumes.CallbackResume)
+	if !isCallback || len(callback.Payload()) == 0 {
+		return nil
+	}
+
+	payload, _ := types.JSONToXValue(callback.Payload()).(*types.XObject)
+	return payload
+}
+
+// merges the properties of the given extras, with those of the later extras replacing those of the earlier ones
+func mergeExtras(extras ...*types.XObject) *types.XObject {
+	merged := make(map[string]types.XValue)
+	for _, extra := range extras {
+		if extra != nil {
+			for _, key := range extra.Properties() {
+				merged[key], _ = extra.Get(key)
+			}
+		}
+	}
+	return types.NewXObject(merged)
+}
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
 
 type baseRouterEnvelope struct {
 	Type       string            `json:"type"                  validate:"required"`
-	Wait       json.RawMessage   `json:"wait,omitempty"`
+	Wait       json.RawMessage   `json:"wait,omitempty"                             jsonschema:"wait"`
 	ResultName string            `json:"result_name,omitempty"`
-	Categories []json.RawMessage `json:"categories,omitempty"  validate:"required,min=1"`
+	Categories []json.RawMessage `json:"categories,omitempty"  validate:"required,min=1" jsonschema:"category"`
 }
 
 // ReadRouter reads a router from the given JSON
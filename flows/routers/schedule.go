@@ -0,0 +1,137 @@
+package routers
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeSchedule, readScheduleRouter)
+}
+
+// TypeSchedule is the type for a schedule router
+const TypeSchedule string = "schedule"
+
+// ScheduleRouter is a router which picks a category based on whether a schedule is currently open
+type ScheduleRouter struct {
+	baseRouter
+
+	schedule           *assets.ScheduleReference
+	openCategoryUUID   flows.CategoryUUID
+	closedCategoryUUID flows.CategoryUUID
+}
+
+// NewSchedule creates a new schedule router
+func NewSchedule(wait flows.Wait, resultName string, categories []flows.Category, schedule *assets.ScheduleReference, openCategoryUUID, closedCategoryUUID flows.CategoryUUID) *ScheduleRouter {
+	return &ScheduleRouter{
+		baseRouter:         newBaseRouter(TypeSchedule, wait, resultName, categories),
+		schedule:           schedule,
+		openCategoryUUID:   openCategoryUUID,
+		closedCategoryUUID: closedCategoryUUID,
+	}
+}
+
+// Schedule returns the reference to the schedule of this router
+func (r *ScheduleRouter) Schedule() *assets.ScheduleReference { return r.schedule }
+
+// Validate validates the arguments for this router
+func (r *ScheduleRouter) Validate(flow flows.Flow, exits []flows.Exit) error {
+	if !r.isValidCategory(r.openCategoryUUID) {
+		return errors.Errorf("open category %s is not a valid category", r.openCategoryUUID)
+	}
+	if !r.isValidCategory(r.closedCategoryUUID) {
+		return errors.Errorf("closed category %s is not a valid category", r.closedCategoryUUID)
+	}
+
+	return r.validate(flow, exits)
+}
+
+// Route determines which exit to take from a node
+func (r *ScheduleRouter) Route(run flows.FlowRun, step flows.Step, logEvent flows.EventCallback) (flows.ExitUUID, string, error) {
+	env := run.Environment()
+	now := env.Now()
+	operand := dates.FormatISO(now)
+
+	// if the schedule is missing, we treat it as closed
+	schedule := run.Session().Assets().Schedules().Get(r.schedule.UUID)
+	if schedule == nil {
+		logEvent(events.NewDependencyError(r.schedule))
+
+		exit, err := r.routeToCategory(run, step, r.closedCategoryUUID, "closed", operand, nil, logEvent)
+		return exit, operand, err
+	}
+
+	if schedule.IsOpen(env, now) {
+		exit, err := r.routeToCategory(run, step, r.openCategoryUUID, "open", operand, nil, logEvent)
+		return exit, operand, err
+	}
+
+	var extra *types.XObject
+	if nextOpen := schedule.NextOpen(env, now); nextOpen != nil {
+		extra = types.NewXObject(map[string]types.XValue{"next_open": types.NewXDateTime(nextOpen.In(env.Timezone()))})
+	}
+
+	exit, err := r.routeToCategory(run, step, r.closedCategoryUUID, "closed", operand, extra, logEvent)
+	return exit, operand, err
+}
+
+// EnumerateDependencies enumerates all dependencies on this object and its children
+func (r *ScheduleRouter) EnumerateDependencies(localization flows.Localization, include func(envs.Language, assets.Reference)) {
+	include(envs.NilLanguage, r.schedule)
+}
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type scheduleRouterEnvelope struct {
+	baseRouterEnvelope
+
+	Schedule           *assets.ScheduleReference `json:"schedule"             validate:"required"`
+	OpenCategoryUUID   flows.CategoryUUID        `json:"open_category_uuid"   validate:"required,uuid4"`
+	ClosedCategoryUUID flows.CategoryUUID        `json:"closed_category_uuid" validate:"required,uuid4"`
+}
+
+func readScheduleRouter(data json.RawMessage) (flows.Router, error) {
+	e := &scheduleRouterEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	r := &ScheduleRouter{
+		schedule:           e.Schedule,
+		openCategoryUUID:   e.OpenCategoryUUID,
+		closedCategoryUUID: e.ClosedCategoryUUID,
+	}
+
+	if err := r.unmarshal(&e.baseRouterEnvelope); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// MarshalJSON marshals this router into JSON
+func (r *ScheduleRouter) MarshalJSON() ([]byte, error) {
+	e := &scheduleRouterEnvelope{
+		Schedule:           r.schedule,
+		OpenCategoryUUID:   r.openCategoryUUID,
+		ClosedCategoryUUID: r.closedCategoryUUID,
+	}
+
+	if err := r.marshal(&e.baseRouterEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
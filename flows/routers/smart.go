@@ -75,6 +75,9 @@ func NewSmart(wait flows.Wait, resultName string, categories []flows.Category, o
 	}
 }
 
+// Operand returns the template which is evaluated and classified by this smart router
+func (r *SmartRouter) Operand() string { return r.operand }
+
 // SmartCases returns the cases for this smart router
 func (r *SmartRouter) SmartCases() []*SmartCase { return r.cases }
 
@@ -256,11 +259,11 @@ func (r *SmartRouter) classifyText(run flows.FlowRun, step flows.Step, operand s
 
 	if trace.Response.StatusCode >= 400 {
 		status = flows.CallStatusConnectionError
-		logEvent(events.NewWebhookCalled(call, status, ""))
+		logEvent(events.NewWebhookCalled(call, status, "", run.Environment().SensitiveData()))
 		return "", "", fmt.Errorf("error: status code equals '%d' and not 200", trace.Response.StatusCode)
 	}
 
-	logEvent(events.NewWebhookCalled(call, status, ""))
+	logEvent(events.NewWebhookCalled(call, status, "", run.Environment().SensitiveData()))
 
 	err = jsonx.Unmarshal(trace.ResponseBody, response)
 	if err != nil {
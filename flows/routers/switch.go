@@ -91,6 +91,9 @@ func NewSwitch(wait flows.Wait, resultName string, categories []flows.Category,
 	}
 }
 
+// Operand returns the template which is evaluated and matched against the cases of this switch router
+func (r *SwitchRouter) Operand() string { return r.operand }
+
 // Cases returns the cases for this switch router
 func (r *SwitchRouter) Cases() []*Case { return r.cases }
 
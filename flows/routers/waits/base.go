@@ -64,6 +64,8 @@ func (w *baseActivatedWait) Type() string { return w.type_ }
 
 func (w *baseActivatedWait) TimeoutSeconds() *int { return w.timeoutSeconds }
 
+func (w *baseActivatedWait) ClearTimeout() { w.timeoutSeconds = nil }
+
 //------------------------------------------------------------------------------------------
 // JSON Encoding / Decoding
 //------------------------------------------------------------------------------------------
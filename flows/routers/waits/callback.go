@@ -0,0 +1,141 @@
+package waits
+
+import (
+	"encoding/json"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeCallback, readCallbackWait, readActivatedCallbackWait)
+}
+
+// TypeCallback is the type of our callback wait
+const TypeCallback string = "callback"
+
+// CallbackWait is a wait which waits for a callback from an external system, e.g. a payment confirmation. It must be
+// resumed with a secret token which is generated for the step where the flow is waiting, and can be accessed by
+// actions on the same node as @node.callback_token, e.g. to include in a webhook call.
+type CallbackWait struct {
+	baseWait
+}
+
+// NewCallbackWait creates a new callback wait
+func NewCallbackWait(timeout *Timeout) *CallbackWait {
+	return &CallbackWait{baseWait: newBaseWait(TypeCallback, timeout)}
+}
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *CallbackWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeMessagingOffline}
+}
+
+// Begin beings waiting at this wait
+func (w *CallbackWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.ActivatedWait {
+	var timeoutSeconds *int
+
+	if w.timeout != nil {
+		seconds := w.timeout.Seconds()
+		timeoutSeconds = &seconds
+	}
+
+	log(events.NewCallbackWait(timeoutSeconds))
+
+	return NewActivatedCallbackWait(run.CallbackToken(), timeoutSeconds)
+}
+
+// End ends this wait or returns an error
+func (w *CallbackWait) End(resume flows.Resume) error {
+	switch resume.Type() {
+	case resumes.TypeCallback, resumes.TypeRunExpiration:
+		return nil
+	case resumes.TypeWaitTimeout:
+		if w.timeout == nil {
+			return errors.Errorf("can't end with timeout as wait doesn't have a timeout")
+		}
+		return nil
+	}
+	return w.resumeTypeError(resume)
+}
+
+var _ flows.Wait = (*CallbackWait)(nil)
+
+// ActivatedCallbackWait is a callback wait which has begun and has the secret token which must be used to resume it
+type ActivatedCallbackWait struct {
+	baseActivatedWait
+
+	token string
+}
+
+// NewActivatedCallbackWait creates a new activated callback wait
+func NewActivatedCallbackWait(token string, timeoutSeconds *int) *ActivatedCallbackWait {
+	return &ActivatedCallbackWait{
+		baseActivatedWait: baseActivatedWait{type_: TypeCallback, timeoutSeconds: timeoutSeconds},
+		token:             token,
+	}
+}
+
+// Token returns the token which must be used to resume this wait
+func (w *ActivatedCallbackWait) Token() string { return w.token }
+
+var _ flows.ActivatedWait = (*ActivatedCallbackWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+func readCallbackWait(data json.RawMessage) (flows.Wait, error) {
+	e := &baseWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &CallbackWait{}
+
+	return w, w.unmarshal(e)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *CallbackWait) MarshalJSON() ([]byte, error) {
+	e := &baseWaitEnvelope{}
+
+	if err := w.marshal(e); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
+
+type activatedCallbackWaitEnvelope struct {
+	baseActivatedWaitEnvelope
+
+	Token string `json:"token" validate:"required"`
+}
+
+func readActivatedCallbackWait(data json.RawMessage) (flows.ActivatedWait, error) {
+	e := &activatedCallbackWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &ActivatedCallbackWait{token: e.Token}
+
+	return w, w.unmarshal(&e.baseActivatedWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *ActivatedCallbackWait) MarshalJSON() ([]byte, error) {
+	e := &activatedCallbackWaitEnvelope{Token: w.token}
+
+	if err := w.marshal(&e.baseActivatedWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
@@ -0,0 +1,225 @@
+package waits_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers/waits"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var callbackWaitJSON = `{
+	"flows": [
+		{
+			"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4",
+			"name": "Payment",
+			"spec_version": "13.0",
+			"language": "eng",
+			"type": "messaging",
+			"nodes": [
+				{
+					"uuid": "46d51f50-58de-49da-8d13-dadbf322685d",
+					"actions": [
+						{
+							"uuid": "e97cd6d5-3354-4dbd-85bc-6c1f87849eec",
+							"type": "send_msg",
+							"text": "Please pay at https://pay.example.com/?ref=@node.callback_token"
+						}
+					],
+					"router": {
+						"type": "switch",
+						"wait": {
+							"type": "callback",
+							"timeout": {
+								"seconds": 3600,
+								"category_uuid": "78ae8f05-f92e-43b2-a886-406eaea1b8e0"
+							}
+						},
+						"result_name": "Payment",
+						"categories": [
+							{
+								"uuid": "c82e161f-fa2d-4e7d-a338-c27f6c349445",
+								"name": "Paid",
+								"exit_uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"
+							},
+							{
+								"uuid": "2a1e0a12-4a8a-4c6e-9e6c-3b0fe6a5d5a4",
+								"name": "Other",
+								"exit_uuid": "c70fe86c-9aac-4cc2-a5cb-d35cbe3fed6e"
+							},
+							{
+								"uuid": "78ae8f05-f92e-43b2-a886-406eaea1b8e0",
+								"name": "No Callback",
+								"exit_uuid": "b787ffe3-c21a-46ad-9475-954614b52477"
+							}
+						],
+						"operand": "@resume.callback.payload.status",
+						"cases": [
+							{
+								"uuid": "98503572-25bf-40ce-ad72-8836b6549a38",
+								"type": "has_only_text",
+								"arguments": ["paid"],
+								"category_uuid": "c82e161f-fa2d-4e7d-a338-c27f6c349445"
+							},
+							{
+								"uuid": "0e8f6bd6-5a47-4d8e-9b8e-3c8f8f2c1c7e",
+								"type": "has_pattern",
+								"arguments": ["^refund(ed)?$"],
+								"category_uuid": "2a1e0a12-4a8a-4c6e-9e6c-3b0fe6a5d5a4"
+							}
+						],
+						"default_category_uuid": "2a1e0a12-4a8a-4c6e-9e6c-3b0fe6a5d5a4"
+					},
+					"exits": [
+						{"uuid": "598ae7a5-2f81-48f1-afac-595262514aa1"},
+						{"uuid": "c70fe86c-9aac-4cc2-a5cb-d35cbe3fed6e"},
+						{"uuid": "b787ffe3-c21a-46ad-9475-954614b52477"}
+					]
+				}
+			]
+		}
+	]
+}`
+
+func TestCallbackWait(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+	run := session.Runs()[0]
+
+	wait, err := waits.ReadWait([]byte(`{"type": "callback", "timeout": {"seconds": 3600, "category_uuid": "63fca57d-5ef6-4afd-9bcd-7bdcf653cea8"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, waits.TypeCallback, wait.Type())
+
+	// test marsalling definition wait
+	marshaled, err := jsonx.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"callback","timeout":{"seconds":3600,"category_uuid":"63fca57d-5ef6-4afd-9bcd-7bdcf653cea8"}}`, string(marshaled))
+
+	// try activating the wait - token is the run's token for the current step, which isn't its UUID
+	step, _, _ := run.PathLocation()
+	token := run.CallbackToken()
+	assert.NotEqual(t, "", token)
+	assert.NotEqual(t, string(step.UUID()), token)
+
+	log := test.NewEventLog()
+	activated := wait.Begin(run, log.Log)
+
+	assert.Equal(t, "callback", activated.Type())
+	assert.Equal(t, 3600, *activated.TimeoutSeconds())
+	assert.Equal(t, token, activated.(*waits.ActivatedCallbackWait).Token())
+	assert.Equal(t, 1, len(log.Events))
+	assert.Equal(t, "callback_wait", log.Events[0].Type())
+
+	// and the token isn't in the event
+	assert.NotContains(t, string(jsonx.MustMarshal(log.Events[0])), token)
+
+	// test marsalling activated wait
+	marshaled, err = jsonx.Marshal(activated)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"callback","timeout_seconds":3600,"token":"`+token+`"}`, string(marshaled))
+
+	// try to end with incorrect resume type
+	err = wait.End(resumes.NewDial(nil, nil, flows.NewDial(flows.DialStatusBusy, 0)))
+	assert.EqualError(t, err, "can't end a wait of type 'callback' with a resume of type 'dial'")
+
+	// try to end with callback and timeout resume types
+	assert.NoError(t, wait.End(resumes.NewCallback(nil, nil, "1234", nil)))
+	assert.NoError(t, wait.End(resumes.NewWaitTimeout(nil, nil)))
+
+	// timeout resume not allowed if wait doesn't have a timeout
+	err = waits.NewCallbackWait(nil).End(resumes.NewWaitTimeout(nil, nil))
+	assert.EqualError(t, err, "can't end with timeout as wait doesn't have a timeout")
+}
+
+func TestCallbackWaitInFlow(t *testing.T) {
+	startSession := func() (flows.Session, string) {
+		session, sprint := test.NewSessionBuilder().WithAssets([]byte(callbackWaitJSON)).
+			WithFlow("615b8a0f-588c-4d20-a05f-363b0b4ce6f4").
+			MustBuild()
+
+		require.Equal(t, flows.SessionStatusWaiting, session.Status())
+		require.Equal(t, 2, len(sprint.Events()))
+
+		// token is available to actions on the same node
+		token := session.Wait().(*waits.ActivatedCallbackWait).Token()
+		assert.Equal(t, "Please pay at https://pay.example.com/?ref="+token, sprint.Events()[0].(*events.MsgCreatedEvent).Msg.Text())
+		assert.NotContains(t, string(jsonx.MustMarshal(sprint.Events()[1])), token)
+
+		// and isn't the UUID of any step, which are visible in events
+		for _, s := range session.Runs()[0].Path() {
+			assert.NotEqual(t, string(s.UUID()), token)
+		}
+
+		return session, token
+	}
+
+	// resume with a payload that the router can branch on
+	session, token := startSession()
+	sprint, err := session.Resume(resumes.NewCallback(nil, nil, token, []byte(`{"status": "paid", "amount": 25}`)))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "callback_received", sprint.Events()[0].Type())
+
+	result := session.Runs()[0].Results().Get("payment")
+	assert.Equal(t, "Paid", result.Category)
+	assert.Equal(t, "paid", result.Value)
+	assert.JSONEq(t, `{"amount": 25, "status": "paid"}`, string(result.Extra))
+
+	// the token for the waiting step is kept when the session is written and read back
+	session, token = startSession()
+	session, err = session.Engine().ReadSession(session.Assets(), jsonx.MustMarshal(session), assets.PanicOnMissing)
+	require.NoError(t, err)
+
+	assert.Equal(t, token, session.Wait().(*waits.ActivatedCallbackWait).Token())
+	assert.Equal(t, token, session.Runs()[0].CallbackToken())
+
+	_, err = session.Resume(resumes.NewCallback(nil, nil, token, []byte(`{"status": "paid"}`)))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "Paid", session.Runs()[0].Results().Get("payment").Category)
+
+	// resume with a payload which doesn't match
+	session, token = startSession()
+	_, err = session.Resume(resumes.NewCallback(nil, nil, token, []byte(`{"status": "failed"}`)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Other", session.Runs()[0].Results().Get("payment").Category)
+
+	// resume with a payload which matches a case with its own extra, which is merged with the payload
+	session, token = startSession()
+	_, err = session.Resume(resumes.NewCallback(nil, nil, token, []byte(`{"status": "refunded", "amount": 25}`)))
+	require.NoError(t, err)
+
+	result = session.Runs()[0].Results().Get("payment")
+	assert.Equal(t, "Other", result.Category)
+	assert.Equal(t, "refunded", result.Value)
+	assert.JSONEq(t, `{"0": "refunded", "1": "ed", "amount": 25, "status": "refunded"}`, string(result.Extra))
+
+	// resume with the wrong token
+	session, _ = startSession()
+	sprint, err = session.Resume(resumes.NewCallback(nil, nil, "ed9fa9b9-2e36-4b65-9a5d-9c8e1a7c7b6b", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusWaiting, session.Status())
+	assert.Equal(t, 1, len(sprint.Events()))
+	assert.Equal(t, "error", sprint.Events()[0].Type())
+	assert.NotContains(t, sprint.Events()[0].(*events.ErrorEvent).Text, token)
+
+	// resume with a timeout
+	session, _ = startSession()
+	_, err = session.Resume(resumes.NewWaitTimeout(nil, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, flows.SessionStatusCompleted, session.Status())
+	assert.Equal(t, "No Callback", session.Runs()[0].Results().Get("payment").Category)
+}
@@ -16,6 +16,11 @@ func registerType(name string, initFunc func() flows.Hint) {
 	registeredTypes[name] = initFunc
 }
 
+// RegisteredTypes gets the registered types of hint
+func RegisteredTypes() map[string](func() flows.Hint) {
+	return registeredTypes
+}
+
 // the base of all hint types
 type baseHint struct {
 	Type_ string `json:"type" validate:"required"`
@@ -110,7 +110,7 @@ var _ flows.ActivatedWait = (*ActivatedMsgWait)(nil)
 type msgWaitEnvelope struct {
 	baseWaitEnvelope
 
-	Hint json.RawMessage `json:"hint,omitempty"`
+	Hint json.RawMessage `json:"hint,omitempty" jsonschema:"hint"`
 }
 
 func readMsgWait(data json.RawMessage) (flows.Wait, error) {
@@ -0,0 +1,166 @@
+package waits
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	registerType(TypeUntil, readUntilWait, readActivatedUntilWait)
+}
+
+// TypeUntil is the type of our until wait
+const TypeUntil string = "until"
+
+// UntilWait is a wait which pauses the flow until a date and time given by an expression which is evaluated when
+// the wait begins. Dates without a time, e.g. a contact field, are taken as midnight in the contact's timezone.
+type UntilWait struct {
+	baseWait
+
+	until        string
+	categoryUUID flows.CategoryUUID
+}
+
+// NewUntilWait creates a new until wait
+func NewUntilWait(until string, categoryUUID flows.CategoryUUID) *UntilWait {
+	return &UntilWait{
+		baseWait:     newBaseWait(TypeUntil, nil),
+		until:        until,
+		categoryUUID: categoryUUID,
+	}
+}
+
+// Until returns the expression which gives the date and time to wait until
+func (w *UntilWait) Until() string { return w.until }
+
+// CategoryUUID returns the category to route to when the wait is resumed
+func (w *UntilWait) CategoryUUID() flows.CategoryUUID { return w.categoryUUID }
+
+// AllowedFlowTypes returns the flow types which this wait is allowed to occur in
+func (w *UntilWait) AllowedFlowTypes() []flows.FlowType {
+	return []flows.FlowType{flows.FlowTypeMessaging, flows.FlowTypeMessagingOffline}
+}
+
+// Begin beings waiting at this wait
+func (w *UntilWait) Begin(run flows.FlowRun, log flows.EventCallback) flows.ActivatedWait {
+	env := run.Environment()
+
+	value, err := run.EvaluateTemplateValue(w.until)
+	if err != nil {
+		log(events.NewError(err))
+		return nil
+	}
+
+	asDateTime, xerr := types.ToXDateTime(env, value)
+	if xerr != nil {
+		log(events.NewError(errors.Wrapf(xerr, "unable to evaluate wait until time")))
+		return nil
+	}
+
+	resumeOn := asDateTime.Native()
+
+	log(events.NewUntilWait(resumeOn))
+
+	return NewActivatedUntilWait(resumeOn)
+}
+
+// End ends this wait or returns an error
+func (w *UntilWait) End(resume flows.Resume) error {
+	switch resume.Type() {
+	case resumes.TypeWaitUntil, resumes.TypeRunExpiration:
+		return nil
+	}
+	return w.resumeTypeError(resume)
+}
+
+var _ flows.Wait = (*UntilWait)(nil)
+
+// ActivatedUntilWait is an until wait which has begun and knows when it should be resumed
+type ActivatedUntilWait struct {
+	baseActivatedWait
+
+	resumeOn time.Time
+}
+
+// NewActivatedUntilWait creates a new activated until wait
+func NewActivatedUntilWait(resumeOn time.Time) *ActivatedUntilWait {
+	return &ActivatedUntilWait{
+		baseActivatedWait: baseActivatedWait{type_: TypeUntil},
+		resumeOn:          resumeOn,
+	}
+}
+
+// ResumeOn returns when the caller should resume this wait
+func (w *ActivatedUntilWait) ResumeOn() time.Time { return w.resumeOn }
+
+var _ flows.ActivatedWait = (*ActivatedUntilWait)(nil)
+
+//------------------------------------------------------------------------------------------
+// JSON Encoding / Decoding
+//------------------------------------------------------------------------------------------
+
+type untilWaitEnvelope struct {
+	baseWaitEnvelope
+
+	Until        string             `json:"until"         validate:"required"`
+	CategoryUUID flows.CategoryUUID `json:"category_uuid" validate:"required,uuid4"`
+}
+
+func readUntilWait(data json.RawMessage) (flows.Wait, error) {
+	e := &untilWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &UntilWait{until: e.Until, categoryUUID: e.CategoryUUID}
+
+	return w, w.unmarshal(&e.baseWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *UntilWait) MarshalJSON() ([]byte, error) {
+	e := &untilWaitEnvelope{Until: w.until, CategoryUUID: w.categoryUUID}
+
+	if err := w.marshal(&e.baseWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
+
+type activatedUntilWaitEnvelope struct {
+	baseActivatedWaitEnvelope
+
+	ResumeOn time.Time `json:"resume_on" validate:"required"`
+}
+
+func readActivatedUntilWait(data json.RawMessage) (flows.ActivatedWait, error) {
+	e := &activatedUntilWaitEnvelope{}
+	if err := utils.UnmarshalAndValidate(data, e); err != nil {
+		return nil, err
+	}
+
+	w := &ActivatedUntilWait{resumeOn: e.ResumeOn}
+
+	return w, w.unmarshal(&e.baseActivatedWaitEnvelope)
+}
+
+// MarshalJSON marshals this wait into JSON
+func (w *ActivatedUntilWait) MarshalJSON() ([]byte, error) {
+	e := &activatedUntilWaitEnvelope{ResumeOn: w.resumeOn}
+
+	if err := w.marshal(&e.baseActivatedWaitEnvelope); err != nil {
+		return nil, err
+	}
+
+	return jsonx.Marshal(e)
+}
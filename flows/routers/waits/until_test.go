@@ -0,0 +1,74 @@
+package waits_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/routers/waits"
+	"github.com/nyaruka/goflow/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUntilWait(t *testing.T) {
+	session, _, err := test.CreateTestSession("", envs.RedactionPolicyNone)
+	require.NoError(t, err)
+	run := session.Runs()[0]
+
+	// until and category fields required
+	_, err = waits.ReadWait([]byte(`{"type": "until"}`))
+	assert.EqualError(t, err, "field 'until' is required, field 'category_uuid' is required")
+
+	wait, err := waits.ReadWait([]byte(`{"type": "until", "until": "@(\"2021-06-10 \" & \"09:30\")", "category_uuid": "63fca57d-5ef6-4afd-9bcd-7bdcf653cea8"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, waits.TypeUntil, wait.Type())
+	assert.Equal(t, flows.CategoryUUID("63fca57d-5ef6-4afd-9bcd-7bdcf653cea8"), wait.(*waits.UntilWait).CategoryUUID())
+
+	// test marsalling definition wait
+	marshaled, err := jsonx.Marshal(wait)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"until","until":"@(\"2021-06-10 \" & \"09:30\")","category_uuid":"63fca57d-5ef6-4afd-9bcd-7bdcf653cea8"}`, string(marshaled))
+
+	// try activating the wait - time should be in the contact's timezone
+	log := test.NewEventLog()
+	activated := wait.Begin(run, log.Log)
+
+	assert.Equal(t, "until", activated.Type())
+	assert.Nil(t, activated.TimeoutSeconds())
+	assert.Equal(t, time.Date(2021, 6, 10, 14, 30, 0, 0, time.UTC), activated.(*waits.ActivatedUntilWait).ResumeOn().UTC())
+	assert.Equal(t, 1, len(log.Events))
+	assert.Equal(t, "until_wait", log.Events[0].Type())
+
+	// test marsalling activated wait
+	marshaled, err = jsonx.Marshal(activated)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"until","resume_on":"2021-06-10T09:30:00-05:00"}`, string(marshaled))
+
+	// and reading it back
+	activated, err = waits.ReadActivatedWait(marshaled)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 6, 10, 14, 30, 0, 0, time.UTC), activated.(*waits.ActivatedUntilWait).ResumeOn().UTC())
+
+	// try to end with incorrect resume type
+	err = wait.End(resumes.NewWaitTimeout(nil, nil))
+	assert.EqualError(t, err, "can't end a wait of type 'until' with a resume of type 'wait_timeout'")
+
+	// try to end with wait until resume type
+	err = wait.End(resumes.NewWaitUntil(nil, nil))
+	assert.NoError(t, err)
+
+	// try when wait expression doesn't evaluate to a date
+	wait = waits.NewUntilWait(`@("foo")`, "63fca57d-5ef6-4afd-9bcd-7bdcf653cea8")
+
+	log = test.NewEventLog()
+	activated = wait.Begin(run, log.Log)
+
+	assert.Nil(t, activated)
+	assert.Equal(t, 1, len(log.Events))
+	assert.Equal(t, "error", log.Events[0].Type())
+}
@@ -22,6 +22,11 @@ func newRunEnvironment(base envs.Environment, run *flowRun) envs.Environment {
 	}
 }
 
+// Now returns the current time according to the engine's clock
+func (e *runEnvironment) Now() time.Time {
+	return e.run.now().In(e.Environment.Timezone())
+}
+
 func (e *runEnvironment) Timezone() *time.Location {
 	contact := e.run.Contact()
 
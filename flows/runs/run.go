@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/nyaruka/gocommon/dates"
 	"github.com/nyaruka/gocommon/jsonx"
 	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/assets"
@@ -27,6 +26,7 @@ type flowRun struct {
 	flowRef *assets.FlowReference
 
 	parent  flows.FlowRun
+	params  *types.XObject
 	results flows.Results
 	path    Path
 	events  []flows.Event
@@ -39,17 +39,27 @@ type flowRun struct {
 
 	webhook     types.XValue
 	legacyExtra *legacyExtra
+
+	// token for a callback wait at the step it was generated for
+	callbackTokenStep flows.StepUUID
+	callbackToken     string
+
+	// node context is memoized for the step it was built for as it can't change until there's a new step
+	nodeContextStep  flows.Step
+	nodeContextNode  flows.Node
+	nodeContextValue *types.XObject
 }
 
 // NewRun initializes a new context and flow run for the passed in flow and contact
-func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun) flows.FlowRun {
-	now := dates.Now()
+func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun, params *types.XObject) flows.FlowRun {
+	now := session.Engine().Clock().Now()
 	r := &flowRun{
-		uuid:       flows.RunUUID(uuids.New()),
+		uuid:       flows.RunUUID(session.Engine().UUIDs().Next()),
 		session:    session,
 		flow:       flow,
 		flowRef:    flow.Reference(),
 		parent:     parent,
+		params:     params,
 		results:    flows.NewResults(),
 		status:     flows.RunStatusActive,
 		events:     make([]flows.Event, 0),
@@ -66,6 +76,9 @@ func NewRun(session flows.Session, flow flows.Flow, parent flows.FlowRun) flows.
 	return r
 }
 
+// gets the current time according to the engine's clock
+func (r *flowRun) now() time.Time { return r.session.Engine().Clock().Now() }
+
 func (r *flowRun) UUID() flows.RunUUID           { return r.uuid }
 func (r *flowRun) Session() flows.Session        { return r.session }
 func (r *flowRun) Environment() envs.Environment { return r.environment }
@@ -75,19 +88,21 @@ func (r *flowRun) FlowReference() *assets.FlowReference { return r.flowRef }
 func (r *flowRun) Contact() *flows.Contact              { return r.session.Contact() }
 func (r *flowRun) Events() []flows.Event                { return r.events }
 
+func (r *flowRun) Params() *types.XObject { return r.params }
+
 func (r *flowRun) Results() flows.Results { return r.results }
 func (r *flowRun) SaveResult(result *flows.Result) {
 	// truncate value if necessary
 	result.Value = utils.Truncate(result.Value, r.Environment().MaxValueLength())
 
 	r.results.Save(result)
-	r.modifiedOn = dates.Now()
+	r.modifiedOn = r.now()
 
 	r.legacyExtra.addResult(result)
 }
 
 func (r *flowRun) Exit(status flows.RunStatus) {
-	now := dates.Now()
+	now := r.now()
 
 	r.status = status
 	r.exitedOn = &now
@@ -99,10 +114,36 @@ func (r *flowRun) Exit(status flows.RunStatus) {
 		r.ParentInSession().ResetExpiration(nil)
 	}
 }
+
+// Migrate switches this run to the given revision of its flow, rewriting the nodes and exits of its path using the
+// given mappings. Path steps whose nodes have no equivalent in the new revision are left as they are, and returned.
+func (r *flowRun) Migrate(flow flows.Flow, nodes map[flows.NodeUUID]flows.NodeUUID, exits map[flows.ExitUUID]flows.ExitUUID) []flows.Step {
+	r.flow = flow
+	r.flowRef = flow.Reference()
+
+	var unmapped []flows.Step
+
+	for _, s := range r.path {
+		st := s.(*step)
+		if nodeUUID, mapped := nodes[st.nodeUUID]; mapped {
+			st.nodeUUID = nodeUUID
+		}
+		if exitUUID, mapped := exits[st.exitUUID]; mapped {
+			st.exitUUID = exitUUID
+		}
+		if flow.GetNode(st.nodeUUID) == nil {
+			unmapped = append(unmapped, st)
+		}
+	}
+
+	r.modifiedOn = r.now()
+	return unmapped
+}
+
 func (r *flowRun) Status() flows.RunStatus { return r.status }
 func (r *flowRun) SetStatus(status flows.RunStatus) {
 	r.status = status
-	r.modifiedOn = dates.Now()
+	r.modifiedOn = r.now()
 }
 
 func (r *flowRun) Webhook() types.XValue {
@@ -148,8 +189,10 @@ func (r *flowRun) LogEvent(s flows.Step, event flows.Event) {
 		event.SetStepUUID(s.UUID())
 	}
 
+	events.Stamp(event, r.session.Engine().Clock())
+
 	r.events = append(r.events, event)
-	r.modifiedOn = dates.Now()
+	r.modifiedOn = r.now()
 }
 
 func (r *flowRun) LogError(step flows.Step, err error) {
@@ -172,8 +215,8 @@ func (r *flowRun) ReceivedInput() bool {
 
 func (r *flowRun) Path() []flows.Step { return r.path }
 func (r *flowRun) CreateStep(node flows.Node) flows.Step {
-	now := dates.Now()
-	step := NewStep(node, now)
+	now := r.now()
+	step := NewStep(flows.StepUUID(r.session.Engine().UUIDs().Next()), node, now)
 	r.path = append(r.path, step)
 	r.modifiedOn = now
 	return step
@@ -198,13 +241,28 @@ func (r *flowRun) PathLocation() (flows.Step, flows.Node, error) {
 	return step, node, nil
 }
 
+// CallbackToken returns the token which a callback wait at the current step must be resumed with. It's generated the
+// first time it's needed at each step, by actions passing it on or by the wait beginning, from the engine's UUID
+// generator, so is random unless the engine has been given a deterministic generator.
+func (r *flowRun) CallbackToken() string {
+	step, _, err := r.PathLocation()
+	if err != nil {
+		return ""
+	}
+	if r.callbackTokenStep != step.UUID() {
+		r.callbackTokenStep = step.UUID()
+		r.callbackToken = string(r.session.Engine().UUIDs().Next())
+	}
+	return r.callbackToken
+}
+
 func (r *flowRun) CreatedOn() time.Time  { return r.createdOn }
 func (r *flowRun) ModifiedOn() time.Time { return r.modifiedOn }
 func (r *flowRun) ExpiresOn() *time.Time { return r.expiresOn }
 func (r *flowRun) ResetExpiration(from *time.Time) {
 	if r.Flow() != nil && r.Flow().ExpireAfterMinutes() >= 0 {
 		if from == nil {
-			now := dates.Now()
+			now := r.now()
 			from = &now
 		}
 
@@ -212,7 +270,7 @@ func (r *flowRun) ResetExpiration(from *time.Time) {
 		expiresOn := from.Add(expiresAfterMinutes * time.Minute)
 
 		r.expiresOn = &expiresOn
-		r.modifiedOn = dates.Now()
+		r.modifiedOn = r.now()
 	}
 
 	if r.ParentInSession() != nil {
@@ -235,54 +293,86 @@ func (r *flowRun) ExitedOn() *time.Time { return r.exitedOn }
 //   ticket:ticket -> the last opened ticket for the contact
 //   webhook:any -> the parsed JSON response of the last webhook call
 //   node:node -> the current node
+//   params:params -> the parameters passed to this run by the parent run
 //   globals:globals -> the global values
+//   schedules:schedules -> the schedules
 //   trigger:trigger -> the trigger that started this session
 //   resume:resume -> the current resume that continued this session
 //
 // @context root
 func (r *flowRun) RootContext(env envs.Environment) map[string]types.XValue {
-	var urns, fields, ticket, node types.XValue
-	if r.Contact() != nil {
-		urns = flows.ContextFunc(env, r.Contact().URNs().MapContext)
-		fields = flows.Context(env, r.Contact().Fields())
-
-		tickets := r.Contact().Tickets()
-
-		if tickets.Count() > 0 {
-			ticket = flows.Context(env, tickets.All()[tickets.Count()-1])
-		}
-	}
-
-	var child = newRelatedRunContext(r.Session().GetCurrentChild(r))
-	var parent = newRelatedRunContext(r.Parent())
-
-	_, n, _ := r.PathLocation()
-	if n != nil {
-		node = flows.ContextFunc(env, r.nodeContext)
+	root := make(map[string]types.XValue, len(flows.RunContextTopLevels))
+	for _, name := range flows.RunContextTopLevels {
+		root[name] = r.rootContextValue(env, name)
 	}
+	return root
+}
 
-	return map[string]types.XValue{
-		// the available runs
-		"run":    flows.Context(env, r),
-		"child":  flows.Context(env, child),
-		"parent": flows.Context(env, parent),
+// returns a lazy version of the root context where each top-level value is only built when it's accessed
+func (r *flowRun) lazyRootContext(env envs.Environment) *types.XObject {
+	return types.NewXLazyPropertyObject(flows.RunContextTopLevels, func(name string) types.XValue {
+		return r.rootContextValue(env, name)
+	})
+}
 
-		// shortcuts to things on the current run or contact
-		"contact": flows.Context(env, r.Contact()),
-		"results": flows.Context(env, r.Results()),
-		"urns":    urns,
-		"fields":  fields,
-		"ticket":  ticket,
+// builds the named top-level value of the root context
+func (r *flowRun) rootContextValue(env envs.Environment, name string) types.XValue {
+	switch name {
+	// the available runs
+	case "run":
+		return flows.Context(env, r)
+	case "child":
+		return flows.Context(env, newRelatedRunContext(r.Session().GetCurrentChild(r)))
+	case "parent":
+		return flows.Context(env, newRelatedRunContext(r.Parent()))
+
+	// shortcuts to things on the current run or contact
+	case "contact":
+		return flows.Context(env, r.Contact())
+	case "results":
+		return flows.Context(env, r.Results())
+	case "urns":
+		if r.Contact() != nil {
+			return flows.ContextFunc(env, r.Contact().URNs().MapContext)
+		}
+	case "fields":
+		if r.Contact() != nil {
+			return flows.Context(env, r.Contact().Fields())
+		}
+	case "ticket":
+		if r.Contact() != nil {
+			tickets := r.Contact().Tickets()
+			if tickets.Count() > 0 {
+				return flows.Context(env, tickets.All()[tickets.Count()-1])
+			}
+		}
 
-		// other
-		"trigger":      flows.Context(env, r.Session().Trigger()),
-		"resume":       flows.Context(env, r.Session().CurrentResume()),
-		"input":        flows.Context(env, r.Session().Input()),
-		"globals":      flows.Context(env, r.Session().Assets().Globals()),
-		"webhook":      r.webhook,
-		"node":         node,
-		"legacy_extra": r.legacyExtra.ToXValue(env),
+	// other
+	case "trigger":
+		return flows.Context(env, r.Session().Trigger())
+	case "resume":
+		return flows.Context(env, r.Session().CurrentResume())
+	case "input":
+		return flows.Context(env, r.Session().Input())
+	case "globals":
+		return flows.Context(env, r.Session().Assets().Globals())
+	case "schedules":
+		return flows.Context(env, r.Session().Assets().Schedules())
+	case "webhook":
+		if env.MaskSensitiveData() {
+			return redactWebhook(env, r.webhook)
+		}
+		return r.webhook
+	case "node":
+		return r.currentNodeContext(env)
+	case "params":
+		if r.params != nil {
+			return r.params
+		}
+	case "legacy_extra":
+		return r.legacyExtra.ToXValue(env)
 	}
+	return nil
 }
 
 // Context returns the properties available in expressions
@@ -316,10 +406,25 @@ func (r *flowRun) Context(env envs.Environment) map[string]types.XValue {
 	}
 }
 
+// returns the context of the current node, reusing the last one built if we're still on the same step and node
+func (r *flowRun) currentNodeContext(env envs.Environment) types.XValue {
+	step, node, _ := r.PathLocation()
+	if node == nil {
+		return nil
+	}
+
+	if step != r.nodeContextStep || node != r.nodeContextNode {
+		r.nodeContextStep, r.nodeContextNode = step, node
+		r.nodeContextValue = flows.ContextFunc(env, r.nodeContext)
+	}
+	return r.nodeContextValue
+}
+
 // returns the context representation of the current node
 //
 //   uuid:text -> the UUID of the node
 //   visit_count:number -> the count of visits to the node in this run
+//   callback_token:text -> the token to resume the callback wait on the node
 //
 // @context node
 func (r *flowRun) nodeContext(env envs.Environment) map[string]types.XValue {
@@ -331,22 +436,43 @@ func (r *flowRun) nodeContext(env envs.Environment) map[string]types.XValue {
 		}
 	}
 
+	// only nodes with callback waits have a token, so that other nodes don't generate one
+	var callbackToken types.XValue
+	if node.Router() != nil && node.Router().Wait() != nil && node.Router().Wait().Type() == "callback" {
+		callbackToken = types.NewXText(r.CallbackToken())
+	}
+
 	return map[string]types.XValue{
-		"uuid":        types.NewXText(string(node.UUID())),
-		"visit_count": types.NewXNumberFromInt(visitCount),
+		"uuid":           types.NewXText(string(node.UUID())),
+		"visit_count":    types.NewXNumberFromInt(visitCount),
+		"callback_token": callbackToken,
 	}
 }
 
+// masks any sensitive values in the given parsed webhook response
+func redactWebhook(env envs.Environment, webhook types.XValue) types.XValue {
+	redact := env.SensitiveData().BodyRedactor(flows.RedactionMask)
+	if redact == nil || webhook == nil {
+		return webhook
+	}
+
+	asJSON, xerr := types.ToXJSON(webhook)
+	if xerr != nil {
+		return webhook
+	}
+	return types.JSONToXValue([]byte(redact(asJSON.Native())))
+}
+
 // EvaluateTemplate evaluates the given template in the context of this run
 func (r *flowRun) EvaluateTemplateValue(template string) (types.XValue, error) {
-	ctx := types.NewXObject(r.RootContext(r.Environment()))
+	ctx := r.lazyRootContext(r.Environment())
 
 	return excellent.EvaluateTemplateValue(r.Environment(), ctx, template)
 }
 
 // EvaluateTemplateText evaluates the given template as text in the context of this run
 func (r *flowRun) EvaluateTemplateText(template string, escaping excellent.Escaping, truncate bool) (string, error) {
-	ctx := types.NewXObject(r.RootContext(r.Environment()))
+	ctx := r.lazyRootContext(r.Environment())
 
 	value, err := excellent.EvaluateTemplate(r.Environment(), ctx, template, escaping)
 	if truncate {
@@ -438,10 +564,14 @@ type runEnvelope struct {
 	Flow       *assets.FlowReference `json:"flow" validate:"required,dive"`
 	Path       []*step               `json:"path" validate:"dive"`
 	Events     []json.RawMessage     `json:"events,omitempty"`
+	Params     json.RawMessage       `json:"params,omitempty"`
 	Results    flows.Results         `json:"results,omitempty" validate:"omitempty,dive"`
 	Status     flows.RunStatus       `json:"status" validate:"required"`
 	ParentUUID flows.RunUUID         `json:"parent_uuid,omitempty" validate:"omitempty,uuid4"`
 
+	// only the token for the current step is kept, as a callback wait can only be resumed at that step
+	CallbackToken string `json:"callback_token,omitempty"`
+
 	CreatedOn  time.Time  `json:"created_on" validate:"required"`
 	ModifiedOn time.Time  `json:"modified_on" validate:"required"`
 	ExpiresOn  *time.Time `json:"expires_on"`
@@ -481,6 +611,12 @@ func ReadRun(session flows.Session, data json.RawMessage, missing assets.Missing
 		}
 	}
 
+	if len(e.Params) > 0 {
+		if r.params, err = readParams(session.Environment(), r.flow, e.Params); err != nil {
+			return nil, errors.Wrap(err, "unable to read params")
+		}
+	}
+
 	if e.Results != nil {
 		r.results = e.Results
 	} else {
@@ -493,6 +629,11 @@ func ReadRun(session flows.Session, data json.RawMessage, missing assets.Missing
 		r.path[i] = step
 	}
 
+	if e.CallbackToken != "" && len(r.path) > 0 {
+		r.callbackTokenStep = r.path[len(r.path)-1].UUID()
+		r.callbackToken = e.CallbackToken
+	}
+
 	// read in our events
 	r.events = make([]flows.Event, len(e.Events))
 	for i := range r.events {
@@ -509,6 +650,30 @@ func ReadRun(session flows.Session, data json.RawMessage, missing assets.Missing
 	return r, nil
 }
 
+// reads the params passed to a run. These are marshaled as plain JSON so values are converted back to the types
+// declared by the flow, e.g. a datetime would otherwise be read back as text.
+func readParams(env envs.Environment, flow flows.Flow, data json.RawMessage) (*types.XObject, error) {
+	params, err := types.ReadXObject(data)
+	if err != nil || flow == nil {
+		return params, err
+	}
+
+	values := make(map[string]types.XValue, params.Count())
+	for _, key := range params.Properties() {
+		values[key], _ = params.Get(key)
+	}
+
+	for _, param := range flow.Params() {
+		if value := values[param.Key]; value != nil {
+			if converted, xerr := param.Convert(env, value); xerr == nil {
+				values[param.Key] = converted
+			}
+		}
+	}
+
+	return types.NewXObject(values), nil
+}
+
 // MarshalJSON marshals this flow run into JSON
 func (r *flowRun) MarshalJSON() ([]byte, error) {
 	var err error
@@ -528,11 +693,21 @@ func (r *flowRun) MarshalJSON() ([]byte, error) {
 		e.ParentUUID = r.parent.UUID()
 	}
 
+	if r.params != nil {
+		if e.Params, err = jsonx.Marshal(r.params); err != nil {
+			return nil, errors.Wrap(err, "unable to marshal params")
+		}
+	}
+
 	e.Path = make([]*step, len(r.path))
 	for i, s := range r.path {
 		e.Path[i] = s.(*step)
 	}
 
+	if len(r.path) > 0 && r.callbackTokenStep == r.path[len(r.path)-1].UUID() {
+		e.CallbackToken = r.callbackToken
+	}
+
 	e.Events = make([]json.RawMessage, len(r.events))
 	for i := range r.events {
 		if e.Events[i], err = jsonx.Marshal(r.events[i]); err != nil {
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/nyaruka/gocommon/jsonx"
-	"github.com/nyaruka/gocommon/uuids"
 	"github.com/nyaruka/goflow/envs"
 	"github.com/nyaruka/goflow/excellent/types"
 	"github.com/nyaruka/goflow/flows"
@@ -18,9 +17,9 @@ type step struct {
 }
 
 // NewStep creates a new step
-func NewStep(node flows.Node, arrivedOn time.Time) flows.Step {
+func NewStep(uuid flows.StepUUID, node flows.Node, arrivedOn time.Time) flows.Step {
 	return &step{
-		stepUUID:  flows.StepUUID(uuids.New()),
+		stepUUID:  uuid,
 		nodeUUID:  node.UUID(),
 		arrivedOn: arrivedOn,
 	}
@@ -0,0 +1,420 @@
+package flows
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/functions"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/utils"
+)
+
+func init() {
+	functions.RegisterXFunction("next_open", functions.MinAndMaxArgsCheck(1, 2, NextOpen))
+}
+
+// how far ahead we look for the next opening of a schedule
+const scheduleMaxLookahead = 366
+
+var scheduleDayCodes = map[time.Weekday]string{
+	time.Sunday:    "sun",
+	time.Monday:    "mon",
+	time.Tuesday:   "tue",
+	time.Wednesday: "wed",
+	time.Thursday:  "thu",
+	time.Friday:    "fri",
+	time.Saturday:  "sat",
+}
+
+var scheduleDaysByCode = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule represents a set of opening hours
+type Schedule struct {
+	assets.Schedule
+
+	location *time.Location
+}
+
+// NewSchedule returns a new schedule object from the given schedule asset
+func NewSchedule(asset assets.Schedule) *Schedule {
+	var location *time.Location
+	if asset.Timezone() != "" {
+		location, _ = time.LoadLocation(asset.Timezone())
+	}
+
+	return &Schedule{Schedule: asset, location: location}
+}
+
+// Asset returns the underlying asset
+func (s *Schedule) Asset() assets.Schedule { return s.Schedule }
+
+// Reference returns a reference to this schedule
+func (s *Schedule) Reference() *assets.ScheduleReference {
+	if s == nil {
+		return nil
+	}
+	return assets.NewScheduleReference(s.UUID(), s.Name())
+}
+
+// Location returns the location of this schedule, falling back to the timezone of the given environment
+// if the schedule doesn't have its own
+func (s *Schedule) Location(env envs.Environment) *time.Location {
+	if s.location != nil {
+		return s.location
+	}
+	return env.Timezone()
+}
+
+// IsOpen returns whether this schedule is open at the given time
+func (s *Schedule) IsOpen(env envs.Environment, t time.Time) bool {
+	local := t.In(s.Location(env))
+	timeOfDay := dates.ExtractTimeOfDay(local)
+
+	for _, h := range s.hoursOn(dates.ExtractDate(local)) {
+		if timeOfDay.Compare(h.Start()) >= 0 && (isEndOfDay(h.End()) || timeOfDay.Compare(h.End()) < 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the time when this schedule next opens after the given time, or the given time itself if the
+// schedule is already open. Returns nil if the schedule never opens.
+func (s *Schedule) NextOpen(env envs.Environment, t time.Time) *time.Time {
+	if s.IsOpen(env, t) {
+		return &t
+	}
+
+	location := s.Location(env)
+	local := t.In(location)
+
+	for d := 0; d <= scheduleMaxLookahead; d++ {
+		day := local.AddDate(0, 0, d)
+		date := dates.ExtractDate(day)
+
+		for _, h := range s.hoursOn(date) {
+			start := h.Start()
+			opens := time.Date(date.Year, date.Month, date.Day, start.Hour, start.Minute, start.Second, start.Nanos, location)
+			if opens.After(t) {
+				return &opens
+			}
+		}
+	}
+	return nil
+}
+
+// gets the opening hours on the given date, sorted by start time
+func (s *Schedule) hoursOn(date dates.Date) []assets.ScheduleHours {
+	var hours []assets.ScheduleHours
+
+	for _, e := range s.Exceptions() {
+		if e.Date().Equal(date) {
+			// copy so that sorting doesn't modify the exception's own hours
+			hours = append(hours, e.Hours()...)
+			sortScheduleHours(hours)
+			return hours
+		}
+	}
+
+	for _, i := range s.Intervals() {
+		if i.Day() == date.Weekday() {
+			hours = append(hours, i)
+		}
+	}
+
+	sortScheduleHours(hours)
+	return hours
+}
+
+// Context returns the properties available in expressions
+//
+//   __default__:text -> the name
+//   uuid:text -> the UUID of the schedule
+//   name:text -> the name of the schedule
+//   timezone:text -> the timezone of the schedule
+//   is_open:any -> whether the schedule is currently open
+//   intervals:any -> the weekly opening intervals of the schedule
+//   exceptions:any -> the dated exceptions to the weekly opening intervals
+//
+// @context schedule
+func (s *Schedule) Context(env envs.Environment) map[string]types.XValue {
+	intervals := make([]types.XValue, 0, len(s.Intervals()))
+	for _, i := range s.Intervals() {
+		intervals = append(intervals, types.NewXObject(map[string]types.XValue{
+			"day":   types.NewXText(scheduleDayCodes[i.Day()]),
+			"start": types.NewXTime(i.Start()),
+			"end":   types.NewXTime(i.End()),
+		}))
+	}
+
+	exceptions := make([]types.XValue, 0, len(s.Exceptions()))
+	for _, e := range s.Exceptions() {
+		hours := make([]types.XValue, 0, len(e.Hours()))
+		for _, h := range e.Hours() {
+			hours = append(hours, types.NewXObject(map[string]types.XValue{
+				"start": types.NewXTime(h.Start()),
+				"end":   types.NewXTime(h.End()),
+			}))
+		}
+
+		exceptions = append(exceptions, types.NewXObject(map[string]types.XValue{
+			"date":  types.NewXDate(e.Date()),
+			"name":  types.NewXText(e.Name()),
+			"hours": types.NewXArray(hours...),
+		}))
+	}
+
+	return map[string]types.XValue{
+		"__default__": types.NewXText(s.Name()),
+		"uuid":        types.NewXText(string(s.UUID())),
+		"name":        types.NewXText(s.Name()),
+		"timezone":    types.NewXText(s.Timezone()),
+		"is_open":     types.NewXBoolean(s.IsOpen(env, env.Now())),
+		"intervals":   types.NewXArray(intervals...),
+		"exceptions":  types.NewXArray(exceptions...),
+	}
+}
+
+var _ assets.Schedule = (*Schedule)(nil)
+
+// ScheduleAssets provides access to all schedule assets
+type ScheduleAssets struct {
+	all    []*Schedule
+	byUUID map[assets.ScheduleUUID]*Schedule
+}
+
+// NewScheduleAssets creates a new set of schedule assets
+func NewScheduleAssets(schedules []assets.Schedule) *ScheduleAssets {
+	s := &ScheduleAssets{
+		all:    make([]*Schedule, len(schedules)),
+		byUUID: make(map[assets.ScheduleUUID]*Schedule, len(schedules)),
+	}
+	for i, asset := range schedules {
+		schedule := NewSchedule(asset)
+		s.all[i] = schedule
+		s.byUUID[schedule.UUID()] = schedule
+	}
+	return s
+}
+
+// All returns all the schedules
+func (s *ScheduleAssets) All() []*Schedule {
+	return s.all
+}
+
+// Get returns the schedule with the given UUID
+func (s *ScheduleAssets) Get(uuid assets.ScheduleUUID) *Schedule {
+	return s.byUUID[uuid]
+}
+
+// FindByName looks for a schedule with the given name (case-insensitive)
+func (s *ScheduleAssets) FindByName(name string) *Schedule {
+	name = strings.ToLower(name)
+	for _, schedule := range s.all {
+		if strings.ToLower(schedule.Name()) == name {
+			return schedule
+		}
+	}
+	return nil
+}
+
+// Context returns the properties available in expressions, which are the schedules keyed by their snakified names
+func (s *ScheduleAssets) Context(env envs.Environment) map[string]types.XValue {
+	entries := make(map[string]types.XValue, len(s.all))
+	for _, schedule := range s.all {
+		entries[utils.Snakify(schedule.Name())] = Context(env, schedule)
+	}
+	return entries
+}
+
+// NextOpen returns when the given `schedule` next opens after `after` which defaults to now.
+//
+// If the schedule is already open, the time returned is `after` itself. Returns null if the schedule
+// doesn't open in the next year.
+//
+//   @(next_open(schedules.office_hours, "2018-04-14T12:00:00Z")) -> 2018-04-16T09:00:00.000000-05:00
+//   @(next_open(schedules.office_hours, "2018-04-16T15:00:00Z")) -> 2018-04-16T10:00:00.000000-05:00
+//   @(next_open("foo")) -> ERROR
+//
+// @function next_open(schedule [, after])
+func NextOpen(env envs.Environment, args ...types.XValue) types.XValue {
+	object, xerr := types.ToXObject(env, args[0])
+	if xerr != nil {
+		return xerr
+	}
+
+	schedule, xerr := scheduleFromXObject(env, object)
+	if xerr != nil {
+		return xerr
+	}
+
+	after := env.Now()
+	if len(args) > 1 {
+		asDateTime, xerr := types.ToXDateTime(env, args[1])
+		if xerr != nil {
+			return xerr
+		}
+		after = asDateTime.Native()
+	}
+
+	next := NewSchedule(schedule).NextOpen(env, after)
+	if next == nil {
+		return nil
+	}
+	return types.NewXDateTime(next.In(env.Timezone()))
+}
+
+func isEndOfDay(t dates.TimeOfDay) bool {
+	return t.Equal(dates.ZeroTimeOfDay)
+}
+
+func sortScheduleHours(hours []assets.ScheduleHours) {
+	sort.SliceStable(hours, func(i, j int) bool { return hours[i].Start().Compare(hours[j].Start()) < 0 })
+}
+
+// schedule types used to read a schedule back from its representation in expressions
+type xScheduleHours struct {
+	start, end dates.TimeOfDay
+}
+
+func (h *xScheduleHours) Start() dates.TimeOfDay { return h.start }
+func (h *xScheduleHours) End() dates.TimeOfDay   { return h.end }
+
+type xScheduleInterval struct {
+	xScheduleHours
+	day time.Weekday
+}
+
+func (i *xScheduleInterval) Day() time.Weekday { return i.day }
+
+type xScheduleException struct {
+	date  dates.Date
+	name  string
+	hours []assets.ScheduleHours
+}
+
+func (e *xScheduleException) Date() dates.Date              { return e.date }
+func (e *xScheduleException) Name() string                  { return e.name }
+func (e *xScheduleException) Hours() []assets.ScheduleHours { return e.hours }
+
+type xSchedule struct {
+	uuid       assets.ScheduleUUID
+	name       string
+	timezone   string
+	intervals  []assets.ScheduleInterval
+	exceptions []assets.ScheduleException
+}
+
+func (s *xSchedule) UUID() assets.ScheduleUUID              { return s.uuid }
+func (s *xSchedule) Name() string                           { return s.name }
+func (s *xSchedule) Timezone() string                       { return s.timezone }
+func (s *xSchedule) Intervals() []assets.ScheduleInterval   { return s.intervals }
+func (s *xSchedule) Exceptions() []assets.ScheduleException { return s.exceptions }
+
+func scheduleFromXObject(env envs.Environment, object *types.XObject) (assets.Schedule, types.XError) {
+	notSchedule := types.NewXErrorf("%s isn't a schedule", types.Describe(object))
+
+	uuid, _ := object.Get("uuid")
+	name, _ := object.Get("name")
+	timezone, _ := object.Get("timezone")
+	intervals, hasIntervals := object.Get("intervals")
+	exceptions, hasExceptions := object.Get("exceptions")
+	if !hasIntervals || !hasExceptions {
+		return nil, notSchedule
+	}
+
+	s := &xSchedule{
+		uuid:     assets.ScheduleUUID(types.Render(uuid)),
+		name:     types.Render(name),
+		timezone: types.Render(timezone),
+	}
+
+	intervalsArray, xerr := types.ToXArray(env, intervals)
+	if xerr != nil {
+		return nil, notSchedule
+	}
+	for i := 0; i < intervalsArray.Count(); i++ {
+		intervalObj, xerr := types.ToXObject(env, intervalsArray.Get(i))
+		if xerr != nil {
+			return nil, notSchedule
+		}
+		hours, xerr := scheduleHoursFromXObject(env, intervalObj)
+		if xerr != nil {
+			return nil, notSchedule
+		}
+
+		dayCode, _ := intervalObj.Get("day")
+		day, valid := scheduleDaysByCode[types.Render(dayCode)]
+		if !valid {
+			return nil, notSchedule
+		}
+		s.intervals = append(s.intervals, &xScheduleInterval{xScheduleHours: *hours, day: day})
+	}
+
+	exceptionsArray, xerr := types.ToXArray(env, exceptions)
+	if xerr != nil {
+		return nil, notSchedule
+	}
+	for i := 0; i < exceptionsArray.Count(); i++ {
+		exceptionObj, xerr := types.ToXObject(env, exceptionsArray.Get(i))
+		if xerr != nil {
+			return nil, notSchedule
+		}
+		dateVal, _ := exceptionObj.Get("date")
+		date, xerr := types.ToXDate(env, dateVal)
+		if xerr != nil {
+			return nil, notSchedule
+		}
+		exceptionName, _ := exceptionObj.Get("name")
+		hoursVal, _ := exceptionObj.Get("hours")
+		hoursArray, xerr := types.ToXArray(env, hoursVal)
+		if xerr != nil {
+			return nil, notSchedule
+		}
+
+		exception := &xScheduleException{date: date.Native(), name: types.Render(exceptionName)}
+
+		for j := 0; j < hoursArray.Count(); j++ {
+			hoursObj, xerr := types.ToXObject(env, hoursArray.Get(j))
+			if xerr != nil {
+				return nil, notSchedule
+			}
+			hours, xerr := scheduleHoursFromXObject(env, hoursObj)
+			if xerr != nil {
+				return nil, notSchedule
+			}
+			exception.hours = append(exception.hours, hours)
+		}
+
+		s.exceptions = append(s.exceptions, exception)
+	}
+
+	return s, nil
+}
+
+func scheduleHoursFromXObject(env envs.Environment, object *types.XObject) (*xScheduleHours, types.XError) {
+	startVal, _ := object.Get("start")
+	endVal, _ := object.Get("end")
+
+	start, xerr := types.ToXTime(env, startVal)
+	if xerr != nil {
+		return nil, xerr
+	}
+	end, xerr := types.ToXTime(env, endVal)
+	if xerr != nil {
+		return nil, xerr
+	}
+	return &xScheduleHours{start: start.Native(), end: end.Native()}, nil
+}
@@ -0,0 +1,159 @@
+package flows_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/assets/static"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedules(t *testing.T) {
+	nineToFive := func(day time.Weekday) *static.ScheduleInterval {
+		return static.NewScheduleInterval(day, dates.NewTimeOfDay(9, 0, 0, 0), dates.NewTimeOfDay(17, 0, 0, 0))
+	}
+
+	sa1 := static.NewSchedule(
+		assets.ScheduleUUID("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6"),
+		"Office Hours",
+		"America/Guayaquil",
+		[]*static.ScheduleInterval{nineToFive(time.Monday), nineToFive(time.Tuesday), nineToFive(time.Wednesday), nineToFive(time.Thursday), nineToFive(time.Friday)},
+		[]*static.ScheduleException{
+			static.NewScheduleException(dates.NewDate(2021, 12, 24), "Christmas Eve", []*static.ScheduleHours{
+				static.NewScheduleHours(dates.NewTimeOfDay(9, 0, 0, 0), dates.NewTimeOfDay(12, 0, 0, 0)),
+			}),
+			static.NewScheduleException(dates.NewDate(2021, 12, 27), "Boxing Day (observed)", nil),
+		},
+	)
+	sa2 := static.NewSchedule(
+		assets.ScheduleUUID("c2a8d6b4-8e02-4c5e-8c8c-3fb8a3b5c7d1"),
+		"Night Shift",
+		"",
+		[]*static.ScheduleInterval{
+			static.NewScheduleInterval(time.Saturday, dates.NewTimeOfDay(22, 0, 0, 0), dates.ZeroTimeOfDay),
+		},
+		nil,
+	)
+	sa3 := static.NewSchedule(assets.ScheduleUUID("e0cbd6c2-0ae6-4d4d-b9a8-40f4b8a8d8c1"), "Never", "", nil, nil)
+
+	schedules := flows.NewScheduleAssets([]assets.Schedule{sa1, sa2, sa3})
+
+	office := schedules.Get("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6")
+	assert.Equal(t, sa1, office.Asset())
+	assert.Equal(t, assets.NewScheduleReference("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6", "Office Hours"), office.Reference())
+	assert.Equal(t, office, schedules.FindByName("office hours"))
+	assert.Nil(t, schedules.Get("a8a3e9f4-7a5d-4b0c-9d3b-2f1c6f1a9e77"))
+	assert.Nil(t, schedules.FindByName("Weekends"))
+
+	night := schedules.Get("c2a8d6b4-8e02-4c5e-8c8c-3fb8a3b5c7d1")
+	never := schedules.Get("e0cbd6c2-0ae6-4d4d-b9a8-40f4b8a8d8c1")
+
+	tz, _ := time.LoadLocation("Africa/Kigali")
+	env := envs.NewBuilder().WithTimezone(tz).Build()
+
+	parse := func(s string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	tcs := []struct {
+		schedule *flows.Schedule
+		time     string
+		isOpen   bool
+		nextOpen string
+	}{
+		{office, "2021-12-20T13:59:00Z", false, "2021-12-20T09:00:00-05:00"}, // Monday 08:59 in Guayaquil
+		{office, "2021-12-20T14:00:00Z", true, "2021-12-20T09:00:00-05:00"},  // Monday 09:00 in Guayaquil
+		{office, "2021-12-20T22:00:00Z", false, "2021-12-21T09:00:00-05:00"}, // Monday 17:00 in Guayaquil
+		{office, "2021-12-24T16:00:00Z", true, "2021-12-24T11:00:00-05:00"},  // Christmas Eve 11:00 in Guayaquil
+		{office, "2021-12-24T17:00:00Z", false, "2021-12-28T09:00:00-05:00"}, // Christmas Eve 12:00, weekend then holiday
+		{office, "2021-12-27T15:00:00Z", false, "2021-12-28T09:00:00-05:00"}, // Boxing Day (observed)
+		{night, "2021-12-25T19:59:00Z", false, "2021-12-25T22:00:00+02:00"},  // Saturday 21:59 in Kigali
+		{night, "2021-12-25T21:59:00Z", true, "2021-12-25T23:59:00+02:00"},   // Saturday 23:59 in Kigali
+		{night, "2021-12-25T22:00:00Z", false, "2022-01-01T22:00:00+02:00"},  // Sunday 00:00 in Kigali
+		{never, "2021-12-25T22:00:00Z", false, ""},
+	}
+
+	for _, tc := range tcs {
+		tm := parse(tc.time)
+
+		assert.Equal(t, tc.isOpen, tc.schedule.IsOpen(env, tm), "is open mismatch for %s at %s", tc.schedule.Name(), tc.time)
+
+		nextOpen := tc.schedule.NextOpen(env, tm)
+		if tc.nextOpen == "" {
+			assert.Nil(t, nextOpen, "expected nil next open for %s at %s", tc.schedule.Name(), tc.time)
+		} else if assert.NotNil(t, nextOpen, "expected next open for %s at %s", tc.schedule.Name(), tc.time) {
+			assert.Equal(t, parse(tc.nextOpen).UTC(), nextOpen.UTC(), "next open mismatch for %s at %s", tc.schedule.Name(), tc.time)
+		}
+	}
+
+	// check use in expressions
+	dates.SetNowSource(dates.NewFixedNowSource(parse("2021-12-20T15:00:00Z")))
+	defer dates.SetNowSource(dates.DefaultNowSource)
+
+	ctx := types.NewXObject(map[string]types.XValue{"schedules": flows.Context(env, schedules)})
+
+	evaluate := func(template string) string {
+		result, err := excellent.EvaluateTemplate(env, ctx, template, nil)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, "Office Hours", evaluate("@schedules.office_hours"))
+	assert.Equal(t, "true", evaluate("@schedules.office_hours.is_open"))
+	assert.Equal(t, "false", evaluate("@schedules.night_shift.is_open"))
+	assert.Equal(t, "America/Guayaquil", evaluate("@schedules.office_hours.timezone"))
+	assert.Equal(t, "mon", evaluate("@(schedules.office_hours.intervals[0].day)"))
+	assert.Equal(t, "Christmas Eve", evaluate("@(schedules.office_hours.exceptions[0].name)"))
+	assert.Equal(t, "2021-12-20T17:00:00.000000+02:00", evaluate("@(next_open(schedules.office_hours))"))
+	assert.Equal(t, "2021-12-28T16:00:00.000000+02:00", evaluate(`@(next_open(schedules.office_hours, "2021-12-24T17:00:00Z"))`))
+	assert.Equal(t, "2021-12-25T22:00:00.000000+02:00", evaluate("@(next_open(schedules.night_shift))"))
+	assert.Equal(t, "", evaluate("@(next_open(schedules.never))"))
+
+	_, err := excellent.EvaluateTemplate(env, ctx, `@(next_open("foo"))`, nil)
+	assert.EqualError(t, err, `error evaluating @(next_open("foo")): error calling next_open(...): unable to convert "foo" to an object`)
+}
+
+// an exception which returns its own hours rather than a copy of them
+type sharedHoursException struct {
+	hours []assets.ScheduleHours
+}
+
+func (e *sharedHoursException) Date() dates.Date              { return dates.NewDate(2021, 12, 24) }
+func (e *sharedHoursException) Name() string                  { return "Christmas Eve" }
+func (e *sharedHoursException) Hours() []assets.ScheduleHours { return e.hours }
+
+type scheduleWithException struct {
+	assets.Schedule
+	exception assets.ScheduleException
+}
+
+func (s *scheduleWithException) Exceptions() []assets.ScheduleException {
+	return []assets.ScheduleException{s.exception}
+}
+
+func TestScheduleDoesNotModifyExceptionHours(t *testing.T) {
+	afternoon := static.NewScheduleHours(dates.NewTimeOfDay(14, 0, 0, 0), dates.NewTimeOfDay(16, 0, 0, 0))
+	morning := static.NewScheduleHours(dates.NewTimeOfDay(9, 0, 0, 0), dates.NewTimeOfDay(12, 0, 0, 0))
+	exception := &sharedHoursException{hours: []assets.ScheduleHours{afternoon, morning}}
+
+	schedule := flows.NewSchedule(&scheduleWithException{
+		Schedule:  static.NewSchedule(assets.ScheduleUUID("4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6"), "Office Hours", "UTC", nil, nil),
+		exception: exception,
+	})
+
+	env := envs.NewBuilder().Build()
+	now := time.Date(2021, 12, 24, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2021, 12, 24, 9, 0, 0, 0, time.UTC), *schedule.NextOpen(env, now))
+	assert.Equal(t, []assets.ScheduleHours{afternoon, morning}, exception.hours)
+}
@@ -149,9 +149,9 @@ type HTTPTrace struct {
 // trim request and response traces to 10K chars to avoid bloating serialized sessions
 const trimTracesTo = 10000
 
-// NewHTTPTrace creates a new HTTP log from a trace
-func NewHTTPTrace(trace *httpx.Trace, status CallStatus) *HTTPTrace {
-	return newHTTPTraceWithStatus(trace, status, nil)
+// NewHTTPTrace creates a new HTTP log from a trace, redacting the URL, request and response if a redactor is given
+func NewHTTPTrace(trace *httpx.Trace, status CallStatus, redact utils.Redactor) *HTTPTrace {
+	return newHTTPTraceWithStatus(trace, status, redact)
 }
 
 // HTTPLog describes an HTTP request/response
@@ -186,7 +186,7 @@ func HTTPStatusFromCode(t *httpx.Trace) CallStatus {
 	return CallStatusSuccess
 }
 
-// RedactionMask is the redaction mask for HTTP service logs
+// RedactionMask is the redaction mask for HTTP service logs and sensitive data
 const RedactionMask = "****************"
 
 // NewHTTPLog creates a new HTTP log from a trace
@@ -0,0 +1,37 @@
+package flows
+
+import (
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/random"
+	"github.com/nyaruka/gocommon/uuids"
+
+	"github.com/shopspring/decimal"
+)
+
+// RandomSource is a source of random numbers
+type RandomSource interface {
+	Decimal() decimal.Decimal
+}
+
+type globalClock struct{}
+
+func (globalClock) Now() time.Time { return dates.Now() }
+
+type globalUUIDs struct{}
+
+func (globalUUIDs) Next() uuids.UUID { return uuids.New() }
+
+type globalRandom struct{}
+
+func (globalRandom) Decimal() decimal.Decimal { return random.Decimal() }
+
+// GlobalClock is a clock which uses the process-wide source of dates.Now()
+var GlobalClock dates.NowSource = globalClock{}
+
+// GlobalUUIDs is a UUID generator which uses the process-wide generator of uuids.New()
+var GlobalUUIDs uuids.Generator = globalUUIDs{}
+
+// GlobalRandom is a random source which uses the process-wide generator of the random package
+var GlobalRandom RandomSource = globalRandom{}
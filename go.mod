@@ -12,6 +12,7 @@ require (
 	github.com/nyaruka/phonenumbers v1.0.71
 	github.com/olivere/elastic/v7 v7.0.22
 	github.com/pkg/errors v0.9.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.1.1
 	github.com/sergi/go-diff v1.1.0
 	github.com/shopspring/decimal v1.2.0
 	github.com/stretchr/testify v1.7.0
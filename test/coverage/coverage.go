@@ -0,0 +1,181 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+// Collector aggregates the paths taken through flows by many sessions
+type Collector struct {
+	flows      map[assets.FlowUUID]flows.Flow
+	flowOrder  []assets.FlowUUID
+	sessions   map[assets.FlowUUID]int
+	nodes      map[flows.NodeUUID]int
+	exits      map[flows.ExitUUID]int
+	categories map[flows.CategoryUUID]int
+}
+
+// NewCollector creates a new empty coverage collector
+func NewCollector() *Collector {
+	return &Collector{
+		flows:      make(map[assets.FlowUUID]flows.Flow),
+		sessions:   make(map[assets.FlowUUID]int),
+		nodes:      make(map[flows.NodeUUID]int),
+		exits:      make(map[flows.ExitUUID]int),
+		categories: make(map[flows.CategoryUUID]int),
+	}
+}
+
+// Add adds a session and the sprints it was created and resumed with. It should be called once per session after
+// it has finished or stopped at the last wait we care about, as the steps in its runs' paths are counted.
+func (c *Collector) Add(session flows.Session, sprints []flows.Sprint) {
+	// gather the categories that routers picked from the result events of each step
+	stepCategories := make(map[flows.StepUUID]map[string]string)
+	for _, sprint := range sprints {
+		for _, e := range sprint.Events() {
+			if typed, isResult := e.(*events.RunResultChangedEvent); isResult && typed.StepUUID() != "" {
+				if stepCategories[typed.StepUUID()] == nil {
+					stepCategories[typed.StepUUID()] = make(map[string]string)
+				}
+				stepCategories[typed.StepUUID()][typed.Name] = typed.Category
+			}
+		}
+
+		// segments record every movement from an exit to a node
+		for _, seg := range sprint.Segments() {
+			c.addFlow(seg.Flow())
+			c.exits[seg.Exit().UUID()]++
+		}
+	}
+
+	counted := make(map[assets.FlowUUID]bool)
+
+	for _, run := range session.Runs() {
+		flow := run.Flow()
+		if flow == nil {
+			continue
+		}
+		c.addFlow(flow)
+
+		if !counted[flow.UUID()] {
+			c.sessions[flow.UUID()]++
+			counted[flow.UUID()] = true
+		}
+
+		for _, step := range run.Path() {
+			c.nodes[step.NodeUUID()]++
+
+			node := flow.GetNode(step.NodeUUID())
+			if node == nil || step.ExitUUID() == "" {
+				continue
+			}
+
+			// exits without destinations don't create segments so they are counted from steps
+			for _, exit := range node.Exits() {
+				if exit.UUID() == step.ExitUUID() && exit.DestinationUUID() == "" {
+					c.exits[exit.UUID()]++
+				}
+			}
+
+			if node.Router() != nil {
+				if category := pickedCategory(node.Router(), step.ExitUUID(), stepCategories[step.UUID()]); category != nil {
+					c.categories[category.UUID()]++
+				}
+			}
+		}
+	}
+}
+
+// works out which category a router picked given the exit taken and the categories of results saved on that step
+func pickedCategory(router flows.Router, exitUUID flows.ExitUUID, stepCategories map[string]string) flows.Category {
+	candidates := make([]flows.Category, 0, 1)
+	for _, cat := range router.Categories() {
+		if cat.ExitUUID() == exitUUID {
+			candidates = append(candidates, cat)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	// categories share an exit so use the result the router saved to decide
+	if router.ResultName() != "" {
+		for _, cat := range candidates {
+			if stepCategories[router.ResultName()] == cat.Name() {
+				return cat
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Collector) addFlow(flow flows.Flow) {
+	if _, seen := c.flows[flow.UUID()]; !seen {
+		c.flows[flow.UUID()] = flow
+		c.flowOrder = append(c.flowOrder, flow.UUID())
+	}
+}
+
+// Report builds a report of the coverage collected so far, with flows sorted by name
+func (c *Collector) Report() *Report {
+	report := &Report{Flows: make([]*FlowCoverage, 0, len(c.flows))}
+
+	for _, uuid := range c.flowOrder {
+		report.Flows = append(report.Flows, c.flowCoverage(c.flows[uuid]))
+	}
+
+	sort.SliceStable(report.Flows, func(i, j int) bool { return report.Flows[i].Flow.Name < report.Flows[j].Flow.Name })
+
+	return report
+}
+
+func (c *Collector) flowCoverage(flow flows.Flow) *FlowCoverage {
+	fc := &FlowCoverage{
+		Flow:     flow.Reference(),
+		Sessions: c.sessions[flow.UUID()],
+		Nodes:    make([]*NodeCoverage, len(flow.Nodes())),
+	}
+
+	for i, node := range flow.Nodes() {
+		nc := &NodeCoverage{
+			UUID:     node.UUID(),
+			Label:    nodeLabel(node),
+			Position: nodePosition(flow.UI(), node.UUID()),
+			Hits:     c.nodes[node.UUID()],
+			Exits:    make([]*ExitCoverage, len(node.Exits())),
+		}
+		fc.Summary.Nodes.add(nc.Hits)
+
+		for j, exit := range node.Exits() {
+			ec := &ExitCoverage{
+				UUID:            exit.UUID(),
+				DestinationUUID: exit.DestinationUUID(),
+				Hits:            c.exits[exit.UUID()],
+			}
+			nc.Exits[j] = ec
+			fc.Summary.Exits.add(ec.Hits)
+		}
+
+		if node.Router() != nil {
+			nc.Categories = make([]*CategoryCoverage, len(node.Router().Categories()))
+
+			for j, cat := range node.Router().Categories() {
+				nc.Categories[j] = &CategoryCoverage{
+					UUID:     cat.UUID(),
+					Name:     cat.Name(),
+					ExitUUID: cat.ExitUUID(),
+					Hits:     c.categories[cat.UUID()],
+				}
+				fc.Summary.Categories.add(nc.Categories[j].Hits)
+			}
+		}
+
+		fc.Nodes[i] = nc
+	}
+
+	return fc
+}
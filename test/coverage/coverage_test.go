@@ -0,0 +1,98 @@
+package coverage_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/test/coverage"
+	"github.com/nyaruka/goflow/test/scenario"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "../scenario/testdata/assets.json")
+	require.NoError(t, err)
+
+	suite, err := scenario.LoadSuite("../scenario/testdata/support.json")
+	require.NoError(t, err)
+
+	collector := coverage.NewCollector()
+	assert.Equal(t, &coverage.Report{Flows: []*coverage.FlowCoverage{}}, collector.Report())
+
+	_, err = scenario.RunWithCoverage(sa, suite, collector)
+	require.NoError(t, err)
+
+	report := collector.Report()
+	reportJSON, err := jsonx.MarshalPretty(report)
+	require.NoError(t, err)
+
+	if test.UpdateSnapshots {
+		err = os.WriteFile("testdata/support.json", reportJSON, 0666)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile("testdata/support.json")
+	require.NoError(t, err)
+	test.AssertEqualJSON(t, expected, reportJSON, "coverage report mismatch")
+
+	support := report.Flow("5f3a8c2e-7b1d-4e6a-9c0f-2d8b4a6e1c37")
+	require.NotNil(t, support)
+	assert.Nil(t, report.Flow("a2b4c6d8-1e3f-4a5b-8c7d-9e0f1a2b3c4d"))
+
+	assert.Equal(t, 2, support.Sessions)
+	assert.Equal(t, "5/5 (100%)", support.Summary.Nodes.String())
+	assert.Equal(t, "6/8 (75%)", support.Summary.Exits.String())
+	assert.Equal(t, "5/8 (62%)", support.Summary.Categories.String())
+
+	// categories which share an exit are distinguished using the result saved by the router
+	classify := support.Nodes[1]
+	assert.Equal(t, "Complaint", classify.Categories[1].Name)
+	assert.Equal(t, 0, classify.Categories[1].Hits)
+	assert.Equal(t, "Other", classify.Categories[2].Name)
+	assert.Equal(t, 1, classify.Categories[2].Hits)
+	assert.Equal(t, 1, classify.Exits[1].Hits)
+
+	// running the scenarios again doubles the counts
+	_, err = scenario.RunWithCoverage(sa, suite, collector)
+	require.NoError(t, err)
+
+	support = collector.Report().Flow("5f3a8c2e-7b1d-4e6a-9c0f-2d8b4a6e1c37")
+	assert.Equal(t, 4, support.Sessions)
+	assert.Equal(t, 4, support.Nodes[0].Hits)
+	assert.Equal(t, 2, support.Nodes[1].Categories[2].Hits)
+}
+
+func TestHTML(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "../scenario/testdata/assets.json")
+	require.NoError(t, err)
+
+	suite, err := scenario.LoadSuite("../scenario/testdata/support.json")
+	require.NoError(t, err)
+
+	collector := coverage.NewCollector()
+	_, err = scenario.RunWithCoverage(sa, suite, collector)
+	require.NoError(t, err)
+
+	out := &strings.Builder{}
+	err = collector.Report().WriteHTML(out)
+	require.NoError(t, err)
+
+	html := out.String()
+	assert.Contains(t, html, "<h2>Support</h2>")
+	assert.Contains(t, html, "2 session(s) · nodes 5/5 (100%) · exits 6/8 (75%) · categories 5/8 (62%)")
+	assert.Contains(t, html, `<div class="node hit" id="f5337fad-21d8-4440-918d-9d05dfe2f95c" style="left: 260px; top: 320px">`)
+	assert.Contains(t, html, `<li class="miss"><span>Complaint</span><span>0</span></li>`)
+	assert.Contains(t, html, `<li class="hit"><span>Other</span><span>1</span></li>`)
+}
+
+func TestCounts(t *testing.T) {
+	assert.Equal(t, 100.0, (&coverage.Counts{}).Percent())
+	assert.Equal(t, 25.0, (&coverage.Counts{Covered: 1, Total: 4}).Percent())
+	assert.Equal(t, "1/4 (25%)", (&coverage.Counts{Covered: 1, Total: 4}).String())
+}
@@ -0,0 +1,93 @@
+package coverage
+
+import (
+	"html/template"
+	"io"
+)
+
+// the size of the grid used to lay out nodes of flows without UI positions
+const (
+	defaultNodeSpacing = 180
+	nodeWidth          = 220
+)
+
+var htmlTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"left":   nodeLeft,
+	"top":    nodeTop,
+	"width":  func() int { return nodeWidth },
+	"height": canvasHeight,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Flow Coverage</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 20px; }
+h2 { margin-bottom: 4px; }
+.summary { color: #555; margin-bottom: 10px; }
+.canvas { position: relative; background: #f7f7f7; border: 1px solid #ddd; overflow: auto; }
+.node { position: absolute; width: {{ width }}px; border-radius: 4px; background: #fff; box-shadow: 0 1px 3px rgba(0,0,0,0.3); }
+.node .header { padding: 4px 6px; color: #fff; display: flex; justify-content: space-between; }
+.node.hit .header { background: #2e8b57; }
+.node.miss .header { background: #c0392b; }
+.node .label { padding: 4px 6px; color: #333; }
+.node ul { list-style: none; margin: 0; padding: 0 6px 4px; }
+.node li { display: flex; justify-content: space-between; }
+.node li.miss { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Flow Coverage</h1>
+{{ range .Flows }}
+<h2>{{ .Flow.Name }}</h2>
+<div class="summary">
+{{ .Sessions }} session(s) · nodes {{ .Summary.Nodes.String }} · exits {{ .Summary.Exits.String }} · categories {{ .Summary.Categories.String }}
+</div>
+<div class="canvas" style="height: {{ height . }}px">
+{{ range $i, $n := .Nodes }}
+<div class="node {{ if $n.Hits }}hit{{ else }}miss{{ end }}" id="{{ $n.UUID }}" style="left: {{ left $n }}px; top: {{ top $n $i }}px">
+<div class="header"><span>{{ $n.UUID }}</span><span>{{ $n.Hits }}</span></div>
+<div class="label">{{ $n.Label }}</div>
+<ul>
+{{ if $n.Categories }}{{ range $n.Categories }}<li class="{{ if .Hits }}hit{{ else }}miss{{ end }}"><span>{{ .Name }}</span><span>{{ .Hits }}</span></li>
+{{ end }}{{ else }}{{ range $n.Exits }}<li class="{{ if .Hits }}hit{{ else }}miss{{ end }}"><span>exit</span><span>{{ .Hits }}</span></li>
+{{ end }}{{ end }}
+</ul>
+</div>
+{{ end }}
+</div>
+{{ end }}
+</body>
+</html>
+`))
+
+func nodeLeft(n *NodeCoverage) int {
+	if n.Position != nil {
+		return n.Position.Left
+	}
+	return 20
+}
+
+func nodeTop(n *NodeCoverage, i int) int {
+	if n.Position != nil {
+		return n.Position.Top
+	}
+	return 20 + i*defaultNodeSpacing
+}
+
+// gets the height needed to show all the nodes of a flow
+func canvasHeight(fc *FlowCoverage) int {
+	height := 0
+	for i, n := range fc.Nodes {
+		if bottom := nodeTop(n, i) + defaultNodeSpacing; bottom > height {
+			height = bottom
+		}
+	}
+	return height
+}
+
+// WriteHTML writes this report as an HTML page which shows the hit counts of each flow's nodes and categories in
+// the positions they have in the flow editor
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
@@ -0,0 +1,132 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/buger/jsonparser"
+)
+
+// Report is the coverage of each flow that sessions passed through
+type Report struct {
+	Flows []*FlowCoverage `json:"flows"`
+}
+
+// Flow gets the coverage of the flow with the given UUID or nil if no session passed through it
+func (r *Report) Flow(uuid assets.FlowUUID) *FlowCoverage {
+	for _, fc := range r.Flows {
+		if fc.Flow.UUID == uuid {
+			return fc
+		}
+	}
+	return nil
+}
+
+// Counts is how many things of a type were hit at least once, out of the total
+type Counts struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+func (c *Counts) add(hits int) {
+	c.Total++
+	if hits > 0 {
+		c.Covered++
+	}
+}
+
+// Percent returns the covered percentage
+func (c *Counts) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+func (c *Counts) String() string {
+	return fmt.Sprintf("%d/%d (%.0f%%)", c.Covered, c.Total, c.Percent())
+}
+
+// Summary is the overall coverage of a flow
+type Summary struct {
+	Nodes      Counts `json:"nodes"`
+	Exits      Counts `json:"exits"`
+	Categories Counts `json:"categories"`
+}
+
+// FlowCoverage is the coverage of a single flow
+type FlowCoverage struct {
+	Flow     *assets.FlowReference `json:"flow"`
+	Sessions int                   `json:"sessions"`
+	Summary  Summary               `json:"summary"`
+	Nodes    []*NodeCoverage       `json:"nodes"`
+}
+
+// Position is the position of a node in the flow editor
+type Position struct {
+	Left int `json:"left"`
+	Top  int `json:"top"`
+}
+
+// NodeCoverage is the hit counts of a node and its exits and categories
+type NodeCoverage struct {
+	UUID       flows.NodeUUID      `json:"uuid"`
+	Label      string              `json:"label"`
+	Position   *Position           `json:"position,omitempty"`
+	Hits       int                 `json:"hits"`
+	Exits      []*ExitCoverage     `json:"exits"`
+	Categories []*CategoryCoverage `json:"categories,omitempty"`
+}
+
+// ExitCoverage is the hit count of an exit
+type ExitCoverage struct {
+	UUID            flows.ExitUUID `json:"uuid"`
+	DestinationUUID flows.NodeUUID `json:"destination_uuid,omitempty"`
+	Hits            int            `json:"hits"`
+}
+
+// CategoryCoverage is the hit count of a router category
+type CategoryCoverage struct {
+	UUID     flows.CategoryUUID `json:"uuid"`
+	Name     string             `json:"name"`
+	ExitUUID flows.ExitUUID     `json:"exit_uuid"`
+	Hits     int                `json:"hits"`
+}
+
+// gets the label shown for a node, i.e. the types of its actions and router
+func nodeLabel(node flows.Node) string {
+	label := ""
+	for _, a := range node.Actions() {
+		if label != "" {
+			label += ", "
+		}
+		label += a.Type()
+	}
+	if node.Router() != nil {
+		if label != "" {
+			label += " → "
+		}
+		label += node.Router().Type()
+		if node.Router().Wait() != nil {
+			label += " (wait)"
+		}
+	}
+	return label
+}
+
+// reads the position of a node from the UI section of a flow definition
+func nodePosition(ui json.RawMessage, nodeUUID flows.NodeUUID) *Position {
+	if ui == nil {
+		return nil
+	}
+
+	left, err1 := jsonparser.GetInt(ui, "nodes", string(nodeUUID), "position", "left")
+	top, err2 := jsonparser.GetInt(ui, "nodes", string(nodeUUID), "position", "top")
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	return &Position{Left: int(left), Top: int(top)}
+}
@@ -18,6 +18,11 @@ import (
 
 // NewEngine creates an engine instance for testing
 func NewEngine() flows.Engine {
+	return NewEngineBuilder().Build()
+}
+
+// NewEngineBuilder creates an engine builder with the services used for testing
+func NewEngineBuilder() *engine.Builder {
 	retries := httpx.NewFixedRetries(1*time.Millisecond, 2*time.Millisecond)
 
 	return engine.NewBuilder().
@@ -29,8 +34,7 @@ func NewEngine() flows.Engine {
 			return newClassificationService(c), nil
 		}).
 		WithTicketServiceFactory(func(s flows.Session, t *flows.Ticketer) (flows.TicketService, error) { return NewTicketService(t), nil }).
-		WithAirtimeServiceFactory(func(flows.Session) (flows.AirtimeService, error) { return newAirtimeService("RWF"), nil }).
-		Build()
+		WithAirtimeServiceFactory(func(flows.Session) (flows.AirtimeService, error) { return newAirtimeService("RWF"), nil })
 }
 
 // implementation of an email service for testing which just fakes sending the email
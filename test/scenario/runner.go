@@ -0,0 +1,356 @@
+package scenario
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nyaruka/gocommon/dates"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/gocommon/uuids"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/excellent/types"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/services/webhooks"
+	"github.com/nyaruka/goflow/test/coverage"
+	"github.com/nyaruka/goflow/test/stubs"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// Result is the outcome of running a single scenario
+type Result struct {
+	Description string   `json:"description"`
+	Failures    []string `json:"failures,omitempty"`
+}
+
+// Passed returns whether the scenario passed
+func (r *Result) Passed() bool { return len(r.Failures) == 0 }
+
+// Run runs each scenario in the given suite and checks the flow behaves as expected.
+//
+// Webhook calls are answered with the scenario's mocked HTTP responses, so no real requests are made. UUIDs and the
+// current time come from the engine and are the same every time a scenario is run.
+func Run(sa flows.SessionAssets, suite *Suite) ([]*Result, error) {
+	return runSuite(sa, suite, false, nil)
+}
+
+// RunWithCoverage is like Run but also adds the session of each scenario to the given coverage collector
+func RunWithCoverage(sa flows.SessionAssets, suite *Suite, collector *coverage.Collector) ([]*Result, error) {
+	return runSuite(sa, suite, false, collector)
+}
+
+// Update runs each scenario in the given suite and rewrites its expected messages and expectations from what
+// the flow actually did, like updating a test snapshot. Only errors which prevent a scenario from running
+// are reported as failures.
+func Update(sa flows.SessionAssets, suite *Suite) ([]*Result, error) {
+	return runSuite(sa, suite, true, nil)
+}
+
+func runSuite(sa flows.SessionAssets, suite *Suite, update bool, collector *coverage.Collector) ([]*Result, error) {
+	flow, err := sa.Flows().Get(suite.Flow.UUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading flow %s", suite.Flow.UUID)
+	}
+
+	env := envs.NewBuilder().Build()
+	if suite.Environment != nil {
+		if env, err = envs.ReadEnvironment(suite.Environment); err != nil {
+			return nil, errors.Wrap(err, "error reading environment")
+		}
+	}
+
+	results := make([]*Result, len(suite.Scenarios))
+	for i, sc := range suite.Scenarios {
+		r := &runner{sa: sa, env: env, flow: flow, scenario: sc, update: update, collector: collector}
+
+		contact := sc.Contact
+		if contact == nil {
+			contact = suite.Contact
+		}
+
+		if err := r.run(contact); err != nil {
+			r.failures = append(r.failures, err.Error())
+		}
+
+		results[i] = &Result{Description: sc.Description, Failures: r.failures}
+	}
+
+	return results, nil
+}
+
+type runner struct {
+	sa        flows.SessionAssets
+	env       envs.Environment
+	flow      flows.Flow
+	scenario  *Scenario
+	update    bool
+	collector *coverage.Collector
+
+	failures   []string
+	transcript []*Turn
+}
+
+// records a mismatch between what was expected and what happened - ignored when updating
+func (r *runner) fail(format string, args ...interface{}) {
+	if !r.update {
+		r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	}
+}
+
+// runs the scenario, returning an error if it couldn't be run at all
+func (r *runner) run(contactJSON []byte) error {
+	sc := r.scenario
+
+	if contactJSON == nil {
+		contactJSON = defaultContact
+	}
+	contact, err := flows.ReadContact(r.sa, contactJSON, assets.IgnoreMissing)
+	if err != nil {
+		return errors.Wrap(err, "error reading contact")
+	}
+
+	transport := newMockTransport(sc.HTTPMocks)
+	classifications := stubs.NewClassificationService(sc.Classifications, true)
+	tickets := stubs.NewTicketService(sc.Tickets, true)
+
+	defer r.checkMocks(transport, classifications, tickets)
+
+	// generated UUIDs and timestamps should be the same every time a scenario is run
+	eng := engine.NewBuilder().
+		WithClock(dates.NewSequentialNowSource(time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC))).
+		WithUUIDGenerator(uuids.NewSeededGenerator(123456)).
+		WithEmailServiceFactory(func(flows.Session) (flows.EmailService, error) { return &stubs.EmailService{}, nil }).
+		WithWebhookServiceFactory(webhooks.NewServiceFactory(&http.Client{Transport: transport}, nil, nil, map[string]string{"User-Agent": "goflow-flowtest"}, 10000)).
+		WithClassificationServiceFactory(func(s flows.Session, c *flows.Classifier) (flows.ClassificationService, error) {
+			return classifications, nil
+		}).
+		WithTicketServiceFactory(func(s flows.Session, t *flows.Ticketer) (flows.TicketService, error) {
+			return tickets.ForTicketer(t), nil
+		}).
+		Build()
+
+	trigger, err := r.buildTrigger(contact)
+	if err != nil {
+		return err
+	}
+
+	session, sprint, err := eng.NewSession(r.sa, trigger)
+	if err != nil {
+		return errors.Wrap(err, "error starting session")
+	}
+
+	sprints := []flows.Sprint{sprint}
+	if r.collector != nil {
+		defer func() { r.collector.Add(session, sprints) }()
+	}
+
+	outbox := r.receive(sprint)
+
+	for i, turn := range sc.Conversation {
+		if !turn.isInput() {
+			if len(outbox) == 0 {
+				r.fail("turn %d: expected message \"%s\", got none", i+1, turn.Out)
+				continue
+			}
+			if outbox[0] != turn.Out {
+				r.fail("turn %d: expected message \"%s\", got \"%s\"", i+1, turn.Out, outbox[0])
+			}
+			outbox = outbox[1:]
+			continue
+		}
+
+		for _, unexpected := range outbox {
+			r.fail("turn %d: unexpected message \"%s\"", i+1, unexpected)
+		}
+
+		if session.Wait() == nil {
+			return errors.Errorf("turn %d: session isn't waiting for input", i+1)
+		}
+
+		var resume flows.Resume
+		if turn.Timeout {
+			resume = resumes.NewWaitTimeout(nil, nil)
+		} else {
+			resume = resumes.NewMsg(nil, nil, newMsg(contact, turn.In))
+		}
+		r.transcript = append(r.transcript, turn)
+
+		if sprint, err = session.Resume(resume); err != nil {
+			return errors.Wrapf(err, "turn %d: error resuming session", i+1)
+		}
+		sprints = append(sprints, sprint)
+
+		outbox = r.receive(sprint)
+	}
+
+	for _, unexpected := range outbox {
+		r.fail("unexpected message \"%s\"", unexpected)
+	}
+
+	actual := actualExpectations(session)
+
+	if r.update {
+		sc.Conversation = r.transcript
+		sc.Expect = actual
+	} else if sc.Expect != nil {
+		r.checkExpectations(sc.Expect, actual)
+	}
+
+	return nil
+}
+
+// checks that every mocked service response was used, and no unmocked HTTP requests were made
+func (r *runner) checkMocks(transport *mockTransport, classifications *stubs.ClassificationService, tickets *stubs.TicketService) {
+	for _, url := range transport.missing {
+		r.failures = append(r.failures, fmt.Sprintf("no mocked HTTP response for %s", url))
+	}
+	if transport.hasUnused() {
+		r.failures = append(r.failures, "not all mocked HTTP responses were used")
+	}
+	if classifications.Unused() > 0 {
+		r.failures = append(r.failures, fmt.Sprintf("%d mocked classification(s) were not used", classifications.Unused()))
+	}
+	if tickets.Unused() > 0 {
+		r.failures = append(r.failures, fmt.Sprintf("%d mocked ticket(s) were not used", tickets.Unused()))
+	}
+}
+
+func (r *runner) buildTrigger(contact *flows.Contact) (flows.Trigger, error) {
+	t := r.scenario.Trigger
+	if t == nil {
+		t = &Trigger{Type: TriggerTypeManual}
+	}
+
+	var params *types.XObject
+	if t.Params != nil {
+		var err error
+		if params, err = types.ReadXObject(t.Params); err != nil {
+			return nil, errors.Wrap(err, "error reading trigger params")
+		}
+	}
+
+	tb := triggers.NewBuilder(r.env, r.flow.Reference(), contact)
+
+	if t.Type == TriggerTypeMsg {
+		return tb.Msg(newMsg(contact, t.Text)).WithParams(params).Build(), nil
+	}
+	return tb.Manual().WithParams(params).Build(), nil
+}
+
+// gets the text of the messages sent in the given sprint, and adds them to our transcript
+func (r *runner) receive(sprint flows.Sprint) []string {
+	texts := make([]string, 0)
+	for _, e := range sprint.Events() {
+		switch typed := e.(type) {
+		case *events.MsgCreatedEvent:
+			texts = append(texts, typed.Msg.Text())
+		case *events.IVRCreatedEvent:
+			texts = append(texts, typed.Msg.Text())
+		}
+	}
+
+	for _, text := range texts {
+		r.transcript = append(r.transcript, &Turn{Out: text})
+	}
+	return texts
+}
+
+func (r *runner) checkExpectations(expected, actual *Expectations) {
+	if expected.Status != "" && expected.Status != actual.Status {
+		r.fail("expected session status '%s', got '%s'", expected.Status, actual.Status)
+	}
+
+	resultKeys := make([]string, 0, len(expected.Results))
+	for key := range expected.Results {
+		resultKeys = append(resultKeys, key)
+	}
+	sort.Strings(resultKeys)
+
+	for _, key := range resultKeys {
+		exp, act := expected.Results[key], actual.Results[key]
+		if act == nil {
+			r.fail("result '%s': expected value \"%s\", got none", key, exp.Value)
+			continue
+		}
+		if exp.Value != act.Value {
+			r.fail("result '%s': expected value \"%s\", got \"%s\"", key, exp.Value, act.Value)
+		}
+		if exp.Category != "" && exp.Category != act.Category {
+			r.fail("result '%s': expected category \"%s\", got \"%s\"", key, exp.Category, act.Category)
+		}
+	}
+
+	fieldKeys := make([]string, 0, len(expected.Fields))
+	for key := range expected.Fields {
+		fieldKeys = append(fieldKeys, key)
+	}
+	sort.Strings(fieldKeys)
+
+	for _, key := range fieldKeys {
+		if exp, act := expected.Fields[key], actual.Fields[key]; exp != act {
+			r.fail("field '%s': expected \"%s\", got \"%s\"", key, exp, act)
+		}
+	}
+
+	if expected.Groups != nil {
+		exp := append([]string(nil), expected.Groups...)
+		sort.Strings(exp)
+		if strings.Join(exp, ", ") != strings.Join(actual.Groups, ", ") {
+			r.fail("expected groups [%s], got [%s]", strings.Join(exp, ", "), strings.Join(actual.Groups, ", "))
+		}
+	}
+}
+
+// builds expectations from the actual state of a session
+func actualExpectations(session flows.Session) *Expectations {
+	e := &Expectations{
+		Status:  session.Status(),
+		Results: make(map[string]*ResultExpectation),
+		Fields:  make(map[string]string),
+		Groups:  make([]string, 0),
+	}
+
+	// results of subflows are prefixed with the subflow name, and if a subflow is entered more than once, the last
+	// run's results are used
+	mainFlow := session.Runs()[0].FlowReference().UUID
+	for _, run := range session.Runs() {
+		prefix := ""
+		if run.FlowReference().UUID != mainFlow {
+			prefix = utils.Snakify(run.FlowReference().Name) + "."
+		}
+
+		for key, result := range run.Results() {
+			e.Results[prefix+key] = &ResultExpectation{Value: result.Value, Category: result.Category}
+		}
+	}
+
+	contact := session.Contact()
+	for key, value := range contact.Fields() {
+		if value != nil {
+			e.Fields[key] = value.Text.Native()
+		}
+	}
+	for _, group := range contact.Groups().All() {
+		e.Groups = append(e.Groups, group.Name())
+	}
+	sort.Strings(e.Groups)
+
+	return e
+}
+
+func newMsg(contact *flows.Contact, text string) *flows.MsgIn {
+	urn := urns.NilURN
+	if len(contact.URNs()) > 0 {
+		urn = contact.URNs()[0].URN()
+	}
+	return flows.NewMsgIn(flows.MsgUUID(uuids.New()), urn, nil, text, nil)
+}
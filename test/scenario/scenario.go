@@ -0,0 +1,131 @@
+package scenario
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/nyaruka/gocommon/httpx"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/test/stubs"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// the contact used when neither the suite nor the scenario provide one
+var defaultContact = []byte(`{
+	"uuid": "ba96bf7f-bc2a-4873-a7c7-254d1927c4e3",
+	"name": "Ben Haggerty",
+	"language": "eng",
+	"created_on": "2018-01-01T12:00:00.000000000-00:00",
+	"urns": ["tel:+12065551212"]
+}`)
+
+// Suite is a set of scenarios for a single flow, e.g.
+//
+//   {
+//     "flow": {"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Favorites"},
+//     "scenarios": [
+//       {
+//         "description": "likes red",
+//         "conversation": [
+//           {"out": "What is your favorite color?"},
+//           {"in": "red"},
+//           {"out": "Red it is!"}
+//         ],
+//         "expect": {
+//           "status": "completed",
+//           "results": {"color": {"value": "red", "category": "Red"}}
+//         }
+//       }
+//     ]
+//   }
+type Suite struct {
+	Flow        *assets.FlowReference `json:"flow" validate:"required"`
+	Environment json.RawMessage       `json:"environment,omitempty"`
+	Contact     json.RawMessage       `json:"contact,omitempty"`
+	Scenarios   []*Scenario           `json:"scenarios" validate:"required,min=1,dive"`
+}
+
+// Scenario is a scripted conversation with a flow and the state we expect the session to end up in
+type Scenario struct {
+	Description     string                           `json:"description" validate:"required"`
+	Contact         json.RawMessage                  `json:"contact,omitempty"`
+	Trigger         *Trigger                         `json:"trigger,omitempty"`
+	HTTPMocks       map[string][]*httpx.MockResponse `json:"http_mocks,omitempty"`
+	Classifications []*flows.Classification          `json:"classifications,omitempty"`
+	Tickets         []*TicketMock                    `json:"tickets,omitempty"`
+	Conversation    []*Turn                          `json:"conversation" validate:"dive"`
+	Expect          *Expectations                    `json:"expect,omitempty"`
+}
+
+// TriggerType is the type of trigger used to start a scenario
+type TriggerType string
+
+// the supported trigger types
+const (
+	TriggerTypeManual TriggerType = "manual"
+	TriggerTypeMsg    TriggerType = "msg"
+)
+
+// Trigger describes how a scenario's session is started. If not specified, sessions are started manually.
+type Trigger struct {
+	Type   TriggerType     `json:"type" validate:"required,eq=manual|eq=msg"`
+	Text   string          `json:"text,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Turn is a single step in a conversation: either a message we expect the flow to send, an input from the
+// contact, or a timeout of the current wait
+type Turn struct {
+	Out     string `json:"out,omitempty"`
+	In      string `json:"in,omitempty"`
+	Timeout bool   `json:"timeout,omitempty"`
+}
+
+func (t *Turn) isInput() bool { return t.In != "" || t.Timeout }
+
+// TicketMock is a mocked response from a ticket service - either the external ID of the opened ticket or an error
+type TicketMock = stubs.TicketMock
+
+// ResultExpectation is the value and category we expect a result to have. An empty category isn't checked.
+type ResultExpectation struct {
+	Value    string `json:"value"`
+	Category string `json:"category,omitempty"`
+}
+
+// Expectations are the assertions made on the session after the conversation has finished. Only the
+// things specified are checked, and an empty field value means the field should be unset. Results saved
+// by subflows are keyed by the snakified name of the subflow and the result key, e.g. "registration.age".
+type Expectations struct {
+	Status  flows.SessionStatus           `json:"status,omitempty"`
+	Results map[string]*ResultExpectation `json:"results,omitempty"`
+	Fields  map[string]string             `json:"fields,omitempty"`
+	Groups  []string                      `json:"groups,omitempty"`
+}
+
+// ReadSuite reads a suite of scenarios from the given JSON
+func ReadSuite(data []byte) (*Suite, error) {
+	s := &Suite{}
+	if err := utils.UnmarshalAndValidate(data, s); err != nil {
+		return nil, errors.Wrap(err, "unable to read scenarios")
+	}
+	for i, sc := range s.Scenarios {
+		for j, turn := range sc.Conversation {
+			if (turn.Out != "" && turn.isInput()) || (turn.In != "" && turn.Timeout) || (turn.Out == "" && !turn.isInput()) {
+				return nil, errors.Errorf("scenario %d, turn %d must have exactly one of out, in or timeout", i+1, j+1)
+			}
+		}
+	}
+	return s, nil
+}
+
+// LoadSuite reads a suite of scenarios from the given file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading scenarios file '%s'", path)
+	}
+	return ReadSuite(data)
+}
@@ -0,0 +1,175 @@
+package scenario_test
+
+import (
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/test/scenario"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSuite(t *testing.T) {
+	_, err := scenario.ReadSuite([]byte(`{"flow": {"uuid": "5f3a8c2e-7b1d-4e6a-9c0f-2d8b4a6e1c37", "name": "Support"}}`))
+	assert.EqualError(t, err, "unable to read scenarios: field 'scenarios' is required")
+
+	_, err = scenario.ReadSuite([]byte(`{
+		"flow": {"uuid": "5f3a8c2e-7b1d-4e6a-9c0f-2d8b4a6e1c37", "name": "Support"},
+		"scenarios": [{"description": "test", "conversation": [{"out": "Hi", "in": "Hello"}]}]
+	}`))
+	assert.EqualError(t, err, "scenario 1, turn 1 must have exactly one of out, in or timeout")
+
+	_, err = scenario.LoadSuite("testdata/missing.json")
+	assert.EqualError(t, err, "error reading scenarios file 'testdata/missing.json': open testdata/missing.json: no such file or directory")
+}
+
+func TestRun(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "testdata/assets.json")
+	require.NoError(t, err)
+
+	suite, err := scenario.LoadSuite("testdata/support.json")
+	require.NoError(t, err)
+
+	// HTTP mocks are written back in the same format they're read in
+	test.AssertEqualJSON(t, []byte(`{
+		"http://example.com/balance?urn=tel%3A%2B12065551212": [{"status": 200, "body": "{\"balance\": \"12.50\", \"plan\": \"Gold\"}"}]
+	}`), jsonx.MustMarshal(suite.Scenarios[0].HTTPMocks), "HTTP mocks mismatch")
+
+	results, err := scenario.Run(sa, suite)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for _, r := range results {
+		assert.True(t, r.Passed(), "scenario '%s' failed: %v", r.Description, r.Failures)
+	}
+
+	// break some expectations of the first scenario
+	suite, _ = scenario.LoadSuite("testdata/support.json")
+	sc := suite.Scenarios[0]
+	sc.Conversation[0].Out = "Hello!"
+	sc.Conversation = sc.Conversation[:3]
+	sc.Expect.Status = "waiting"
+	sc.Expect.Results["problem"].Value = "how much?"
+	sc.Expect.Results["intent"].Category = "Other"
+	sc.Expect.Results["color"] = &scenario.ResultExpectation{Value: "red"}
+	sc.Expect.Fields["plan"] = "Silver"
+	sc.Expect.Groups = []string{}
+	sc.Classifications = append(sc.Classifications, sc.Classifications[0])
+
+	// and make the second scenario miss a mocked ticket
+	suite.Scenarios[1].Tickets = nil
+
+	results, err = scenario.Run(sa, suite)
+	require.NoError(t, err)
+
+	assert.False(t, results[0].Passed())
+	assert.Equal(t, []string{
+		`turn 1: expected message "Hello!", got "Hi Ben Haggerty! What can we help you with?"`,
+		`result 'color': expected value "red", got none`,
+		`result 'intent': expected category "Other", got "Billing"`,
+		`result 'problem': expected value "how much?", got "how much do I owe?"`,
+		`field 'plan': expected "Silver", got "Gold"`,
+		`expected groups [], got [Billing]`,
+		`1 mocked classification(s) were not used`,
+	}, results[0].Failures)
+
+	assert.False(t, results[1].Passed())
+	assert.Equal(t, []string{
+		`result 'ticket': expected value "5ecda5fc-951c-437b-a17e-f85e49829fb9", got ""`,
+		`result 'ticket': expected category "Success", got "Failure"`,
+	}, results[1].Failures)
+
+	// a webhook call without a mocked response is reported
+	suite, _ = scenario.LoadSuite("testdata/support.json")
+	suite.Scenarios[0].HTTPMocks = nil
+
+	results, err = scenario.Run(sa, suite)
+	require.NoError(t, err)
+	assert.Contains(t, results[0].Failures, "no mocked HTTP response for http://example.com/balance?urn=tel%3A%2B12065551212")
+
+	// inputs when the session has ended are errors
+	suite, _ = scenario.LoadSuite("testdata/support.json")
+	suite.Scenarios[1].Conversation = append(suite.Scenarios[1].Conversation, &scenario.Turn{In: "hello?"})
+
+	results, err = scenario.Run(sa, suite)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"turn 3: session isn't waiting for input"}, results[1].Failures)
+
+	// flow must exist
+	suite.Flow.UUID = "a2b4c6d8-1e3f-4a5b-8c7d-9e0f1a2b3c4d"
+	_, err = scenario.Run(sa, suite)
+	assert.EqualError(t, err, "error loading flow a2b4c6d8-1e3f-4a5b-8c7d-9e0f1a2b3c4d: no such flow with UUID 'a2b4c6d8-1e3f-4a5b-8c7d-9e0f1a2b3c4d'")
+}
+
+func TestRunWithSubflow(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "testdata/assets.json")
+	require.NoError(t, err)
+
+	suite, err := scenario.LoadSuite("testdata/feedback.json")
+	require.NoError(t, err)
+
+	results, err := scenario.Run(sa, suite)
+	require.NoError(t, err)
+	assert.True(t, results[0].Passed(), "scenario failed: %v", results[0].Failures)
+
+	// results of the subflow are checked too
+	suite.Scenarios[0].Expect.Results["rating.rating"].Category = "Other"
+
+	results, err = scenario.Run(sa, suite)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`result 'rating.rating': expected category "Other", got "Good"`}, results[0].Failures)
+}
+
+func TestUpdate(t *testing.T) {
+	sa, err := test.LoadSessionAssets(envs.NewBuilder().Build(), "testdata/assets.json")
+	require.NoError(t, err)
+
+	expected, err := scenario.LoadSuite("testdata/support.json")
+	require.NoError(t, err)
+
+	// remove all expected messages and expectations
+	suite, _ := scenario.LoadSuite("testdata/support.json")
+	for _, sc := range suite.Scenarios {
+		inputs := make([]*scenario.Turn, 0)
+		for _, turn := range sc.Conversation {
+			if turn.Out == "" {
+				inputs = append(inputs, turn)
+			}
+		}
+		sc.Conversation = inputs
+		sc.Expect = nil
+	}
+
+	results, err := scenario.Update(sa, suite)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.True(t, r.Passed())
+	}
+
+	for i := range suite.Scenarios {
+		assert.Equal(t, expected.Scenarios[i].Conversation, suite.Scenarios[i].Conversation)
+	}
+
+	// updated suite should now pass
+	results, err = scenario.Run(sa, suite)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.True(t, r.Passed(), "scenario '%s' failed: %v", r.Description, r.Failures)
+	}
+
+	test.AssertEqualJSON(t, jsonx.MustMarshal(map[string]interface{}{
+		"status": "completed",
+		"results": map[string]interface{}{
+			"_intent":       map[string]string{"value": "billing", "category": "Success"},
+			"anything_else": map[string]string{"value": "2021-01-01T12:00:47.000000Z", "category": "No Response"},
+			"balance":       map[string]string{"value": "200", "category": "Success"},
+			"intent":        map[string]string{"value": "billing", "category": "Billing"},
+			"problem":       map[string]string{"value": "how much do I owe?", "category": "All Responses"},
+		},
+		"fields": map[string]string{"plan": "Gold"},
+		"groups": []string{"Billing"},
+	}), jsonx.MustMarshal(suite.Scenarios[0].Expect), "updated expectations mismatch")
+}
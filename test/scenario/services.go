@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"net/http"
+
+	"github.com/nyaruka/gocommon/httpx"
+
+	"github.com/pkg/errors"
+)
+
+// HTTP transport which answers requests with the mocked responses of a scenario, so that no real requests are made,
+// and records requests to URLs which weren't mocked
+type mockTransport struct {
+	mocks   map[string][]*httpx.MockResponse
+	missing []string
+}
+
+func newMockTransport(mocks map[string][]*httpx.MockResponse) *mockTransport {
+	// copy so that using up responses doesn't modify the scenario
+	copied := make(map[string][]*httpx.MockResponse, len(mocks))
+	for url, responses := range mocks {
+		copied[url] = responses
+	}
+	return &mockTransport{mocks: copied}
+}
+
+func (t *mockTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	url := request.URL.String()
+	responses := t.mocks[url]
+	if len(responses) == 0 {
+		t.missing = append(t.missing, url)
+		return nil, errors.New("no mocked response for URL")
+	}
+
+	next := responses[0]
+	t.mocks[url] = responses[1:]
+
+	if next.Status == 0 {
+		return nil, errors.New("unable to connect to server")
+	}
+	return next.Make(request), nil
+}
+
+func (t *mockTransport) hasUnused() bool {
+	for _, responses := range t.mocks {
+		if len(responses) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -284,6 +284,23 @@ var sessionAssets = `{
             ]
         }
     ],
+    "schedules": [
+        {
+            "uuid": "4c9ae0d5-5d43-4bd5-a6b3-68ee4b4c0bd6",
+            "name": "Office Hours",
+            "timezone": "America/Guayaquil",
+            "intervals": [
+                {"day": "mon", "start": "09:00", "end": "17:00"},
+                {"day": "tue", "start": "09:00", "end": "17:00"},
+                {"day": "wed", "start": "09:00", "end": "17:00"},
+                {"day": "thu", "start": "09:00", "end": "17:00"},
+                {"day": "fri", "start": "09:00", "end": "17:00"}
+            ],
+            "exceptions": [
+                {"date": "2018-12-25", "name": "Christmas Day"}
+            ]
+        }
+    ],
     "users": [
         {
             "email": "bob@nyaruka.com",
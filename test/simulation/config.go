@@ -0,0 +1,102 @@
+package simulation
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/utils"
+
+	"github.com/pkg/errors"
+)
+
+// the default maximum number of replies given to a single session
+const defaultMaxReplies = 20
+
+// Config describes how a flow is simulated, e.g.
+//
+//   {
+//     "flow": {"uuid": "615b8a0f-588c-4d20-a05f-363b0b4ce6f4", "name": "Favorites"},
+//     "query": "gender = female AND age > 18",
+//     "replies": {
+//       "favorite_color": [
+//         {"text": "red", "weight": 3},
+//         {"text": "blue", "weight": 1},
+//         {"timeout": true}
+//       ]
+//     },
+//     "script": [{"text": "yes"}],
+//     "webhooks": [
+//       {"status": 200, "body": "{\"ok\": true}", "weight": 9},
+//       {"status": 503, "weight": 1}
+//     ],
+//     "seed": 123
+//   }
+//
+// Waits whose node UUID or result key is in replies are answered with a reply sampled by weight. Other waits are
+// answered with the next reply from the script, and once the script runs out, sessions are left waiting.
+type Config struct {
+	Flow       *assets.FlowReference `json:"flow" validate:"required"`
+	Query      string                `json:"query,omitempty"`
+	Replies    map[string][]*Reply   `json:"replies,omitempty" validate:"dive,min=1,dive"`
+	Script     []*Reply              `json:"script,omitempty" validate:"dive"`
+	Webhooks   []*WebhookResponse    `json:"webhooks,omitempty" validate:"dive"`
+	Seed       int64                 `json:"seed,omitempty"`
+	MaxReplies int                   `json:"max_replies,omitempty"`
+}
+
+// Reply is a reply to a wait, which is either a message or a timeout of the wait
+type Reply struct {
+	Text    string `json:"text,omitempty"`
+	Timeout bool   `json:"timeout,omitempty"`
+	Weight  int    `json:"weight,omitempty"`
+}
+
+// WebhookResponse is a response to a webhook call
+type WebhookResponse struct {
+	Status int    `json:"status" validate:"required"`
+	Body   string `json:"body,omitempty"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+// ReadConfig reads a simulation config from the given JSON
+func ReadConfig(data json.RawMessage) (*Config, error) {
+	c := &Config{}
+	if err := utils.UnmarshalAndValidate(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadConfig loads a simulation config from the given JSON file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading simulation file '%s'", path)
+	}
+
+	c, err := ReadConfig(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading simulation file '%s'", path)
+	}
+	return c, nil
+}
+
+// finds the sampled replies for a wait at the given node
+func (c *Config) repliesFor(node flows.Node) []*Reply {
+	if replies, ok := c.Replies[string(node.UUID())]; ok {
+		return replies
+	}
+	if node.Router() != nil && node.Router().ResultName() != "" {
+		return c.Replies[utils.Snakify(node.Router().ResultName())]
+	}
+	return nil
+}
+
+func (c *Config) maxReplies() int {
+	if c.MaxReplies > 0 {
+		return c.MaxReplies
+	}
+	return defaultMaxReplies
+}
@@ -0,0 +1,226 @@
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/events"
+)
+
+// Report is the aggregated outcome of a simulation
+type Report struct {
+	Contacts  int                         `json:"contacts"`
+	Simulated int                         `json:"simulated"`
+	Statuses  map[flows.SessionStatus]int `json:"statuses"`
+	Segments  []*SegmentCount             `json:"segments"`
+	Results   []*ResultCount              `json:"results"`
+	Errors    []*ErrorCount               `json:"errors"`
+	Failures  []*Failure                  `json:"failures"`
+
+	segmentsByKey map[string]*SegmentCount
+	resultsByKey  map[string]*ResultCount
+	errorsByText  map[string]*ErrorCount
+}
+
+// SegmentCount is the number of sessions which went from an exit to a node at least once
+type SegmentCount struct {
+	FlowUUID        assets.FlowUUID `json:"flow_uuid"`
+	NodeUUID        flows.NodeUUID  `json:"node_uuid"`
+	ExitUUID        flows.ExitUUID  `json:"exit_uuid"`
+	DestinationUUID flows.NodeUUID  `json:"destination_uuid"`
+	Count           int             `json:"count"`
+}
+
+// ResultCount is the distribution of the categories of a result across the sessions which saved it
+type ResultCount struct {
+	Flow       *assets.FlowReference `json:"flow"`
+	Key        string                `json:"key"`
+	Name       string                `json:"name"`
+	Count      int                   `json:"count"`
+	Categories []*CategoryCount      `json:"categories"`
+}
+
+// CategoryCount is the number of sessions whose final value of a result had the given category
+type CategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ErrorCount is the number of sessions which logged an error with the given text
+type ErrorCount struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// Failure is a session which failed or couldn't be started or resumed
+type Failure struct {
+	Contact *flows.ContactReference `json:"contact"`
+	Error   string                  `json:"error"`
+}
+
+func newReport(contacts int) *Report {
+	return &Report{
+		Contacts:      contacts,
+		Statuses:      make(map[flows.SessionStatus]int),
+		Segments:      make([]*SegmentCount, 0),
+		Results:       make([]*ResultCount, 0),
+		Errors:        make([]*ErrorCount, 0),
+		Failures:      make([]*Failure, 0),
+		segmentsByKey: make(map[string]*SegmentCount),
+		resultsByKey:  make(map[string]*ResultCount),
+		errorsByText:  make(map[string]*ErrorCount),
+	}
+}
+
+// adds the outcome of a single session. Segments, results and errors are counted once per session, and are ordered by
+// when they were first seen.
+func (r *Report) add(contact *flows.Contact, session flows.Session, sprints []flows.Sprint, err error) {
+	r.Simulated++
+
+	if err != nil {
+		r.Failures = append(r.Failures, &Failure{Contact: contact.Reference(), Error: err.Error()})
+	}
+	if session == nil {
+		return
+	}
+
+	r.Statuses[session.Status()]++
+
+	if err == nil && session.Status() == flows.SessionStatusFailed {
+		r.Failures = append(r.Failures, &Failure{Contact: contact.Reference(), Error: failureText(sprints)})
+	}
+
+	seenSegments := make(map[string]bool)
+	seenErrors := make(map[string]bool)
+
+	for _, sprint := range sprints {
+		for _, seg := range sprint.Segments() {
+			key := fmt.Sprintf("%s>%s", seg.Exit().UUID(), seg.Destination().UUID())
+			if seenSegments[key] {
+				continue
+			}
+			seenSegments[key] = true
+
+			count := r.segmentsByKey[key]
+			if count == nil {
+				count = &SegmentCount{FlowUUID: seg.Flow().UUID(), NodeUUID: seg.Node().UUID(), ExitUUID: seg.Exit().UUID(), DestinationUUID: seg.Destination().UUID()}
+				r.segmentsByKey[key] = count
+				r.Segments = append(r.Segments, count)
+			}
+			count.Count++
+		}
+
+		for _, e := range sprint.Events() {
+			if typed, ok := e.(*events.ErrorEvent); ok && !seenErrors[typed.Text] {
+				seenErrors[typed.Text] = true
+
+				count := r.errorsByText[typed.Text]
+				if count == nil {
+					count = &ErrorCount{Text: typed.Text}
+					r.errorsByText[typed.Text] = count
+					r.Errors = append(r.Errors, count)
+				}
+				count.Count++
+			}
+		}
+	}
+
+	// results are counted from the final values in each run, so a result which is saved more than once is counted once
+	for _, run := range session.Runs() {
+		keys := make([]string, 0, len(run.Results()))
+		for key := range run.Results() {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return run.Results()[keys[i]].CreatedOn.Before(run.Results()[keys[j]].CreatedOn)
+		})
+
+		for _, key := range keys {
+			r.addResult(run.Flow(), key, run.Results()[key])
+		}
+	}
+}
+
+func (r *Report) addResult(flow flows.Flow, key string, result *flows.Result) {
+	fullKey := fmt.Sprintf("%s/%s", flow.UUID(), key)
+
+	count := r.resultsByKey[fullKey]
+	if count == nil {
+		count = &ResultCount{Flow: flow.Reference(), Key: key, Name: result.Name, Categories: make([]*CategoryCount, 0)}
+
+		// include all the categories of the router which saved the result, so that categories which no session hit
+		// are also reported
+		node := flow.GetNode(result.NodeUUID)
+		if node != nil && node.Router() != nil && node.Router().ResultName() == result.Name {
+			for _, c := range node.Router().Categories() {
+				count.Categories = append(count.Categories, &CategoryCount{Name: c.Name()})
+			}
+		}
+
+		r.resultsByKey[fullKey] = count
+		r.Results = append(r.Results, count)
+	}
+	count.Count++
+
+	for _, c := range count.Categories {
+		if c.Name == result.Category {
+			c.Count++
+			return
+		}
+	}
+	count.Categories = append(count.Categories, &CategoryCount{Name: result.Category, Count: 1})
+}
+
+// Write writes a human readable summary of the report, with the fraction of simulated contacts in each category of
+// each result
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "Simulated %d of %d contacts\n", r.Simulated, r.Contacts)
+
+	statuses := make([]string, 0, len(r.Statuses))
+	for status := range r.Statuses {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+
+	fmt.Fprintln(w, "\nStatuses:")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "  %-12s %6d %s\n", status, r.Statuses[flows.SessionStatus(status)], r.percent(r.Statuses[flows.SessionStatus(status)]))
+	}
+
+	fmt.Fprintln(w, "\nResults:")
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "  %s (%s) %d %s\n", res.Name, res.Flow.Name, res.Count, r.percent(res.Count))
+		for _, c := range res.Categories {
+			name := c.Name
+			if name == "" {
+				name = "<none>"
+			}
+			fmt.Fprintf(w, "    %-20s %6d %s\n", name, c.Count, r.percent(c.Count))
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, e := range r.Errors {
+			fmt.Fprintf(w, "  %6d %s\n", e.Count, e.Text)
+		}
+	}
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintln(w, "\nFailures:")
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "  %s: %s\n", f.Contact.Name, f.Error)
+		}
+	}
+}
+
+// formats the given count as a percentage of the simulated contacts
+func (r *Report) percent(count int) string {
+	if r.Simulated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%.1f%%)", 100*float64(count)/float64(r.Simulated))
+}
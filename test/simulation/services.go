@@ -0,0 +1,61 @@
+package simulation
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// samples an item from a list of weighted items, where items without a weight have a weight of 1
+func sample(rnd *rand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += weightOrDefault(w)
+	}
+
+	n := rnd.Intn(total)
+	for i, w := range weights {
+		n -= weightOrDefault(w)
+		if n < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+func weightOrDefault(w int) int {
+	if w <= 0 {
+		return 1
+	}
+	return w
+}
+
+// HTTP transport which answers every request with a sampled webhook response, so that no real requests are made
+type webhookTransport struct {
+	rnd       *rand.Rand
+	responses []*WebhookResponse
+}
+
+func (t *webhookTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	r := &WebhookResponse{Status: 200, Body: `{}`}
+	if len(t.responses) > 0 {
+		weights := make([]int, len(t.responses))
+		for i, resp := range t.responses {
+			weights[i] = resp.Weight
+		}
+		r = t.responses[sample(t.rnd, weights)]
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(r.Status),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}, nil
+}
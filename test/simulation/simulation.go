@@ -0,0 +1,192 @@
+// Package simulation runs a flow offline for a population of contacts, with stubbed services and sampled replies, and
+// reports what fraction of contacts take each path through the flow, e.g. to check a flow before starting it for a
+// large group.
+//
+// Random routers and replies are sampled from a random source seeded from the config, so the same config gives the
+// same report. Webhook calls are answered with sampled responses, classifiers never match any intents, and emails
+// and tickets are never actually sent or opened. Other services, e.g. airtime transfers, aren't available so any
+// actions which use them fail. No process-wide state is changed, so simulations can be run concurrently.
+package simulation
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/gocommon/urns"
+	"github.com/nyaruka/goflow/assets"
+	"github.com/nyaruka/goflow/contactql"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/flows/engine"
+	"github.com/nyaruka/goflow/flows/events"
+	"github.com/nyaruka/goflow/flows/resumes"
+	"github.com/nyaruka/goflow/flows/triggers"
+	"github.com/nyaruka/goflow/services/webhooks"
+	"github.com/nyaruka/goflow/test/stubs"
+
+	"github.com/pkg/errors"
+)
+
+// ReadContacts reads a JSON array of contacts. References to assets which don't exist are ignored.
+func ReadContacts(sa flows.SessionAssets, data json.RawMessage) ([]*flows.Contact, error) {
+	items := make([]json.RawMessage, 0)
+	if err := jsonx.Unmarshal(data, &items); err != nil {
+		return nil, errors.Wrap(err, "error reading contacts")
+	}
+
+	contacts := make([]*flows.Contact, len(items))
+	for i, item := range items {
+		contact, err := flows.ReadContact(sa, item, assets.IgnoreMissing)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading contact[%d]", i)
+		}
+		contacts[i] = contact
+	}
+	return contacts, nil
+}
+
+// Simulate runs the configured flow for each of the given contacts which match the config's query, and returns a
+// report of how the sessions went. An error is only returned if the simulation can't be run at all, and errors
+// starting or resuming individual sessions are reported as failures.
+func Simulate(env envs.Environment, sa flows.SessionAssets, contacts []*flows.Contact, config *Config) (*Report, error) {
+	flow, err := sa.Flows().Get(config.Flow.UUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading flow %s", config.Flow.UUID)
+	}
+
+	var query *contactql.ContactQuery
+	if config.Query != "" {
+		if query, err = contactql.ParseQuery(env, config.Query, sa); err != nil {
+			return nil, errors.Wrap(err, "error parsing query")
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(config.Seed))
+	transport := &webhookTransport{rnd: rnd, responses: config.Webhooks}
+
+	classifications := stubs.NewClassificationService(nil, false)
+	tickets := stubs.NewTicketService(nil, false)
+
+	eng := engine.NewBuilder().
+		WithEmailServiceFactory(func(flows.Session) (flows.EmailService, error) { return &stubs.EmailService{}, nil }).
+		WithWebhookServiceFactory(webhooks.NewServiceFactory(&http.Client{Transport: transport}, nil, nil, map[string]string{"User-Agent": "goflow-simulation"}, 10000)).
+		WithClassificationServiceFactory(func(flows.Session, *flows.Classifier) (flows.ClassificationService, error) {
+			return classifications, nil
+		}).
+		WithTicketServiceFactory(func(s flows.Session, t *flows.Ticketer) (flows.TicketService, error) {
+			return tickets.ForTicketer(t), nil
+		}).
+		WithRandom(rand.New(rand.NewSource(config.Seed))).
+		Build()
+
+	report := newReport(len(contacts))
+
+	for _, contact := range contacts {
+		if query != nil && !contactql.EvaluateQuery(env, query, contact) {
+			continue
+		}
+
+		s := &simulator{eng: eng, sa: sa, env: env, flow: flow, config: config, rnd: rnd}
+		session, sprints, err := s.run(contact)
+		report.add(contact, session, sprints, err)
+	}
+
+	return report, nil
+}
+
+// simulates a single session
+type simulator struct {
+	eng    flows.Engine
+	sa     flows.SessionAssets
+	env    envs.Environment
+	flow   flows.Flow
+	config *Config
+	rnd    *rand.Rand
+
+	scriptPosition int
+}
+
+func (s *simulator) run(contact *flows.Contact) (flows.Session, []flows.Sprint, error) {
+	trigger := triggers.NewBuilder(s.env, s.flow.Reference(), contact).Manual().Build()
+
+	session, sprint, err := s.eng.NewSession(s.sa, trigger)
+	if err != nil {
+		return session, nil, errors.Wrap(err, "error starting session")
+	}
+
+	sprints := []flows.Sprint{sprint}
+
+	for i := 0; session.Status() == flows.SessionStatusWaiting && i < s.config.maxReplies(); i++ {
+		reply := s.nextReply(session)
+		if reply == nil {
+			break
+		}
+
+		var resume flows.Resume
+		if reply.Timeout {
+			resume = resumes.NewWaitTimeout(nil, nil)
+		} else {
+			urn := urns.NilURN
+			if u := session.Contact().PreferredURN(); u != nil {
+				urn = u.URN()
+			}
+			msg := flows.NewMsgIn(flows.MsgUUID(s.eng.UUIDs().Next()), urn, nil, reply.Text, nil)
+			resume = resumes.NewMsg(nil, nil, msg)
+		}
+
+		sprint, err := session.Resume(resume)
+		if err != nil {
+			return session, sprints, errors.Wrapf(err, "error resuming session with reply %d", i+1)
+		}
+		sprints = append(sprints, sprint)
+	}
+
+	return session, sprints, nil
+}
+
+// gets the reply to the wait the session is currently at, or nil if there isn't one
+func (s *simulator) nextReply(session flows.Session) *Reply {
+	if node := waitingNode(session); node != nil {
+		if replies := s.config.repliesFor(node); len(replies) > 0 {
+			weights := make([]int, len(replies))
+			for i, r := range replies {
+				weights[i] = r.Weight
+			}
+			return replies[sample(s.rnd, weights)]
+		}
+	}
+
+	if s.scriptPosition < len(s.config.Script) {
+		reply := s.config.Script[s.scriptPosition]
+		s.scriptPosition++
+		return reply
+	}
+	return nil
+}
+
+// gets the node of the run which is waiting
+func waitingNode(session flows.Session) flows.Node {
+	for _, run := range session.Runs() {
+		if run.Status() == flows.RunStatusWaiting {
+			_, node, err := run.PathLocation()
+			if err == nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// gets the text of the first failure event in the given sprints
+func failureText(sprints []flows.Sprint) string {
+	for _, sprint := range sprints {
+		for _, e := range sprint.Events() {
+			if typed, ok := e.(*events.FailureEvent); ok {
+				return typed.Text
+			}
+		}
+	}
+	return "session failed"
+}
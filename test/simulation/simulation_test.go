@@ -0,0 +1,168 @@
+package simulation_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nyaruka/gocommon/jsonx"
+	"github.com/nyaruka/goflow/envs"
+	"github.com/nyaruka/goflow/flows"
+	"github.com/nyaruka/goflow/test"
+	"github.com/nyaruka/goflow/test/simulation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadPopulation(t *testing.T) (envs.Environment, flows.SessionAssets, []*flows.Contact) {
+	env := envs.NewBuilder().Build()
+
+	sa, err := test.LoadSessionAssets(env, "testdata/assets.json")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("testdata/contacts.json")
+	require.NoError(t, err)
+
+	contacts, err := simulation.ReadContacts(sa, data)
+	require.NoError(t, err)
+	require.Equal(t, 12, len(contacts))
+
+	return env, sa, contacts
+}
+
+func categoryCounts(r *simulation.ResultCount) map[string]int {
+	counts := make(map[string]int, len(r.Categories))
+	for _, c := range r.Categories {
+		counts[c.Name] = c.Count
+	}
+	return counts
+}
+
+func TestSimulate(t *testing.T) {
+	env, sa, contacts := loadPopulation(t)
+
+	config, err := simulation.LoadConfig("testdata/simulation.json")
+	require.NoError(t, err)
+
+	report, err := simulation.Simulate(env, sa, contacts, config)
+	require.NoError(t, err)
+
+	// only adults are simulated
+	assert.Equal(t, 12, report.Contacts)
+	assert.Equal(t, 9, report.Simulated)
+	assert.Equal(t, map[flows.SessionStatus]int{flows.SessionStatusCompleted: 9}, report.Statuses)
+	assert.Equal(t, 0, len(report.Failures))
+	assert.Equal(t, 0, len(report.Errors))
+
+	require.Equal(t, 4, len(report.Results))
+
+	// splits on fields depend only on the contacts
+	assert.Equal(t, "gender", report.Results[0].Key)
+	assert.Equal(t, 9, report.Results[0].Count)
+	assert.Equal(t, map[string]int{"Male": 5, "Female": 3, "Other": 1}, categoryCounts(report.Results[0]))
+
+	// all categories of a router are included, in the order they're defined, even if no session hit them
+	assert.Equal(t, "favorite_color", report.Results[2].Key)
+	assert.Equal(t, "Red", report.Results[2].Categories[0].Name)
+	assert.Equal(t, "Blue", report.Results[2].Categories[1].Name)
+	assert.Equal(t, "Other", report.Results[2].Categories[2].Name)
+	assert.Equal(t, "No Response", report.Results[2].Categories[3].Name)
+
+	// random splits and sampled replies and webhook responses still add up
+	for _, r := range report.Results[1:3] {
+		total := 0
+		for _, c := range r.Categories {
+			total += c.Count
+		}
+		assert.Equal(t, 9, total, "category counts mismatch for result %s", r.Key)
+	}
+
+	// contacts who replied red or blue were sent to the webhook
+	colors := categoryCounts(report.Results[2])
+	assert.Equal(t, "save", report.Results[3].Key)
+	assert.Equal(t, colors["Red"]+colors["Blue"], report.Results[3].Count)
+
+	// and the same config gives the same report
+	report2, err := simulation.Simulate(env, sa, contacts, config)
+	require.NoError(t, err)
+	assert.Equal(t, string(jsonx.MustMarshal(report)), string(jsonx.MustMarshal(report2)))
+
+	out := &bytes.Buffer{}
+	report.Write(out)
+	test.AssertSnapshot(t, "report", out.String())
+}
+
+func TestSimulateWithScript(t *testing.T) {
+	env, sa, contacts := loadPopulation(t)
+
+	config, err := simulation.ReadConfig([]byte(`{
+		"flow": {"uuid": "a8d3a5e2-5d2e-4c8a-9f3a-0e2a2c2a3b41", "name": "Favorite Colors"},
+		"script": [{"text": "blue"}],
+		"webhooks": [{"status": 200}]
+	}`))
+	require.NoError(t, err)
+
+	report, err := simulation.Simulate(env, sa, contacts, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 12, report.Simulated)
+	assert.Equal(t, map[flows.SessionStatus]int{flows.SessionStatusCompleted: 12}, report.Statuses)
+	assert.Equal(t, map[string]int{"Red": 0, "Blue": 12, "Other": 0, "No Response": 0}, categoryCounts(report.Results[2]))
+	assert.Equal(t, map[string]int{"Success": 12}, categoryCounts(report.Results[3]))
+
+	// without replies sessions are left waiting
+	config.Script = nil
+
+	report, err = simulation.Simulate(env, sa, contacts, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[flows.SessionStatus]int{flows.SessionStatusWaiting: 12}, report.Statuses)
+	assert.Equal(t, 2, len(report.Results))
+}
+
+func TestSimulateFailures(t *testing.T) {
+	env, sa, contacts := loadPopulation(t)
+
+	config, err := simulation.ReadConfig([]byte(`{"flow": {"uuid": "76f0a02f-3b75-4b86-9064-e9195e1b3a02", "name": "Broken"}, "query": "gender = male"}`))
+	require.NoError(t, err)
+
+	report, err := simulation.Simulate(env, sa, contacts, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Simulated)
+	assert.Equal(t, map[flows.SessionStatus]int{flows.SessionStatusFailed: 6}, report.Statuses)
+
+	// errors are counted once per session
+	require.Equal(t, 1, len(report.Errors))
+	assert.Equal(t, "error evaluating @(fields.age / 0): division by zero", report.Errors[0].Text)
+	assert.Equal(t, 6, report.Errors[0].Count)
+
+	require.Equal(t, 6, len(report.Failures))
+	assert.Equal(t, "Bob Smith", report.Failures[0].Contact.Name)
+	assert.Equal(t, "reached maximum number of steps per sprint (100)", report.Failures[0].Error)
+
+	// errors which prevent the simulation from running at all
+	config.Query = "gender = "
+	_, err = simulation.Simulate(env, sa, contacts, config)
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "error parsing query: "), "got: %s", err)
+	}
+
+	config.Flow.UUID = "d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c"
+	_, err = simulation.Simulate(env, sa, contacts, config)
+	assert.EqualError(t, err, "error loading flow d9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c: no such flow with UUID 'd9f2ffd5-7f8e-4e2e-b5ab-ed1dd1ebaf1c'")
+}
+
+func TestReadConfig(t *testing.T) {
+	_, err := simulation.ReadConfig([]byte(`{"replies": {"color": []}}`))
+	assert.EqualError(t, err, "field 'flow' is required, field 'replies[color]' must have a minimum of 1 items")
+
+	_, err = simulation.LoadConfig("testdata/xxx.json")
+	assert.EqualError(t, err, "error reading simulation file 'testdata/xxx.json': open testdata/xxx.json: no such file or directory")
+
+	_, sa, _ := loadPopulation(t)
+	_, err = simulation.ReadContacts(sa, []byte(`[{"name": "Bob"}]`))
+	assert.EqualError(t, err, "error reading contact[0]: unable to read contact: field 'uuid' is required, field 'created_on' is required")
+}
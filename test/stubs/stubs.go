@@ -0,0 +1,105 @@
+// Package stubs provides stand-ins for the services used by the engine, so that flows can be run by scenarios and
+// simulations without calling any external services.
+package stubs
+
+import (
+	"github.com/nyaruka/goflow/flows"
+
+	"github.com/pkg/errors"
+)
+
+// EmailService is an email service which pretends to send emails
+type EmailService struct{}
+
+// Send pretends to send an email
+func (s *EmailService) Send(session flows.Session, addresses []string, subject, body string) error {
+	return nil
+}
+
+var _ flows.EmailService = (*EmailService)(nil)
+
+// ClassificationService is a classification service which returns queued classifications in order. Once they've all
+// been used, it returns an error if it's strict, and otherwise a classification with no intents.
+type ClassificationService struct {
+	queue  []*flows.Classification
+	strict bool
+}
+
+// NewClassificationService creates a new classification service with the given queued classifications
+func NewClassificationService(queue []*flows.Classification, strict bool) *ClassificationService {
+	return &ClassificationService{queue: queue, strict: strict}
+}
+
+// Classify returns the next queued classification
+func (s *ClassificationService) Classify(session flows.Session, input string, logHTTP flows.HTTPLogCallback) (*flows.Classification, error) {
+	if len(s.queue) == 0 {
+		if s.strict {
+			return nil, errors.New("no mocked classification")
+		}
+		return &flows.Classification{}, nil
+	}
+
+	next := s.queue[0]
+	s.queue = s.queue[1:]
+	return next, nil
+}
+
+// Unused returns the number of queued classifications which haven't been used
+func (s *ClassificationService) Unused() int { return len(s.queue) }
+
+var _ flows.ClassificationService = (*ClassificationService)(nil)
+
+// TicketMock is a queued result of opening a ticket - either the external ID of the opened ticket or an error
+type TicketMock struct {
+	ExternalID string `json:"external_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TicketService opens tickets using queued results in order. Once they've all been used, it returns an error if it's
+// strict, and otherwise opens tickets without external IDs. Ticket UUIDs come from the engine so they're the same
+// every time a flow is run by an engine with a seeded UUID generator.
+type TicketService struct {
+	queue  []*TicketMock
+	strict bool
+}
+
+// NewTicketService creates a new ticket service with the given queued results
+func NewTicketService(queue []*TicketMock, strict bool) *TicketService {
+	return &TicketService{queue: queue, strict: strict}
+}
+
+// ForTicketer returns a service which opens tickets with the given ticketer using the results queued on this service
+func (s *TicketService) ForTicketer(ticketer *flows.Ticketer) flows.TicketService {
+	return &ticketerService{tickets: s, ticketer: ticketer}
+}
+
+// Unused returns the number of queued results which haven't been used
+func (s *TicketService) Unused() int { return len(s.queue) }
+
+func (s *TicketService) open(session flows.Session, ticketer *flows.Ticketer, topic *flows.Topic, body string, assignee *flows.User) (*flows.Ticket, error) {
+	next := &TicketMock{}
+	if len(s.queue) > 0 {
+		next = s.queue[0]
+		s.queue = s.queue[1:]
+	} else if s.strict {
+		return nil, errors.New("no mocked ticket")
+	}
+
+	if next.Error != "" {
+		return nil, errors.New(next.Error)
+	}
+
+	return flows.NewTicket(flows.TicketUUID(session.Engine().UUIDs().Next()), ticketer, topic, body, next.ExternalID, assignee), nil
+}
+
+// binds a ticket service to a ticketer
+type ticketerService struct {
+	tickets  *TicketService
+	ticketer *flows.Ticketer
+}
+
+func (s *ticketerService) Open(session flows.Session, topic *flows.Topic, body string, assignee *flows.User, logHTTP flows.HTTPLogCallback) (*flows.Ticket, error) {
+	return s.tickets.open(session, s.ticketer, topic, body, assignee)
+}
+
+var _ flows.TicketService = (*ticketerService)(nil)
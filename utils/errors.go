@@ -1,8 +1,37 @@
 package utils
 
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
 // RichError is a common interface for error types that can provide more detail
 type RichError interface {
 	error
 	Code() string
 	Extra() map[string]string
 }
+
+// ErrorPosition is the range of characters in some source text, e.g. a query or expression, that an error relates
+// to. Offsets are counted in characters rather than bytes, and end is exclusive.
+type ErrorPosition struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ExpectedTokens returns the display names of the tokens which the given parser would have accepted at its current
+// position, e.g. '(', NAME
+func ExpectedTokens(parser antlr.Parser) []string {
+	set := parser.GetExpectedTokens()
+	if set == nil {
+		return nil
+	}
+
+	// the only public way to get at the token types in a set is via its string representation
+	names := strings.Trim(set.StringVerbose(parser.GetLiteralNames(), parser.GetSymbolicNames(), false), "{}")
+	if names == "" {
+		return nil
+	}
+	return strings.Split(names, ", ")
+}
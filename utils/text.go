@@ -2,11 +2,14 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/blevesearch/segment"
+	"github.com/buger/jsonparser"
 )
 
 var snakedChars = regexp.MustCompile(`[^\p{L}\d_]+`)
@@ -136,6 +139,69 @@ func truncate(s string, limit int, ending string) string {
 	return string(runes[:limit-len(ending)]) + ending
 }
 
+// EditDistance returns the Levenshtein distance between the two given strings, i.e. the minimum number of single
+// character insertions, deletions or substitutions needed to turn one into the other
+func EditDistance(s1, s2 string) int {
+	r1, r2 := []rune(s1), []rune(s2)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = MinInt(MinInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(r2)]
+}
+
+// Suggest returns up to limit of the given candidates which are close enough to the given value to be plausible
+// corrections of it, ordered by closeness. Comparisons are case-insensitive.
+func Suggest(value string, candidates []string, limit int) []string {
+	value = strings.ToLower(value)
+	maxDistance := MaxInt(1, len([]rune(value))/3)
+
+	type match struct {
+		candidate string
+		distance  int
+	}
+	matches := make([]match, 0)
+	seen := make(map[string]bool, len(candidates))
+
+	for _, c := range candidates {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		d := EditDistance(value, strings.ToLower(c))
+		if d > 0 && d <= maxDistance {
+			matches = append(matches, match{c, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+
+	suggestions := make([]string, 0, limit)
+	for i := 0; i < len(matches) && i < limit; i++ {
+		suggestions = append(suggestions, matches[i].candidate)
+	}
+	return suggestions
+}
+
 // Redactor is a function which can redact the given string
 type Redactor func(s string) string
 
@@ -150,6 +216,104 @@ func NewRedactor(mask string, values ...string) Redactor {
 	return strings.NewReplacer(replacements...).Replace
 }
 
+// NewJSONRedactor creates a new redaction function which replaces the values at the given paths in JSON documents.
+// Paths are dot separated keys, e.g. "patient.diagnosis", where * matches every key of an object, and arrays along a
+// path are redacted item by item. Input which isn't JSON is returned unchanged.
+func NewJSONRedactor(mask string, paths ...string) Redactor {
+	maskJSON, _ := json.Marshal(mask)
+
+	return func(s string) string {
+		data := []byte(s)
+		for _, p := range paths {
+			data = redactJSONPath(data, maskJSON, []string{}, strings.Split(p, "."))
+		}
+		return string(data)
+	}
+}
+
+func redactJSONPath(data, mask []byte, at, rest []string) []byte {
+	value, valueType, _, err := jsonparser.Get(data, at...)
+	if err != nil {
+		return data
+	}
+
+	if len(rest) == 0 {
+		if valueType == jsonparser.Null {
+			return data // nothing to hide
+		}
+		if redacted, err := jsonparser.Set(data, mask, at...); err == nil {
+			return redacted
+		}
+		return data
+	}
+
+	switch valueType {
+	case jsonparser.Array:
+		count := 0
+		jsonparser.ArrayEach(value, func([]byte, jsonparser.ValueType, int, error) { count++ })
+
+		for i := 0; i < count; i++ {
+			data = redactJSONPath(data, mask, appendKey(at, fmt.Sprintf("[%d]", i)), rest)
+		}
+	case jsonparser.Object:
+		if rest[0] == "*" {
+			keys := make([]string, 0)
+			jsonparser.ObjectEach(value, func(key []byte, _ []byte, _ jsonparser.ValueType, _ int) error {
+				keys = append(keys, string(key))
+				return nil
+			})
+
+			for _, key := range keys {
+				data = redactJSONPath(data, mask, appendKey(at, key), rest[1:])
+			}
+		} else {
+			data = redactJSONPath(data, mask, appendKey(at, rest[0]), rest[1:])
+		}
+	}
+	return data
+}
+
+// appends a key to a path without modifying the original path
+func appendKey(path []string, key string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), key)
+}
+
+// NewHTTPRedactor creates a new redaction function for dumps of HTTP requests and responses, which replaces the values
+// of the given headers, and the values at the given paths in JSON bodies as described by NewJSONRedactor
+func NewHTTPRedactor(mask string, headers []string, bodyPaths []string) Redactor {
+	redactBody := NewJSONRedactor(mask, bodyPaths...)
+
+	return func(s string) string {
+		head, body, sep := s, "", ""
+		for _, candidate := range []string{"\r\n\r\n", "\n\n"} {
+			if i := strings.Index(s, candidate); i >= 0 {
+				head, body, sep = s[:i], s[i+len(candidate):], candidate
+				break
+			}
+		}
+
+		lines := strings.Split(head, "\n")
+		for i, line := range lines[1:] { // first line is the request or status line
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) < 2 {
+				continue
+			}
+			for _, h := range headers {
+				if strings.EqualFold(strings.TrimSpace(parts[0]), h) {
+					lines[i+1] = parts[0] + ": " + mask
+					if strings.HasSuffix(line, "\r") {
+						lines[i+1] += "\r"
+					}
+					break
+				}
+			}
+		}
+		head = strings.Join(lines, "\n")
+
+		return head + sep + redactBody(body)
+	}
+}
+
 // replaces any `\u0000` sequences with the given replacement sequence which may be empty.
 // A sequence such as `\\u0000` is preserved as it is an escaped slash followed by the sequence `u0000`
 func ReplaceEscapedNulls(data []byte, repl []byte) []byte {
@@ -180,6 +180,29 @@ func TestTruncate(t *testing.T) {
 	assert.Equal(t, "你喜欢我当然喜", utils.Truncate("你喜欢我当然喜欢的电", 7))
 }
 
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, utils.EditDistance("", ""))
+	assert.Equal(t, 3, utils.EditDistance("abc", ""))
+	assert.Equal(t, 3, utils.EditDistance("", "abc"))
+	assert.Equal(t, 0, utils.EditDistance("district", "district"))
+	assert.Equal(t, 1, utils.EditDistance("distrct", "district"))
+	assert.Equal(t, 2, utils.EditDistance("dsitrict", "district"))
+	assert.Equal(t, 3, utils.EditDistance("kitten", "sitting"))
+	assert.Equal(t, 1, utils.EditDistance("喜欢", "喜"))
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"district", "state", "ward", "age", "gender", "dob"}
+
+	assert.Equal(t, []string{"district"}, utils.Suggest("distrct", candidates, 3))
+	assert.Equal(t, []string{"district"}, utils.Suggest("DISTRCT", candidates, 3))
+	assert.Equal(t, []string{"start", "state", "stats"}, utils.Suggest("stat", []string{"stats", "state", "start", "xyz"}, 3))
+	assert.Equal(t, []string{"start", "state"}, utils.Suggest("stat", []string{"stats", "state", "start", "xyz"}, 2))
+	assert.Equal(t, []string{}, utils.Suggest("district", candidates, 3)) // exact matches aren't suggestions
+	assert.Equal(t, []string{}, utils.Suggest("xyz", candidates, 3))
+	assert.Equal(t, []string{}, utils.Suggest("xyz", nil, 3))
+}
+
 func TestRedactor(t *testing.T) {
 	assert.Equal(t, "hello world", utils.NewRedactor("****")("hello world"))                         // nothing to redact
 	assert.Equal(t, "", utils.NewRedactor("****", "abc")(""))                                        // empty input
@@ -187,6 +210,36 @@ func TestRedactor(t *testing.T) {
 	assert.Equal(t, "**** def **** jkl", utils.NewRedactor("****", "abc", "ghi")("abc def ghi jkl")) // all values redacted
 }
 
+func TestJSONRedactor(t *testing.T) {
+	redact := utils.NewJSONRedactor("****", "patient.diagnosis", "tests.result", "visits.*.notes", "missing.path")
+
+	assert.Equal(t, "", redact(""))
+	assert.Equal(t, "not json", redact("not json"))
+	assert.Equal(t, `{"foo": "bar"}`, redact(`{"foo": "bar"}`))
+	assert.Equal(t, `{"patient": {"name": "Bob", "diagnosis": "****"}}`, redact(`{"patient": {"name": "Bob", "diagnosis": "flu"}}`))
+	assert.Equal(t, `{"patient": {"diagnosis": "****"}}`, redact(`{"patient": {"diagnosis": {"code": 123, "text": "flu"}}}`))
+	assert.Equal(t, `{"patient": {"diagnosis": null}}`, redact(`{"patient": {"diagnosis": null}}`))
+	assert.Equal(t, `{"tests": [{"result": "****"}, {"id": 2}, {"result": "****"}]}`, redact(`{"tests": [{"result": "positive"}, {"id": 2}, {"result": 5}]}`))
+	assert.Equal(t, `[{"patient": {"diagnosis": "****"}}]`, redact(`[{"patient": {"diagnosis": "flu"}}]`))
+	assert.Equal(t, `{"visits": {"a": {"notes": "****", "date": "2021"}, "b": {"notes": "****"}}}`, redact(`{"visits": {"a": {"notes": "x", "date": "2021"}, "b": {"notes": "y"}}}`))
+}
+
+func TestHTTPRedactor(t *testing.T) {
+	redact := utils.NewHTTPRedactor("****", []string{"Authorization", "X-Patient-ID"}, []string{"diagnosis"})
+
+	assert.Equal(t, "", redact(""))
+	assert.Equal(t, "GET / HTTP/1.1\r\nHost: example.com\r\nAuthorization: ****\r\n\r\n", redact("GET / HTTP/1.1\r\nHost: example.com\r\nAuthorization: Token 123\r\n\r\n"))
+	assert.Equal(t,
+		"POST / HTTP/1.1\r\nx-patient-id: ****\r\nContent-Type: application/json\r\n\r\n{\"name\": \"Bob\", \"diagnosis\": \"****\"}",
+		redact("POST / HTTP/1.1\r\nx-patient-id: 1234\r\nContent-Type: application/json\r\n\r\n{\"name\": \"Bob\", \"diagnosis\": \"flu\"}"),
+	)
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhello", redact("HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhello"))
+	assert.Equal(t, "HTTP/1.1 200 OK\nAuthorization: ****", redact("HTTP/1.1 200 OK\nAuthorization: xyz"))
+
+	// nothing to redact
+	assert.Equal(t, "GET / HTTP/1.1\r\nAuthorization: xyz\r\n\r\n{\"diagnosis\": \"flu\"}", utils.NewHTTPRedactor("****", nil, nil)("GET / HTTP/1.1\r\nAuthorization: xyz\r\n\r\n{\"diagnosis\": \"flu\"}"))
+}
+
 func TestReplaceEscapedNulls(t *testing.T) {
 	assert.Equal(t, []byte(nil), utils.ReplaceEscapedNulls(nil, []byte(`?`)))
 	assert.Equal(t, []byte(`abcdef`), utils.ReplaceEscapedNulls([]byte(`abc\u0000def`), nil))
@@ -15,6 +15,9 @@ import (
 // our system validator, it can be shared across threads
 var valx = validator.New()
 
+// the tags of each registered alias
+var aliases = map[string]string{}
+
 // ErrorMessageFunc is the type for a function that can convert a field error to user friendly message
 type ErrorMessageFunc func(validator.FieldError) string
 
@@ -58,9 +61,15 @@ func RegisterValidatorTag(tag string, fn validator.Func, message ErrorMessageFun
 func RegisterValidatorAlias(alias, tags string, message ErrorMessageFunc) {
 	valx.RegisterAlias(alias, tags)
 
+	aliases[alias] = tags
 	messageFuncs[alias] = message
 }
 
+// ValidatorAlias gets the tags of the given registered alias, or an empty string if it's not an alias
+func ValidatorAlias(alias string) string {
+	return aliases[alias]
+}
+
 // RegisterStructValidator registers a struct level validator
 func RegisterStructValidator(fn validator.StructLevelFunc, types ...interface{}) {
 	valx.RegisterStructValidation(fn, types...)
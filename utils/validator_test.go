@@ -43,6 +43,9 @@ type NoJSONObject struct {
 func TestValidate(t *testing.T) {
 	utils.RegisterValidatorAlias("two_or_three", "eq=2|eq=3", func(e validator.FieldError) string { return "is not two or three!" })
 
+	assert.Equal(t, "eq=2|eq=3", utils.ValidatorAlias("two_or_three"))
+	assert.Equal(t, "", utils.ValidatorAlias("required"))
+
 	// test with valid object
 	errs := utils.Validate(&TestObject{
 		BaseObject: BaseObject{Foo: "hello"},